@@ -0,0 +1,125 @@
+package controller
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+
+	"github.com/TomeuUris/recipes-catalog/api/v1/view"
+	"github.com/TomeuUris/recipes-catalog/pkg/audit"
+	"github.com/TomeuUris/recipes-catalog/pkg/event"
+	auditRepo "github.com/TomeuUris/recipes-catalog/pkg/repo/audit"
+	"github.com/gin-gonic/gin"
+)
+
+// Headers identifying the writes of a request in the audit log
+const (
+	// ActorHeader names who makes the request, the API has no authentication
+	// so it is trusted as sent by the gateway
+	ActorHeader = "X-Actor"
+	// RequestIDHeader is generated when missing and returned in the response
+	RequestIDHeader = "X-Request-ID"
+)
+
+// maxRequestIDLength bounds the request IDs accepted from clients
+const maxRequestIDLength = 128
+
+// AuditContext adds the actor and request ID of the request to its context.
+// Handlers pass the gin context to the repositories, the engine must have
+// ContextWithFallback set for it to read the request context.
+func AuditContext() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		requestID := ctx.GetHeader(RequestIDHeader)
+		if requestID == "" || len(requestID) > maxRequestIDLength {
+			generated, err := generateRequestID()
+			if err != nil {
+				ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+				return
+			}
+			requestID = generated
+		}
+		ctx.Header(RequestIDHeader, requestID)
+
+		requestCtx := audit.WithRequestID(ctx.Request.Context(), requestID)
+		requestCtx = audit.WithActor(requestCtx, ctx.GetHeader(ActorHeader))
+		ctx.Request = ctx.Request.WithContext(requestCtx)
+		ctx.Next()
+	}
+}
+
+func generateRequestID() (string, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(id), nil
+}
+
+type AuditController struct {
+	repo auditRepo.Repo
+}
+
+func NewAuditController(repo auditRepo.Repo) *AuditController {
+	return &AuditController{repo: repo}
+}
+
+// @Summary Get audit log
+// @Description Retrieves who wrote what and when, oldest first
+// @Tags Audit
+// @Produce  json
+// @Param   filter     query    audit.FindFilter     false        "Filter parameters"
+// @Success 200 {array} view.AuditEntry
+// @Router /audit [get]
+func (c *AuditController) GetAuditHandler(ctx *gin.Context) {
+	// Parse the filter from the query parameters
+	var filter auditRepo.FindFilter
+	if err := ctx.ShouldBindQuery(&filter); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := validateAuditFilter(&filter); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Find the entries in the database
+	entries, err := c.repo.FindByFilter(ctx, &filter)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Convert the entries to a view
+	entryViews := make([]*view.AuditEntry, len(entries))
+	for i, entry := range entries {
+		entryViews[i] = &view.AuditEntry{}
+		entryViews[i].FromEntity(entry)
+	}
+
+	ctx.JSON(http.StatusOK, entryViews)
+}
+
+func validateAuditFilter(f *auditRepo.FindFilter) error {
+	switch f.Entity {
+	case "", event.EntityRecipe, event.EntityIngredient, event.EntityCookingUnit:
+	default:
+		return fmt.Errorf("invalid entity %q, expected %s, %s or %s",
+			f.Entity, event.EntityRecipe, event.EntityIngredient, event.EntityCookingUnit)
+	}
+	if f.EntityID != 0 && f.Entity == "" {
+		return fmt.Errorf("id requires the entity")
+	}
+	if f.Since != nil && f.Until != nil && f.Since.After(*f.Until) {
+		return fmt.Errorf("since must not be after until")
+	}
+	if f.Limit < 0 {
+		return fmt.Errorf("invalid limit %d", f.Limit)
+	}
+	return nil
+}
+
+func SetupAuditRouter(controller *AuditController, router *gin.RouterGroup) *gin.RouterGroup {
+	router.GET("/audit", controller.GetAuditHandler)
+	return router
+}
@@ -0,0 +1,64 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/TomeuUris/recipes-catalog/api/v1/view"
+	"github.com/TomeuUris/recipes-catalog/pkg/sqlite"
+	"github.com/gin-gonic/gin"
+)
+
+type BackupController struct {
+	backups *sqlite.Backups
+}
+
+func NewBackupController(backups *sqlite.Backups) *BackupController {
+	return &BackupController{backups: backups}
+}
+
+// @Summary Create backup
+// @Description Takes a consistent backup of the database while it is in use and removes the backups out of the retention policy
+// @Tags Admin
+// @Produce  json
+// @Success 201 {object} view.Backup
+// @Router /admin/backups [post]
+func (c *BackupController) CreateBackupHandler(ctx *gin.Context) {
+	backup, err := c.backups.Create(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	backupView := &view.Backup{}
+	backupView.FromBackup(backup)
+	ctx.JSON(http.StatusCreated, backupView)
+}
+
+// @Summary Get backups
+// @Description Retrieves the backups of the database, newest first
+// @Tags Admin
+// @Produce  json
+// @Success 200 {array} view.Backup
+// @Router /admin/backups [get]
+func (c *BackupController) GetBackupsHandler(ctx *gin.Context) {
+	backups, err := c.backups.List()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Convert the backups to a view
+	backupViews := make([]*view.Backup, len(backups))
+	for i, backup := range backups {
+		backupViews[i] = &view.Backup{}
+		backupViews[i].FromBackup(backup)
+	}
+
+	ctx.JSON(http.StatusOK, backupViews)
+}
+
+func SetupBackupRouter(controller *BackupController, router *gin.RouterGroup) *gin.RouterGroup {
+	router.POST("/admin/backups", controller.CreateBackupHandler)
+	router.GET("/admin/backups", controller.GetBackupsHandler)
+	return router
+}
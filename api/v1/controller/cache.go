@@ -0,0 +1,46 @@
+package controller
+
+import (
+	"net/http"
+	"sort"
+
+	"github.com/TomeuUris/recipes-catalog/api/v1/view"
+	"github.com/TomeuUris/recipes-catalog/pkg/cache"
+	"github.com/gin-gonic/gin"
+)
+
+type CacheController struct {
+	caches map[string]*cache.LRU
+}
+
+// NewCacheController serves the stats of the caches by the name of their repository
+func NewCacheController(caches map[string]*cache.LRU) *CacheController {
+	return &CacheController{caches: caches}
+}
+
+// @Summary Get cache stats
+// @Description Retrieves the hits, misses and size of the caches of the repositories
+// @Tags Admin
+// @Produce  json
+// @Success 200 {array} view.CacheStats
+// @Router /admin/cache [get]
+func (c *CacheController) GetCacheStatsHandler(ctx *gin.Context) {
+	names := make([]string, 0, len(c.caches))
+	for name := range c.caches {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	statsViews := make([]*view.CacheStats, len(names))
+	for i, name := range names {
+		statsViews[i] = &view.CacheStats{}
+		statsViews[i].FromStats(name, c.caches[name].Stats())
+	}
+
+	ctx.JSON(http.StatusOK, statsViews)
+}
+
+func SetupCacheRouter(controller *CacheController, router *gin.RouterGroup) *gin.RouterGroup {
+	router.GET("/admin/cache", controller.GetCacheStatsHandler)
+	return router
+}
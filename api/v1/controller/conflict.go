@@ -0,0 +1,44 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/TomeuUris/recipes-catalog/api/v1/view"
+	"github.com/TomeuUris/recipes-catalog/pkg/entity"
+	"github.com/gin-gonic/gin"
+)
+
+// respondConflict answers 409 when the error is an *entity.AlreadyExistsError,
+// linking the existing entity of the collection, or an *entity.InUseError. The
+// route is the one of the handler, used to find the base path of the API. It
+// reports whether it answered.
+func respondConflict(ctx *gin.Context, err error, route, collection string) bool {
+	var exists *entity.AlreadyExistsError
+	if errors.As(err, &exists) {
+		base := strings.TrimSuffix(ctx.FullPath(), route)
+		ctx.JSON(http.StatusConflict, view.AlreadyExists{
+			Error: err.Error(),
+			ID:    exists.ID,
+			Href:  fmt.Sprintf("%s/%s/%d", base, collection, exists.ID),
+		})
+		return true
+	}
+
+	var inUse *entity.InUseError
+	if errors.As(err, &inUse) {
+		var inUseView view.InUse
+		inUseView.FromEntity(inUse)
+		ctx.JSON(http.StatusConflict, inUseView)
+		return true
+	}
+
+	// Without details
+	if entity.IsErrAlreadyExists(err) || entity.IsErrInUse(err) {
+		ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return true
+	}
+	return false
+}
@@ -25,7 +25,7 @@ func NewCookingUnitController(repo cooking_unit.Repo) *CookingUnitController {
 // @Tags Cooking Units
 // @Produce  json
 // @Param   filter     query    cooking_unit.FindFilter     true        "Filter parameters"
-// @Success 200 {object} view.CookingUnit
+// @Success 200 {array} view.CookingUnit
 // @Router /cooking-units [get]
 func (c *CookingUnitController) GetCookingUnitByFilterHandler(ctx *gin.Context) {
 	// Parse the filter from the query parameters
@@ -120,7 +120,8 @@ func (c *CookingUnitController) GetCookingUnitByIdHandler(ctx *gin.Context) {
 // @Accept  json
 // @Produce  json
 // @Param   cookingUnit     body    payload.CookingUnit     true        "Cooking unit info"
-// @Success 200 {object} view.CookingUnit
+// @Success 201 {object} view.CookingUnit
+// @Failure 409 {object} view.AlreadyExists
 // @Router /cooking-units [post]
 func (c *CookingUnitController) CreateCookingUnitHandler(ctx *gin.Context) {
 	// Parse the request payload
@@ -133,9 +134,18 @@ func (c *CookingUnitController) CreateCookingUnitHandler(ctx *gin.Context) {
 	// Convert the payload to an entity
 	cookingUnit := cookingUnitPayload.ToEntity()
 
+	// Validate the cooking unit
+	if err := cookingUnit.Validate(); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	// Create the cooking unit in the database
 	err := c.repo.Add(ctx, cookingUnit)
 	if err != nil {
+		if respondConflict(ctx, err, "/cooking-units", "cooking-units") {
+			return
+		}
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -156,6 +166,7 @@ func (c *CookingUnitController) CreateCookingUnitHandler(ctx *gin.Context) {
 // @Param   id     path    int64               true        "Cooking unit ID"
 // @Param   cookingUnit     body    payload.CookingUnit     true        "Cooking unit info"
 // @Success 200 {object} view.CookingUnit
+// @Failure 409 {object} view.AlreadyExists
 // @Router /cooking-units/{id} [patch]
 func (c *CookingUnitController) EditCookingUnitHandler(ctx *gin.Context) {
 	// Get the cooking unit ID from the URL parameter
@@ -186,9 +197,18 @@ func (c *CookingUnitController) EditCookingUnitHandler(ctx *gin.Context) {
 	// Apply the changes to the cooking unit
 	cookingUnitPayload.ApplyTo(targetCookingUnit)
 
+	// Validate the cooking unit
+	if err := targetCookingUnit.Validate(); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	// Update the cooking unit in the database
 	err = c.repo.Edit(ctx, targetCookingUnit)
 	if err != nil {
+		if respondConflict(ctx, err, "/cooking-units/:id", "cooking-units") {
+			return
+		}
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -207,6 +227,7 @@ func (c *CookingUnitController) EditCookingUnitHandler(ctx *gin.Context) {
 // @Accept  json
 // @Produce  json
 // @Param   id     path    int64               true        "Cooking unit ID"
+// @Param   options     query    controller.DeleteOptions     false        "Delete options"
 // @Success 204 "No Content"
 // @Router /cooking-units/{id} [delete]
 func (c *CookingUnitController) DeleteCookingUnitHandler(ctx *gin.Context) {
@@ -218,10 +239,26 @@ func (c *CookingUnitController) DeleteCookingUnitHandler(ctx *gin.Context) {
 		return
 	}
 
+	// Parse the options from the query parameters. Recipes don't reference
+	// cooking units yet, so there is nothing to detach nor blocking the delete.
+	var options DeleteOptions
+	if err := ctx.ShouldBindQuery(&options); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if options.Cascade != "" && options.Cascade != CascadeDetach {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid cascade, expected detach"})
+		return
+	}
+
 	cookingUnitEntity := &entity.CookingUnit{ID: cookingUnitID}
 	// Logic to create the ingredient in the database
 	err = c.repo.Delete(ctx, cookingUnitEntity)
 	if err != nil {
+		if errors.Is(err, entity.ErrNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -0,0 +1,107 @@
+package controller
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/TomeuUris/recipes-catalog/api/v1/view"
+	"github.com/TomeuUris/recipes-catalog/pkg/event"
+	"github.com/gin-contrib/sse"
+	"github.com/gin-gonic/gin"
+)
+
+// keepAliveInterval is how often a comment is sent to keep idle connections open
+const keepAliveInterval = 15 * time.Second
+
+type EventController struct {
+	hub *event.Hub
+}
+
+func NewEventController(hub *event.Hub) *EventController {
+	return &EventController{hub: hub}
+}
+
+// @Summary Stream catalog changes
+// @Description Streams created/updated/deleted events for recipes, ingredients and cooking units as Server-Sent Events.
+// @Description Send the Last-Event-ID header (or the last_event_id query parameter) to resume after a disconnection.
+// @Description A "reset" event is sent when the requested events are no longer available and the client must reload its data.
+// @Tags Events
+// @Produce  text/event-stream
+// @Param   Last-Event-ID     header    int     false        "ID of the last event received"
+// @Param   last_event_id     query    int     false        "ID of the last event received"
+// @Success 200 {object} view.Event
+// @Router /events [get]
+func (c *EventController) StreamEventsHandler(ctx *gin.Context) {
+	// Get the last event received by the client
+	lastEventIDStr := ctx.GetHeader("Last-Event-ID")
+	if lastEventIDStr == "" {
+		lastEventIDStr = ctx.Query("last_event_id")
+	}
+	lastEventID := event.NoReplay
+	if lastEventIDStr != "" {
+		var err error
+		lastEventID, err = strconv.ParseInt(lastEventIDStr, 10, 64)
+		if err != nil || lastEventID < 0 {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid Last-Event-ID"})
+			return
+		}
+	}
+
+	// Subscribe before replaying so no event is lost in between
+	sub, missed, ok := c.hub.Subscribe(lastEventID)
+	defer sub.Cancel()
+
+	ctx.Header("Content-Type", "text/event-stream")
+	ctx.Header("Cache-Control", "no-cache")
+	ctx.Header("Connection", "keep-alive")
+	ctx.Header("X-Accel-Buffering", "no")
+	ctx.Status(http.StatusOK)
+
+	if !ok {
+		ctx.Render(-1, sse.Event{
+			Id:    strconv.FormatInt(sub.StartID, 10),
+			Event: "reset",
+			Data:  gin.H{"last_event_id": sub.StartID},
+		})
+	}
+	for _, e := range missed {
+		writeEvent(ctx, e)
+	}
+	ctx.Writer.Flush()
+
+	ticker := time.NewTicker(keepAliveInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Request.Context().Done():
+			return
+		case e, open := <-sub.C:
+			if !open {
+				// The subscriber fell behind, the client will reconnect with its Last-Event-ID
+				return
+			}
+			writeEvent(ctx, e)
+			ctx.Writer.Flush()
+		case <-ticker.C:
+			fmt.Fprint(ctx.Writer, ": keep-alive\n\n")
+			ctx.Writer.Flush()
+		}
+	}
+}
+
+func writeEvent(ctx *gin.Context, e *event.Event) {
+	eventView := &view.Event{}
+	eventView.FromEvent(e)
+	ctx.Render(-1, sse.Event{
+		Id:    strconv.FormatInt(e.ID, 10),
+		Event: e.Name(),
+		Data:  eventView,
+	})
+}
+
+func SetupEventsRouter(controller *EventController, router *gin.RouterGroup) *gin.RouterGroup {
+	router.GET("/events", controller.StreamEventsHandler)
+	return router
+}
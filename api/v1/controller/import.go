@@ -0,0 +1,69 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/TomeuUris/recipes-catalog/api/v1/payload"
+	"github.com/TomeuUris/recipes-catalog/api/v1/view"
+	"github.com/TomeuUris/recipes-catalog/pkg/importer"
+	"github.com/gin-gonic/gin"
+)
+
+type ImportController struct {
+	importer *importer.Importer
+}
+
+func NewImportController(importer *importer.Importer) *ImportController {
+	return &ImportController{importer: importer}
+}
+
+// @Summary Import recipe
+// @Description Makes a draft recipe from the schema.org Recipe JSON-LD or microdata of an HTML page, uploaded as a text/html body or fetched from the URL of a JSON body. Ingredient lines are matched to the ingredients and cooking units, the unmatched ones are reported. The draft isn't stored.
+// @Tags recipes
+// @Accept  json
+// @Accept  html
+// @Produce  json
+// @Param   page     body    payload.RecipeImport     false        "URL of the page, unless the page is uploaded"
+// @Success 200 {object} view.RecipeDraft
+// @Router /recipes/import [post]
+func (c *ImportController) ImportRecipeHandler(ctx *gin.Context) {
+	var draft *importer.Draft
+	var err error
+	switch ctx.ContentType() {
+	case "text/html", "application/xhtml+xml":
+		draft, err = c.importer.ImportHTML(ctx, ctx.Request.Body)
+	default:
+		var importPayload payload.RecipeImport
+		if err := ctx.ShouldBindJSON(&importPayload); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		draft, err = c.importer.ImportURL(ctx, importPayload.URL)
+	}
+	if err != nil {
+		switch {
+		case errors.Is(err, importer.ErrInvalidURL):
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, importer.ErrTooLarge):
+			ctx.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": err.Error()})
+		case errors.Is(err, importer.ErrNoRecipe):
+			ctx.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		case errors.Is(err, importer.ErrFetch):
+			ctx.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+		default:
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	draftView := &view.RecipeDraft{}
+	draftView.FromDraft(draft)
+	ctx.JSON(http.StatusOK, draftView)
+}
+
+// SetupImportRouter sets up the routes for the import endpoints
+func SetupImportRouter(controller *ImportController, router *gin.RouterGroup) *gin.RouterGroup {
+	router.POST("/recipes/import", controller.ImportRecipeHandler)
+	return router
+}
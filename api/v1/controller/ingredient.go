@@ -20,12 +20,21 @@ func NewIngredientController(repo ingredient.Repo) *IngredientController {
 	return &IngredientController{repo: repo}
 }
 
+// CascadeDetach removes the deleted entity from the recipes using it
+const CascadeDetach = "detach"
+
+// DeleteOptions selects what happens to the recipes using a deleted entity
+type DeleteOptions struct {
+	// Cascade is empty to refuse deleting entities in use, or detach
+	Cascade string `form:"cascade"`
+}
+
 // @Summary Get ingredient by filter
 // @Description Retrieves a list of ingredients filtered by the given parameters
 // @Tags Ingredients
 // @Produce  json
 // @Param   filter     query    ingredient.FindFilter     true        "Filter parameters"
-// @Success 200 {object} view.Ingredient
+// @Success 200 {array} view.Ingredient
 // @Router /ingredients [get]
 func (c *IngredientController) GetIngredientByFilterHandler(ctx *gin.Context) {
 	// Parse the filter from the query parameters
@@ -120,7 +129,8 @@ func (c *IngredientController) GetIngredientByIdHandler(ctx *gin.Context) {
 // @Accept  json
 // @Produce  json
 // @Param   ingredient     body    payload.Ingredient     true        "Ingredient info"
-// @Success 200 {object} view.Ingredient
+// @Success 201 {object} view.Ingredient
+// @Failure 409 {object} view.AlreadyExists
 // @Router /ingredients [post]
 func (c *IngredientController) CreateIngredientHandler(ctx *gin.Context) {
 	// Parse the request payload
@@ -133,9 +143,18 @@ func (c *IngredientController) CreateIngredientHandler(ctx *gin.Context) {
 	// Convert the payload to an entity
 	ingredient := ingredientPayload.ToEntity()
 
+	// Validate the ingredient
+	if err := ingredient.Validate(); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	// Create the ingredient in the database
 	err := c.repo.Add(ctx, ingredient)
 	if err != nil {
+		if respondConflict(ctx, err, "/ingredients", "ingredients") {
+			return
+		}
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -156,6 +175,7 @@ func (c *IngredientController) CreateIngredientHandler(ctx *gin.Context) {
 // @Param   id     path    int64               true        "Ingredient ID"
 // @Param   ingredient     body    payload.Ingredient     true        "Ingredient info"
 // @Success 200 {object} view.Ingredient
+// @Failure 409 {object} view.AlreadyExists
 // @Router /ingredients/{id} [patch]
 func (c *IngredientController) EditIngredientHandler(ctx *gin.Context) {
 	// Get the ingredient ID from the URL parameter
@@ -186,9 +206,18 @@ func (c *IngredientController) EditIngredientHandler(ctx *gin.Context) {
 	// Apply the changes to the ingredient
 	ingredientPayload.ApplyTo(targetIngredient)
 
+	// Validate the ingredient
+	if err := targetIngredient.Validate(); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	// Update the ingredient in the database
 	err = c.repo.Edit(ctx, targetIngredient)
 	if err != nil {
+		if respondConflict(ctx, err, "/ingredients/:id", "ingredients") {
+			return
+		}
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -207,7 +236,9 @@ func (c *IngredientController) EditIngredientHandler(ctx *gin.Context) {
 // @Accept  json
 // @Produce  json
 // @Param   id     path    int64               true        "Ingredient ID"
+// @Param   options     query    controller.DeleteOptions     false        "Delete options"
 // @Success 204 "No Content"
+// @Failure 409 {object} view.InUse
 // @Router /ingredients/{id} [delete]
 func (c *IngredientController) DeleteIngredientHandler(ctx *gin.Context) {
 	// Get the ingredient ID from the URL parameter
@@ -218,10 +249,32 @@ func (c *IngredientController) DeleteIngredientHandler(ctx *gin.Context) {
 		return
 	}
 
+	// Parse the options from the query parameters
+	var options DeleteOptions
+	if err := ctx.ShouldBindQuery(&options); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	ingredientEntity := &entity.Ingredient{ID: ingredientID}
-	// Logic to create the ingredient in the database
-	err = c.repo.Delete(ctx, ingredientEntity)
+	// Delete the ingredient from the database
+	switch options.Cascade {
+	case "":
+		err = c.repo.Delete(ctx, ingredientEntity)
+	case CascadeDetach:
+		err = c.repo.DetachAndDelete(ctx, ingredientEntity)
+	default:
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid cascade, expected detach"})
+		return
+	}
 	if err != nil {
+		if errors.Is(err, entity.ErrNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if respondConflict(ctx, err, "/ingredients/:id", "ingredients") {
+			return
+		}
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -230,12 +283,117 @@ func (c *IngredientController) DeleteIngredientHandler(ctx *gin.Context) {
 	ctx.JSON(http.StatusNoContent, nil)
 }
 
+// DuplicatesFilter selects the ingredients reported as likely duplicates
+type DuplicatesFilter struct {
+	// MinSimilarity of the names between 0 and 1, ingredient.DefaultMinSimilarity by default
+	MinSimilarity float64 `form:"min_similarity"`
+}
+
+// @Summary Get likely duplicated ingredients
+// @Description Groups the ingredients whose normalized names are similar
+// @Tags Ingredients
+// @Produce  json
+// @Param   filter     query    controller.DuplicatesFilter     false        "Filter parameters"
+// @Success 200 {array} view.DuplicateGroup
+// @Router /ingredients/duplicates [get]
+func (c *IngredientController) GetIngredientDuplicatesHandler(ctx *gin.Context) {
+	// Parse the filter from the query parameters
+	var filter DuplicatesFilter
+	if err := ctx.ShouldBindQuery(&filter); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if filter.MinSimilarity == 0 {
+		filter.MinSimilarity = ingredient.DefaultMinSimilarity
+	}
+	if filter.MinSimilarity < 0 || filter.MinSimilarity > 1 {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid min_similarity, expected a number between 0 and 1"})
+		return
+	}
+
+	// Find every ingredient in the database
+	ingredients, err := c.repo.FindByFilter(ctx, &ingredient.FindFilter{})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Group the duplicates and convert them to views
+	groups := ingredient.FindDuplicates(ingredients, filter.MinSimilarity)
+	groupViews := make([]view.DuplicateGroup, len(groups))
+	for i, group := range groups {
+		groupViews[i].FromEntity(group)
+	}
+
+	ctx.JSON(http.StatusOK, groupViews)
+}
+
+// @Summary Merge ingredients
+// @Description Makes the recipes using the duplicates use the ingredient instead and deletes the duplicates
+// @Tags Ingredients
+// @Accept  json
+// @Produce  json
+// @Param   id     path    int64               true        "Ingredient ID"
+// @Param   merge     body    payload.IngredientMerge     true        "Duplicated ingredients"
+// @Success 200 {object} view.Ingredient
+// @Router /ingredients/{id}/merge [post]
+func (c *IngredientController) MergeIngredientHandler(ctx *gin.Context) {
+	// Get the ingredient ID from the URL parameter
+	ingredientIDStr := ctx.Params.ByName("id")
+	ingredientID, err := strconv.ParseInt(ingredientIDStr, 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ingredient ID"})
+		return
+	}
+	// Parse the request payload
+	var mergePayload payload.IngredientMerge
+	if err := ctx.ShouldBindJSON(&mergePayload); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Find the ingredient in the database
+	targetIngredient, err := c.repo.FindByID(ctx, int(ingredientID))
+	if err != nil {
+		if errors.Is(err, entity.ErrNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Merge the duplicates into it
+	err = c.repo.Merge(ctx, targetIngredient, mergePayload.IDs)
+	if err != nil {
+		if errors.Is(err, entity.ErrInvalidID) {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, entity.ErrNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Convert the ingredient to a view
+	ingredientView := &view.Ingredient{}
+	ingredientView.FromEntity(targetIngredient)
+
+	// Return the merged ingredient as a response
+	ctx.JSON(http.StatusOK, ingredientView)
+}
+
 func SetupIngredientsRouter(controller *IngredientController, router *gin.RouterGroup) *gin.RouterGroup {
 	router.GET("/ingredients", controller.GetIngredientByFilterHandler)
 	router.POST("/ingredients", controller.CreateIngredientHandler)
 	router.GET("/ingredients/count", controller.CountIngredientByFilterHandler)
+	router.GET("/ingredients/duplicates", controller.GetIngredientDuplicatesHandler)
 	router.GET("/ingredients/:id", controller.GetIngredientByIdHandler)
 	router.PATCH("/ingredients/:id", controller.EditIngredientHandler)
 	router.DELETE("/ingredients/:id", controller.DeleteIngredientHandler)
+	router.POST("/ingredients/:id/merge", controller.MergeIngredientHandler)
 	return router
 }
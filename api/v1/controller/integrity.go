@@ -0,0 +1,74 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/TomeuUris/recipes-catalog/api/v1/view"
+	"github.com/TomeuUris/recipes-catalog/pkg/doctor"
+	"github.com/gin-gonic/gin"
+)
+
+// RepairOptions selects how the issues are repaired
+type RepairOptions struct {
+	// DryRun reports the repairs without making them
+	DryRun bool `form:"dry_run"`
+}
+
+type IntegrityController struct {
+	doctor *doctor.Doctor
+}
+
+// NewIntegrityController checks the database with the doctor, which repairs it
+// in the units of work of the server so the caches drop the repaired recipes
+func NewIntegrityController(doctor *doctor.Doctor) *IntegrityController {
+	return &IntegrityController{doctor: doctor}
+}
+
+// @Summary Check integrity
+// @Description Checks the database for orphaned steps, gaps or duplicates in the step numbers, recipes using deleted ingredients, recipes without steps and schema drift
+// @Tags Admin
+// @Produce  json
+// @Success 200 {object} view.IntegrityReport
+// @Router /admin/integrity [get]
+func (c *IntegrityController) CheckHandler(ctx *gin.Context) {
+	report, err := c.doctor.Check(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	reportView := &view.IntegrityReport{}
+	reportView.FromReport(report)
+	ctx.JSON(http.StatusOK, reportView)
+}
+
+// @Summary Repair integrity
+// @Description Checks the database and repairs the issues that can be repaired, in a transaction rolled back on dry runs. Schema drift is only reported.
+// @Tags Admin
+// @Produce  json
+// @Param   options     query    controller.RepairOptions     false        "Repair options"
+// @Success 200 {object} view.IntegrityReport
+// @Router /admin/integrity/repair [post]
+func (c *IntegrityController) RepairHandler(ctx *gin.Context) {
+	var options RepairOptions
+	if err := ctx.ShouldBindQuery(&options); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	report, err := c.doctor.Repair(ctx, options.DryRun)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	reportView := &view.IntegrityReport{}
+	reportView.FromReport(report)
+	ctx.JSON(http.StatusOK, reportView)
+}
+
+func SetupIntegrityRouter(controller *IntegrityController, router *gin.RouterGroup) *gin.RouterGroup {
+	router.GET("/admin/integrity", controller.CheckHandler)
+	router.POST("/admin/integrity/repair", controller.RepairHandler)
+	return router
+}
@@ -3,21 +3,38 @@ package controller
 import (
 	"errors"
 	"net/http"
+	"path"
 	"strconv"
 
 	"github.com/TomeuUris/recipes-catalog/api/v1/payload"
 	"github.com/TomeuUris/recipes-catalog/api/v1/view"
 	"github.com/TomeuUris/recipes-catalog/pkg/entity"
+	"github.com/TomeuUris/recipes-catalog/pkg/publicid"
 	"github.com/TomeuUris/recipes-catalog/pkg/repo/recipe"
+	"github.com/TomeuUris/recipes-catalog/pkg/repo/transaction"
+	"github.com/TomeuUris/recipes-catalog/pkg/schemaorg"
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
 )
 
 type RecipeController struct {
 	repo recipe.Repo
+	// tx runs the writes creating ingredients along with the recipe
+	tx transaction.Manager
+	// publicIDs identifies the recipes instead of their IDs, nil to use the IDs
+	publicIDs *publicid.Codec
 }
 
-func NewRecipeController(repo recipe.Repo) *RecipeController {
-	return &RecipeController{repo: repo}
+func NewRecipeController(repo recipe.Repo, tx transaction.Manager) *RecipeController {
+	return &RecipeController{repo: repo, tx: tx}
+}
+
+// SetPublicIDs makes the recipes identified by their public IDs encoded with
+// the codec. Views hide the IDs, and the /recipes/:id paths and the id
+// filter don't accept them. Only this controller uses them: conflicts,
+// events, webhooks, the audit log and gRPC keep the IDs.
+func (c *RecipeController) SetPublicIDs(codec *publicid.Codec) {
+	c.publicIDs = codec
 }
 
 // @Summary Get recipes by filter
@@ -29,14 +46,18 @@ func NewRecipeController(repo recipe.Repo) *RecipeController {
 // @Router /recipes [get]
 func (c *RecipeController) GetRecipesByFilterHandler(ctx *gin.Context) {
 	// Parse the request query
-	var filter recipe.FindFilter
-	if err := ctx.ShouldBindQuery(&filter); err != nil {
+	filter, err := c.bindFilter(ctx)
+	if errors.Is(err, errInvalidRecipeID) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid recipe ID"})
+		return
+	}
+	if err != nil {
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
 	// Get the recipes from the database
-	recipes, err := c.repo.FindByFilter(ctx, &filter)
+	recipes, err := c.repo.FindByFilter(ctx, filter)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -45,9 +66,7 @@ func (c *RecipeController) GetRecipesByFilterHandler(ctx *gin.Context) {
 	// Convert the recipes to the view model
 	recipesView := make([]*view.Recipe, len(recipes))
 	for i, recipe := range recipes {
-		recipeView := &view.Recipe{}
-		recipeView.FromEntity(recipe)
-		recipesView[i] = recipeView
+		recipesView[i] = c.view(recipe)
 	}
 
 	// Return the recipes as a response
@@ -55,16 +74,16 @@ func (c *RecipeController) GetRecipesByFilterHandler(ctx *gin.Context) {
 }
 
 // @Summary Get recipe by ID
-// @Description Retrieves a recipe by ID
+// @Description Retrieves a recipe by ID, as schema.org JSON-LD when the Accept header asks for application/ld+json
 // @Tags recipes
 // @Produce  json
+// @Produce  application/ld+json
 // @Param   id     path    int     true        "recipe ID"
 // @Success 200 {object} view.Recipe
 // @Router /recipes/{id} [get]
 func (c *RecipeController) GetRecipeByIdHandler(ctx *gin.Context) {
 	// Get the recipe ID from the URL parameter
-	recipeIDStr := ctx.Params.ByName("id")
-	recipeID, err := strconv.ParseInt(recipeIDStr, 10, 64)
+	recipeID, err := c.parseID(ctx.Params.ByName("id"))
 	if err != nil {
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid recipe ID"})
 		return
@@ -82,11 +101,68 @@ func (c *RecipeController) GetRecipeByIdHandler(ctx *gin.Context) {
 		return
 	}
 
-	recipeView := &view.Recipe{}
-	recipeView.FromEntity(recipe)
-
 	// Return the recipes as a response
-	ctx.JSON(http.StatusOK, recipeView)
+	c.respond(ctx, recipe)
+}
+
+// @Summary Get recipe by slug
+// @Description Retrieves a recipe by slug, previous slugs redirect to the current one
+// @Tags recipes
+// @Produce  json
+// @Produce  application/ld+json
+// @Param   slug     path    string     true        "recipe slug"
+// @Success 200 {object} view.Recipe
+// @Success 301
+// @Router /recipes/by-slug/{slug} [get]
+func (c *RecipeController) GetRecipeBySlugHandler(ctx *gin.Context) {
+	slug := ctx.Params.ByName("slug")
+	recipe, err := c.repo.FindBySlug(ctx, slug)
+	if err != nil {
+		if errors.Is(err, entity.ErrNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Links to a previous name moved to the current one
+	if recipe.Slug != slug {
+		ctx.Header("Location", path.Dir(ctx.Request.URL.Path)+"/"+recipe.Slug)
+		ctx.Status(http.StatusMovedPermanently)
+		return
+	}
+
+	c.respond(ctx, recipe)
+}
+
+// @Summary Export recipes as JSON-LD
+// @Description Exports the recipes matching the filter as a schema.org JSON-LD graph of Recipes
+// @Tags recipes
+// @Produce  application/ld+json
+// @Param   filter     query    recipe.FindFilter     true        "Filter parameters"
+// @Success 200 {object} schemaorg.Graph
+// @Router /recipes/export [get]
+func (c *RecipeController) ExportRecipesHandler(ctx *gin.Context) {
+	filter, err := c.bindFilter(ctx)
+	if errors.Is(err, errInvalidRecipeID) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid recipe ID"})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	recipes, err := c.repo.FindByFilter(ctx, filter)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.Header("Content-Type", schemaorg.MIMEType)
+	ctx.Header("Content-Disposition", `attachment; filename="recipes.jsonld"`)
+	ctx.JSON(http.StatusOK, schemaorg.NewGraph(recipes))
 }
 
 // @Summary Count recipes by filter
@@ -98,14 +174,18 @@ func (c *RecipeController) GetRecipeByIdHandler(ctx *gin.Context) {
 // @Router /recipes/count [get]
 func (c *RecipeController) CountRecipeByFilterHandler(ctx *gin.Context) {
 	// Parse the filter from the query parameters
-	var filter recipe.FindFilter
-	if err := ctx.ShouldBindQuery(&filter); err != nil {
+	filter, err := c.bindFilter(ctx)
+	if errors.Is(err, errInvalidRecipeID) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid recipe ID"})
+		return
+	}
+	if err != nil {
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
 	// Count the recipes in the database
-	count, err := c.repo.CountByFilter(ctx, &filter)
+	count, err := c.repo.CountByFilter(ctx, filter)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -121,7 +201,8 @@ func (c *RecipeController) CountRecipeByFilterHandler(ctx *gin.Context) {
 // @Accept  json
 // @Produce  json
 // @Param   recipe     body    payload.Recipe     true        "Recipe info"
-// @Success 200 {object} view.Recipe
+// @Success 201 {object} view.Recipe
+// @Failure 409 {object} view.AlreadyExists
 // @Router /recipes [post]
 func (c *RecipeController) CreateRecipeHandler(ctx *gin.Context) {
 	// Parse the request payload
@@ -131,18 +212,25 @@ func (c *RecipeController) CreateRecipeHandler(ctx *gin.Context) {
 		return
 	}
 	recipe := recipePayload.ToEntity()
-	// Logic to create the recipe in the database
-	err := c.repo.Add(ctx, recipe)
-	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+
+	// Validate the recipe
+	if err := recipe.Validate(); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	recipeView := &view.Recipe{}
-	recipeView.FromEntity(recipe)
+	// Create the recipe and its new ingredients in one transaction
+	if err := transaction.AddRecipe(ctx, c.tx, recipe); err != nil {
+		// A new ingredient of the recipe has a taken name
+		if respondConflict(ctx, err, "/recipes", "ingredients") {
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
 
 	// Return the created recipe as a response
-	ctx.JSON(http.StatusCreated, recipeView)
+	ctx.JSON(http.StatusCreated, c.view(recipe))
 }
 
 // @Summary Edit recipe
@@ -153,11 +241,11 @@ func (c *RecipeController) CreateRecipeHandler(ctx *gin.Context) {
 // @Param   id     path    int     true        "recipe ID"
 // @Param   recipe     body    payload.Recipe     true        "Recipe info"
 // @Success 200 {object} view.Recipe
+// @Failure 409 {object} view.AlreadyExists
 // @Router /recipes/{id} [patch]
 func (c *RecipeController) EditRecipeHandler(ctx *gin.Context) {
 	// Get the recipe ID from the URL parameter
-	recipeIDStr := ctx.Params.ByName("id")
-	recipeID, err := strconv.ParseInt(recipeIDStr, 10, 64)
+	recipeID, err := c.parseID(ctx.Params.ByName("id"))
 	if err != nil {
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid recipe ID"})
 		return
@@ -184,19 +272,24 @@ func (c *RecipeController) EditRecipeHandler(ctx *gin.Context) {
 	// Update the recipe entity
 	recipePayload.ApplyTo(targetRecipe)
 
-	// Update the recipe in the database
-	err = c.repo.Edit(ctx, targetRecipe)
-	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	// Validate the recipe
+	if err := targetRecipe.Validate(); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	// Convert the recipe to a view
-	recipeView := &view.Recipe{}
-	recipeView.FromEntity(targetRecipe)
+	// Update the recipe and create its new ingredients in one transaction
+	if err := transaction.EditRecipe(ctx, c.tx, targetRecipe); err != nil {
+		// A new ingredient of the recipe has a taken name
+		if respondConflict(ctx, err, "/recipes/:id", "ingredients") {
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
 
 	// Return the updated recipe as a response
-	ctx.JSON(http.StatusOK, recipeView)
+	ctx.JSON(http.StatusOK, c.view(targetRecipe))
 }
 
 // @Summary Delete recipe
@@ -209,8 +302,7 @@ func (c *RecipeController) EditRecipeHandler(ctx *gin.Context) {
 // @Router /recipes/{id} [delete]
 func (c *RecipeController) DeleteRecipeHandler(ctx *gin.Context) {
 	// Get the recipe ID from the URL parameter
-	recipeIDStr := ctx.Params.ByName("id")
-	recipeID, err := strconv.ParseInt(recipeIDStr, 10, 64)
+	recipeID, err := c.parseID(ctx.Params.ByName("id"))
 	if err != nil {
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid recipe ID"})
 		return
@@ -238,11 +330,73 @@ func (c *RecipeController) DeleteRecipeHandler(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, nil)
 }
 
+// parseID returns the ID of a recipe path parameter, its public ID when they
+// are enabled
+func (c *RecipeController) parseID(param string) (int64, error) {
+	if c.publicIDs != nil {
+		return c.publicIDs.Decode(param)
+	}
+	return strconv.ParseInt(param, 10, 64)
+}
+
+// errInvalidRecipeID is returned by bindFilter when the id of the filter
+// isn't a public ID
+var errInvalidRecipeID = errors.New("invalid recipe ID")
+
+// bindFilter binds the filter of the query string, where the id is a public
+// ID when they are enabled
+func (c *RecipeController) bindFilter(ctx *gin.Context) (*recipe.FindFilter, error) {
+	filter := &recipe.FindFilter{}
+	if c.publicIDs == nil {
+		return filter, ctx.ShouldBindQuery(filter)
+	}
+
+	query := ctx.Request.URL.Query()
+	if param := query.Get("id"); param != "" {
+		id, err := c.publicIDs.Decode(param)
+		if err != nil {
+			return nil, errInvalidRecipeID
+		}
+		filter.Id = int(id)
+	}
+	query.Del("id")
+	return filter, binding.MapFormWithTag(filter, query, "form")
+}
+
+// view returns the view of the recipe, identified by its public ID when they
+// are enabled
+func (c *RecipeController) view(recipe *entity.Recipe) *view.Recipe {
+	recipeView := &view.Recipe{}
+	recipeView.FromEntity(recipe)
+	if c.publicIDs != nil {
+		recipeView.ID = 0
+		recipeView.PublicID = c.publicIDs.Encode(recipe.ID)
+	}
+	return recipeView
+}
+
+// respond writes the view of the recipe, or its schema.org JSON-LD when the
+// client prefers it
+func (c *RecipeController) respond(ctx *gin.Context, recipe *entity.Recipe) {
+	ctx.Header("Vary", "Accept")
+	if ctx.NegotiateFormat(gin.MIMEJSON, schemaorg.MIMEType) == schemaorg.MIMEType {
+		document := &schemaorg.Recipe{}
+		document.FromEntity(recipe)
+		// JSON keeps a content type already set
+		ctx.Header("Content-Type", schemaorg.MIMEType)
+		ctx.JSON(http.StatusOK, document)
+		return
+	}
+	ctx.JSON(http.StatusOK, c.view(recipe))
+}
+
 // SetupRecipesRouter sets up the routes for the recipes endpoints
 func SetupRecipesRouter(controller *RecipeController, router *gin.RouterGroup) *gin.RouterGroup {
 	router.POST("/recipes", controller.CreateRecipeHandler)
 	router.GET("/recipes", controller.GetRecipesByFilterHandler)
 	router.GET("/recipes/count", controller.CountRecipeByFilterHandler)
+	router.GET("/recipes/export", controller.ExportRecipesHandler)
+	router.GET("/recipes/by-slug/:slug", controller.GetRecipeBySlugHandler)
 	router.GET("/recipes/:id", controller.GetRecipeByIdHandler)
 	router.PATCH("/recipes/:id", controller.EditRecipeHandler)
 	router.DELETE("/recipes/:id", controller.DeleteRecipeHandler)
@@ -0,0 +1,47 @@
+package controller
+
+import "github.com/gin-gonic/gin"
+
+// Controllers groups every controller served by the API
+type Controllers struct {
+	Ingredients  *IngredientController
+	Recipes      *RecipeController
+	CookingUnits *CookingUnitController
+	Events       *EventController
+	Webhooks     *WebhookController
+	Audit        *AuditController
+	Import       *ImportController
+	// Trash is nil when the backend doesn't soft delete
+	Trash *TrashController
+	// Backups is nil when the backend has no database file
+	Backups *BackupController
+	// Cache is nil when the repositories aren't cached
+	Cache *CacheController
+	// Integrity is nil when the backend has no database file
+	Integrity *IntegrityController
+}
+
+// SetupRouter sets up the routes of every controller
+func SetupRouter(controllers *Controllers, router *gin.RouterGroup) *gin.RouterGroup {
+	router.Use(AuditContext())
+	router = SetupIngredientsRouter(controllers.Ingredients, router)
+	router = SetupRecipesRouter(controllers.Recipes, router)
+	router = SetupCookingUnitsRouter(controllers.CookingUnits, router)
+	router = SetupEventsRouter(controllers.Events, router)
+	router = SetupWebhooksRouter(controllers.Webhooks, router)
+	router = SetupAuditRouter(controllers.Audit, router)
+	router = SetupImportRouter(controllers.Import, router)
+	if controllers.Trash != nil {
+		router = SetupTrashRouter(controllers.Trash, router)
+	}
+	if controllers.Backups != nil {
+		router = SetupBackupRouter(controllers.Backups, router)
+	}
+	if controllers.Cache != nil {
+		router = SetupCacheRouter(controllers.Cache, router)
+	}
+	if controllers.Integrity != nil {
+		router = SetupIntegrityRouter(controllers.Integrity, router)
+	}
+	return router
+}
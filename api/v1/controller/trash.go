@@ -0,0 +1,200 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/TomeuUris/recipes-catalog/api/v1/view"
+	"github.com/TomeuUris/recipes-catalog/pkg/entity"
+	"github.com/TomeuUris/recipes-catalog/pkg/repo/ingredient"
+	"github.com/TomeuUris/recipes-catalog/pkg/repo/recipe"
+	"github.com/TomeuUris/recipes-catalog/pkg/repo/transaction"
+	"github.com/gin-gonic/gin"
+)
+
+// DefaultTrashRetention is how long deleted entities are kept by default
+const DefaultTrashRetention = 30 * 24 * time.Hour
+
+type TrashController struct {
+	recipes     recipe.TrashRepo
+	ingredients ingredient.TrashRepo
+	// tx purges both trashes in one unit of work
+	tx        transaction.Manager
+	retention time.Duration
+}
+
+func NewTrashController(recipes recipe.TrashRepo, ingredients ingredient.TrashRepo, tx transaction.Manager, retention time.Duration) *TrashController {
+	return &TrashController{recipes: recipes, ingredients: ingredients, tx: tx, retention: retention}
+}
+
+// TrashFilter selects the kind of entities listed from the trash
+type TrashFilter struct {
+	Entity string `form:"entity"`
+}
+
+// PurgeFilter selects the entities purged from the trash
+type PurgeFilter struct {
+	// OlderThan is a duration like 720h, the retention period by default
+	OlderThan string `form:"older_than"`
+}
+
+// @Summary Get trash
+// @Description Retrieves the deleted recipes and ingredients, most recently deleted first
+// @Tags Trash
+// @Produce  json
+// @Param   filter     query    controller.TrashFilter     false        "Filter parameters"
+// @Success 200 {array} view.TrashItem
+// @Router /trash [get]
+func (c *TrashController) GetTrashHandler(ctx *gin.Context) {
+	// Parse the filter from the query parameters
+	var filter TrashFilter
+	if err := ctx.ShouldBindQuery(&filter); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var items []*entity.TrashItem
+	switch filter.Entity {
+	case "", entity.TrashRecipe, entity.TrashIngredient:
+	default:
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid entity, expected recipe or ingredient"})
+		return
+	}
+
+	// Find the deleted entities in the database
+	if filter.Entity == "" || filter.Entity == entity.TrashRecipe {
+		recipes, err := c.recipes.FindDeleted(ctx)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		items = append(items, recipes...)
+	}
+	if filter.Entity == "" || filter.Entity == entity.TrashIngredient {
+		ingredients, err := c.ingredients.FindDeleted(ctx)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		items = append(items, ingredients...)
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].DeletedAt.After(items[j].DeletedAt)
+	})
+
+	// Convert the entities to a view
+	itemViews := make([]*view.TrashItem, len(items))
+	for i, item := range items {
+		itemViews[i] = &view.TrashItem{}
+		itemViews[i].FromEntity(item)
+	}
+
+	ctx.JSON(http.StatusOK, itemViews)
+}
+
+// @Summary Restore recipe
+// @Description Restores a deleted recipe together with its steps
+// @Tags Trash
+// @Param   id     path    int     true        "Recipe ID"
+// @Success 204 "No Content"
+// @Router /trash/recipes/{id}/restore [post]
+func (c *TrashController) RestoreRecipeHandler(ctx *gin.Context) {
+	recipeID, err := strconv.ParseInt(ctx.Params.ByName("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid recipe ID"})
+		return
+	}
+
+	c.restore(ctx, c.recipes.Restore(ctx, recipeID))
+}
+
+// @Summary Restore ingredient
+// @Description Restores a deleted ingredient
+// @Tags Trash
+// @Param   id     path    int     true        "Ingredient ID"
+// @Success 204 "No Content"
+// @Failure 409 {object} view.AlreadyExists
+// @Router /trash/ingredients/{id}/restore [post]
+func (c *TrashController) RestoreIngredientHandler(ctx *gin.Context) {
+	ingredientID, err := strconv.ParseInt(ctx.Params.ByName("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ingredient ID"})
+		return
+	}
+
+	c.restore(ctx, c.ingredients.Restore(ctx, ingredientID))
+}
+
+func (c *TrashController) restore(ctx *gin.Context, err error) {
+	if err != nil {
+		if errors.Is(err, entity.ErrNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		// Another ingredient took its name meanwhile
+		if respondConflict(ctx, err, "/trash/ingredients/:id/restore", "ingredients") {
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// @Summary Purge trash
+// @Description Permanently removes the entities deleted longer ago than the retention period
+// @Tags Trash
+// @Produce  json
+// @Param   filter     query    controller.PurgeFilter     false        "Filter parameters"
+// @Success 200 {object} view.PurgeResult
+// @Router /trash [delete]
+func (c *TrashController) PurgeTrashHandler(ctx *gin.Context) {
+	// Parse the filter from the query parameters
+	var filter PurgeFilter
+	if err := ctx.ShouldBindQuery(&filter); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	retention := c.retention
+	if filter.OlderThan != "" {
+		var err error
+		retention, err = time.ParseDuration(filter.OlderThan)
+		if err != nil || retention < 0 {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid older_than %q", filter.OlderThan)})
+			return
+		}
+	}
+
+	result, err := PurgeTrash(ctx, c.tx, time.Now().Add(-retention))
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
+
+// PurgeTrash permanently removes the recipes and ingredients deleted before
+// the time, together in one unit of work
+func PurgeTrash(ctx context.Context, tx transaction.Manager, before time.Time) (*view.PurgeResult, error) {
+	purgedRecipes, purgedIngredients, err := transaction.PurgeTrash(ctx, tx, before)
+	if err != nil {
+		return nil, err
+	}
+	return &view.PurgeResult{Before: before, Recipes: len(purgedRecipes), Ingredients: len(purgedIngredients)}, nil
+}
+
+func SetupTrashRouter(controller *TrashController, router *gin.RouterGroup) *gin.RouterGroup {
+	router.GET("/trash", controller.GetTrashHandler)
+	router.DELETE("/trash", controller.PurgeTrashHandler)
+	router.POST("/trash/recipes/:id/restore", controller.RestoreRecipeHandler)
+	router.POST("/trash/ingredients/:id/restore", controller.RestoreIngredientHandler)
+	return router
+}
@@ -0,0 +1,328 @@
+package controller
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/TomeuUris/recipes-catalog/api/v1/payload"
+	"github.com/TomeuUris/recipes-catalog/api/v1/view"
+	"github.com/TomeuUris/recipes-catalog/pkg/entity"
+	"github.com/TomeuUris/recipes-catalog/pkg/repo/webhook"
+	"github.com/gin-gonic/gin"
+)
+
+type WebhookController struct {
+	repo       webhook.Repo
+	deliveries webhook.DeliveryRepo
+}
+
+func NewWebhookController(repo webhook.Repo, deliveries webhook.DeliveryRepo) *WebhookController {
+	return &WebhookController{repo: repo, deliveries: deliveries}
+}
+
+// @Summary Get webhooks
+// @Description Retrieves the registered webhooks
+// @Tags Webhooks
+// @Produce  json
+// @Param   filter     query    webhook.FindFilter     false        "Filter parameters"
+// @Success 200 {array} view.Webhook
+// @Router /webhooks [get]
+func (c *WebhookController) GetWebhooksHandler(ctx *gin.Context) {
+	// Parse the filter from the query parameters
+	var filter webhook.FindFilter
+	if err := ctx.ShouldBindQuery(&filter); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Find the webhooks in the database
+	webhooks, err := c.repo.FindByFilter(ctx, &filter)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Convert the webhooks to a view
+	webhookViews := make([]*view.Webhook, len(webhooks))
+	for i, webhook := range webhooks {
+		webhookViews[i] = &view.Webhook{}
+		webhookViews[i].FromEntity(webhook)
+	}
+
+	ctx.JSON(http.StatusOK, webhookViews)
+}
+
+// @Summary Get webhook by ID
+// @Description Retrieves a webhook by its ID
+// @Tags Webhooks
+// @Produce  json
+// @Param   id     path    int     true        "Webhook ID"
+// @Success 200 {object} view.Webhook
+// @Router /webhooks/{id} [get]
+func (c *WebhookController) GetWebhookByIdHandler(ctx *gin.Context) {
+	webhookID, err := strconv.ParseInt(ctx.Params.ByName("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid webhook ID"})
+		return
+	}
+
+	webhook, err := c.repo.FindByID(ctx, webhookID)
+	if err != nil {
+		if errors.Is(err, entity.ErrNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	webhookView := &view.Webhook{}
+	webhookView.FromEntity(webhook)
+	ctx.JSON(http.StatusOK, webhookView)
+}
+
+// @Summary Create webhook
+// @Description Registers a webhook for catalog events. When no secret is given one is generated.
+// @Description The secret is only returned in this response, deliveries are signed with it.
+// @Tags Webhooks
+// @Accept  json
+// @Produce  json
+// @Param   webhook     body    payload.Webhook     true        "Webhook info"
+// @Success 201 {object} view.Webhook
+// @Router /webhooks [post]
+func (c *WebhookController) CreateWebhookHandler(ctx *gin.Context) {
+	// Parse the request payload
+	var webhookPayload payload.Webhook
+	if err := ctx.ShouldBindJSON(&webhookPayload); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Convert the payload to an entity
+	webhook := webhookPayload.ToEntity()
+	if webhook.Secret == "" {
+		secret, err := generateSecret()
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		webhook.Secret = secret
+	}
+
+	// Validate the webhook
+	if err := webhook.Validate(); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Create the webhook in the database
+	if err := c.repo.Add(ctx, webhook); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	webhookView := &view.Webhook{}
+	webhookView.FromEntity(webhook)
+	webhookView.Secret = webhook.Secret
+	ctx.JSON(http.StatusCreated, webhookView)
+}
+
+// @Summary Edit webhook
+// @Description Edits an existing webhook
+// @Tags Webhooks
+// @Accept  json
+// @Produce  json
+// @Param   id     path    int64               true        "Webhook ID"
+// @Param   webhook     body    payload.Webhook     true        "Webhook info"
+// @Success 200 {object} view.Webhook
+// @Router /webhooks/{id} [patch]
+func (c *WebhookController) EditWebhookHandler(ctx *gin.Context) {
+	webhookID, err := strconv.ParseInt(ctx.Params.ByName("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid webhook ID"})
+		return
+	}
+	// Parse the request payload
+	var webhookPayload payload.Webhook
+	if err := ctx.ShouldBindJSON(&webhookPayload); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Find the webhook in the database
+	targetWebhook, err := c.repo.FindByID(ctx, webhookID)
+	if err != nil {
+		if errors.Is(err, entity.ErrNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Apply the changes to the webhook
+	webhookPayload.ApplyTo(targetWebhook)
+
+	// Validate the webhook
+	if err := targetWebhook.Validate(); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Update the webhook in the database
+	if err := c.repo.Edit(ctx, targetWebhook); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	webhookView := &view.Webhook{}
+	webhookView.FromEntity(targetWebhook)
+	ctx.JSON(http.StatusOK, webhookView)
+}
+
+// @Summary Delete webhook
+// @Description Deletes an existing webhook
+// @Tags Webhooks
+// @Param   id     path    int64               true        "Webhook ID"
+// @Success 204 "No Content"
+// @Router /webhooks/{id} [delete]
+func (c *WebhookController) DeleteWebhookHandler(ctx *gin.Context) {
+	webhookID, err := strconv.ParseInt(ctx.Params.ByName("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid webhook ID"})
+		return
+	}
+
+	if err := c.repo.Delete(ctx, &entity.Webhook{ID: webhookID}); err != nil {
+		if errors.Is(err, entity.ErrNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusNoContent, nil)
+}
+
+// @Summary Get webhook deliveries
+// @Description Retrieves the delivery history of a webhook, newest first
+// @Tags Webhooks
+// @Produce  json
+// @Param   id     path    int64               true        "Webhook ID"
+// @Param   filter     query    webhook.DeliveryFilter     false        "Filter parameters"
+// @Success 200 {array} view.WebhookDelivery
+// @Router /webhooks/{id}/deliveries [get]
+func (c *WebhookController) GetWebhookDeliveriesHandler(ctx *gin.Context) {
+	webhookID, err := strconv.ParseInt(ctx.Params.ByName("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid webhook ID"})
+		return
+	}
+	var filter webhook.DeliveryFilter
+	if err := ctx.ShouldBindQuery(&filter); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	filter.WebhookID = webhookID
+
+	// Check the webhook exists
+	if _, err := c.repo.FindByID(ctx, webhookID); err != nil {
+		if errors.Is(err, entity.ErrNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	deliveries, err := c.deliveries.FindByFilter(ctx, &filter)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	deliveryViews := make([]*view.WebhookDelivery, len(deliveries))
+	for i, delivery := range deliveries {
+		deliveryViews[i] = &view.WebhookDelivery{}
+		deliveryViews[i].FromEntity(delivery)
+	}
+
+	ctx.JSON(http.StatusOK, deliveryViews)
+}
+
+// @Summary Redeliver a webhook delivery
+// @Description Queues a new delivery with the same payload as an existing one
+// @Tags Webhooks
+// @Produce  json
+// @Param   id     path    int64               true        "Webhook ID"
+// @Param   delivery_id     path    int64               true        "Delivery ID"
+// @Success 202 {object} view.WebhookDelivery
+// @Router /webhooks/{id}/deliveries/{delivery_id}/redeliver [post]
+func (c *WebhookController) RedeliverWebhookHandler(ctx *gin.Context) {
+	webhookID, err := strconv.ParseInt(ctx.Params.ByName("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid webhook ID"})
+		return
+	}
+	deliveryID, err := strconv.ParseInt(ctx.Params.ByName("delivery_id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid delivery ID"})
+		return
+	}
+
+	// Find the original delivery
+	original, err := c.deliveries.FindByID(ctx, deliveryID)
+	if err == nil && original.WebhookID != webhookID {
+		err = entity.ErrNotFound
+	}
+	if err != nil {
+		if errors.Is(err, entity.ErrNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Queue a copy, the dispatcher picks it up on its next poll
+	delivery := &entity.WebhookDelivery{
+		WebhookID:     original.WebhookID,
+		EventID:       original.EventID,
+		EventType:     original.EventType,
+		Payload:       original.Payload,
+		Status:        entity.DeliveryPending,
+		NextAttemptAt: time.Now(),
+	}
+	if err := c.deliveries.Add(ctx, delivery); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	deliveryView := &view.WebhookDelivery{}
+	deliveryView.FromEntity(delivery)
+	ctx.JSON(http.StatusAccepted, deliveryView)
+}
+
+func generateSecret() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(secret), nil
+}
+
+func SetupWebhooksRouter(controller *WebhookController, router *gin.RouterGroup) *gin.RouterGroup {
+	router.GET("/webhooks", controller.GetWebhooksHandler)
+	router.POST("/webhooks", controller.CreateWebhookHandler)
+	router.GET("/webhooks/:id", controller.GetWebhookByIdHandler)
+	router.PATCH("/webhooks/:id", controller.EditWebhookHandler)
+	router.DELETE("/webhooks/:id", controller.DeleteWebhookHandler)
+	router.GET("/webhooks/:id/deliveries", controller.GetWebhookDeliveriesHandler)
+	router.POST("/webhooks/:id/deliveries/:delivery_id/redeliver", controller.RedeliverWebhookHandler)
+	return router
+}
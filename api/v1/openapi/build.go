@@ -0,0 +1,124 @@
+package openapi
+
+import (
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var ginParam = regexp.MustCompile(`:([A-Za-z_]+)`)
+
+// OpenAPIPath converts a gin path (/recipes/:id) to an OpenAPI one (/recipes/{id})
+func OpenAPIPath(path string) string {
+	return ginParam.ReplaceAllString(path, "{$1}")
+}
+
+// Build generates the OpenAPI document describing Routes
+func Build() *Document {
+	registry := newSchemaRegistry()
+	doc := &Document{
+		OpenAPI: Version,
+		Info: Info{
+			Title:       "Recipes Catalog API",
+			Description: "REST API that allows you to manage recipes, ingredients and cooking units.",
+			Version:     "v1",
+		},
+		Servers: []Server{{URL: BasePath}},
+		Paths:   map[string]PathItem{},
+	}
+
+	for _, route := range Routes {
+		path := OpenAPIPath(route.Path)
+		if doc.Paths[path] == nil {
+			doc.Paths[path] = PathItem{}
+		}
+		doc.Paths[path][strings.ToLower(route.Method)] = buildOperation(registry, route)
+	}
+
+	doc.Components.Schemas = registry.components
+	return doc
+}
+
+func buildOperation(registry *schemaRegistry, route Route) *Operation {
+	op := &Operation{
+		OperationID: route.OperationID,
+		Summary:     route.Summary,
+		Description: route.Description,
+		Responses:   map[string]*Response{},
+	}
+	if route.Tag != "" {
+		op.Tags = []string{route.Tag}
+	}
+
+	// Path parameters are integer IDs unless documented otherwise
+	for _, match := range ginParam.FindAllStringSubmatch(route.Path, -1) {
+		op.Parameters = append(op.Parameters, route.pathParam(match[1]))
+	}
+	if route.Query != nil {
+		for _, param := range registry.queryParameters(reflect.TypeOf(route.Query)) {
+			op.Parameters = append(op.Parameters, route.queryParam(param))
+		}
+	}
+	op.Parameters = append(op.Parameters, route.Headers...)
+
+	if route.Body != nil {
+		op.RequestBody = &RequestBody{
+			Required: true,
+			Content: map[string]MediaType{
+				contentTypeJSON: {Schema: registry.schemaOf(reflect.TypeOf(route.Body))},
+			},
+		}
+		for _, contentType := range route.Uploads {
+			op.RequestBody.Content[contentType] = MediaType{Schema: Schema{"type": "string"}}
+		}
+	}
+
+	// Responses of the same status with other content types are alternatives
+	for _, spec := range route.Responses {
+		response, ok := op.Responses[strconv.Itoa(spec.Status)]
+		if !ok {
+			response = &Response{Description: spec.Description}
+			op.Responses[strconv.Itoa(spec.Status)] = response
+		}
+		if spec.Body != nil {
+			if response.Content == nil {
+				response.Content = map[string]MediaType{}
+			}
+			response.Content[spec.contentType()] = MediaType{Schema: registry.schemaOf(reflect.TypeOf(spec.Body))}
+		}
+	}
+	return op
+}
+
+func (r Route) pathParam(name string) *Parameter {
+	for _, param := range r.PathParams {
+		if param.Name == name {
+			return param
+		}
+	}
+	return &Parameter{
+		Name:     name,
+		In:       "path",
+		Required: true,
+		Schema:   Schema{"type": "integer"},
+	}
+}
+
+// queryParam returns the documented query parameter of the same name, or the
+// one described from the Query field
+func (r Route) queryParam(field *Parameter) *Parameter {
+	for _, param := range r.QueryParams {
+		if param.Name == field.Name {
+			return param
+		}
+	}
+	return field
+}
+
+func (s ResponseSpec) contentType() string {
+	if s.ContentType == "" {
+		return contentTypeJSON
+	}
+	return s.ContentType
+}
@@ -0,0 +1,262 @@
+package openapi_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/TomeuUris/recipes-catalog/api/v1/controller"
+	"github.com/TomeuUris/recipes-catalog/api/v1/openapi"
+	"github.com/TomeuUris/recipes-catalog/pkg/event"
+	"github.com/TomeuUris/recipes-catalog/pkg/importer"
+	"github.com/TomeuUris/recipes-catalog/pkg/repo/cooking_unit"
+	"github.com/TomeuUris/recipes-catalog/pkg/repo/ingredient"
+	"github.com/TomeuUris/recipes-catalog/pkg/repo/recipe"
+	"github.com/TomeuUris/recipes-catalog/pkg/repo/transaction"
+	"github.com/gin-gonic/gin"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestRoutesMatchRouter(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	controller.SetupRouter(&controller.Controllers{
+		Ingredients:  controller.NewIngredientController(nil),
+		Recipes:      controller.NewRecipeController(nil, nil),
+		CookingUnits: controller.NewCookingUnitController(nil),
+		Events:       controller.NewEventController(event.NewHub(1)),
+		Webhooks:     controller.NewWebhookController(nil, nil),
+		Trash:        controller.NewTrashController(nil, nil, nil, controller.DefaultTrashRetention),
+		Backups:      controller.NewBackupController(nil),
+		Cache:        controller.NewCacheController(nil),
+		Integrity:    controller.NewIntegrityController(nil),
+		Import:       controller.NewImportController(nil),
+	}, r.Group(openapi.BasePath))
+
+	var registered []string
+	for _, route := range r.Routes() {
+		registered = append(registered, route.Method+" "+strings.TrimPrefix(route.Path, openapi.BasePath))
+	}
+	var documented []string
+	for _, route := range openapi.Routes {
+		documented = append(documented, route.Method+" "+route.Path)
+	}
+	sort.Strings(registered)
+	sort.Strings(documented)
+
+	if strings.Join(registered, "\n") != strings.Join(documented, "\n") {
+		t.Fatalf("Documented routes don't match the router\nregistered:\n%s\ndocumented:\n%s",
+			strings.Join(registered, "\n"), strings.Join(documented, "\n"))
+	}
+}
+
+func TestDocumentIsUpToDate(t *testing.T) {
+	golden, err := os.ReadFile("../../../docs/openapi.json")
+	if err != nil {
+		t.Fatalf("Failed to read the document: %v", err)
+	}
+	generated, err := json.MarshalIndent(openapi.Build(), "", "  ")
+	if err != nil {
+		t.Fatalf("Failed to encode the document: %v", err)
+	}
+	if strings.TrimSpace(string(golden)) != string(generated) {
+		t.Fatalf("docs/openapi.json is outdated, run make gen-openapi")
+	}
+}
+
+func setupValidatedRouter(t *testing.T, strict bool) *gin.Engine {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to connect database: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("Failed to get database: %v", err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+	if err := ingredient.RunMigrations(db); err != nil {
+		t.Fatalf("Failed to run migrations: %v", err)
+	}
+	if err := recipe.RunMigrations(db); err != nil {
+		t.Fatalf("Failed to run migrations: %v", err)
+	}
+	if err := cooking_unit.RunMigrations(db); err != nil {
+		t.Fatalf("Failed to run migrations: %v", err)
+	}
+
+	validator := openapi.MustNewValidator()
+	validator.StrictResponses = strict
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	v1 := r.Group(openapi.BasePath)
+	v1.Use(validator.Middleware())
+	controller.SetupIngredientsRouter(controller.NewIngredientController(ingredient.NewGormRepo(db)), v1)
+	controller.SetupRecipesRouter(controller.NewRecipeController(recipe.NewGormRepo(db), transaction.NewGormManager(db)), v1)
+	controller.SetupImportRouter(controller.NewImportController(importer.New(ingredient.NewGormRepo(db), cooking_unit.NewGormRepo(db))), v1)
+
+	// Handlers breaking the document
+	v1.GET("/cooking-units/:id", func(ctx *gin.Context) {
+		ctx.JSON(http.StatusOK, gin.H{"id": "not a number"})
+	})
+	v1.GET("/cooking-units/count", func(ctx *gin.Context) {
+		ctx.JSON(http.StatusTeapot, 0)
+	})
+	return r
+}
+
+func TestValidatorRequests(t *testing.T) {
+	r := setupValidatedRouter(t, true)
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		status int
+	}{
+		{"valid body", http.MethodPost, "/ingredients", `{"name": "Salt", "type": "Spice"}`, http.StatusCreated},
+		{"conflict", http.MethodPost, "/ingredients", `{"name": "SALT", "type": "Spice"}`, http.StatusConflict},
+		{"wrong field type", http.MethodPost, "/ingredients", `{"name": 1, "type": "Spice"}`, http.StatusBadRequest},
+		{"malformed body", http.MethodPost, "/ingredients", `{"name"`, http.StatusBadRequest},
+		{"valid path parameter", http.MethodGet, "/ingredients/1", "", http.StatusOK},
+		{"invalid path parameter", http.MethodGet, "/ingredients/abc", "", http.StatusBadRequest},
+		{"partial body", http.MethodPatch, "/ingredients/1", `{"name": "Sea salt"}`, http.StatusOK},
+		{"nested body", http.MethodPost, "/recipes", `{"name": "Salad", "ingredients": [{"id": 1, "name": "Salt", "type": "Spice"}]}`, http.StatusCreated},
+		{"partial body without lists", http.MethodPatch, "/recipes/1", `{"description": "Fresh"}`, http.StatusOK},
+		{"string path parameter", http.MethodGet, "/recipes/by-slug/salad", "", http.StatusOK},
+		{"rename", http.MethodPatch, "/recipes/1", `{"name": "Green salad"}`, http.StatusOK},
+		{"redirect", http.MethodGet, "/recipes/by-slug/salad", "", http.StatusMovedPermanently},
+		{"missing slug", http.MethodGet, "/recipes/by-slug/soup", "", http.StatusNotFound},
+		{"wrong nested type", http.MethodPatch, "/recipes/1", `{"steps": [1]}`, http.StatusBadRequest},
+		{"in use", http.MethodDelete, "/ingredients/1", "", http.StatusConflict},
+		{"invalid query parameter", http.MethodDelete, "/ingredients/1?cascade=remove", "", http.StatusBadRequest},
+		{"no content", http.MethodDelete, "/ingredients/1?cascade=detach", "", http.StatusNoContent},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(test.method, openapi.BasePath+test.path, strings.NewReader(test.body))
+			req.Header.Set("Content-Type", "application/json")
+			r.ServeHTTP(w, req)
+
+			if w.Code != test.status {
+				t.Fatalf("Expected status %d, got %d: %s", test.status, w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestValidatorResponses(t *testing.T) {
+	tests := []struct {
+		name   string
+		strict bool
+		path   string
+		status int
+	}{
+		{"invalid body", true, "/cooking-units/1", http.StatusInternalServerError},
+		{"undocumented status", true, "/cooking-units/count", http.StatusInternalServerError},
+		{"invalid body reported only", false, "/cooking-units/1", http.StatusOK},
+		{"undocumented status reported only", false, "/cooking-units/count", http.StatusTeapot},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := setupValidatedRouter(t, test.strict)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, openapi.BasePath+test.path, nil)
+			r.ServeHTTP(w, req)
+
+			if w.Code != test.status {
+				t.Fatalf("Expected status %d, got %d: %s", test.status, w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestValidatorNegotiatesJSONLD(t *testing.T) {
+	r := setupValidatedRouter(t, true)
+	serve := func(method, path, accept, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(method, openapi.BasePath+path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if accept != "" {
+			req.Header.Set("Accept", accept)
+		}
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := serve(http.MethodPost, "/recipes", "", `{"name": "Soup", "steps": ["Boil"], "prep_minutes": 5, "cook_minutes": 20, "yield": "2 bowls", "calories": 90}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusCreated, w.Code, w.Body.String())
+	}
+
+	tests := []struct {
+		name        string
+		path        string
+		accept      string
+		contentType string
+		field       string
+	}{
+		{"view by default", "/recipes/1", "", "application/json", "slug"},
+		{"view of any type", "/recipes/1", "*/*", "application/json", "slug"},
+		{"JSON-LD by ID", "/recipes/1", "application/ld+json", "application/ld+json", "recipeInstructions"},
+		{"JSON-LD by slug", "/recipes/by-slug/soup", "application/ld+json, application/json;q=0.9", "application/ld+json", "totalTime"},
+		{"export", "/recipes/export", "", "application/ld+json", "@graph"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			w := serve(http.MethodGet, test.path, test.accept, "")
+			if w.Code != http.StatusOK {
+				t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+			}
+			if contentType := w.Header().Get("Content-Type"); !strings.HasPrefix(contentType, test.contentType) {
+				t.Fatalf("Expected content type %s, got %s", test.contentType, contentType)
+			}
+			var body map[string]interface{}
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatalf("Failed to decode the response: %v", err)
+			}
+			if _, ok := body[test.field]; !ok {
+				t.Fatalf("Expected the response to have %s, got %s", test.field, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestValidatorAcceptsUploads(t *testing.T) {
+	r := setupValidatedRouter(t, true)
+
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		status      int
+	}{
+		{"uploaded page", "text/html; charset=utf-8", `<script type="application/ld+json">{"@type": "Recipe", "name": "Soup", "recipeIngredient": ["1 cup water"]}</script>`, http.StatusOK},
+		{"page without recipe", "text/html", `<p>Soup</p>`, http.StatusUnprocessableEntity},
+		{"invalid URL", "application/json", `{"url": "file:///etc/passwd"}`, http.StatusBadRequest},
+		{"JSON body still validated", "application/json", `{"url": 1}`, http.StatusBadRequest},
+		{"other content types as JSON", "text/plain", `<p>Soup</p>`, http.StatusBadRequest},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, openapi.BasePath+"/recipes/import", strings.NewReader(test.body))
+			req.Header.Set("Content-Type", test.contentType)
+			r.ServeHTTP(w, req)
+
+			if w.Code != test.status {
+				t.Fatalf("Expected status %d, got %d: %s", test.status, w.Code, w.Body.String())
+			}
+		})
+	}
+}
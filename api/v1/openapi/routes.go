@@ -0,0 +1,289 @@
+package openapi
+
+import (
+	"net/http"
+
+	"github.com/TomeuUris/recipes-catalog/api/v1/controller"
+	"github.com/TomeuUris/recipes-catalog/api/v1/payload"
+	"github.com/TomeuUris/recipes-catalog/api/v1/view"
+	"github.com/TomeuUris/recipes-catalog/pkg/repo/audit"
+	"github.com/TomeuUris/recipes-catalog/pkg/repo/cooking_unit"
+	"github.com/TomeuUris/recipes-catalog/pkg/repo/ingredient"
+	"github.com/TomeuUris/recipes-catalog/pkg/repo/recipe"
+	"github.com/TomeuUris/recipes-catalog/pkg/repo/webhook"
+	"github.com/TomeuUris/recipes-catalog/pkg/schemaorg"
+)
+
+// Error is the body returned by every failed request
+type Error struct {
+	Error string `json:"error"`
+}
+
+// Route documents an endpoint registered in the router
+type Route struct {
+	Method string
+	// Path in gin syntax, relative to BasePath
+	Path        string
+	OperationID string
+	Summary     string
+	Description string
+	Tag         string
+	// PathParams document the path parameters that aren't integer IDs
+	PathParams []*Parameter
+	// Query is the filter struct bound from the query string, if any
+	Query interface{}
+	// QueryParams document the query parameters of Query that differ from
+	// their fields, by name
+	QueryParams []*Parameter
+	// Headers are the header parameters read by the handler
+	Headers []*Parameter
+	// Body is the payload bound from the JSON body, if any
+	Body interface{}
+	// Uploads are the content types of documents accepted as the body instead
+	// of the JSON one
+	Uploads   []string
+	Responses []ResponseSpec
+}
+
+// ResponseSpec documents a response status. A nil Body means no content.
+type ResponseSpec struct {
+	Status      int
+	Description string
+	Body        interface{}
+	// ContentType defaults to application/json
+	ContentType string
+}
+
+const (
+	contentTypeJSON        = "application/json"
+	contentTypeEventStream = "text/event-stream"
+	contentTypeHTML        = "text/html"
+)
+
+// BasePath is the prefix every route is served under
+const BasePath = "/api/v1"
+
+func ok(body interface{}) ResponseSpec {
+	return ResponseSpec{Status: http.StatusOK, Description: "OK", Body: body}
+}
+
+// okJSONLD is the schema.org JSON-LD alternative of a response
+func okJSONLD(body interface{}) ResponseSpec {
+	return ResponseSpec{Status: http.StatusOK, Description: "OK", Body: body, ContentType: schemaorg.MIMEType}
+}
+
+func created(body interface{}) ResponseSpec {
+	return ResponseSpec{Status: http.StatusCreated, Description: "Created", Body: body}
+}
+
+func failure(status int) ResponseSpec {
+	return ResponseSpec{Status: status, Description: http.StatusText(status), Body: Error{}}
+}
+
+var noContent = ResponseSpec{Status: http.StatusNoContent, Description: "No Content"}
+
+var movedPermanently = ResponseSpec{Status: http.StatusMovedPermanently, Description: "Moved Permanently to the Location header"}
+
+var (
+	badRequest     = failure(http.StatusBadRequest)
+	notFound       = failure(http.StatusNotFound)
+	internalError  = failure(http.StatusInternalServerError)
+	tooLarge       = failure(http.StatusRequestEntityTooLarge)
+	unprocessable  = failure(http.StatusUnprocessableEntity)
+	badGateway     = failure(http.StatusBadGateway)
+	lastEventIDDoc = "ID of the last event received"
+
+	// Recipes are identified by public IDs when they are enabled, only in
+	// their paths and filters
+	recipeIDDoc   = "recipe ID, or its public ID when they are enabled. Conflicts, events, webhooks, the audit log and gRPC keep the recipe IDs."
+	recipeIDParam = &Parameter{Name: "id", In: "path", Required: true, Schema: Schema{"type": "string"},
+		Description: recipeIDDoc}
+	recipeIDFilterParam = &Parameter{Name: "id", In: "query", Schema: Schema{"type": "string"},
+		Description: recipeIDDoc}
+	slugParam = &Parameter{Name: "slug", In: "path", Required: true, Schema: Schema{"type": "string"}}
+
+	alreadyExists = ResponseSpec{Status: http.StatusConflict, Description: "The name is taken", Body: view.AlreadyExists{}}
+	inUse         = ResponseSpec{Status: http.StatusConflict, Description: "Recipes use it", Body: view.InUse{}}
+)
+
+// Routes lists every endpoint of the API, it is checked against the router in tests
+var Routes = []Route{
+	// Ingredients
+	{Method: http.MethodGet, Path: "/ingredients", OperationID: "listIngredients", Tag: "Ingredients",
+		Summary: "Get ingredients by filter", Query: ingredient.FindFilter{},
+		Responses: []ResponseSpec{ok([]view.Ingredient{}), badRequest, internalError}},
+	{Method: http.MethodPost, Path: "/ingredients", OperationID: "createIngredient", Tag: "Ingredients",
+		Summary: "Create ingredient", Body: payload.Ingredient{},
+		Description: "Names are unique per type ignoring case and accents.",
+		Responses:   []ResponseSpec{created(view.Ingredient{}), badRequest, alreadyExists, internalError}},
+	{Method: http.MethodGet, Path: "/ingredients/count", OperationID: "countIngredients", Tag: "Ingredients",
+		Summary: "Count ingredients by filter", Query: ingredient.FindFilter{},
+		Responses: []ResponseSpec{ok(0), badRequest, internalError}},
+	{Method: http.MethodGet, Path: "/ingredients/duplicates", OperationID: "listIngredientDuplicates", Tag: "Ingredients",
+		Summary:     "Get likely duplicated ingredients",
+		Description: "Groups the ingredients whose names, ignoring case and accents, are at least min_similarity (0.8 by default) similar.",
+		Query:       controller.DuplicatesFilter{},
+		Responses:   []ResponseSpec{ok([]view.DuplicateGroup{}), badRequest, internalError}},
+	{Method: http.MethodGet, Path: "/ingredients/:id", OperationID: "getIngredient", Tag: "Ingredients",
+		Summary:   "Get ingredient by ID",
+		Responses: []ResponseSpec{ok(view.Ingredient{}), badRequest, notFound, internalError}},
+	{Method: http.MethodPatch, Path: "/ingredients/:id", OperationID: "editIngredient", Tag: "Ingredients",
+		Summary: "Edit ingredient", Body: payload.Ingredient{},
+		Responses: []ResponseSpec{ok(view.Ingredient{}), badRequest, notFound, alreadyExists, internalError}},
+	{Method: http.MethodDelete, Path: "/ingredients/:id", OperationID: "deleteIngredient", Tag: "Ingredients",
+		Summary:     "Delete ingredient",
+		Description: "Fails with a conflict listing the recipes using the ingredient, unless cascade=detach removes it from them.",
+		Query:       controller.DeleteOptions{},
+		Responses:   []ResponseSpec{noContent, badRequest, notFound, inUse, internalError}},
+	{Method: http.MethodPost, Path: "/ingredients/:id/merge", OperationID: "mergeIngredients", Tag: "Ingredients",
+		Summary:     "Merge duplicated ingredients",
+		Description: "Makes the recipes using the duplicates use the ingredient instead and deletes the duplicates in one transaction.",
+		Body:        payload.IngredientMerge{},
+		Responses:   []ResponseSpec{ok(view.Ingredient{}), badRequest, notFound, internalError}},
+
+	// Recipes
+	{Method: http.MethodGet, Path: "/recipes", OperationID: "listRecipes", Tag: "Recipes",
+		Summary: "Get recipes by filter", Query: recipe.FindFilter{}, QueryParams: []*Parameter{recipeIDFilterParam},
+		Responses: []ResponseSpec{ok([]view.Recipe{}), badRequest, internalError}},
+	{Method: http.MethodPost, Path: "/recipes", OperationID: "createRecipe", Tag: "Recipes",
+		Summary: "Create recipe", Body: payload.Recipe{},
+		Responses: []ResponseSpec{created(view.Recipe{}), badRequest, alreadyExists, internalError}},
+	{Method: http.MethodPost, Path: "/recipes/import", OperationID: "importRecipe", Tag: "Recipes",
+		Summary:     "Import recipe from an HTML page",
+		Description: "Makes a draft recipe from the schema.org Recipe JSON-LD or microdata of a page, uploaded as a text/html body or fetched from the URL given. Ingredient lines are matched to the ingredients and cooking units, the unmatched ones are reported. The draft isn't stored, it can be created once reviewed.",
+		Body:        payload.RecipeImport{}, Uploads: []string{contentTypeHTML},
+		Responses: []ResponseSpec{ok(view.RecipeDraft{}), badRequest, tooLarge, unprocessable, badGateway, internalError}},
+	{Method: http.MethodGet, Path: "/recipes/count", OperationID: "countRecipes", Tag: "Recipes",
+		Summary: "Count recipes by filter", Query: recipe.FindFilter{}, QueryParams: []*Parameter{recipeIDFilterParam},
+		Responses: []ResponseSpec{ok(0), badRequest, internalError}},
+	{Method: http.MethodGet, Path: "/recipes/by-slug/:slug", OperationID: "getRecipeBySlug", Tag: "Recipes",
+		Summary:     "Get recipe by slug",
+		Description: "Slugs are made from the recipe name. Slugs of previous names redirect to the current one. Accept: application/ld+json returns the schema.org Recipe JSON-LD instead of the view.",
+		PathParams:  []*Parameter{slugParam},
+		Responses:   []ResponseSpec{ok(view.Recipe{}), okJSONLD(schemaorg.Recipe{}), movedPermanently, notFound, internalError}},
+	{Method: http.MethodGet, Path: "/recipes/export", OperationID: "exportRecipes", Tag: "Recipes",
+		Summary:     "Export recipes as JSON-LD",
+		Description: "Exports the recipes matching the filter as a schema.org JSON-LD graph of Recipes, for search engines and other catalogs.",
+		Query:       recipe.FindFilter{}, QueryParams: []*Parameter{recipeIDFilterParam},
+		Responses: []ResponseSpec{okJSONLD(schemaorg.Graph{}), badRequest, internalError}},
+	{Method: http.MethodGet, Path: "/recipes/:id", OperationID: "getRecipe", Tag: "Recipes",
+		Summary:     "Get recipe by ID",
+		Description: "Accept: application/ld+json returns the schema.org Recipe JSON-LD instead of the view.",
+		PathParams:  []*Parameter{recipeIDParam},
+		Responses:   []ResponseSpec{ok(view.Recipe{}), okJSONLD(schemaorg.Recipe{}), badRequest, notFound, internalError}},
+	{Method: http.MethodPatch, Path: "/recipes/:id", OperationID: "editRecipe", Tag: "Recipes",
+		Summary: "Edit recipe", Body: payload.Recipe{}, PathParams: []*Parameter{recipeIDParam},
+		Responses: []ResponseSpec{ok(view.Recipe{}), badRequest, notFound, alreadyExists, internalError}},
+	{Method: http.MethodDelete, Path: "/recipes/:id", OperationID: "deleteRecipe", Tag: "Recipes",
+		Summary:    "Delete recipe",
+		PathParams: []*Parameter{recipeIDParam},
+		Responses:  []ResponseSpec{ok(Null{}), badRequest, notFound, internalError}},
+
+	// Cooking units
+	{Method: http.MethodGet, Path: "/cooking-units", OperationID: "listCookingUnits", Tag: "Cooking Units",
+		Summary: "Get cooking units by filter", Query: cooking_unit.FindFilter{},
+		Responses: []ResponseSpec{ok([]view.CookingUnit{}), badRequest, internalError}},
+	{Method: http.MethodPost, Path: "/cooking-units", OperationID: "createCookingUnit", Tag: "Cooking Units",
+		Summary: "Create cooking unit", Body: payload.CookingUnit{},
+		Description: "Names are unique ignoring case and accents.",
+		Responses:   []ResponseSpec{created(view.CookingUnit{}), badRequest, alreadyExists, internalError}},
+	{Method: http.MethodGet, Path: "/cooking-units/count", OperationID: "countCookingUnits", Tag: "Cooking Units",
+		Summary: "Count cooking units by filter", Query: cooking_unit.FindFilter{},
+		Responses: []ResponseSpec{ok(0), badRequest, internalError}},
+	{Method: http.MethodGet, Path: "/cooking-units/:id", OperationID: "getCookingUnit", Tag: "Cooking Units",
+		Summary:   "Get cooking unit by ID",
+		Responses: []ResponseSpec{ok(view.CookingUnit{}), badRequest, notFound, internalError}},
+	{Method: http.MethodPatch, Path: "/cooking-units/:id", OperationID: "editCookingUnit", Tag: "Cooking Units",
+		Summary: "Edit cooking unit", Body: payload.CookingUnit{},
+		Responses: []ResponseSpec{ok(view.CookingUnit{}), badRequest, notFound, alreadyExists, internalError}},
+	{Method: http.MethodDelete, Path: "/cooking-units/:id", OperationID: "deleteCookingUnit", Tag: "Cooking Units",
+		Summary:   "Delete cooking unit",
+		Query:     controller.DeleteOptions{},
+		Responses: []ResponseSpec{noContent, badRequest, notFound, internalError}},
+
+	// Events
+	{Method: http.MethodGet, Path: "/events", OperationID: "streamEvents", Tag: "Events",
+		Summary:     "Stream catalog changes",
+		Description: "Server-Sent Events stream of created/updated/deleted events. Resume with Last-Event-ID.",
+		Headers: []*Parameter{
+			{Name: "Last-Event-ID", In: "header", Description: lastEventIDDoc, Schema: Schema{"type": "integer"}},
+		},
+		Query: struct {
+			LastEventID int64 `form:"last_event_id"`
+		}{},
+		Responses: []ResponseSpec{
+			{Status: http.StatusOK, Description: "OK", Body: view.Event{}, ContentType: contentTypeEventStream},
+			badRequest,
+		}},
+
+	// Webhooks
+	{Method: http.MethodGet, Path: "/webhooks", OperationID: "listWebhooks", Tag: "Webhooks",
+		Summary: "Get webhooks", Query: webhook.FindFilter{},
+		Responses: []ResponseSpec{ok([]view.Webhook{}), badRequest, internalError}},
+	{Method: http.MethodPost, Path: "/webhooks", OperationID: "createWebhook", Tag: "Webhooks",
+		Summary: "Create webhook", Body: payload.Webhook{},
+		Responses: []ResponseSpec{created(view.Webhook{}), badRequest, internalError}},
+	{Method: http.MethodGet, Path: "/webhooks/:id", OperationID: "getWebhook", Tag: "Webhooks",
+		Summary:   "Get webhook by ID",
+		Responses: []ResponseSpec{ok(view.Webhook{}), badRequest, notFound, internalError}},
+	{Method: http.MethodPatch, Path: "/webhooks/:id", OperationID: "editWebhook", Tag: "Webhooks",
+		Summary: "Edit webhook", Body: payload.Webhook{},
+		Responses: []ResponseSpec{ok(view.Webhook{}), badRequest, notFound, internalError}},
+	{Method: http.MethodDelete, Path: "/webhooks/:id", OperationID: "deleteWebhook", Tag: "Webhooks",
+		Summary:   "Delete webhook",
+		Responses: []ResponseSpec{noContent, badRequest, notFound, internalError}},
+	{Method: http.MethodGet, Path: "/webhooks/:id/deliveries", OperationID: "listWebhookDeliveries", Tag: "Webhooks",
+		Summary: "Get webhook deliveries", Query: webhook.DeliveryFilter{},
+		Responses: []ResponseSpec{ok([]view.WebhookDelivery{}), badRequest, notFound, internalError}},
+	{Method: http.MethodPost, Path: "/webhooks/:id/deliveries/:delivery_id/redeliver", OperationID: "redeliverWebhook", Tag: "Webhooks",
+		Summary: "Redeliver a webhook delivery",
+		Responses: []ResponseSpec{
+			{Status: http.StatusAccepted, Description: "Accepted", Body: view.WebhookDelivery{}},
+			badRequest, notFound, internalError,
+		}},
+
+	// Audit
+	{Method: http.MethodGet, Path: "/audit", OperationID: "listAuditEntries", Tag: "Audit",
+		Summary:     "Get audit log",
+		Description: "Writes on recipes, ingredients and cooking units, oldest first. They are made by the X-Actor header of their request.",
+		Query:       audit.FindFilter{},
+		Responses:   []ResponseSpec{ok([]view.AuditEntry{}), badRequest, internalError}},
+
+	// Trash
+	{Method: http.MethodGet, Path: "/trash", OperationID: "listTrash", Tag: "Trash",
+		Summary: "Get deleted recipes and ingredients", Query: controller.TrashFilter{},
+		Responses: []ResponseSpec{ok([]view.TrashItem{}), badRequest, internalError}},
+	{Method: http.MethodDelete, Path: "/trash", OperationID: "purgeTrash", Tag: "Trash",
+		Summary:     "Purge trash",
+		Description: "Permanently removes the entities deleted longer ago than older_than (a duration like 720h), the retention period by default.",
+		Query:       controller.PurgeFilter{},
+		Responses:   []ResponseSpec{ok(view.PurgeResult{}), badRequest, internalError}},
+	{Method: http.MethodPost, Path: "/trash/recipes/:id/restore", OperationID: "restoreRecipe", Tag: "Trash",
+		Summary:   "Restore a deleted recipe with its steps",
+		Responses: []ResponseSpec{noContent, badRequest, notFound, internalError}},
+	{Method: http.MethodPost, Path: "/trash/ingredients/:id/restore", OperationID: "restoreIngredient", Tag: "Trash",
+		Summary:   "Restore a deleted ingredient",
+		Responses: []ResponseSpec{noContent, badRequest, notFound, alreadyExists, internalError}},
+
+	// Admin
+	{Method: http.MethodPost, Path: "/admin/backups", OperationID: "createBackup", Tag: "Admin",
+		Summary:     "Create backup",
+		Description: "Takes a consistent backup of the database while it is in use and removes the backups out of the retention policy.",
+		Responses:   []ResponseSpec{created(view.Backup{}), internalError}},
+	{Method: http.MethodGet, Path: "/admin/backups", OperationID: "listBackups", Tag: "Admin",
+		Summary:   "Get backups, newest first",
+		Responses: []ResponseSpec{ok([]view.Backup{}), internalError}},
+	{Method: http.MethodGet, Path: "/admin/cache", OperationID: "listCacheStats", Tag: "Admin",
+		Summary:     "Get cache stats",
+		Description: "Hits, misses and size of the caches of recipes, ingredients and cooking units read by ID.",
+		Responses:   []ResponseSpec{ok([]view.CacheStats{})}},
+	{Method: http.MethodGet, Path: "/admin/integrity", OperationID: "checkIntegrity", Tag: "Admin",
+		Summary:     "Check integrity",
+		Description: "Checks the database for orphaned steps, gaps or duplicates in the step numbers, recipes using deleted ingredients, recipes without steps and schema drift.",
+		Responses:   []ResponseSpec{ok(view.IntegrityReport{}), internalError}},
+	{Method: http.MethodPost, Path: "/admin/integrity/repair", OperationID: "repairIntegrity", Tag: "Admin",
+		Summary:     "Repair integrity",
+		Description: "Checks the database and repairs the issues that can be repaired, in a transaction rolled back when dry_run is set. Schema drift is only reported, run the migrate command.",
+		Query:       controller.RepairOptions{},
+		Responses:   []ResponseSpec{ok(view.IntegrityReport{}), badRequest, internalError}},
+}
@@ -0,0 +1,159 @@
+package openapi
+
+import (
+	"path"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Null documents a response whose body is the JSON literal null
+type Null struct{}
+
+// Any documents a value of any type
+type Any struct{}
+
+var (
+	timeType = reflect.TypeOf(time.Time{})
+	nullType = reflect.TypeOf(Null{})
+	anyType  = reflect.TypeOf(Any{})
+)
+
+// schemaRegistry generates JSON schemas from Go types, registering named structs as components
+type schemaRegistry struct {
+	components map[string]Schema
+}
+
+func newSchemaRegistry() *schemaRegistry {
+	return &schemaRegistry{components: map[string]Schema{}}
+}
+
+// componentName follows swag naming, the package name followed by the type name (e.g. view.Recipe)
+func componentName(t reflect.Type) string {
+	return path.Base(t.PkgPath()) + "." + t.Name()
+}
+
+// schemaOf returns the schema of the type, a reference for named structs
+func (r *schemaRegistry) schemaOf(t reflect.Type) Schema {
+	switch {
+	case t == nullType:
+		return Schema{"type": "null"}
+	case t == anyType || t.Kind() == reflect.Interface:
+		return Schema{}
+	case t == timeType:
+		return Schema{"type": "string", "format": "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		return nullable(r.schemaOf(t.Elem()))
+	case reflect.Bool:
+		return Schema{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return Schema{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return Schema{"type": "number"}
+	case reflect.String:
+		return Schema{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		// nil slices are encoded as null
+		return nullable(Schema{"type": "array", "items": r.schemaOf(t.Elem())})
+	case reflect.Map:
+		return Schema{"type": "object", "additionalProperties": r.schemaOf(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return r.structSchema(t)
+		}
+		name := componentName(t)
+		if _, ok := r.components[name]; !ok {
+			// Register before generating so recursive types terminate
+			r.components[name] = Schema{}
+			r.components[name] = r.structSchema(t)
+		}
+		return Schema{"$ref": "#/components/schemas/" + name}
+	}
+	return Schema{}
+}
+
+// structSchema describes the JSON encoding of a struct. Fields are required
+// unless they can be nil (decoding leaves them nil when missing) or are
+// tagged with omitempty.
+func (r *schemaRegistry) structSchema(t reflect.Type) Schema {
+	properties := Schema{}
+	var required []string
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, opts, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		properties[name] = r.schemaOf(field.Type)
+		if !isNilable(field.Type) && !strings.Contains(opts, "omitempty") {
+			required = append(required, name)
+		}
+	}
+
+	schema := Schema{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+func isNilable(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Pointer, reflect.Slice, reflect.Map, reflect.Interface:
+		return true
+	}
+	return false
+}
+
+// nullable allows the schema to also be null
+func nullable(s Schema) Schema {
+	if typ, ok := s["type"].(string); ok && len(s) > 0 {
+		result := Schema{}
+		for k, v := range s {
+			result[k] = v
+		}
+		result["type"] = []string{typ, "null"}
+		return result
+	}
+	if len(s) == 0 {
+		return s
+	}
+	return Schema{"anyOf": []Schema{s, {"type": "null"}}}
+}
+
+// queryParameters describes the fields of a filter struct bound with form tags
+func (r *schemaRegistry) queryParameters(t reflect.Type) []*Parameter {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	var params []*Parameter
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := field.Tag.Get("form")
+		if !field.IsExported() || name == "" || name == "-" {
+			continue
+		}
+		fieldType := field.Type
+		if fieldType.Kind() == reflect.Pointer {
+			fieldType = fieldType.Elem()
+		}
+		params = append(params, &Parameter{
+			Name:   name,
+			In:     "query",
+			Schema: r.schemaOf(fieldType),
+		})
+	}
+	return params
+}
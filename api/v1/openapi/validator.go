@@ -0,0 +1,344 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+// documentURL is the resource name the document is registered as in the schema compiler
+const documentURL = "openapi.json"
+
+// Validator checks requests and responses against the OpenAPI document
+type Validator struct {
+	// StrictResponses replaces responses that don't match the document with a 500
+	StrictResponses bool
+	operations      map[string]*operationValidator
+}
+
+type operationValidator struct {
+	params []*Parameter
+	body   *jsonschema.Schema
+	// uploads are the content types of bodies that aren't validated
+	uploads   map[string]bool
+	responses map[int]*responseValidator
+	streaming bool
+}
+
+type responseValidator struct {
+	// schemas has the schema of every content type, it is empty for responses
+	// without content
+	schemas map[string]*jsonschema.Schema
+}
+
+// NewValidator compiles the schemas of every operation in the document
+func NewValidator(doc *Document) (*Validator, error) {
+	raw, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	compiler := jsonschema.NewCompiler()
+	compiler.Draft = jsonschema.Draft2020
+	if err := compiler.AddResource(documentURL, bytes.NewReader(raw)); err != nil {
+		return nil, err
+	}
+
+	v := &Validator{operations: map[string]*operationValidator{}}
+	for path, item := range doc.Paths {
+		for method, op := range item {
+			pointer := "/paths/" + escapePointer(path) + "/" + method
+			operation := &operationValidator{params: op.Parameters, responses: map[int]*responseValidator{}}
+
+			if op.RequestBody != nil {
+				operation.body, err = compiler.Compile(documentURL + "#" + pointer + "/requestBody/content/application~1json/schema")
+				if err != nil {
+					return nil, err
+				}
+				operation.uploads = map[string]bool{}
+				for contentType := range op.RequestBody.Content {
+					if contentType != contentTypeJSON {
+						operation.uploads[contentType] = true
+					}
+				}
+			}
+
+			for code, response := range op.Responses {
+				status, err := strconv.Atoi(code)
+				if err != nil {
+					return nil, fmt.Errorf("invalid status %q in %s %s", code, method, path)
+				}
+				validator := &responseValidator{schemas: map[string]*jsonschema.Schema{}}
+				for contentType := range response.Content {
+					if contentType == contentTypeEventStream {
+						operation.streaming = true
+						continue
+					}
+					validator.schemas[contentType], err = compiler.Compile(documentURL + "#" + pointer + "/responses/" + code + "/content/" + escapePointer(contentType) + "/schema")
+					if err != nil {
+						return nil, err
+					}
+				}
+				operation.responses[status] = validator
+			}
+
+			v.operations[operationKey(strings.ToUpper(method), ginPath(path))] = operation
+		}
+	}
+	return v, nil
+}
+
+// MustNewValidator builds a validator for the API document, panicking if it is invalid
+func MustNewValidator() *Validator {
+	v, err := NewValidator(Build())
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+// Middleware rejects requests that don't match the document with a 400 and
+// reports responses that don't match it. It must be used on the BasePath group.
+func (v *Validator) Middleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		operation, ok := v.operations[operationKey(ctx.Request.Method, strings.TrimPrefix(ctx.FullPath(), BasePath))]
+		if !ok {
+			ctx.Next()
+			return
+		}
+
+		// Validate the request
+		if err := operation.validateRequest(ctx); err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if operation.streaming {
+			ctx.Next()
+			return
+		}
+
+		// Buffer the response so it can be replaced if it's invalid
+		writer := &bufferedWriter{ResponseWriter: ctx.Writer, status: http.StatusOK}
+		ctx.Writer = writer
+		ctx.Next()
+		ctx.Writer = writer.ResponseWriter
+
+		if err := operation.validateResponse(writer.status, writer.Header().Get("Content-Type"), writer.body.Bytes()); err != nil {
+			log.Printf("openapi: invalid response for %s %s: %v", ctx.Request.Method, ctx.Request.URL.Path, err)
+			if v.StrictResponses {
+				writer.body.Reset()
+				writer.Header().Set("Content-Type", "application/json; charset=utf-8")
+				writer.status = http.StatusInternalServerError
+				json.NewEncoder(&writer.body).Encode(gin.H{"error": "invalid response: " + err.Error()})
+			}
+		}
+		writer.flush()
+	}
+}
+
+func (o *operationValidator) validateRequest(ctx *gin.Context) error {
+	for _, param := range o.params {
+		var value string
+		var present bool
+		switch param.In {
+		case "path":
+			value = ctx.Param(param.Name)
+			present = true
+		case "query":
+			value, present = ctx.GetQuery(param.Name)
+		case "header":
+			value = ctx.GetHeader(param.Name)
+			present = value != ""
+		}
+		if !present {
+			if param.Required {
+				return fmt.Errorf("missing %s parameter %q", param.In, param.Name)
+			}
+			continue
+		}
+		if err := checkParameter(param.Schema, value); err != nil {
+			return fmt.Errorf("invalid %s parameter %q: %w", param.In, param.Name, err)
+		}
+	}
+
+	if o.body == nil || o.uploads[ctx.ContentType()] {
+		return nil
+	}
+
+	// Read the body and restore it for the handler
+	raw, err := io.ReadAll(ctx.Request.Body)
+	if err != nil {
+		return err
+	}
+	ctx.Request.Body = io.NopCloser(bytes.NewReader(raw))
+
+	body, err := decodeJSON(raw)
+	if err != nil {
+		return fmt.Errorf("invalid request body: %w", err)
+	}
+	if err := o.body.Validate(body); err != nil {
+		return fmt.Errorf("invalid request body: %w", describe(err))
+	}
+	return nil
+}
+
+func (o *operationValidator) validateResponse(status int, contentType string, raw []byte) error {
+	response, ok := o.responses[status]
+	if !ok {
+		return fmt.Errorf("undocumented status %d", status)
+	}
+	if len(response.schemas) == 0 {
+		if len(raw) > 0 {
+			return fmt.Errorf("unexpected content for status %d", status)
+		}
+		return nil
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	if mediaType == "" {
+		mediaType = contentTypeJSON
+	}
+	schema, ok := response.schemas[mediaType]
+	if !ok {
+		return fmt.Errorf("undocumented content type %q for status %d", mediaType, status)
+	}
+
+	body, err := decodeJSON(raw)
+	if err != nil {
+		return err
+	}
+	return describe(schema.Validate(body))
+}
+
+// describe reduces schema validation errors to the failing locations of the
+// instance, leaving out the schema URLs
+func describe(err error) error {
+	validationErr, ok := err.(*jsonschema.ValidationError)
+	if !ok {
+		return err
+	}
+
+	var messages []string
+	var walk func(e *jsonschema.ValidationError)
+	walk = func(e *jsonschema.ValidationError) {
+		if len(e.Causes) == 0 {
+			location := e.InstanceLocation
+			if location == "" {
+				location = "/"
+			}
+			messages = append(messages, location+": "+e.Message)
+			return
+		}
+		for _, cause := range e.Causes {
+			walk(cause)
+		}
+	}
+	walk(validationErr)
+	return errors.New(strings.Join(messages, "; "))
+}
+
+// checkParameter checks a raw parameter value can be parsed as the schema type
+func checkParameter(schema Schema, value string) error {
+	var err error
+	switch schema["type"] {
+	case "integer":
+		_, err = strconv.ParseInt(value, 10, 64)
+	case "number":
+		_, err = strconv.ParseFloat(value, 64)
+	case "boolean":
+		_, err = strconv.ParseBool(value)
+	default:
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("%q is not a valid %s", value, schema["type"])
+	}
+	return nil
+}
+
+func decodeJSON(raw []byte) (interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+func operationKey(method, path string) string {
+	return method + " " + path
+}
+
+// ginPath converts an OpenAPI path (/recipes/{id}) back to gin syntax (/recipes/:id)
+func ginPath(path string) string {
+	path = strings.ReplaceAll(path, "{", ":")
+	return strings.ReplaceAll(path, "}", "")
+}
+
+// escapePointer escapes a JSON pointer token
+func escapePointer(token string) string {
+	token = strings.ReplaceAll(token, "~", "~0")
+	return strings.ReplaceAll(token, "/", "~1")
+}
+
+// bufferedWriter holds the response until it has been validated
+type bufferedWriter struct {
+	gin.ResponseWriter
+	status  int
+	written bool
+	body    bytes.Buffer
+}
+
+func (w *bufferedWriter) WriteHeader(code int) {
+	w.status = code
+	w.written = true
+}
+
+func (w *bufferedWriter) WriteHeaderNow() {
+	w.written = true
+}
+
+func (w *bufferedWriter) Write(data []byte) (int, error) {
+	w.written = true
+	return w.body.Write(data)
+}
+
+func (w *bufferedWriter) WriteString(s string) (int, error) {
+	w.written = true
+	return w.body.WriteString(s)
+}
+
+func (w *bufferedWriter) Status() int {
+	return w.status
+}
+
+func (w *bufferedWriter) Size() int {
+	if !w.written {
+		return -1
+	}
+	return w.body.Len()
+}
+
+func (w *bufferedWriter) Written() bool {
+	return w.written
+}
+
+// flush writes the buffered response to the underlying writer
+func (w *bufferedWriter) flush() {
+	w.ResponseWriter.WriteHeader(w.status)
+	if w.body.Len() == 0 {
+		w.ResponseWriter.WriteHeaderNow()
+		return
+	}
+	w.ResponseWriter.Write(w.body.Bytes())
+}
@@ -15,7 +15,7 @@ func (i *CookingUnit) ApplyTo(ingredient *entity.CookingUnit) {
 }
 
 func (i *CookingUnit) ToEntity() *entity.CookingUnit {
-	return &entity.CookingUnit{
-		Name: *i.Name,
-	}
+	unit := &entity.CookingUnit{}
+	i.ApplyTo(unit)
+	return unit
 }
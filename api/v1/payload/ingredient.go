@@ -19,8 +19,12 @@ func (i *Ingredient) ApplyTo(ingredient *entity.Ingredient) {
 }
 
 func (i *Ingredient) ToEntity() *entity.Ingredient {
-	return &entity.Ingredient{
-		Name: *i.Name,
-		Type: *i.Type,
-	}
+	ingredient := &entity.Ingredient{}
+	i.ApplyTo(ingredient)
+	return ingredient
+}
+
+// IngredientMerge lists the duplicates merged into an ingredient
+type IngredientMerge struct {
+	IDs []int64 `json:"ids"`
 }
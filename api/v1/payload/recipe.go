@@ -1,6 +1,8 @@
 package payload
 
 import (
+	"time"
+
 	"github.com/TomeuUris/recipes-catalog/api/v1/view"
 	"github.com/TomeuUris/recipes-catalog/pkg/entity"
 )
@@ -8,8 +10,13 @@ import (
 // Recipe is the payload for the recipe entity
 type Recipe struct {
 	Name        *string           `json:"name"`
+	Description *string           `json:"description"`
 	Ingredients []view.Ingredient `json:"ingredients"`
 	Steps       []string          `json:"steps"`
+	PrepMinutes *int              `json:"prep_minutes"`
+	CookMinutes *int              `json:"cook_minutes"`
+	Yield       *string           `json:"yield"`
+	Calories    *int              `json:"calories"`
 }
 
 // Convert the payload to the entity
@@ -22,11 +29,15 @@ func (p *Recipe) ToEntity() *entity.Recipe {
 			Type: ingredient.Type,
 		}
 	}
-	return &entity.Recipe{
-		Name:        *p.Name,
+	recipe := &entity.Recipe{
 		Ingredients: ingredients,
 		Steps:       p.Steps,
 	}
+	if p.Name != nil {
+		recipe.Name = *p.Name
+	}
+	p.applyDetails(recipe)
+	return recipe
 }
 
 // Apply the payload to the entity
@@ -34,6 +45,7 @@ func (p *Recipe) ApplyTo(e *entity.Recipe) {
 	if p.Name != nil {
 		e.Name = *p.Name
 	}
+	p.applyDetails(e)
 	if p.Steps != nil {
 		e.Steps = p.Steps
 	}
@@ -49,3 +61,27 @@ func (p *Recipe) ApplyTo(e *entity.Recipe) {
 		e.Ingredients = ingredientsList
 	}
 }
+
+// applyDetails sets the description, times, yield and calories given
+func (p *Recipe) applyDetails(e *entity.Recipe) {
+	if p.Description != nil {
+		e.Description = *p.Description
+	}
+	if p.PrepMinutes != nil {
+		e.PrepTime = time.Duration(*p.PrepMinutes) * time.Minute
+	}
+	if p.CookMinutes != nil {
+		e.CookTime = time.Duration(*p.CookMinutes) * time.Minute
+	}
+	if p.Yield != nil {
+		e.Yield = *p.Yield
+	}
+	if p.Calories != nil {
+		e.Calories = *p.Calories
+	}
+}
+
+// RecipeImport is the page a recipe is imported from
+type RecipeImport struct {
+	URL string `json:"url"`
+}
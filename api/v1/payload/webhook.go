@@ -0,0 +1,32 @@
+package payload
+
+import "github.com/TomeuUris/recipes-catalog/pkg/entity"
+
+type Webhook struct {
+	URL    *string  `json:"url"`
+	Secret *string  `json:"secret"`
+	Events []string `json:"events"`
+	Active *bool    `json:"active"`
+}
+
+func (w *Webhook) ApplyTo(webhook *entity.Webhook) {
+	if w.URL != nil {
+		webhook.URL = *w.URL
+	}
+	if w.Secret != nil {
+		webhook.Secret = *w.Secret
+	}
+	if w.Events != nil {
+		webhook.Events = w.Events
+	}
+	if w.Active != nil {
+		webhook.Active = *w.Active
+	}
+}
+
+// ToEntity converts the payload to a webhook, active unless stated otherwise
+func (w *Webhook) ToEntity() *entity.Webhook {
+	webhook := &entity.Webhook{Active: true}
+	w.ApplyTo(webhook)
+	return webhook
+}
@@ -0,0 +1,682 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.30.0
+// 	protoc        v4.25.1
+// source: cooking_unit.proto
+
+package pb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	emptypb "google.golang.org/protobuf/types/known/emptypb"
+	fieldmaskpb "google.golang.org/protobuf/types/known/fieldmaskpb"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type CookingUnit struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id   int64  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name string `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+}
+
+func (x *CookingUnit) Reset() {
+	*x = CookingUnit{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_cooking_unit_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CookingUnit) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CookingUnit) ProtoMessage() {}
+
+func (x *CookingUnit) ProtoReflect() protoreflect.Message {
+	mi := &file_cooking_unit_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CookingUnit.ProtoReflect.Descriptor instead.
+func (*CookingUnit) Descriptor() ([]byte, []int) {
+	return file_cooking_unit_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *CookingUnit) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *CookingUnit) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+type ListCookingUnitsRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+}
+
+func (x *ListCookingUnitsRequest) Reset() {
+	*x = ListCookingUnitsRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_cooking_unit_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ListCookingUnitsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListCookingUnitsRequest) ProtoMessage() {}
+
+func (x *ListCookingUnitsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_cooking_unit_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListCookingUnitsRequest.ProtoReflect.Descriptor instead.
+func (*ListCookingUnitsRequest) Descriptor() ([]byte, []int) {
+	return file_cooking_unit_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ListCookingUnitsRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+type ListCookingUnitsResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	CookingUnits []*CookingUnit `protobuf:"bytes,1,rep,name=cooking_units,json=cookingUnits,proto3" json:"cooking_units,omitempty"`
+}
+
+func (x *ListCookingUnitsResponse) Reset() {
+	*x = ListCookingUnitsResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_cooking_unit_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ListCookingUnitsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListCookingUnitsResponse) ProtoMessage() {}
+
+func (x *ListCookingUnitsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_cooking_unit_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListCookingUnitsResponse.ProtoReflect.Descriptor instead.
+func (*ListCookingUnitsResponse) Descriptor() ([]byte, []int) {
+	return file_cooking_unit_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ListCookingUnitsResponse) GetCookingUnits() []*CookingUnit {
+	if x != nil {
+		return x.CookingUnits
+	}
+	return nil
+}
+
+type CountCookingUnitsRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+}
+
+func (x *CountCookingUnitsRequest) Reset() {
+	*x = CountCookingUnitsRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_cooking_unit_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CountCookingUnitsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CountCookingUnitsRequest) ProtoMessage() {}
+
+func (x *CountCookingUnitsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_cooking_unit_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CountCookingUnitsRequest.ProtoReflect.Descriptor instead.
+func (*CountCookingUnitsRequest) Descriptor() ([]byte, []int) {
+	return file_cooking_unit_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *CountCookingUnitsRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+type GetCookingUnitRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id int64 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+}
+
+func (x *GetCookingUnitRequest) Reset() {
+	*x = GetCookingUnitRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_cooking_unit_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetCookingUnitRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetCookingUnitRequest) ProtoMessage() {}
+
+func (x *GetCookingUnitRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_cooking_unit_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetCookingUnitRequest.ProtoReflect.Descriptor instead.
+func (*GetCookingUnitRequest) Descriptor() ([]byte, []int) {
+	return file_cooking_unit_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *GetCookingUnitRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+type CreateCookingUnitRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	CookingUnit *CookingUnit `protobuf:"bytes,1,opt,name=cooking_unit,json=cookingUnit,proto3" json:"cooking_unit,omitempty"`
+}
+
+func (x *CreateCookingUnitRequest) Reset() {
+	*x = CreateCookingUnitRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_cooking_unit_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CreateCookingUnitRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateCookingUnitRequest) ProtoMessage() {}
+
+func (x *CreateCookingUnitRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_cooking_unit_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateCookingUnitRequest.ProtoReflect.Descriptor instead.
+func (*CreateCookingUnitRequest) Descriptor() ([]byte, []int) {
+	return file_cooking_unit_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *CreateCookingUnitRequest) GetCookingUnit() *CookingUnit {
+	if x != nil {
+		return x.CookingUnit
+	}
+	return nil
+}
+
+// UpdateCookingUnitRequest only applies the fields listed in update_mask.
+// An empty mask updates every field.
+type UpdateCookingUnitRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	CookingUnit *CookingUnit           `protobuf:"bytes,1,opt,name=cooking_unit,json=cookingUnit,proto3" json:"cooking_unit,omitempty"`
+	UpdateMask  *fieldmaskpb.FieldMask `protobuf:"bytes,2,opt,name=update_mask,json=updateMask,proto3" json:"update_mask,omitempty"`
+}
+
+func (x *UpdateCookingUnitRequest) Reset() {
+	*x = UpdateCookingUnitRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_cooking_unit_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *UpdateCookingUnitRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateCookingUnitRequest) ProtoMessage() {}
+
+func (x *UpdateCookingUnitRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_cooking_unit_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateCookingUnitRequest.ProtoReflect.Descriptor instead.
+func (*UpdateCookingUnitRequest) Descriptor() ([]byte, []int) {
+	return file_cooking_unit_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *UpdateCookingUnitRequest) GetCookingUnit() *CookingUnit {
+	if x != nil {
+		return x.CookingUnit
+	}
+	return nil
+}
+
+func (x *UpdateCookingUnitRequest) GetUpdateMask() *fieldmaskpb.FieldMask {
+	if x != nil {
+		return x.UpdateMask
+	}
+	return nil
+}
+
+type DeleteCookingUnitRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id int64 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+}
+
+func (x *DeleteCookingUnitRequest) Reset() {
+	*x = DeleteCookingUnitRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_cooking_unit_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DeleteCookingUnitRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteCookingUnitRequest) ProtoMessage() {}
+
+func (x *DeleteCookingUnitRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_cooking_unit_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteCookingUnitRequest.ProtoReflect.Descriptor instead.
+func (*DeleteCookingUnitRequest) Descriptor() ([]byte, []int) {
+	return file_cooking_unit_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *DeleteCookingUnitRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+var File_cooking_unit_proto protoreflect.FileDescriptor
+
+var file_cooking_unit_proto_rawDesc = []byte{
+	0x0a, 0x12, 0x63, 0x6f, 0x6f, 0x6b, 0x69, 0x6e, 0x67, 0x5f, 0x75, 0x6e, 0x69, 0x74, 0x2e, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x12, 0x11, 0x72, 0x65, 0x63, 0x69, 0x70, 0x65, 0x73, 0x63, 0x61, 0x74,
+	0x61, 0x6c, 0x6f, 0x67, 0x2e, 0x76, 0x31, 0x1a, 0x1b, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x65, 0x6d, 0x70, 0x74, 0x79, 0x2e, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x20, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x66, 0x69, 0x65, 0x6c, 0x64, 0x5f, 0x6d, 0x61, 0x73, 0x6b,
+	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x10, 0x69, 0x6e, 0x67, 0x72, 0x65, 0x64, 0x69, 0x65,
+	0x6e, 0x74, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0x31, 0x0a, 0x0b, 0x43, 0x6f, 0x6f, 0x6b,
+	0x69, 0x6e, 0x67, 0x55, 0x6e, 0x69, 0x74, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x03, 0x52, 0x02, 0x69, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x22, 0x2d, 0x0a, 0x17, 0x4c,
+	0x69, 0x73, 0x74, 0x43, 0x6f, 0x6f, 0x6b, 0x69, 0x6e, 0x67, 0x55, 0x6e, 0x69, 0x74, 0x73, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x22, 0x5f, 0x0a, 0x18, 0x4c, 0x69,
+	0x73, 0x74, 0x43, 0x6f, 0x6f, 0x6b, 0x69, 0x6e, 0x67, 0x55, 0x6e, 0x69, 0x74, 0x73, 0x52, 0x65,
+	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x43, 0x0a, 0x0d, 0x63, 0x6f, 0x6f, 0x6b, 0x69, 0x6e,
+	0x67, 0x5f, 0x75, 0x6e, 0x69, 0x74, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1e, 0x2e,
+	0x72, 0x65, 0x63, 0x69, 0x70, 0x65, 0x73, 0x63, 0x61, 0x74, 0x61, 0x6c, 0x6f, 0x67, 0x2e, 0x76,
+	0x31, 0x2e, 0x43, 0x6f, 0x6f, 0x6b, 0x69, 0x6e, 0x67, 0x55, 0x6e, 0x69, 0x74, 0x52, 0x0c, 0x63,
+	0x6f, 0x6f, 0x6b, 0x69, 0x6e, 0x67, 0x55, 0x6e, 0x69, 0x74, 0x73, 0x22, 0x2e, 0x0a, 0x18, 0x43,
+	0x6f, 0x75, 0x6e, 0x74, 0x43, 0x6f, 0x6f, 0x6b, 0x69, 0x6e, 0x67, 0x55, 0x6e, 0x69, 0x74, 0x73,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x22, 0x27, 0x0a, 0x15, 0x47,
+	0x65, 0x74, 0x43, 0x6f, 0x6f, 0x6b, 0x69, 0x6e, 0x67, 0x55, 0x6e, 0x69, 0x74, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03,
+	0x52, 0x02, 0x69, 0x64, 0x22, 0x5d, 0x0a, 0x18, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x43, 0x6f,
+	0x6f, 0x6b, 0x69, 0x6e, 0x67, 0x55, 0x6e, 0x69, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x12, 0x41, 0x0a, 0x0c, 0x63, 0x6f, 0x6f, 0x6b, 0x69, 0x6e, 0x67, 0x5f, 0x75, 0x6e, 0x69, 0x74,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1e, 0x2e, 0x72, 0x65, 0x63, 0x69, 0x70, 0x65, 0x73,
+	0x63, 0x61, 0x74, 0x61, 0x6c, 0x6f, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x6f, 0x6f, 0x6b, 0x69,
+	0x6e, 0x67, 0x55, 0x6e, 0x69, 0x74, 0x52, 0x0b, 0x63, 0x6f, 0x6f, 0x6b, 0x69, 0x6e, 0x67, 0x55,
+	0x6e, 0x69, 0x74, 0x22, 0x9a, 0x01, 0x0a, 0x18, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x43, 0x6f,
+	0x6f, 0x6b, 0x69, 0x6e, 0x67, 0x55, 0x6e, 0x69, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x12, 0x41, 0x0a, 0x0c, 0x63, 0x6f, 0x6f, 0x6b, 0x69, 0x6e, 0x67, 0x5f, 0x75, 0x6e, 0x69, 0x74,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1e, 0x2e, 0x72, 0x65, 0x63, 0x69, 0x70, 0x65, 0x73,
+	0x63, 0x61, 0x74, 0x61, 0x6c, 0x6f, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x6f, 0x6f, 0x6b, 0x69,
+	0x6e, 0x67, 0x55, 0x6e, 0x69, 0x74, 0x52, 0x0b, 0x63, 0x6f, 0x6f, 0x6b, 0x69, 0x6e, 0x67, 0x55,
+	0x6e, 0x69, 0x74, 0x12, 0x3b, 0x0a, 0x0b, 0x75, 0x70, 0x64, 0x61, 0x74, 0x65, 0x5f, 0x6d, 0x61,
+	0x73, 0x6b, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c,
+	0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x46, 0x69, 0x65, 0x6c, 0x64,
+	0x4d, 0x61, 0x73, 0x6b, 0x52, 0x0a, 0x75, 0x70, 0x64, 0x61, 0x74, 0x65, 0x4d, 0x61, 0x73, 0x6b,
+	0x22, 0x2a, 0x0a, 0x18, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x43, 0x6f, 0x6f, 0x6b, 0x69, 0x6e,
+	0x67, 0x55, 0x6e, 0x69, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x0e, 0x0a, 0x02,
+	0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x02, 0x69, 0x64, 0x32, 0xdf, 0x04, 0x0a,
+	0x12, 0x43, 0x6f, 0x6f, 0x6b, 0x69, 0x6e, 0x67, 0x55, 0x6e, 0x69, 0x74, 0x53, 0x65, 0x72, 0x76,
+	0x69, 0x63, 0x65, 0x12, 0x6b, 0x0a, 0x10, 0x4c, 0x69, 0x73, 0x74, 0x43, 0x6f, 0x6f, 0x6b, 0x69,
+	0x6e, 0x67, 0x55, 0x6e, 0x69, 0x74, 0x73, 0x12, 0x2a, 0x2e, 0x72, 0x65, 0x63, 0x69, 0x70, 0x65,
+	0x73, 0x63, 0x61, 0x74, 0x61, 0x6c, 0x6f, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x4c, 0x69, 0x73, 0x74,
+	0x43, 0x6f, 0x6f, 0x6b, 0x69, 0x6e, 0x67, 0x55, 0x6e, 0x69, 0x74, 0x73, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x1a, 0x2b, 0x2e, 0x72, 0x65, 0x63, 0x69, 0x70, 0x65, 0x73, 0x63, 0x61, 0x74,
+	0x61, 0x6c, 0x6f, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x43, 0x6f, 0x6f, 0x6b,
+	0x69, 0x6e, 0x67, 0x55, 0x6e, 0x69, 0x74, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
+	0x12, 0x62, 0x0a, 0x11, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x43, 0x6f, 0x6f, 0x6b, 0x69, 0x6e, 0x67,
+	0x55, 0x6e, 0x69, 0x74, 0x73, 0x12, 0x2b, 0x2e, 0x72, 0x65, 0x63, 0x69, 0x70, 0x65, 0x73, 0x63,
+	0x61, 0x74, 0x61, 0x6c, 0x6f, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x43,
+	0x6f, 0x6f, 0x6b, 0x69, 0x6e, 0x67, 0x55, 0x6e, 0x69, 0x74, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x1a, 0x20, 0x2e, 0x72, 0x65, 0x63, 0x69, 0x70, 0x65, 0x73, 0x63, 0x61, 0x74, 0x61,
+	0x6c, 0x6f, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x52, 0x65, 0x73, 0x70,
+	0x6f, 0x6e, 0x73, 0x65, 0x12, 0x5a, 0x0a, 0x0e, 0x47, 0x65, 0x74, 0x43, 0x6f, 0x6f, 0x6b, 0x69,
+	0x6e, 0x67, 0x55, 0x6e, 0x69, 0x74, 0x12, 0x28, 0x2e, 0x72, 0x65, 0x63, 0x69, 0x70, 0x65, 0x73,
+	0x63, 0x61, 0x74, 0x61, 0x6c, 0x6f, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65, 0x74, 0x43, 0x6f,
+	0x6f, 0x6b, 0x69, 0x6e, 0x67, 0x55, 0x6e, 0x69, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x1a, 0x1e, 0x2e, 0x72, 0x65, 0x63, 0x69, 0x70, 0x65, 0x73, 0x63, 0x61, 0x74, 0x61, 0x6c, 0x6f,
+	0x67, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x6f, 0x6f, 0x6b, 0x69, 0x6e, 0x67, 0x55, 0x6e, 0x69, 0x74,
+	0x12, 0x60, 0x0a, 0x11, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x43, 0x6f, 0x6f, 0x6b, 0x69, 0x6e,
+	0x67, 0x55, 0x6e, 0x69, 0x74, 0x12, 0x2b, 0x2e, 0x72, 0x65, 0x63, 0x69, 0x70, 0x65, 0x73, 0x63,
+	0x61, 0x74, 0x61, 0x6c, 0x6f, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65,
+	0x43, 0x6f, 0x6f, 0x6b, 0x69, 0x6e, 0x67, 0x55, 0x6e, 0x69, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x1a, 0x1e, 0x2e, 0x72, 0x65, 0x63, 0x69, 0x70, 0x65, 0x73, 0x63, 0x61, 0x74, 0x61,
+	0x6c, 0x6f, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x6f, 0x6f, 0x6b, 0x69, 0x6e, 0x67, 0x55, 0x6e,
+	0x69, 0x74, 0x12, 0x60, 0x0a, 0x11, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x43, 0x6f, 0x6f, 0x6b,
+	0x69, 0x6e, 0x67, 0x55, 0x6e, 0x69, 0x74, 0x12, 0x2b, 0x2e, 0x72, 0x65, 0x63, 0x69, 0x70, 0x65,
+	0x73, 0x63, 0x61, 0x74, 0x61, 0x6c, 0x6f, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x55, 0x70, 0x64, 0x61,
+	0x74, 0x65, 0x43, 0x6f, 0x6f, 0x6b, 0x69, 0x6e, 0x67, 0x55, 0x6e, 0x69, 0x74, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x1a, 0x1e, 0x2e, 0x72, 0x65, 0x63, 0x69, 0x70, 0x65, 0x73, 0x63, 0x61,
+	0x74, 0x61, 0x6c, 0x6f, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x6f, 0x6f, 0x6b, 0x69, 0x6e, 0x67,
+	0x55, 0x6e, 0x69, 0x74, 0x12, 0x58, 0x0a, 0x11, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x43, 0x6f,
+	0x6f, 0x6b, 0x69, 0x6e, 0x67, 0x55, 0x6e, 0x69, 0x74, 0x12, 0x2b, 0x2e, 0x72, 0x65, 0x63, 0x69,
+	0x70, 0x65, 0x73, 0x63, 0x61, 0x74, 0x61, 0x6c, 0x6f, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x44, 0x65,
+	0x6c, 0x65, 0x74, 0x65, 0x43, 0x6f, 0x6f, 0x6b, 0x69, 0x6e, 0x67, 0x55, 0x6e, 0x69, 0x74, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x42, 0x33,
+	0x5a, 0x31, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x54, 0x6f, 0x6d,
+	0x65, 0x75, 0x55, 0x72, 0x69, 0x73, 0x2f, 0x72, 0x65, 0x63, 0x69, 0x70, 0x65, 0x73, 0x2d, 0x63,
+	0x61, 0x74, 0x61, 0x6c, 0x6f, 0x67, 0x2f, 0x61, 0x70, 0x69, 0x2f, 0x76, 0x31, 0x2f, 0x70, 0x62,
+	0x3b, 0x70, 0x62, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_cooking_unit_proto_rawDescOnce sync.Once
+	file_cooking_unit_proto_rawDescData = file_cooking_unit_proto_rawDesc
+)
+
+func file_cooking_unit_proto_rawDescGZIP() []byte {
+	file_cooking_unit_proto_rawDescOnce.Do(func() {
+		file_cooking_unit_proto_rawDescData = protoimpl.X.CompressGZIP(file_cooking_unit_proto_rawDescData)
+	})
+	return file_cooking_unit_proto_rawDescData
+}
+
+var file_cooking_unit_proto_msgTypes = make([]protoimpl.MessageInfo, 8)
+var file_cooking_unit_proto_goTypes = []interface{}{
+	(*CookingUnit)(nil),              // 0: recipescatalog.v1.CookingUnit
+	(*ListCookingUnitsRequest)(nil),  // 1: recipescatalog.v1.ListCookingUnitsRequest
+	(*ListCookingUnitsResponse)(nil), // 2: recipescatalog.v1.ListCookingUnitsResponse
+	(*CountCookingUnitsRequest)(nil), // 3: recipescatalog.v1.CountCookingUnitsRequest
+	(*GetCookingUnitRequest)(nil),    // 4: recipescatalog.v1.GetCookingUnitRequest
+	(*CreateCookingUnitRequest)(nil), // 5: recipescatalog.v1.CreateCookingUnitRequest
+	(*UpdateCookingUnitRequest)(nil), // 6: recipescatalog.v1.UpdateCookingUnitRequest
+	(*DeleteCookingUnitRequest)(nil), // 7: recipescatalog.v1.DeleteCookingUnitRequest
+	(*fieldmaskpb.FieldMask)(nil),    // 8: google.protobuf.FieldMask
+	(*CountResponse)(nil),            // 9: recipescatalog.v1.CountResponse
+	(*emptypb.Empty)(nil),            // 10: google.protobuf.Empty
+}
+var file_cooking_unit_proto_depIdxs = []int32{
+	0,  // 0: recipescatalog.v1.ListCookingUnitsResponse.cooking_units:type_name -> recipescatalog.v1.CookingUnit
+	0,  // 1: recipescatalog.v1.CreateCookingUnitRequest.cooking_unit:type_name -> recipescatalog.v1.CookingUnit
+	0,  // 2: recipescatalog.v1.UpdateCookingUnitRequest.cooking_unit:type_name -> recipescatalog.v1.CookingUnit
+	8,  // 3: recipescatalog.v1.UpdateCookingUnitRequest.update_mask:type_name -> google.protobuf.FieldMask
+	1,  // 4: recipescatalog.v1.CookingUnitService.ListCookingUnits:input_type -> recipescatalog.v1.ListCookingUnitsRequest
+	3,  // 5: recipescatalog.v1.CookingUnitService.CountCookingUnits:input_type -> recipescatalog.v1.CountCookingUnitsRequest
+	4,  // 6: recipescatalog.v1.CookingUnitService.GetCookingUnit:input_type -> recipescatalog.v1.GetCookingUnitRequest
+	5,  // 7: recipescatalog.v1.CookingUnitService.CreateCookingUnit:input_type -> recipescatalog.v1.CreateCookingUnitRequest
+	6,  // 8: recipescatalog.v1.CookingUnitService.UpdateCookingUnit:input_type -> recipescatalog.v1.UpdateCookingUnitRequest
+	7,  // 9: recipescatalog.v1.CookingUnitService.DeleteCookingUnit:input_type -> recipescatalog.v1.DeleteCookingUnitRequest
+	2,  // 10: recipescatalog.v1.CookingUnitService.ListCookingUnits:output_type -> recipescatalog.v1.ListCookingUnitsResponse
+	9,  // 11: recipescatalog.v1.CookingUnitService.CountCookingUnits:output_type -> recipescatalog.v1.CountResponse
+	0,  // 12: recipescatalog.v1.CookingUnitService.GetCookingUnit:output_type -> recipescatalog.v1.CookingUnit
+	0,  // 13: recipescatalog.v1.CookingUnitService.CreateCookingUnit:output_type -> recipescatalog.v1.CookingUnit
+	0,  // 14: recipescatalog.v1.CookingUnitService.UpdateCookingUnit:output_type -> recipescatalog.v1.CookingUnit
+	10, // 15: recipescatalog.v1.CookingUnitService.DeleteCookingUnit:output_type -> google.protobuf.Empty
+	10, // [10:16] is the sub-list for method output_type
+	4,  // [4:10] is the sub-list for method input_type
+	4,  // [4:4] is the sub-list for extension type_name
+	4,  // [4:4] is the sub-list for extension extendee
+	0,  // [0:4] is the sub-list for field type_name
+}
+
+func init() { file_cooking_unit_proto_init() }
+func file_cooking_unit_proto_init() {
+	if File_cooking_unit_proto != nil {
+		return
+	}
+	file_ingredient_proto_init()
+	if !protoimpl.UnsafeEnabled {
+		file_cooking_unit_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CookingUnit); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_cooking_unit_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ListCookingUnitsRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_cooking_unit_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ListCookingUnitsResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_cooking_unit_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CountCookingUnitsRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_cooking_unit_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetCookingUnitRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_cooking_unit_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CreateCookingUnitRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_cooking_unit_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*UpdateCookingUnitRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_cooking_unit_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*DeleteCookingUnitRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_cooking_unit_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   8,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_cooking_unit_proto_goTypes,
+		DependencyIndexes: file_cooking_unit_proto_depIdxs,
+		MessageInfos:      file_cooking_unit_proto_msgTypes,
+	}.Build()
+	File_cooking_unit_proto = out.File
+	file_cooking_unit_proto_rawDesc = nil
+	file_cooking_unit_proto_goTypes = nil
+	file_cooking_unit_proto_depIdxs = nil
+}
@@ -0,0 +1,295 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             v4.25.1
+// source: cooking_unit.proto
+
+package pb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+	emptypb "google.golang.org/protobuf/types/known/emptypb"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	CookingUnitService_ListCookingUnits_FullMethodName  = "/recipescatalog.v1.CookingUnitService/ListCookingUnits"
+	CookingUnitService_CountCookingUnits_FullMethodName = "/recipescatalog.v1.CookingUnitService/CountCookingUnits"
+	CookingUnitService_GetCookingUnit_FullMethodName    = "/recipescatalog.v1.CookingUnitService/GetCookingUnit"
+	CookingUnitService_CreateCookingUnit_FullMethodName = "/recipescatalog.v1.CookingUnitService/CreateCookingUnit"
+	CookingUnitService_UpdateCookingUnit_FullMethodName = "/recipescatalog.v1.CookingUnitService/UpdateCookingUnit"
+	CookingUnitService_DeleteCookingUnit_FullMethodName = "/recipescatalog.v1.CookingUnitService/DeleteCookingUnit"
+)
+
+// CookingUnitServiceClient is the client API for CookingUnitService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type CookingUnitServiceClient interface {
+	ListCookingUnits(ctx context.Context, in *ListCookingUnitsRequest, opts ...grpc.CallOption) (*ListCookingUnitsResponse, error)
+	CountCookingUnits(ctx context.Context, in *CountCookingUnitsRequest, opts ...grpc.CallOption) (*CountResponse, error)
+	GetCookingUnit(ctx context.Context, in *GetCookingUnitRequest, opts ...grpc.CallOption) (*CookingUnit, error)
+	CreateCookingUnit(ctx context.Context, in *CreateCookingUnitRequest, opts ...grpc.CallOption) (*CookingUnit, error)
+	UpdateCookingUnit(ctx context.Context, in *UpdateCookingUnitRequest, opts ...grpc.CallOption) (*CookingUnit, error)
+	DeleteCookingUnit(ctx context.Context, in *DeleteCookingUnitRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+}
+
+type cookingUnitServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewCookingUnitServiceClient(cc grpc.ClientConnInterface) CookingUnitServiceClient {
+	return &cookingUnitServiceClient{cc}
+}
+
+func (c *cookingUnitServiceClient) ListCookingUnits(ctx context.Context, in *ListCookingUnitsRequest, opts ...grpc.CallOption) (*ListCookingUnitsResponse, error) {
+	out := new(ListCookingUnitsResponse)
+	err := c.cc.Invoke(ctx, CookingUnitService_ListCookingUnits_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cookingUnitServiceClient) CountCookingUnits(ctx context.Context, in *CountCookingUnitsRequest, opts ...grpc.CallOption) (*CountResponse, error) {
+	out := new(CountResponse)
+	err := c.cc.Invoke(ctx, CookingUnitService_CountCookingUnits_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cookingUnitServiceClient) GetCookingUnit(ctx context.Context, in *GetCookingUnitRequest, opts ...grpc.CallOption) (*CookingUnit, error) {
+	out := new(CookingUnit)
+	err := c.cc.Invoke(ctx, CookingUnitService_GetCookingUnit_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cookingUnitServiceClient) CreateCookingUnit(ctx context.Context, in *CreateCookingUnitRequest, opts ...grpc.CallOption) (*CookingUnit, error) {
+	out := new(CookingUnit)
+	err := c.cc.Invoke(ctx, CookingUnitService_CreateCookingUnit_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cookingUnitServiceClient) UpdateCookingUnit(ctx context.Context, in *UpdateCookingUnitRequest, opts ...grpc.CallOption) (*CookingUnit, error) {
+	out := new(CookingUnit)
+	err := c.cc.Invoke(ctx, CookingUnitService_UpdateCookingUnit_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cookingUnitServiceClient) DeleteCookingUnit(ctx context.Context, in *DeleteCookingUnitRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, CookingUnitService_DeleteCookingUnit_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// CookingUnitServiceServer is the server API for CookingUnitService service.
+// All implementations must embed UnimplementedCookingUnitServiceServer
+// for forward compatibility
+type CookingUnitServiceServer interface {
+	ListCookingUnits(context.Context, *ListCookingUnitsRequest) (*ListCookingUnitsResponse, error)
+	CountCookingUnits(context.Context, *CountCookingUnitsRequest) (*CountResponse, error)
+	GetCookingUnit(context.Context, *GetCookingUnitRequest) (*CookingUnit, error)
+	CreateCookingUnit(context.Context, *CreateCookingUnitRequest) (*CookingUnit, error)
+	UpdateCookingUnit(context.Context, *UpdateCookingUnitRequest) (*CookingUnit, error)
+	DeleteCookingUnit(context.Context, *DeleteCookingUnitRequest) (*emptypb.Empty, error)
+	mustEmbedUnimplementedCookingUnitServiceServer()
+}
+
+// UnimplementedCookingUnitServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedCookingUnitServiceServer struct {
+}
+
+func (UnimplementedCookingUnitServiceServer) ListCookingUnits(context.Context, *ListCookingUnitsRequest) (*ListCookingUnitsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListCookingUnits not implemented")
+}
+func (UnimplementedCookingUnitServiceServer) CountCookingUnits(context.Context, *CountCookingUnitsRequest) (*CountResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CountCookingUnits not implemented")
+}
+func (UnimplementedCookingUnitServiceServer) GetCookingUnit(context.Context, *GetCookingUnitRequest) (*CookingUnit, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetCookingUnit not implemented")
+}
+func (UnimplementedCookingUnitServiceServer) CreateCookingUnit(context.Context, *CreateCookingUnitRequest) (*CookingUnit, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateCookingUnit not implemented")
+}
+func (UnimplementedCookingUnitServiceServer) UpdateCookingUnit(context.Context, *UpdateCookingUnitRequest) (*CookingUnit, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateCookingUnit not implemented")
+}
+func (UnimplementedCookingUnitServiceServer) DeleteCookingUnit(context.Context, *DeleteCookingUnitRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteCookingUnit not implemented")
+}
+func (UnimplementedCookingUnitServiceServer) mustEmbedUnimplementedCookingUnitServiceServer() {}
+
+// UnsafeCookingUnitServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to CookingUnitServiceServer will
+// result in compilation errors.
+type UnsafeCookingUnitServiceServer interface {
+	mustEmbedUnimplementedCookingUnitServiceServer()
+}
+
+func RegisterCookingUnitServiceServer(s grpc.ServiceRegistrar, srv CookingUnitServiceServer) {
+	s.RegisterService(&CookingUnitService_ServiceDesc, srv)
+}
+
+func _CookingUnitService_ListCookingUnits_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListCookingUnitsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CookingUnitServiceServer).ListCookingUnits(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CookingUnitService_ListCookingUnits_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CookingUnitServiceServer).ListCookingUnits(ctx, req.(*ListCookingUnitsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CookingUnitService_CountCookingUnits_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CountCookingUnitsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CookingUnitServiceServer).CountCookingUnits(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CookingUnitService_CountCookingUnits_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CookingUnitServiceServer).CountCookingUnits(ctx, req.(*CountCookingUnitsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CookingUnitService_GetCookingUnit_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetCookingUnitRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CookingUnitServiceServer).GetCookingUnit(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CookingUnitService_GetCookingUnit_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CookingUnitServiceServer).GetCookingUnit(ctx, req.(*GetCookingUnitRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CookingUnitService_CreateCookingUnit_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateCookingUnitRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CookingUnitServiceServer).CreateCookingUnit(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CookingUnitService_CreateCookingUnit_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CookingUnitServiceServer).CreateCookingUnit(ctx, req.(*CreateCookingUnitRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CookingUnitService_UpdateCookingUnit_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateCookingUnitRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CookingUnitServiceServer).UpdateCookingUnit(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CookingUnitService_UpdateCookingUnit_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CookingUnitServiceServer).UpdateCookingUnit(ctx, req.(*UpdateCookingUnitRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CookingUnitService_DeleteCookingUnit_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteCookingUnitRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CookingUnitServiceServer).DeleteCookingUnit(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CookingUnitService_DeleteCookingUnit_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CookingUnitServiceServer).DeleteCookingUnit(ctx, req.(*DeleteCookingUnitRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// CookingUnitService_ServiceDesc is the grpc.ServiceDesc for CookingUnitService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var CookingUnitService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "recipescatalog.v1.CookingUnitService",
+	HandlerType: (*CookingUnitServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListCookingUnits",
+			Handler:    _CookingUnitService_ListCookingUnits_Handler,
+		},
+		{
+			MethodName: "CountCookingUnits",
+			Handler:    _CookingUnitService_CountCookingUnits_Handler,
+		},
+		{
+			MethodName: "GetCookingUnit",
+			Handler:    _CookingUnitService_GetCookingUnit_Handler,
+		},
+		{
+			MethodName: "CreateCookingUnit",
+			Handler:    _CookingUnitService_CreateCookingUnit_Handler,
+		},
+		{
+			MethodName: "UpdateCookingUnit",
+			Handler:    _CookingUnitService_UpdateCookingUnit_Handler,
+		},
+		{
+			MethodName: "DeleteCookingUnit",
+			Handler:    _CookingUnitService_DeleteCookingUnit_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "cooking_unit.proto",
+}
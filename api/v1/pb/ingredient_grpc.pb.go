@@ -0,0 +1,295 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             v4.25.1
+// source: ingredient.proto
+
+package pb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+	emptypb "google.golang.org/protobuf/types/known/emptypb"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	IngredientService_ListIngredients_FullMethodName  = "/recipescatalog.v1.IngredientService/ListIngredients"
+	IngredientService_CountIngredients_FullMethodName = "/recipescatalog.v1.IngredientService/CountIngredients"
+	IngredientService_GetIngredient_FullMethodName    = "/recipescatalog.v1.IngredientService/GetIngredient"
+	IngredientService_CreateIngredient_FullMethodName = "/recipescatalog.v1.IngredientService/CreateIngredient"
+	IngredientService_UpdateIngredient_FullMethodName = "/recipescatalog.v1.IngredientService/UpdateIngredient"
+	IngredientService_DeleteIngredient_FullMethodName = "/recipescatalog.v1.IngredientService/DeleteIngredient"
+)
+
+// IngredientServiceClient is the client API for IngredientService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type IngredientServiceClient interface {
+	ListIngredients(ctx context.Context, in *ListIngredientsRequest, opts ...grpc.CallOption) (*ListIngredientsResponse, error)
+	CountIngredients(ctx context.Context, in *CountIngredientsRequest, opts ...grpc.CallOption) (*CountResponse, error)
+	GetIngredient(ctx context.Context, in *GetIngredientRequest, opts ...grpc.CallOption) (*Ingredient, error)
+	CreateIngredient(ctx context.Context, in *CreateIngredientRequest, opts ...grpc.CallOption) (*Ingredient, error)
+	UpdateIngredient(ctx context.Context, in *UpdateIngredientRequest, opts ...grpc.CallOption) (*Ingredient, error)
+	DeleteIngredient(ctx context.Context, in *DeleteIngredientRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+}
+
+type ingredientServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewIngredientServiceClient(cc grpc.ClientConnInterface) IngredientServiceClient {
+	return &ingredientServiceClient{cc}
+}
+
+func (c *ingredientServiceClient) ListIngredients(ctx context.Context, in *ListIngredientsRequest, opts ...grpc.CallOption) (*ListIngredientsResponse, error) {
+	out := new(ListIngredientsResponse)
+	err := c.cc.Invoke(ctx, IngredientService_ListIngredients_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ingredientServiceClient) CountIngredients(ctx context.Context, in *CountIngredientsRequest, opts ...grpc.CallOption) (*CountResponse, error) {
+	out := new(CountResponse)
+	err := c.cc.Invoke(ctx, IngredientService_CountIngredients_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ingredientServiceClient) GetIngredient(ctx context.Context, in *GetIngredientRequest, opts ...grpc.CallOption) (*Ingredient, error) {
+	out := new(Ingredient)
+	err := c.cc.Invoke(ctx, IngredientService_GetIngredient_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ingredientServiceClient) CreateIngredient(ctx context.Context, in *CreateIngredientRequest, opts ...grpc.CallOption) (*Ingredient, error) {
+	out := new(Ingredient)
+	err := c.cc.Invoke(ctx, IngredientService_CreateIngredient_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ingredientServiceClient) UpdateIngredient(ctx context.Context, in *UpdateIngredientRequest, opts ...grpc.CallOption) (*Ingredient, error) {
+	out := new(Ingredient)
+	err := c.cc.Invoke(ctx, IngredientService_UpdateIngredient_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ingredientServiceClient) DeleteIngredient(ctx context.Context, in *DeleteIngredientRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, IngredientService_DeleteIngredient_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// IngredientServiceServer is the server API for IngredientService service.
+// All implementations must embed UnimplementedIngredientServiceServer
+// for forward compatibility
+type IngredientServiceServer interface {
+	ListIngredients(context.Context, *ListIngredientsRequest) (*ListIngredientsResponse, error)
+	CountIngredients(context.Context, *CountIngredientsRequest) (*CountResponse, error)
+	GetIngredient(context.Context, *GetIngredientRequest) (*Ingredient, error)
+	CreateIngredient(context.Context, *CreateIngredientRequest) (*Ingredient, error)
+	UpdateIngredient(context.Context, *UpdateIngredientRequest) (*Ingredient, error)
+	DeleteIngredient(context.Context, *DeleteIngredientRequest) (*emptypb.Empty, error)
+	mustEmbedUnimplementedIngredientServiceServer()
+}
+
+// UnimplementedIngredientServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedIngredientServiceServer struct {
+}
+
+func (UnimplementedIngredientServiceServer) ListIngredients(context.Context, *ListIngredientsRequest) (*ListIngredientsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListIngredients not implemented")
+}
+func (UnimplementedIngredientServiceServer) CountIngredients(context.Context, *CountIngredientsRequest) (*CountResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CountIngredients not implemented")
+}
+func (UnimplementedIngredientServiceServer) GetIngredient(context.Context, *GetIngredientRequest) (*Ingredient, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetIngredient not implemented")
+}
+func (UnimplementedIngredientServiceServer) CreateIngredient(context.Context, *CreateIngredientRequest) (*Ingredient, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateIngredient not implemented")
+}
+func (UnimplementedIngredientServiceServer) UpdateIngredient(context.Context, *UpdateIngredientRequest) (*Ingredient, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateIngredient not implemented")
+}
+func (UnimplementedIngredientServiceServer) DeleteIngredient(context.Context, *DeleteIngredientRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteIngredient not implemented")
+}
+func (UnimplementedIngredientServiceServer) mustEmbedUnimplementedIngredientServiceServer() {}
+
+// UnsafeIngredientServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to IngredientServiceServer will
+// result in compilation errors.
+type UnsafeIngredientServiceServer interface {
+	mustEmbedUnimplementedIngredientServiceServer()
+}
+
+func RegisterIngredientServiceServer(s grpc.ServiceRegistrar, srv IngredientServiceServer) {
+	s.RegisterService(&IngredientService_ServiceDesc, srv)
+}
+
+func _IngredientService_ListIngredients_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListIngredientsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IngredientServiceServer).ListIngredients(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: IngredientService_ListIngredients_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IngredientServiceServer).ListIngredients(ctx, req.(*ListIngredientsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _IngredientService_CountIngredients_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CountIngredientsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IngredientServiceServer).CountIngredients(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: IngredientService_CountIngredients_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IngredientServiceServer).CountIngredients(ctx, req.(*CountIngredientsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _IngredientService_GetIngredient_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetIngredientRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IngredientServiceServer).GetIngredient(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: IngredientService_GetIngredient_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IngredientServiceServer).GetIngredient(ctx, req.(*GetIngredientRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _IngredientService_CreateIngredient_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateIngredientRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IngredientServiceServer).CreateIngredient(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: IngredientService_CreateIngredient_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IngredientServiceServer).CreateIngredient(ctx, req.(*CreateIngredientRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _IngredientService_UpdateIngredient_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateIngredientRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IngredientServiceServer).UpdateIngredient(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: IngredientService_UpdateIngredient_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IngredientServiceServer).UpdateIngredient(ctx, req.(*UpdateIngredientRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _IngredientService_DeleteIngredient_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteIngredientRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IngredientServiceServer).DeleteIngredient(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: IngredientService_DeleteIngredient_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IngredientServiceServer).DeleteIngredient(ctx, req.(*DeleteIngredientRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// IngredientService_ServiceDesc is the grpc.ServiceDesc for IngredientService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var IngredientService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "recipescatalog.v1.IngredientService",
+	HandlerType: (*IngredientServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListIngredients",
+			Handler:    _IngredientService_ListIngredients_Handler,
+		},
+		{
+			MethodName: "CountIngredients",
+			Handler:    _IngredientService_CountIngredients_Handler,
+		},
+		{
+			MethodName: "GetIngredient",
+			Handler:    _IngredientService_GetIngredient_Handler,
+		},
+		{
+			MethodName: "CreateIngredient",
+			Handler:    _IngredientService_CreateIngredient_Handler,
+		},
+		{
+			MethodName: "UpdateIngredient",
+			Handler:    _IngredientService_UpdateIngredient_Handler,
+		},
+		{
+			MethodName: "DeleteIngredient",
+			Handler:    _IngredientService_DeleteIngredient_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "ingredient.proto",
+}
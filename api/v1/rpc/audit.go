@@ -0,0 +1,29 @@
+package rpc
+
+import (
+	"context"
+
+	"github.com/TomeuUris/recipes-catalog/pkg/audit"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// Metadata keys identifying the writes of a call in the audit log, like the
+// X-Actor and X-Request-ID headers of the HTTP API
+const (
+	actorMetadata     = "x-actor"
+	requestIDMetadata = "x-request-id"
+)
+
+// auditContext adds the actor and request ID of the call metadata to its context
+func auditContext(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(actorMetadata); len(values) > 0 {
+			ctx = audit.WithActor(ctx, values[0])
+		}
+		if values := md.Get(requestIDMetadata); len(values) > 0 {
+			ctx = audit.WithRequestID(ctx, values[0])
+		}
+	}
+	return handler(ctx, req)
+}
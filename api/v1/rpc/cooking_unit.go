@@ -0,0 +1,113 @@
+package rpc
+
+import (
+	"context"
+
+	"github.com/TomeuUris/recipes-catalog/api/v1/pb"
+	"github.com/TomeuUris/recipes-catalog/pkg/entity"
+	"github.com/TomeuUris/recipes-catalog/pkg/repo/cooking_unit"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+type CookingUnitServer struct {
+	pb.UnimplementedCookingUnitServiceServer
+	repo cooking_unit.Repo
+}
+
+func NewCookingUnitServer(repo cooking_unit.Repo) *CookingUnitServer {
+	return &CookingUnitServer{repo: repo}
+}
+
+func cookingUnitToPb(u *entity.CookingUnit) *pb.CookingUnit {
+	return &pb.CookingUnit{
+		Id:   u.ID,
+		Name: u.Name,
+	}
+}
+
+func cookingUnitFromPb(u *pb.CookingUnit) *entity.CookingUnit {
+	return &entity.CookingUnit{
+		ID:   u.GetId(),
+		Name: u.GetName(),
+	}
+}
+
+func (s *CookingUnitServer) ListCookingUnits(ctx context.Context, req *pb.ListCookingUnitsRequest) (*pb.ListCookingUnitsResponse, error) {
+	units, err := s.repo.FindByFilter(ctx, &cooking_unit.FindFilter{Name: req.GetName()})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	result := make([]*pb.CookingUnit, len(units))
+	for i, unit := range units {
+		result[i] = cookingUnitToPb(unit)
+	}
+	return &pb.ListCookingUnitsResponse{CookingUnits: result}, nil
+}
+
+func (s *CookingUnitServer) CountCookingUnits(ctx context.Context, req *pb.CountCookingUnitsRequest) (*pb.CountResponse, error) {
+	count, err := s.repo.CountByFilter(&cooking_unit.FindFilter{Name: req.GetName()})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &pb.CountResponse{Count: int64(count)}, nil
+}
+
+func (s *CookingUnitServer) GetCookingUnit(ctx context.Context, req *pb.GetCookingUnitRequest) (*pb.CookingUnit, error) {
+	found, err := s.repo.FindByID(ctx, int(req.GetId()))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return cookingUnitToPb(found), nil
+}
+
+func (s *CookingUnitServer) CreateCookingUnit(ctx context.Context, req *pb.CreateCookingUnitRequest) (*pb.CookingUnit, error) {
+	if req.GetCookingUnit() == nil {
+		return nil, status.Error(codes.InvalidArgument, "cooking unit is required")
+	}
+
+	unit := cookingUnitFromPb(req.GetCookingUnit())
+	unit.ID = 0
+	if err := unit.Validate(); err != nil {
+		return nil, toStatus(err)
+	}
+
+	if err := s.repo.Add(ctx, unit); err != nil {
+		return nil, toStatus(err)
+	}
+	return cookingUnitToPb(unit), nil
+}
+
+func (s *CookingUnitServer) UpdateCookingUnit(ctx context.Context, req *pb.UpdateCookingUnitRequest) (*pb.CookingUnit, error) {
+	if req.GetCookingUnit() == nil {
+		return nil, status.Error(codes.InvalidArgument, "cooking unit is required")
+	}
+
+	target, err := s.repo.FindByID(ctx, int(req.GetCookingUnit().GetId()))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	// Apply the masked fields
+	mask := maskPaths(req.GetUpdateMask().GetPaths())
+	if shouldUpdate(mask, "name") {
+		target.Name = req.GetCookingUnit().GetName()
+	}
+	if err := target.Validate(); err != nil {
+		return nil, toStatus(err)
+	}
+
+	if err := s.repo.Edit(ctx, target); err != nil {
+		return nil, toStatus(err)
+	}
+	return cookingUnitToPb(target), nil
+}
+
+func (s *CookingUnitServer) DeleteCookingUnit(ctx context.Context, req *pb.DeleteCookingUnitRequest) (*emptypb.Empty, error) {
+	if err := s.repo.Delete(ctx, &entity.CookingUnit{ID: req.GetId()}); err != nil {
+		return nil, toStatus(err)
+	}
+	return &emptypb.Empty{}, nil
+}
@@ -0,0 +1,40 @@
+package rpc
+
+import (
+	"github.com/TomeuUris/recipes-catalog/pkg/entity"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// toStatus translates repository and validation errors into gRPC status errors
+func toStatus(err error) error {
+	switch {
+	case entity.IsErrNotFound(err):
+		return status.Error(codes.NotFound, err.Error())
+	case entity.IsErrInvalidEntity(err), entity.IsErrInvalidID(err):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case entity.IsErrAlreadyExists(err):
+		return status.Error(codes.AlreadyExists, err.Error())
+	case entity.IsErrInUse(err):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	default:
+		return status.Error(codes.Internal, err.Error())
+	}
+}
+
+// maskPaths returns the set of paths in the update mask, or nil when every field must be updated
+func maskPaths(paths []string) map[string]bool {
+	if len(paths) == 0 {
+		return nil
+	}
+	result := make(map[string]bool, len(paths))
+	for _, path := range paths {
+		result[path] = true
+	}
+	return result
+}
+
+// shouldUpdate reports whether the field is selected by the mask returned by maskPaths
+func shouldUpdate(mask map[string]bool, field string) bool {
+	return mask == nil || mask[field]
+}
@@ -0,0 +1,118 @@
+package rpc
+
+import (
+	"context"
+
+	"github.com/TomeuUris/recipes-catalog/api/v1/pb"
+	"github.com/TomeuUris/recipes-catalog/pkg/entity"
+	"github.com/TomeuUris/recipes-catalog/pkg/repo/ingredient"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+type IngredientServer struct {
+	pb.UnimplementedIngredientServiceServer
+	repo ingredient.Repo
+}
+
+func NewIngredientServer(repo ingredient.Repo) *IngredientServer {
+	return &IngredientServer{repo: repo}
+}
+
+func ingredientToPb(i *entity.Ingredient) *pb.Ingredient {
+	return &pb.Ingredient{
+		Id:   i.ID,
+		Name: i.Name,
+		Type: i.Type,
+	}
+}
+
+func ingredientFromPb(i *pb.Ingredient) *entity.Ingredient {
+	return &entity.Ingredient{
+		ID:   i.GetId(),
+		Name: i.GetName(),
+		Type: i.GetType(),
+	}
+}
+
+func (s *IngredientServer) ListIngredients(ctx context.Context, req *pb.ListIngredientsRequest) (*pb.ListIngredientsResponse, error) {
+	ingredients, err := s.repo.FindByFilter(ctx, &ingredient.FindFilter{Type: req.GetType()})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	result := make([]*pb.Ingredient, len(ingredients))
+	for i, ingredient := range ingredients {
+		result[i] = ingredientToPb(ingredient)
+	}
+	return &pb.ListIngredientsResponse{Ingredients: result}, nil
+}
+
+func (s *IngredientServer) CountIngredients(ctx context.Context, req *pb.CountIngredientsRequest) (*pb.CountResponse, error) {
+	count, err := s.repo.CountByFilter(&ingredient.FindFilter{Type: req.GetType()})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &pb.CountResponse{Count: int64(count)}, nil
+}
+
+func (s *IngredientServer) GetIngredient(ctx context.Context, req *pb.GetIngredientRequest) (*pb.Ingredient, error) {
+	found, err := s.repo.FindByID(ctx, int(req.GetId()))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return ingredientToPb(found), nil
+}
+
+func (s *IngredientServer) CreateIngredient(ctx context.Context, req *pb.CreateIngredientRequest) (*pb.Ingredient, error) {
+	if req.GetIngredient() == nil {
+		return nil, status.Error(codes.InvalidArgument, "ingredient is required")
+	}
+
+	newIngredient := ingredientFromPb(req.GetIngredient())
+	newIngredient.ID = 0
+	if err := newIngredient.Validate(); err != nil {
+		return nil, toStatus(err)
+	}
+
+	if err := s.repo.Add(ctx, newIngredient); err != nil {
+		return nil, toStatus(err)
+	}
+	return ingredientToPb(newIngredient), nil
+}
+
+func (s *IngredientServer) UpdateIngredient(ctx context.Context, req *pb.UpdateIngredientRequest) (*pb.Ingredient, error) {
+	if req.GetIngredient() == nil {
+		return nil, status.Error(codes.InvalidArgument, "ingredient is required")
+	}
+
+	target, err := s.repo.FindByID(ctx, int(req.GetIngredient().GetId()))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	// Apply the masked fields
+	mask := maskPaths(req.GetUpdateMask().GetPaths())
+	if shouldUpdate(mask, "name") {
+		target.Name = req.GetIngredient().GetName()
+	}
+	if shouldUpdate(mask, "type") {
+		target.Type = req.GetIngredient().GetType()
+	}
+	if err := target.Validate(); err != nil {
+		return nil, toStatus(err)
+	}
+
+	if err := s.repo.Edit(ctx, target); err != nil {
+		return nil, toStatus(err)
+	}
+	return ingredientToPb(target), nil
+}
+
+func (s *IngredientServer) DeleteIngredient(ctx context.Context, req *pb.DeleteIngredientRequest) (*emptypb.Empty, error) {
+	if err := s.repo.Delete(ctx, &entity.Ingredient{ID: req.GetId()}); err != nil {
+		return nil, toStatus(err)
+	}
+	return &emptypb.Empty{}, nil
+}
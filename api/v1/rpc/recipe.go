@@ -0,0 +1,171 @@
+package rpc
+
+import (
+	"context"
+	"time"
+
+	"github.com/TomeuUris/recipes-catalog/api/v1/pb"
+	"github.com/TomeuUris/recipes-catalog/pkg/entity"
+	"github.com/TomeuUris/recipes-catalog/pkg/publicid"
+	"github.com/TomeuUris/recipes-catalog/pkg/repo/recipe"
+	"github.com/TomeuUris/recipes-catalog/pkg/repo/transaction"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+type RecipeServer struct {
+	pb.UnimplementedRecipeServiceServer
+	repo recipe.Repo
+	// tx writes the recipes along with their new ingredients, like the REST API
+	tx transaction.Manager
+	// publicIDs fills the public IDs of the recipes, nil when they are disabled
+	publicIDs *publicid.Codec
+}
+
+func NewRecipeServer(repo recipe.Repo, tx transaction.Manager, publicIDs *publicid.Codec) *RecipeServer {
+	return &RecipeServer{repo: repo, tx: tx, publicIDs: publicIDs}
+}
+
+func (s *RecipeServer) recipeToPb(r *entity.Recipe) *pb.Recipe {
+	ingredients := make([]*pb.Ingredient, len(r.Ingredients))
+	for i, ingredient := range r.Ingredients {
+		ingredients[i] = ingredientToPb(ingredient)
+	}
+	result := &pb.Recipe{
+		Id:          r.ID,
+		Name:        r.Name,
+		Slug:        r.Slug,
+		Description: r.Description,
+		Ingredients: ingredients,
+		Steps:       r.Steps,
+		PrepMinutes: int32(r.PrepTime / time.Minute),
+		CookMinutes: int32(r.CookTime / time.Minute),
+		Yield:       r.Yield,
+		Calories:    int32(r.Calories),
+	}
+	if s.publicIDs != nil {
+		result.PublicId = s.publicIDs.Encode(r.ID)
+	}
+	return result
+}
+
+func ingredientsFromPb(ingredients []*pb.Ingredient) []*entity.Ingredient {
+	result := make([]*entity.Ingredient, len(ingredients))
+	for i, ingredient := range ingredients {
+		result[i] = ingredientFromPb(ingredient)
+	}
+	return result
+}
+
+func (s *RecipeServer) ListRecipes(ctx context.Context, req *pb.ListRecipesRequest) (*pb.ListRecipesResponse, error) {
+	recipes, err := s.repo.FindByFilter(ctx, &recipe.FindFilter{Id: int(req.GetId())})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	result := make([]*pb.Recipe, len(recipes))
+	for i, recipe := range recipes {
+		result[i] = s.recipeToPb(recipe)
+	}
+	return &pb.ListRecipesResponse{Recipes: result}, nil
+}
+
+func (s *RecipeServer) CountRecipes(ctx context.Context, req *pb.CountRecipesRequest) (*pb.CountResponse, error) {
+	count, err := s.repo.CountByFilter(ctx, &recipe.FindFilter{Id: int(req.GetId())})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &pb.CountResponse{Count: int64(count)}, nil
+}
+
+func (s *RecipeServer) GetRecipe(ctx context.Context, req *pb.GetRecipeRequest) (*pb.Recipe, error) {
+	found, err := s.repo.FindByID(ctx, req.GetId())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return s.recipeToPb(found), nil
+}
+
+func (s *RecipeServer) CreateRecipe(ctx context.Context, req *pb.CreateRecipeRequest) (*pb.Recipe, error) {
+	if req.GetRecipe() == nil {
+		return nil, status.Error(codes.InvalidArgument, "recipe is required")
+	}
+
+	newRecipe := &entity.Recipe{
+		Name:        req.GetRecipe().GetName(),
+		Description: req.GetRecipe().GetDescription(),
+		Ingredients: ingredientsFromPb(req.GetRecipe().GetIngredients()),
+		Steps:       req.GetRecipe().GetSteps(),
+		PrepTime:    time.Duration(req.GetRecipe().GetPrepMinutes()) * time.Minute,
+		CookTime:    time.Duration(req.GetRecipe().GetCookMinutes()) * time.Minute,
+		Yield:       req.GetRecipe().GetYield(),
+		Calories:    int(req.GetRecipe().GetCalories()),
+	}
+	if err := newRecipe.Validate(); err != nil {
+		return nil, toStatus(err)
+	}
+
+	if err := transaction.AddRecipe(ctx, s.tx, newRecipe); err != nil {
+		return nil, toStatus(err)
+	}
+	return s.recipeToPb(newRecipe), nil
+}
+
+func (s *RecipeServer) UpdateRecipe(ctx context.Context, req *pb.UpdateRecipeRequest) (*pb.Recipe, error) {
+	if req.GetRecipe() == nil {
+		return nil, status.Error(codes.InvalidArgument, "recipe is required")
+	}
+
+	target, err := s.repo.FindByID(ctx, req.GetRecipe().GetId())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	// Apply the masked fields
+	mask := maskPaths(req.GetUpdateMask().GetPaths())
+	if shouldUpdate(mask, "name") {
+		target.Name = req.GetRecipe().GetName()
+	}
+	if shouldUpdate(mask, "description") {
+		target.Description = req.GetRecipe().GetDescription()
+	}
+	if shouldUpdate(mask, "ingredients") {
+		target.Ingredients = ingredientsFromPb(req.GetRecipe().GetIngredients())
+	}
+	if shouldUpdate(mask, "steps") {
+		target.Steps = req.GetRecipe().GetSteps()
+	}
+	if shouldUpdate(mask, "prep_minutes") {
+		target.PrepTime = time.Duration(req.GetRecipe().GetPrepMinutes()) * time.Minute
+	}
+	if shouldUpdate(mask, "cook_minutes") {
+		target.CookTime = time.Duration(req.GetRecipe().GetCookMinutes()) * time.Minute
+	}
+	if shouldUpdate(mask, "yield") {
+		target.Yield = req.GetRecipe().GetYield()
+	}
+	if shouldUpdate(mask, "calories") {
+		target.Calories = int(req.GetRecipe().GetCalories())
+	}
+	if err := target.Validate(); err != nil {
+		return nil, toStatus(err)
+	}
+
+	if err := transaction.EditRecipe(ctx, s.tx, target); err != nil {
+		return nil, toStatus(err)
+	}
+	return s.recipeToPb(target), nil
+}
+
+func (s *RecipeServer) DeleteRecipe(ctx context.Context, req *pb.DeleteRecipeRequest) (*emptypb.Empty, error) {
+	target, err := s.repo.FindByID(ctx, req.GetId())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	if err := s.repo.Delete(ctx, target); err != nil {
+		return nil, toStatus(err)
+	}
+	return &emptypb.Empty{}, nil
+}
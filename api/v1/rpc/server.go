@@ -0,0 +1,27 @@
+package rpc
+
+import (
+	"github.com/TomeuUris/recipes-catalog/api/v1/pb"
+	"github.com/TomeuUris/recipes-catalog/pkg/publicid"
+	"github.com/TomeuUris/recipes-catalog/pkg/repo/cooking_unit"
+	"github.com/TomeuUris/recipes-catalog/pkg/repo/ingredient"
+	"github.com/TomeuUris/recipes-catalog/pkg/repo/recipe"
+	"github.com/TomeuUris/recipes-catalog/pkg/repo/transaction"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/reflection"
+)
+
+// NewServer creates a gRPC server exposing the catalog services over the given repositories.
+// Recipes are written with their new ingredients in the units of work of tx, and
+// carry their public IDs when publicIDs isn't nil. They are still identified by ID.
+// Server reflection is enabled so tools like grpcurl can discover the services.
+// The x-actor and x-request-id metadata of the calls are recorded in the audit log.
+func NewServer(recipes recipe.Repo, ingredients ingredient.Repo, units cooking_unit.Repo, tx transaction.Manager, publicIDs *publicid.Codec, opts ...grpc.ServerOption) *grpc.Server {
+	opts = append([]grpc.ServerOption{grpc.ChainUnaryInterceptor(auditContext)}, opts...)
+	s := grpc.NewServer(opts...)
+	pb.RegisterRecipeServiceServer(s, NewRecipeServer(recipes, tx, publicIDs))
+	pb.RegisterIngredientServiceServer(s, NewIngredientServer(ingredients))
+	pb.RegisterCookingUnitServiceServer(s, NewCookingUnitServer(units))
+	reflection.Register(s)
+	return s
+}
@@ -0,0 +1,212 @@
+package rpc_test
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/TomeuUris/recipes-catalog/api/v1/pb"
+	"github.com/TomeuUris/recipes-catalog/api/v1/rpc"
+	"github.com/TomeuUris/recipes-catalog/pkg/repo/cooking_unit"
+	"github.com/TomeuUris/recipes-catalog/pkg/repo/ingredient"
+	"github.com/TomeuUris/recipes-catalog/pkg/repo/recipe"
+	"github.com/TomeuUris/recipes-catalog/pkg/repo/transaction"
+)
+
+var ctx = context.Background()
+
+// setupServer starts a gRPC server over an in-memory listener backed by an in-memory database
+func setupServer(t *testing.T) *grpc.ClientConn {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to connect database: %v", err)
+	}
+	// Every connection to file::memory: gets its own database, so keep a single one
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("failed to get database handle: %v", err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+	if err := db.AutoMigrate(&ingredient.Ingredient{}, &recipe.Recipe{}, &recipe.RecipeStep{}, &recipe.RecipeSlug{}, &cooking_unit.CookingUnit{}); err != nil {
+		t.Fatalf("failed to migrate database schema: %v", err)
+	}
+
+	lis := bufconn.Listen(1024 * 1024)
+	server := rpc.NewServer(recipe.NewGormRepo(db), ingredient.NewGormRepo(db), cooking_unit.NewGormRepo(db), transaction.NewGormManager(db), nil)
+	go server.Serve(lis)
+
+	conn, err := grpc.DialContext(ctx, "bufnet",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) { return lis.Dial() }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("failed to dial server: %v", err)
+	}
+
+	t.Cleanup(func() {
+		conn.Close()
+		server.Stop()
+		sqlDB.Close()
+	})
+	return conn
+}
+
+func TestIngredientService_CRUD(t *testing.T) {
+	client := pb.NewIngredientServiceClient(setupServer(t))
+
+	// Create an ingredient
+	created, err := client.CreateIngredient(ctx, &pb.CreateIngredientRequest{
+		Ingredient: &pb.Ingredient{Name: "Spaghetti", Type: "Pasta"},
+	})
+	if err != nil {
+		t.Fatalf("failed to create ingredient: %v", err)
+	}
+	if created.GetId() == 0 {
+		t.Fatalf("expected ingredient id to be set")
+	}
+
+	// Only update the name
+	updated, err := client.UpdateIngredient(ctx, &pb.UpdateIngredientRequest{
+		Ingredient: &pb.Ingredient{Id: created.GetId(), Name: "Macaroni"},
+		UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"name"}},
+	})
+	if err != nil {
+		t.Fatalf("failed to update ingredient: %v", err)
+	}
+	if updated.GetName() != "Macaroni" || updated.GetType() != "Pasta" {
+		t.Fatalf("unexpected ingredient after update: %v", updated)
+	}
+
+	// Count by type
+	count, err := client.CountIngredients(ctx, &pb.CountIngredientsRequest{Type: "Pasta"})
+	if err != nil {
+		t.Fatalf("failed to count ingredients: %v", err)
+	}
+	if count.GetCount() != 1 {
+		t.Fatalf("expected 1 ingredient, got %d", count.GetCount())
+	}
+
+	// Delete and check it is gone
+	if _, err := client.DeleteIngredient(ctx, &pb.DeleteIngredientRequest{Id: created.GetId()}); err != nil {
+		t.Fatalf("failed to delete ingredient: %v", err)
+	}
+	_, err = client.GetIngredient(ctx, &pb.GetIngredientRequest{Id: created.GetId()})
+	if status.Code(err) != codes.NotFound {
+		t.Fatalf("expected NotFound, got %v", err)
+	}
+}
+
+func TestIngredientService_CreateInvalid(t *testing.T) {
+	client := pb.NewIngredientServiceClient(setupServer(t))
+
+	_, err := client.CreateIngredient(ctx, &pb.CreateIngredientRequest{
+		Ingredient: &pb.Ingredient{Type: "Pasta"},
+	})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("expected InvalidArgument, got %v", err)
+	}
+}
+
+func TestRecipeService_CreateAndGet(t *testing.T) {
+	conn := setupServer(t)
+	ingredients := pb.NewIngredientServiceClient(conn)
+	recipes := pb.NewRecipeServiceClient(conn)
+
+	tomato, err := ingredients.CreateIngredient(ctx, &pb.CreateIngredientRequest{
+		Ingredient: &pb.Ingredient{Name: "Tomato", Type: "Vegetable"},
+	})
+	if err != nil {
+		t.Fatalf("failed to create ingredient: %v", err)
+	}
+
+	created, err := recipes.CreateRecipe(ctx, &pb.CreateRecipeRequest{
+		Recipe: &pb.Recipe{
+			Name:        "Salad",
+			Description: "Fresh",
+			Ingredients: []*pb.Ingredient{tomato},
+			Steps:       []string{"Cut", "Serve"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to create recipe: %v", err)
+	}
+
+	found, err := recipes.GetRecipe(ctx, &pb.GetRecipeRequest{Id: created.GetId()})
+	if err != nil {
+		t.Fatalf("failed to get recipe: %v", err)
+	}
+	if found.GetName() != "Salad" || len(found.GetSteps()) != 2 || len(found.GetIngredients()) != 1 {
+		t.Fatalf("unexpected recipe: %v", found)
+	}
+	if found.GetSteps()[0] != "Cut" {
+		t.Fatalf("expected steps in order, got %v", found.GetSteps())
+	}
+
+	_, err = recipes.GetRecipe(ctx, &pb.GetRecipeRequest{Id: created.GetId() + 100})
+	if status.Code(err) != codes.NotFound {
+		t.Fatalf("expected NotFound, got %v", err)
+	}
+}
+
+func TestRecipeService_CreatesNewIngredients(t *testing.T) {
+	recipes := pb.NewRecipeServiceClient(setupServer(t))
+
+	created, err := recipes.CreateRecipe(ctx, &pb.CreateRecipeRequest{
+		Recipe: &pb.Recipe{
+			Name:        "Gazpacho",
+			Ingredients: []*pb.Ingredient{{Name: "Tomato", Type: "Vegetable"}},
+			Steps:       []string{"Blend"},
+			PrepMinutes: 15,
+			Yield:       "4 servings",
+			Calories:    90,
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to create recipe: %v", err)
+	}
+	if created.GetSlug() != "gazpacho" || created.GetPrepMinutes() != 15 || created.GetYield() != "4 servings" || created.GetCalories() != 90 {
+		t.Fatalf("unexpected recipe: %v", created)
+	}
+	if created.GetIngredients()[0].GetId() == 0 {
+		t.Fatalf("expected the new ingredient to be created, got %v", created.GetIngredients())
+	}
+
+	// The new ingredient of the recipe has a taken name, nothing is written
+	_, err = recipes.CreateRecipe(ctx, &pb.CreateRecipeRequest{
+		Recipe: &pb.Recipe{
+			Name:        "Salad",
+			Ingredients: []*pb.Ingredient{{Name: "Tomato", Type: "Vegetable"}},
+		},
+	})
+	if status.Code(err) != codes.AlreadyExists {
+		t.Fatalf("expected AlreadyExists, got %v", err)
+	}
+	count, err := recipes.CountRecipes(ctx, &pb.CountRecipesRequest{})
+	if err != nil {
+		t.Fatalf("failed to count recipes: %v", err)
+	}
+	if count.GetCount() != 1 {
+		t.Fatalf("expected 1 recipe, got %d", count.GetCount())
+	}
+
+	// Only update the yield
+	updated, err := recipes.UpdateRecipe(ctx, &pb.UpdateRecipeRequest{
+		Recipe:     &pb.Recipe{Id: created.GetId(), Yield: "2 servings"},
+		UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"yield"}},
+	})
+	if err != nil {
+		t.Fatalf("failed to update recipe: %v", err)
+	}
+	if updated.GetYield() != "2 servings" || updated.GetPrepMinutes() != 15 || updated.GetName() != "Gazpacho" {
+		t.Fatalf("unexpected recipe after update: %v", updated)
+	}
+}
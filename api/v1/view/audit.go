@@ -0,0 +1,37 @@
+package view
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/TomeuUris/recipes-catalog/pkg/entity"
+)
+
+type AuditEntry struct {
+	ID        int64  `json:"id"`
+	Entity    string `json:"entity"`
+	EntityID  int64  `json:"entity_id"`
+	Action    string `json:"action"`
+	Actor     string `json:"actor"`
+	RequestID string `json:"request_id"`
+	// Before and After are the entity around the write, null when it didn't exist
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+	At     time.Time   `json:"at"`
+}
+
+func (a *AuditEntry) FromEntity(entry *entity.AuditEntry) {
+	a.ID = entry.ID
+	a.Entity = entry.Entity
+	a.EntityID = entry.EntityID
+	a.Action = entry.Action
+	a.Actor = entry.Actor
+	a.RequestID = entry.RequestID
+	if entry.Before != "" {
+		a.Before = json.RawMessage(entry.Before)
+	}
+	if entry.After != "" {
+		a.After = json.RawMessage(entry.After)
+	}
+	a.At = entry.At
+}
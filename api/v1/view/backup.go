@@ -0,0 +1,20 @@
+package view
+
+import (
+	"time"
+
+	"github.com/TomeuUris/recipes-catalog/pkg/sqlite"
+)
+
+// Backup is a backup of the database, named after the time it was taken
+type Backup struct {
+	Name      string    `json:"name"`
+	Size      int64     `json:"size"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (b *Backup) FromBackup(backup *sqlite.Backup) {
+	b.Name = backup.Name
+	b.Size = backup.Size
+	b.CreatedAt = backup.CreatedAt
+}
@@ -0,0 +1,27 @@
+package view
+
+import "github.com/TomeuUris/recipes-catalog/pkg/cache"
+
+// CacheStats counts the lookups of the cache of a repository
+type CacheStats struct {
+	Name      string `json:"name"`
+	Hits      uint64 `json:"hits"`
+	Misses    uint64 `json:"misses"`
+	Evictions uint64 `json:"evictions"`
+	Size      int    `json:"size"`
+	Capacity  int    `json:"capacity"`
+	// HitRatio is the share of lookups served from the cache, 0 without lookups
+	HitRatio float64 `json:"hit_ratio"`
+}
+
+func (s *CacheStats) FromStats(name string, stats cache.Stats) {
+	s.Name = name
+	s.Hits = stats.Hits
+	s.Misses = stats.Misses
+	s.Evictions = stats.Evictions
+	s.Size = stats.Size
+	s.Capacity = stats.Capacity
+	if lookups := stats.Hits + stats.Misses; lookups > 0 {
+		s.HitRatio = float64(stats.Hits) / float64(lookups)
+	}
+}
@@ -0,0 +1,25 @@
+package view
+
+import "github.com/TomeuUris/recipes-catalog/pkg/entity"
+
+// InUse is the conflict returned when deleting an entity recipes still use
+type InUse struct {
+	Error   string      `json:"error"`
+	Recipes []RecipeRef `json:"recipes"`
+}
+
+func (u *InUse) FromEntity(err *entity.InUseError) {
+	u.Error = err.Error()
+	u.Recipes = make([]RecipeRef, len(err.Recipes))
+	for i, recipe := range err.Recipes {
+		u.Recipes[i].FromEntity(recipe)
+	}
+}
+
+// AlreadyExists is the conflict returned when another entity holds the name
+type AlreadyExists struct {
+	Error string `json:"error"`
+	ID    int64  `json:"id"`
+	// Href is the path of the existing entity
+	Href string `json:"href"`
+}
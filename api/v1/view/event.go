@@ -0,0 +1,47 @@
+package view
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/TomeuUris/recipes-catalog/pkg/entity"
+	"github.com/TomeuUris/recipes-catalog/pkg/event"
+)
+
+type Event struct {
+	ID         int64       `json:"id"`
+	Type       string      `json:"type"`
+	EntityID   int64       `json:"entity_id"`
+	OccurredAt time.Time   `json:"occurred_at"`
+	Data       interface{} `json:"data,omitempty"`
+}
+
+func (e *Event) FromEvent(ev *event.Event) {
+	e.ID = ev.ID
+	e.Type = ev.Name()
+	e.EntityID = ev.EntityID
+	e.OccurredAt = ev.OccurredAt
+
+	switch payload := ev.Payload.(type) {
+	case *entity.Recipe:
+		recipeView := &Recipe{}
+		recipeView.FromEntity(payload)
+		e.Data = recipeView
+	case *entity.Ingredient:
+		ingredientView := &Ingredient{}
+		ingredientView.FromEntity(payload)
+		e.Data = ingredientView
+	case *entity.CookingUnit:
+		cookingUnitView := &CookingUnit{}
+		cookingUnitView.FromEntity(payload)
+		e.Data = cookingUnitView
+	}
+}
+
+// EncodeEntity serializes the entities of the audit log the same way they are
+// streamed on /events
+func EncodeEntity(v interface{}) ([]byte, error) {
+	eventView := &Event{}
+	eventView.FromEvent(&event.Event{Payload: v})
+	return json.Marshal(eventView.Data)
+}
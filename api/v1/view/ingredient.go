@@ -21,3 +21,18 @@ func (i *Ingredient) ToEntity() *entity.Ingredient {
 		Type: i.Type,
 	}
 }
+
+// DuplicateGroup lists ingredients with similar names, the first one is the
+// oldest and the natural merge target
+type DuplicateGroup struct {
+	Similarity  float64      `json:"similarity"`
+	Ingredients []Ingredient `json:"ingredients"`
+}
+
+func (g *DuplicateGroup) FromEntity(group *entity.DuplicateGroup) {
+	g.Similarity = group.Similarity
+	g.Ingredients = make([]Ingredient, len(group.Ingredients))
+	for i, duplicate := range group.Ingredients {
+		g.Ingredients[i].FromEntity(duplicate)
+	}
+}
@@ -0,0 +1,38 @@
+package view
+
+import "github.com/TomeuUris/recipes-catalog/pkg/doctor"
+
+// IntegrityIssue is a problem found in the database
+type IntegrityIssue struct {
+	Check string `json:"check"`
+	Table string `json:"table,omitempty"`
+	// RowID is the row of the table concerned, omitted when it is the whole table
+	RowID      int64  `json:"row_id,omitempty"`
+	Message    string `json:"message"`
+	Repairable bool   `json:"repairable"`
+	Repaired   bool   `json:"repaired"`
+}
+
+// IntegrityReport lists the problems found in the database
+type IntegrityReport struct {
+	// DryRun is set when the repairs were rolled back
+	DryRun     bool              `json:"dry_run"`
+	Unresolved int               `json:"unresolved"`
+	Issues     []*IntegrityIssue `json:"issues"`
+}
+
+func (r *IntegrityReport) FromReport(report *doctor.Report) {
+	r.DryRun = report.DryRun
+	r.Unresolved = report.Unresolved()
+	r.Issues = make([]*IntegrityIssue, len(report.Issues))
+	for i, issue := range report.Issues {
+		r.Issues[i] = &IntegrityIssue{
+			Check:      issue.Check,
+			Table:      issue.Table,
+			RowID:      issue.RowID,
+			Message:    issue.Message,
+			Repairable: issue.Repairable,
+			Repaired:   issue.Repaired,
+		}
+	}
+}
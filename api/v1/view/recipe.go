@@ -1,20 +1,52 @@
 package view
 
-import "github.com/TomeuUris/recipes-catalog/pkg/entity"
+import (
+	"time"
+
+	"github.com/TomeuUris/recipes-catalog/pkg/entity"
+)
 
 type Recipe struct {
-	ID          int64        `json:"id"`
-	Name        string       `json:"name"`
+	// ID is omitted when the recipes are identified by PublicID
+	ID       int64  `json:"id,omitempty"`
+	PublicID string `json:"public_id,omitempty"`
+	Name     string `json:"name"`
+	// Slug finds the recipe at /recipes/by-slug/{slug}
+	Slug        string       `json:"slug"`
+	Description string       `json:"description"`
 	Ingredients []Ingredient `json:"ingredients"`
 	Steps       []string     `json:"steps"`
+	// PrepMinutes and CookMinutes are 0 when unknown
+	PrepMinutes int    `json:"prep_minutes"`
+	CookMinutes int    `json:"cook_minutes"`
+	Yield       string `json:"yield"`
+	// Calories are the kcal of a serving, 0 when unknown
+	Calories int `json:"calories"`
 }
 
 func (r *Recipe) FromEntity(recipe *entity.Recipe) {
 	r.ID = recipe.ID
 	r.Name = recipe.Name
+	r.Slug = recipe.Slug
+	r.Description = recipe.Description
 	r.Steps = recipe.Steps
+	r.PrepMinutes = int(recipe.PrepTime / time.Minute)
+	r.CookMinutes = int(recipe.CookTime / time.Minute)
+	r.Yield = recipe.Yield
+	r.Calories = recipe.Calories
 	r.Ingredients = make([]Ingredient, len(recipe.Ingredients))
 	for i, ingredient := range recipe.Ingredients {
 		r.Ingredients[i].FromEntity(ingredient)
 	}
 }
+
+// RecipeRef identifies a recipe without its details
+type RecipeRef struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+}
+
+func (r *RecipeRef) FromEntity(recipe *entity.Recipe) {
+	r.ID = recipe.ID
+	r.Name = recipe.Name
+}
@@ -0,0 +1,47 @@
+package view
+
+import "github.com/TomeuUris/recipes-catalog/pkg/importer"
+
+// RecipeDraft is a recipe imported from a page, it can be created as is once
+// reviewed
+type RecipeDraft struct {
+	// Recipe has no ID nor slug until it is created
+	Recipe Recipe           `json:"recipe"`
+	Lines  []IngredientLine `json:"lines"`
+	// Unmatched are the lines without an ingredient, to be added by hand
+	Unmatched []string `json:"unmatched"`
+	// URL is the page the recipe was fetched from, omitted for uploads
+	URL string `json:"url,omitempty"`
+}
+
+func (d *RecipeDraft) FromDraft(draft *importer.Draft) {
+	d.Recipe.FromEntity(draft.Recipe)
+	d.Lines = make([]IngredientLine, len(draft.Lines))
+	for i, line := range draft.Lines {
+		d.Lines[i].FromLine(line)
+	}
+	d.Unmatched = draft.Unmatched()
+	d.URL = draft.URL
+}
+
+// IngredientLine is an ingredient line of the page with what it matched
+type IngredientLine struct {
+	Text string `json:"text"`
+	// Quantity is omitted when the line doesn't start with one
+	Quantity   float64      `json:"quantity,omitempty"`
+	Unit       *CookingUnit `json:"unit,omitempty"`
+	Ingredient *Ingredient  `json:"ingredient,omitempty"`
+}
+
+func (l *IngredientLine) FromLine(line *importer.Line) {
+	l.Text = line.Text
+	l.Quantity = line.Quantity
+	if line.Unit != nil {
+		l.Unit = &CookingUnit{}
+		l.Unit.FromEntity(line.Unit)
+	}
+	if line.Ingredient != nil {
+		l.Ingredient = &Ingredient{}
+		l.Ingredient.FromEntity(line.Ingredient)
+	}
+}
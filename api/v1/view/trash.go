@@ -0,0 +1,28 @@
+package view
+
+import (
+	"time"
+
+	"github.com/TomeuUris/recipes-catalog/pkg/entity"
+)
+
+type TrashItem struct {
+	Entity    string    `json:"entity"`
+	ID        int64     `json:"id"`
+	Name      string    `json:"name"`
+	DeletedAt time.Time `json:"deleted_at"`
+}
+
+func (t *TrashItem) FromEntity(item *entity.TrashItem) {
+	t.Entity = item.Entity
+	t.ID = item.ID
+	t.Name = item.Name
+	t.DeletedAt = item.DeletedAt
+}
+
+// PurgeResult counts the entities permanently removed from the trash
+type PurgeResult struct {
+	Before      time.Time `json:"before"`
+	Recipes     int       `json:"recipes"`
+	Ingredients int       `json:"ingredients"`
+}
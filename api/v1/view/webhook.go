@@ -0,0 +1,59 @@
+package view
+
+import (
+	"time"
+
+	"github.com/TomeuUris/recipes-catalog/pkg/entity"
+)
+
+type Webhook struct {
+	ID     int64    `json:"id"`
+	URL    string   `json:"url"`
+	Events []string `json:"events"`
+	Active bool     `json:"active"`
+	// Secret is only returned when the webhook is created
+	Secret string `json:"secret,omitempty"`
+}
+
+func (w *Webhook) FromEntity(webhook *entity.Webhook) {
+	w.ID = webhook.ID
+	w.URL = webhook.URL
+	w.Events = webhook.Events
+	if w.Events == nil {
+		w.Events = []string{}
+	}
+	w.Active = webhook.Active
+}
+
+type WebhookDelivery struct {
+	ID             int64      `json:"id"`
+	WebhookID      int64      `json:"webhook_id"`
+	EventID        int64      `json:"event_id"`
+	EventType      string     `json:"event_type"`
+	Payload        string     `json:"payload"`
+	Status         string     `json:"status"`
+	Attempts       int        `json:"attempts"`
+	ResponseStatus int        `json:"response_status,omitempty"`
+	LastError      string     `json:"last_error,omitempty"`
+	NextAttemptAt  *time.Time `json:"next_attempt_at,omitempty"`
+	DeliveredAt    *time.Time `json:"delivered_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+func (d *WebhookDelivery) FromEntity(delivery *entity.WebhookDelivery) {
+	d.ID = delivery.ID
+	d.WebhookID = delivery.WebhookID
+	d.EventID = delivery.EventID
+	d.EventType = delivery.EventType
+	d.Payload = delivery.Payload
+	d.Status = delivery.Status
+	d.Attempts = delivery.Attempts
+	d.ResponseStatus = delivery.ResponseStatus
+	d.LastError = delivery.LastError
+	if delivery.Status == entity.DeliveryPending {
+		nextAttemptAt := delivery.NextAttemptAt
+		d.NextAttemptAt = &nextAttemptAt
+	}
+	d.DeliveredAt = delivery.DeliveredAt
+	d.CreatedAt = delivery.CreatedAt
+}
@@ -0,0 +1,228 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/TomeuUris/recipes-catalog/pkg/entity"
+	"github.com/TomeuUris/recipes-catalog/pkg/repo/audit"
+	"github.com/TomeuUris/recipes-catalog/pkg/repo/cooking_unit"
+	"github.com/TomeuUris/recipes-catalog/pkg/repo/ingredient"
+	"github.com/TomeuUris/recipes-catalog/pkg/repo/outbox"
+	"github.com/TomeuUris/recipes-catalog/pkg/repo/recipe"
+	"github.com/TomeuUris/recipes-catalog/pkg/repo/schema"
+	"github.com/TomeuUris/recipes-catalog/pkg/repo/transaction"
+	"github.com/TomeuUris/recipes-catalog/pkg/repo/webhook"
+	sqldb "github.com/TomeuUris/recipes-catalog/pkg/sqlite"
+	"github.com/TomeuUris/recipes-catalog/pkg/sqlite/migrations"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// Storage backends the server can run on
+const (
+	// BackendGorm stores the catalog with gorm, migrated with the embedded gorm schema migrations
+	BackendGorm = "gorm"
+	// BackendSQL stores the catalog with database/sql, migrated with the embedded SQL migrations
+	BackendSQL = "sql"
+	// BackendMemory keeps the catalog in memory, optionally saved to a JSON snapshot
+	BackendMemory = "memory"
+)
+
+// Config selects and configures the storage backend
+type Config struct {
+	Backend string
+	// DBPath is the SQLite database of the gorm and sql backends
+	DBPath string
+	// DBReaders is the number of read-only connections of the gorm and sql
+	// backends, writes go through a single connection
+	DBReaders int
+	// SnapshotPath is the JSON file the memory backend is loaded from and
+	// saved to on Close, empty to not persist it
+	SnapshotPath string
+}
+
+// OpenRepo builds the repositories of the configured backend
+func OpenRepo(cfg Config) (*Repo, error) {
+	switch cfg.Backend {
+	case BackendGorm:
+		pool, err := sqldb.OpenPool(cfg.DBPath, sqldb.PoolOptions{Readers: cfg.DBReaders})
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect database: %w", err)
+		}
+		db, err := OpenDB(pool)
+		if err != nil {
+			pool.Close()
+			return nil, fmt.Errorf("failed to connect database: %w", err)
+		}
+		if err := schema.Migrate(context.Background(), db); err != nil {
+			pool.Close()
+			return nil, fmt.Errorf("failed to migrate database: %w", err)
+		}
+		return &Repo{
+			Ingredients:       ingredient.NewGormRepo(db),
+			Recipes:           recipe.NewGormRepo(db),
+			CookingUnits:      cooking_unit.NewGormRepo(db),
+			Webhooks:          webhook.NewGormRepo(db),
+			WebhookDeliveries: webhook.NewGormDeliveryRepo(db),
+			Audit:             audit.NewGormRepo(db),
+			Outbox:            outbox.NewGormRepo(db),
+			Tx:                transaction.NewGormManager(db),
+			DB:                pool.Writer(),
+			ReadDB:            pool.Reader(),
+			close:             pool.Close,
+		}, nil
+
+	case BackendSQL:
+		pool, err := sqldb.OpenPool(cfg.DBPath, sqldb.PoolOptions{Readers: cfg.DBReaders, ForeignKeys: true})
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect database: %w", err)
+		}
+		if err := migrations.Store.Apply(context.Background(), pool.Writer()); err != nil {
+			pool.Close()
+			return nil, err
+		}
+		if err := migrations.Store.Verify(context.Background(), pool.Writer()); err != nil {
+			pool.Close()
+			return nil, err
+		}
+
+		// Webhooks only have a gorm repository, it shares the connections
+		db, err := OpenDB(pool)
+		if err != nil {
+			pool.Close()
+			return nil, err
+		}
+		return &Repo{
+			Ingredients:       ingredient.NewRepo(pool),
+			Recipes:           recipe.NewRepo(pool),
+			CookingUnits:      cooking_unit.NewRepo(pool),
+			Webhooks:          webhook.NewGormRepo(db),
+			WebhookDeliveries: webhook.NewGormDeliveryRepo(db),
+			Audit:             audit.NewRepo(pool),
+			Outbox:            outbox.NewRepo(pool),
+			Tx:                transaction.NewSQLManager(pool),
+			DB:                pool.Writer(),
+			ReadDB:            pool.Reader(),
+			close:             pool.Close,
+		}, nil
+
+	case BackendMemory:
+		return openMemoryRepo(cfg.SnapshotPath)
+	}
+
+	return nil, fmt.Errorf("unknown backend %q, expected %s, %s or %s", cfg.Backend, BackendGorm, BackendSQL, BackendMemory)
+}
+
+// memorySnapshot is the JSON document the memory backend is persisted to
+type memorySnapshot struct {
+	Ingredients  []*entity.Ingredient  `json:"ingredients"`
+	CookingUnits []*entity.CookingUnit `json:"cooking_units"`
+	Recipes      []*entity.Recipe      `json:"recipes"`
+	// RecipeSlugs has the recipe of every slug given, current and previous
+	RecipeSlugs map[string]int64 `json:"recipe_slugs,omitempty"`
+}
+
+func openMemoryRepo(snapshotPath string) (*Repo, error) {
+	ingredients := ingredient.NewMemoryRepo()
+	units := cooking_unit.NewMemoryRepo()
+	recipes := recipe.NewMemoryRepo(ingredients)
+	messages := outbox.NewMemoryRepo()
+
+	// Load the previous snapshot, if any
+	if snapshotPath != "" {
+		data, err := os.ReadFile(snapshotPath)
+		switch {
+		case err == nil:
+			snapshot := &memorySnapshot{}
+			if err := json.Unmarshal(data, snapshot); err != nil {
+				return nil, fmt.Errorf("invalid snapshot %s: %w", snapshotPath, err)
+			}
+			ingredients.Restore(snapshot.Ingredients)
+			units.Restore(snapshot.CookingUnits)
+			recipes.Restore(snapshot.Recipes)
+			recipes.RestoreSlugs(snapshot.RecipeSlugs)
+			log.Printf("Loaded snapshot %s", snapshotPath)
+		case !errors.Is(err, os.ErrNotExist):
+			return nil, err
+		}
+	}
+
+	// Webhooks and the audit log are kept in an in-memory database migrated
+	// like the gorm backend
+	db, err := gorm.Open(&sqlite.Dialector{DriverName: sqldb.DriverName, DSN: "file::memory:"}, &gorm.Config{})
+	if err != nil {
+		return nil, err
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+	// Every connection to :memory: opens a new database
+	sqlDB.SetMaxOpenConns(1)
+	if err := schema.Migrate(context.Background(), db); err != nil {
+		sqlDB.Close()
+		return nil, err
+	}
+	auditLog := audit.NewGormRepo(db)
+
+	return &Repo{
+		Ingredients:       ingredients,
+		Recipes:           recipes,
+		CookingUnits:      units,
+		Webhooks:          webhook.NewGormRepo(db),
+		WebhookDeliveries: webhook.NewGormDeliveryRepo(db),
+		Audit:             auditLog,
+		Outbox:            messages,
+		// The memory repositories have no transactions
+		Tx: transaction.NewPassthroughManager(transaction.Repos{
+			Ingredients:  ingredients,
+			Recipes:      recipes,
+			CookingUnits: units,
+			Outbox:       messages,
+			Audit:        auditLog,
+		}),
+		close: func() error {
+			defer sqlDB.Close()
+			if snapshotPath == "" {
+				return nil
+			}
+			return saveSnapshot(snapshotPath, &memorySnapshot{
+				Ingredients:  ingredients.Snapshot(),
+				CookingUnits: units.Snapshot(),
+				Recipes:      recipes.Snapshot(),
+				RecipeSlugs:  recipes.Slugs(),
+			})
+		},
+	}, nil
+}
+
+// saveSnapshot writes the snapshot to a temporary file and renames it, so a
+// failed write doesn't corrupt the previous one
+func saveSnapshot(path string, snapshot *memorySnapshot) error {
+	data, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return err
+	}
+	log.Printf("Saved snapshot %s", path)
+	return nil
+}
+
+// getEnv returns the environment variable or fallback when it is not set
+func getEnv(key, fallback string) string {
+	if value, ok := os.LookupEnv(key); ok {
+		return value
+	}
+	return fallback
+}
@@ -0,0 +1,110 @@
+// Command backup takes online backups of the SQLite database and restores them
+//
+//	backup [-backend gorm|sql] [-db file] [-dir dir] [-keep N] [-max-age duration] [create | list | restore file]
+//
+// Backups can be taken while the server runs. Restoring replaces the database
+// file, stop the server first: a database still in use is not restored.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/TomeuUris/recipes-catalog/pkg/repo/schema"
+	"github.com/TomeuUris/recipes-catalog/pkg/sqlite"
+	"github.com/TomeuUris/recipes-catalog/pkg/sqlite/migrations"
+)
+
+func main() {
+	backend := flag.String("backend", getEnv("DB_BACKEND", "gorm"), "schema of the database, gorm or sql")
+	dbPath := flag.String("db", getEnv("DB_PATH", "database.sqlite"), "SQLite database file")
+	dir := flag.String("dir", getEnv("BACKUP_DIR", "backups"), "directory of the backups")
+	retention := sqlite.Retention{}
+	flag.IntVar(&retention.Keep, "keep", 7, "number of backups kept after creating one, 0 for no limit")
+	flag.DurationVar(&retention.MaxAge, "max-age", 30*24*time.Hour, "age of the backups removed after creating one, 0 for no limit")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] [create | list | restore file]\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	var store *sqlite.MigrationStore
+	switch *backend {
+	case "gorm":
+		store = &schema.Store
+	case "sql":
+		store = &migrations.Store
+	default:
+		log.Fatalf("unknown backend %q, expected gorm or sql", *backend)
+	}
+
+	if err := run(context.Background(), store, *dbPath, *dir, retention, flag.Args()); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func run(ctx context.Context, store *sqlite.MigrationStore, dbPath, dir string, retention sqlite.Retention, args []string) error {
+	command := "create"
+	if len(args) > 0 {
+		command = args[0]
+	}
+
+	switch {
+	case command == "create" && len(args) <= 1:
+		if _, err := os.Stat(dbPath); err != nil {
+			return err
+		}
+		db, err := sqlite.Open(dbPath)
+		if err != nil {
+			return fmt.Errorf("error opening database: %w", err)
+		}
+		defer db.Close()
+
+		backup, err := sqlite.NewBackups(db, dbPath, dir, retention).Create(ctx)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Backed up %s to %s (%d bytes)\n", dbPath, backup.Path, backup.Size)
+		return nil
+
+	case command == "list" && len(args) <= 1:
+		backups, err := sqlite.NewBackups(nil, dbPath, dir, retention).List()
+		if err != nil {
+			return err
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "NAME\tSIZE\tCREATED AT")
+		for _, backup := range backups {
+			fmt.Fprintf(w, "%s\t%d\t%s\n", backup.Name, backup.Size, backup.CreatedAt.Local().Format(time.RFC3339))
+		}
+		return w.Flush()
+
+	case command == "restore" && len(args) == 2:
+		restored, err := sqlite.Restore(ctx, args[1], dbPath, store)
+		if err != nil {
+			return fmt.Errorf("error restoring %s: %w", args[1], err)
+		}
+		if restored.Replaced == "" {
+			fmt.Printf("Restored %s at version %d\n", args[1], restored.Status.Version)
+			return nil
+		}
+		fmt.Printf("Restored %s at version %d, the replaced database is %s\n", args[1], restored.Status.Version, restored.Replaced)
+		return nil
+	}
+
+	flag.Usage()
+	os.Exit(2)
+	return nil
+}
+
+func getEnv(key, fallback string) string {
+	if value, ok := os.LookupEnv(key); ok {
+		return value
+	}
+	return fallback
+}
@@ -0,0 +1,142 @@
+// Command doctor checks the integrity of the SQLite database and repairs it
+//
+//	doctor [-backend gorm|sql] [-db file] [-repair] [-dry-run]
+//
+// It reports orphaned steps, gaps or duplicates in the step numbers, recipes
+// using deleted or missing ingredients, recipes without steps and schema
+// drift. -repair fixes what can be fixed, -dry-run reports what it would fix.
+// The repaired recipes get events in the outbox, sent by the server, and
+// entries in the audit log like any other write. It exits with status 1 while
+// issues remain.
+package main
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"text/tabwriter"
+
+	"github.com/TomeuUris/recipes-catalog/api/v1/view"
+	"github.com/TomeuUris/recipes-catalog/pkg/audit"
+	"github.com/TomeuUris/recipes-catalog/pkg/doctor"
+	"github.com/TomeuUris/recipes-catalog/pkg/outbox"
+	"github.com/TomeuUris/recipes-catalog/pkg/repo/transaction"
+	"github.com/TomeuUris/recipes-catalog/pkg/sqlite"
+	gormsqlite "gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func main() {
+	backend := flag.String("backend", getEnv("DB_BACKEND", "gorm"), "schema of the database, gorm or sql")
+	dbPath := flag.String("db", getEnv("DB_PATH", "database.sqlite"), "SQLite database file")
+	repair := flag.Bool("repair", false, "repair the issues that can be repaired")
+	dryRun := flag.Bool("dry-run", false, "report the repairs without making them")
+	flag.Parse()
+
+	var dbSchema *doctor.Schema
+	switch *backend {
+	case "gorm":
+		dbSchema = doctor.GormSchema
+	case "sql":
+		dbSchema = doctor.SQLSchema
+	default:
+		log.Fatalf("unknown backend %q, expected gorm or sql", *backend)
+	}
+
+	if _, err := os.Stat(*dbPath); err != nil {
+		log.Fatal(err)
+	}
+	db, err := sqlite.Open(*dbPath)
+	if err != nil {
+		log.Fatalf("error opening database: %v", err)
+	}
+	defer db.Close()
+
+	d := doctor.New(db, dbSchema)
+	manager, err := newTxManager(*backend, db)
+	if err != nil {
+		log.Fatalf("error opening database: %v", err)
+	}
+	d.SetTxManager(manager)
+	var report *doctor.Report
+	if *repair || *dryRun {
+		report, err = d.Repair(context.Background(), *dryRun)
+	} else {
+		report, err = d.Check(context.Background())
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if err := printReport(report); err != nil {
+		log.Fatal(err)
+	}
+	if report.Unresolved() > 0 {
+		db.Close()
+		os.Exit(1)
+	}
+}
+
+// newTxManager returns the units of work of the backend, recording the events
+// and audit entries of the writes like the server
+func newTxManager(backend string, db *sql.DB) (transaction.Manager, error) {
+	var manager transaction.Manager = transaction.NewSQLManager(db)
+	if backend == "gorm" {
+		gormDB, err := gorm.Open(&gormsqlite.Dialector{DriverName: sqlite.DriverName, Conn: db}, &gorm.Config{})
+		if err != nil {
+			return nil, err
+		}
+		manager = transaction.NewGormManager(gormDB)
+	}
+	return outbox.NewTxManager(audit.NewTxManager(manager, view.EncodeEntity), nil), nil
+}
+
+func printReport(report *doctor.Report) error {
+	if len(report.Issues) == 0 {
+		fmt.Println("No issues found")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "CHECK\tTABLE\tROW\tISSUE\tREPAIR")
+	repaired := 0
+	for _, issue := range report.Issues {
+		row, repair := "-", "manual"
+		if issue.RowID != 0 {
+			row = fmt.Sprint(issue.RowID)
+		}
+		switch {
+		case issue.Repaired && report.DryRun:
+			repair = "would repair"
+		case issue.Repaired:
+			repair = "repaired"
+		case issue.Repairable:
+			repair = "-repair"
+		}
+		if issue.Repaired {
+			repaired++
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", issue.Check, issue.Table, row, issue.Message, repair)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	switch {
+	case report.DryRun:
+		fmt.Printf("\n%d issues, %d would be repaired\n", len(report.Issues), repaired)
+	default:
+		fmt.Printf("\n%d issues, %d repaired\n", len(report.Issues), repaired)
+	}
+	return nil
+}
+
+func getEnv(key, fallback string) string {
+	if value, ok := os.LookupEnv(key); ok {
+		return value
+	}
+	return fallback
+}
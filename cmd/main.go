@@ -1,23 +1,71 @@
 package main
 
 import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"flag"
+	"log"
+	"net"
+	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/TomeuUris/recipes-catalog/api/v1/controller"
-	_ "github.com/TomeuUris/recipes-catalog/docs"
+	"github.com/TomeuUris/recipes-catalog/api/v1/openapi"
+	"github.com/TomeuUris/recipes-catalog/api/v1/rpc"
+	"github.com/TomeuUris/recipes-catalog/api/v1/view"
+	"github.com/TomeuUris/recipes-catalog/pkg/audit"
+	"github.com/TomeuUris/recipes-catalog/pkg/cache"
+	"github.com/TomeuUris/recipes-catalog/pkg/doctor"
+	"github.com/TomeuUris/recipes-catalog/pkg/event"
+	"github.com/TomeuUris/recipes-catalog/pkg/importer"
+	"github.com/TomeuUris/recipes-catalog/pkg/outbox"
+	"github.com/TomeuUris/recipes-catalog/pkg/publicid"
+	auditRepo "github.com/TomeuUris/recipes-catalog/pkg/repo/audit"
 	"github.com/TomeuUris/recipes-catalog/pkg/repo/cooking_unit"
 	"github.com/TomeuUris/recipes-catalog/pkg/repo/ingredient"
+	outboxRepo "github.com/TomeuUris/recipes-catalog/pkg/repo/outbox"
 	"github.com/TomeuUris/recipes-catalog/pkg/repo/recipe"
+	"github.com/TomeuUris/recipes-catalog/pkg/repo/transaction"
+	"github.com/TomeuUris/recipes-catalog/pkg/repo/webhook"
+	sqldb "github.com/TomeuUris/recipes-catalog/pkg/sqlite"
+	webhookDispatcher "github.com/TomeuUris/recipes-catalog/pkg/webhook"
 	"github.com/gin-gonic/gin"
-	swaggerFiles "github.com/swaggo/files"
-	ginSwagger "github.com/swaggo/gin-swagger"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 )
 
+// Repo groups the repositories the server is built on
 type Repo struct {
-	Ingredients ingredient.Repo
-	Recipes     recipe.Repo
+	Ingredients       ingredient.Repo
+	Recipes           recipe.Repo
+	CookingUnits      cooking_unit.Repo
+	Webhooks          webhook.Repo
+	WebhookDeliveries webhook.DeliveryRepo
+	Audit             auditRepo.Repo
+	// Outbox holds the events of the writes until they are delivered
+	Outbox outboxRepo.Repo
+	// Tx runs writes on several repositories atomically
+	Tx transaction.Manager
+	// DB is the writer connection of the SQLite database of the gorm and sql
+	// backends, nil for memory
+	DB *sql.DB
+	// ReadDB are the read-only connections of the database
+	ReadDB *sql.DB
+
+	close func() error
+}
+
+// Close releases the storage, the memory backend saves its snapshot
+func (r *Repo) Close() error {
+	if r.close == nil {
+		return nil
+	}
+	return r.close()
 }
 
 // @title Recipes Catalog API
@@ -28,45 +76,206 @@ type Repo struct {
 // @version v1
 // @schemes http
 func main() {
-	db, err := OpenDB()
+	cfg := Config{}
+	flag.StringVar(&cfg.Backend, "backend", getEnv("DB_BACKEND", BackendGorm), "storage backend, gorm, sql or memory")
+	flag.StringVar(&cfg.DBPath, "db", getEnv("DB_PATH", "database.sqlite"), "SQLite database file")
+	flag.IntVar(&cfg.DBReaders, "db-readers", 4, "read-only connections to the SQLite database, writes are queued for a single connection")
+	flag.StringVar(&cfg.SnapshotPath, "snapshot", getEnv("MEMORY_SNAPSHOT", ""), "JSON snapshot of the memory backend, loaded on start and saved on shutdown")
+	trashRetention := flag.Duration("trash-retention", controller.DefaultTrashRetention, "how long deleted recipes and ingredients are kept")
+	backupDir := flag.String("backup-dir", getEnv("BACKUP_DIR", "backups"), "directory of the backups taken on /admin/backups")
+	backupRetention := sqldb.Retention{}
+	flag.IntVar(&backupRetention.Keep, "backup-keep", 7, "number of backups kept, 0 for no limit")
+	flag.DurationVar(&backupRetention.MaxAge, "backup-max-age", 30*24*time.Hour, "age of the backups removed, 0 for no limit")
+	cacheSize := flag.Int("cache-size", 1000, "recipes, ingredients and cooking units cached by ID each, 0 to disable the cache")
+	cacheTTL := flag.Duration("cache-ttl", 5*time.Minute, "how long entities are cached, 0 for no limit")
+	publicIDKey := flag.String("public-id-key", getEnv("PUBLIC_ID_KEY", ""), "secret key identifying recipes by opaque public IDs instead of their IDs in the REST recipe paths, filters and views, empty to use the IDs. Conflicts, events, webhooks, the audit log and gRPC keep the IDs")
+	flag.Parse()
+
+	repo, err := OpenRepo(cfg)
 	if err != nil {
-		panic("failed to connect database")
+		log.Fatalf("failed to open %s backend: %v", cfg.Backend, err)
+	}
+
+	// Reads by ID go through the caches, writes invalidate them
+	ingredients, recipes, cookingUnits, tx := repo.Ingredients, repo.Recipes, repo.CookingUnits, repo.Tx
+	var cacheController *controller.CacheController
+	if *cacheSize > 0 {
+		recipesCache := cache.NewLRU(*cacheSize, *cacheTTL)
+		ingredientsCache := cache.NewLRU(*cacheSize, *cacheTTL)
+		cookingUnitsCache := cache.NewLRU(*cacheSize, *cacheTTL)
+		recipes = cache.NewRecipeRepo(recipes, recipesCache)
+		ingredients = cache.NewIngredientRepo(ingredients, ingredientsCache, recipesCache)
+		cookingUnits = cache.NewCookingUnitRepo(cookingUnits, cookingUnitsCache)
+		tx = cache.NewTxManager(tx, recipesCache, ingredientsCache, cookingUnitsCache)
+		cacheController = controller.NewCacheController(map[string]*cache.LRU{
+			"recipes":       recipesCache,
+			"ingredients":   ingredientsCache,
+			"cooking_units": cookingUnitsCache,
+		})
+	}
+
+	// Webhooks are sent the events of the outbox
+	webhooksRepo := repo.Webhooks
+	webhookDeliveriesRepo := repo.WebhookDeliveries
+	dispatcher := webhookDispatcher.NewDispatcher(webhooksRepo, webhookDeliveriesRepo, EncodeEvent)
+	go dispatcher.Run(context.Background())
+
+	// Every write is recorded in the audit log and adds its event to the outbox
+	// in its transaction, the events are delivered once committed
+	hub := event.NewHub(event.DefaultHistorySize)
+	outboxDispatcher := outbox.NewDispatcher(repo.Outbox,
+		outbox.NewLogSink(), outbox.NewHubSink(hub), outbox.NewWebhookSink(dispatcher))
+	go outboxDispatcher.Run(context.Background())
+	txManager := outbox.NewTxManager(audit.NewTxManager(tx, view.EncodeEntity), outboxDispatcher.Notify)
+	ingredientsRepo := outbox.NewIngredientRepo(ingredients, txManager)
+	recipesRepo := outbox.NewRecipeRepo(recipes, txManager)
+	cookingUnitsRepo := outbox.NewCookingUnitRepo(cookingUnits, txManager)
+
+	var publicIDs *publicid.Codec
+	if *publicIDKey != "" {
+		publicIDs = publicid.NewCodec("r", []byte(*publicIDKey))
+	}
+
+	// Serve the gRPC API on its own port
+	go func() {
+		if err := RunGRPC(recipesRepo, ingredientsRepo, cookingUnitsRepo, txManager, publicIDs); err != nil {
+			log.Fatalf("gRPC server stopped: %v", err)
+		}
+	}()
+
+	ingredientsController := controller.NewIngredientController(ingredientsRepo)
+	recipesController := controller.NewRecipeController(recipesRepo, txManager)
+	if publicIDs != nil {
+		recipesController.SetPublicIDs(publicIDs)
 	}
+	cookingUnitController := controller.NewCookingUnitController(cookingUnitsRepo)
+	eventController := controller.NewEventController(hub)
+	webhookController := controller.NewWebhookController(webhooksRepo, webhookDeliveriesRepo)
+	auditController := controller.NewAuditController(repo.Audit)
+	importController := controller.NewImportController(importer.New(ingredientsRepo, cookingUnitsRepo))
 
-	// Migrate the schema
-	if err := RunMigrations(db); err != nil {
-		panic(err)
+	// Only backends that soft delete have a trash, restoring and purging go
+	// through the unit of work like the other writes
+	var trashController *controller.TrashController
+	rawRecipesTrash, recipesOk := repo.Recipes.(recipe.TrashRepo)
+	rawIngredientsTrash, ingredientsOk := repo.Ingredients.(ingredient.TrashRepo)
+	if recipesOk && ingredientsOk {
+		recipesTrash := outbox.NewRecipeTrashRepo(rawRecipesTrash, txManager)
+		ingredientsTrash := outbox.NewIngredientTrashRepo(rawIngredientsTrash, txManager)
+		trashController = controller.NewTrashController(recipesTrash, ingredientsTrash, txManager, *trashRetention)
+		go PurgeTrashEvery(context.Background(), time.Hour, *trashRetention, txManager)
 	}
 
-	ingredientsController := controller.NewIngredientController(ingredient.NewGormRepo(db))
-	recipesController := controller.NewRecipeController(recipe.NewGormRepo(db))
-	cookingUnitController := controller.NewCookingUnitController(cooking_unit.NewGormRepo(db))
+	// Only backends with a database file are backed up and checked
+	var backupController *controller.BackupController
+	var integrityController *controller.IntegrityController
+	if repo.DB != nil {
+		backupController = controller.NewBackupController(sqldb.NewBackups(repo.ReadDB, cfg.DBPath, *backupDir, backupRetention))
+		dbSchema := doctor.GormSchema
+		if cfg.Backend == BackendSQL {
+			dbSchema = doctor.SQLSchema
+		}
+		repoDoctor := doctor.New(repo.DB, dbSchema)
+		repoDoctor.SetTxManager(txManager)
+		integrityController = controller.NewIntegrityController(repoDoctor)
+	}
 
 	r := gin.Default()
-	v1 := r.Group("/api/v1")
-	v1 = controller.SetupIngredientsRouter(ingredientsController, v1)
-	v1 = controller.SetupRecipesRouter(recipesController, v1)
-	controller.SetupCookingUnitsRouter(cookingUnitController, v1)
+	// Repositories read the actor and request ID of the audit log from the request context
+	r.ContextWithFallback = true
+	v1 := r.Group(openapi.BasePath)
 	if os.Getenv("ENV") != "prod" {
-		r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
+		// Check requests and responses against the OpenAPI document
+		v1.Use(openapi.MustNewValidator().Middleware())
 	}
+	controller.SetupRouter(&controller.Controllers{
+		Ingredients:  ingredientsController,
+		Recipes:      recipesController,
+		CookingUnits: cookingUnitController,
+		Events:       eventController,
+		Webhooks:     webhookController,
+		Audit:        auditController,
+		Import:       importController,
+		Trash:        trashController,
+		Backups:      backupController,
+		Cache:        cacheController,
+		Integrity:    integrityController,
+	}, v1)
+
+	document := openapi.Build()
+	r.GET("/openapi.json", func(ctx *gin.Context) {
+		ctx.JSON(http.StatusOK, document)
+	})
 
-	r.Run()
+	// Serve until interrupted, then release the storage
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	srv := &http.Server{Addr: ":" + getEnv("PORT", "8080"), Handler: r}
+	go func() {
+		log.Printf("Listening and serving HTTP on %s", srv.Addr)
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatalf("HTTP server stopped: %v", err)
+		}
+	}()
+	<-ctx.Done()
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		log.Printf("failed to shut down HTTP server: %v", err)
+	}
+	if err := repo.Close(); err != nil {
+		log.Fatalf("failed to close %s backend: %v", cfg.Backend, err)
+	}
 }
 
-func OpenDB() (*gorm.DB, error) {
-	return gorm.Open(sqlite.Open("database.sqlite"), &gorm.Config{})
+// OpenDB opens gorm on the connections, opened with the driver registering the
+// functions the migrations use
+func OpenDB(conn gorm.ConnPool) (*gorm.DB, error) {
+	return gorm.Open(&sqlite.Dialector{DriverName: sqldb.DriverName, Conn: conn}, &gorm.Config{})
 }
 
-func RunMigrations(db *gorm.DB) error {
-	if err := ingredient.RunMigrations(db); err != nil {
-		return err
+// RunGRPC serves the gRPC API on GRPC_ADDR (":9090" by default)
+func RunGRPC(recipes recipe.Repo, ingredients ingredient.Repo, units cooking_unit.Repo, tx transaction.Manager, publicIDs *publicid.Codec) error {
+	addr := os.Getenv("GRPC_ADDR")
+	if addr == "" {
+		addr = ":9090"
 	}
-	if err := recipe.RunMigrations(db); err != nil {
+
+	lis, err := net.Listen("tcp", addr)
+	if err != nil {
 		return err
 	}
-	if err := cooking_unit.RunMigrations(db); err != nil {
-		return err
+
+	log.Printf("Listening and serving gRPC on %s", addr)
+	return rpc.NewServer(recipes, ingredients, units, tx, publicIDs).Serve(lis)
+}
+
+// EncodeEvent serializes events the same way they are streamed on /events
+func EncodeEvent(e *event.Event) ([]byte, error) {
+	eventView := &view.Event{}
+	eventView.FromEvent(e)
+	return json.Marshal(eventView)
+}
+
+// PurgeTrashEvery purges the entities deleted longer ago than the retention period every interval
+func PurgeTrashEvery(ctx context.Context, interval, retention time.Duration, tx transaction.Manager) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		result, err := controller.PurgeTrash(ctx, tx, time.Now().Add(-retention))
+		if err != nil {
+			log.Printf("failed to purge trash: %v", err)
+		} else if result.Recipes > 0 || result.Ingredients > 0 {
+			log.Printf("Purged %d recipes and %d ingredients from the trash", result.Recipes, result.Ingredients)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
 	}
-	return nil
 }
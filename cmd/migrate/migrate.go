@@ -1,21 +1,170 @@
+// Command migrate moves the SQLite database between migration versions
+//
+//	migrate [-backend gorm|sql] [-db file] [-dry-run] [up | down | goto N | status]
 package main
 
 import (
 	"context"
+	"database/sql"
+	"flag"
 	"fmt"
 	"log"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
 
+	"github.com/TomeuUris/recipes-catalog/pkg/repo/schema"
 	"github.com/TomeuUris/recipes-catalog/pkg/sqlite"
 	"github.com/TomeuUris/recipes-catalog/pkg/sqlite/migrations"
+	gormsqlite "gorm.io/driver/sqlite"
+	"gorm.io/gorm"
 )
 
 func main() {
-	db := sqlite.MustOpen("database.sqlite")
+	backend := flag.String("backend", getEnv("DB_BACKEND", "gorm"), "schema to migrate, gorm or sql")
+	dbPath := flag.String("db", getEnv("DB_PATH", "database.sqlite"), "SQLite database file")
+	dryRun := flag.Bool("dry-run", false, "print the migrations that would run without running them")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] [up | down | goto N | status]\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	db, err := sqlite.Open(*dbPath)
+	if err != nil {
+		log.Fatalf("error opening database: %v", err)
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+	var store *sqlite.MigrationStore
+	switch *backend {
+	case "sql":
+		store = &migrations.Store
+	case "gorm":
+		store = &schema.Store
+		if err := adopt(ctx, db, *dryRun); err != nil {
+			log.Fatal(err)
+		}
+	default:
+		log.Fatalf("unknown backend %q, expected gorm or sql", *backend)
+	}
+
+	if err := run(ctx, store, db, flag.Args(), *dryRun); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// adopt versions a gorm database created by AutoMigrate
+func adopt(ctx context.Context, sqlDB *sql.DB, dryRun bool) error {
+	db, err := gorm.Open(&gormsqlite.Dialector{Conn: sqlDB}, &gorm.Config{})
+	if err != nil {
+		return err
+	}
+	if !dryRun {
+		return schema.Adopt(ctx, db)
+	}
+
+	legacy, err := schema.IsLegacy(ctx, db)
+	if err != nil {
+		return err
+	}
+	if legacy {
+		fmt.Printf("-- database created by AutoMigrate, it would be adopted at version 1 first\n\n")
+	}
+	return nil
+}
+
+func run(ctx context.Context, store *sqlite.MigrationStore, db *sql.DB, args []string, dryRun bool) error {
+	command := "up"
+	if len(args) > 0 {
+		command = args[0]
+	}
 
-	if err := migrations.Store.Apply(context.Background(), db); err != nil {
-		log.Panicf("error apply database migrations: %v", err.Error())
+	var target int
+	switch {
+	case command == "status" && len(args) <= 1:
+		return printStatus(ctx, store, db)
+	case command == "up" && len(args) <= 1:
+		target = store.Latest()
+	case command == "down" && len(args) <= 1:
+		status, err := store.Status(ctx, db)
+		if err != nil {
+			return err
+		}
+		if status.Version == 0 {
+			return fmt.Errorf("no migration to roll back")
+		}
+		target = status.Version - 1
+	case command == "goto" && len(args) == 2:
+		version, err := strconv.Atoi(args[1])
+		if err != nil {
+			return fmt.Errorf("invalid version %q", args[1])
+		}
+		target = version
+	default:
+		flag.Usage()
+		os.Exit(2)
 	}
 
-	_ = db.Close()
-	fmt.Println("Migrations successfully applied")
+	if dryRun {
+		return printPlan(ctx, store, db, target)
+	}
+	if err := store.Migrate(ctx, db, target); err != nil {
+		return fmt.Errorf("error applying database migrations: %w", err)
+	}
+	fmt.Printf("Database migrated to version %d\n", target)
+	return nil
+}
+
+func printStatus(ctx context.Context, store *sqlite.MigrationStore, db *sql.DB) error {
+	status, err := store.Status(ctx, db)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Version %d, latest %d\n\n", status.Version, status.Latest)
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED\tAPPLIED AT\tEDITED")
+	for _, migration := range status.Migrations {
+		appliedAt := "-"
+		if !migration.AppliedAt.IsZero() {
+			appliedAt = migration.AppliedAt.Local().Format(time.RFC3339)
+		} else if migration.Applied {
+			appliedAt = "unknown"
+		}
+		fmt.Fprintf(w, "%d\t%s\t%t\t%s\t%t\n", migration.Version, migration.Name, migration.Applied, appliedAt, migration.Edited)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	return status.Check()
+}
+
+func printPlan(ctx context.Context, store *sqlite.MigrationStore, db *sql.DB, target int) error {
+	steps, err := store.Plan(ctx, db, target)
+	if err != nil {
+		return err
+	}
+	if len(steps) == 0 {
+		fmt.Printf("Database already at version %d\n", target)
+		return nil
+	}
+
+	for _, step := range steps {
+		direction := "down"
+		if step.Up {
+			direction = "up"
+		}
+		fmt.Printf("-- %d %s %s\n%s\n\n", step.Version, step.Name, direction, step.SQL())
+	}
+	return nil
+}
+
+func getEnv(key, fallback string) string {
+	if value, ok := os.LookupEnv(key); ok {
+		return value
+	}
+	return fallback
 }
@@ -0,0 +1,30 @@
+// Command openapi writes the OpenAPI document of the API
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"log"
+	"os"
+
+	"github.com/TomeuUris/recipes-catalog/api/v1/openapi"
+)
+
+func main() {
+	out := flag.String("o", "docs/openapi.json", "output file, - for stdout")
+	flag.Parse()
+
+	data, err := json.MarshalIndent(openapi.Build(), "", "  ")
+	if err != nil {
+		log.Fatal(err)
+	}
+	data = append(data, '\n')
+
+	if *out == "-" {
+		os.Stdout.Write(data)
+		return
+	}
+	if err := os.WriteFile(*out, data, 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
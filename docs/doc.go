@@ -1,2 +0,0 @@
-// Package docs is a placeholder package for the Swagger documentation.
-package docs
@@ -0,0 +1,213 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/TomeuUris/recipes-catalog/pkg/entity"
+	"github.com/TomeuUris/recipes-catalog/pkg/event"
+	"github.com/TomeuUris/recipes-catalog/pkg/repo/cooking_unit"
+	"github.com/TomeuUris/recipes-catalog/pkg/repo/ingredient"
+	"github.com/TomeuUris/recipes-catalog/pkg/repo/recipe"
+)
+
+// Log receives the entries of the repository decorators
+type Log interface {
+	Add(ctx context.Context, entry *entity.AuditEntry) error
+}
+
+// Encoder returns the JSON of an entity, *entity.Recipe, *entity.Ingredient
+// or *entity.CookingUnit
+type Encoder func(v interface{}) ([]byte, error)
+
+// recorder adds the entries of the writes to the log. Failing to add one
+// fails the write, so it is rolled back along with it.
+type recorder struct {
+	log    Log
+	encode Encoder
+}
+
+func newRecorder(l Log, encode Encoder) recorder {
+	if encode == nil {
+		encode = json.Marshal
+	}
+	return recorder{log: l, encode: encode}
+}
+
+// record adds the entry of a write, before and after are nil when the entity
+// didn't exist
+func (r recorder) record(ctx context.Context, entityName string, id int64, action string, before, after interface{}) error {
+	entry := &entity.AuditEntry{
+		Entity:    entityName,
+		EntityID:  id,
+		Action:    action,
+		Actor:     Actor(ctx),
+		RequestID: RequestID(ctx),
+		At:        time.Now(),
+	}
+	var err error
+	if entry.Before, err = r.encodeJSON(before); err != nil {
+		return fmt.Errorf("failed to encode audit entry of %s %d %s: %w", entityName, id, action, err)
+	}
+	if entry.After, err = r.encodeJSON(after); err != nil {
+		return fmt.Errorf("failed to encode audit entry of %s %d %s: %w", entityName, id, action, err)
+	}
+	return r.log.Add(ctx, entry)
+}
+
+func (r recorder) encodeJSON(v interface{}) (string, error) {
+	if v == nil {
+		return "", nil
+	}
+	data, err := r.encode(v)
+	return string(data), err
+}
+
+// RecipeRepo records an audit entry for every successful write on the wrapped repository
+type RecipeRepo struct {
+	recipe.Repo
+	recorder
+}
+
+func NewRecipeRepo(repo recipe.Repo, l Log, encode Encoder) *RecipeRepo {
+	return &RecipeRepo{Repo: repo, recorder: newRecorder(l, encode)}
+}
+
+func (r *RecipeRepo) Add(ctx context.Context, rp *entity.Recipe) error {
+	if err := r.Repo.Add(ctx, rp); err != nil {
+		return err
+	}
+	return r.record(ctx, event.EntityRecipe, rp.ID, event.ActionCreated, nil, rp)
+}
+
+func (r *RecipeRepo) Edit(ctx context.Context, rp *entity.Recipe) error {
+	before := r.find(ctx, rp.ID)
+	if err := r.Repo.Edit(ctx, rp); err != nil {
+		return err
+	}
+	return r.record(ctx, event.EntityRecipe, rp.ID, event.ActionUpdated, before, rp)
+}
+
+func (r *RecipeRepo) Delete(ctx context.Context, rp *entity.Recipe) error {
+	before := r.find(ctx, rp.ID)
+	if err := r.Repo.Delete(ctx, rp); err != nil {
+		return err
+	}
+	return r.record(ctx, event.EntityRecipe, rp.ID, event.ActionDeleted, before, nil)
+}
+
+// find returns the stored recipe, nil when it can't be read
+func (r *RecipeRepo) find(ctx context.Context, id int64) interface{} {
+	if found, err := r.Repo.FindByID(ctx, id); err == nil {
+		return found
+	}
+	return nil
+}
+
+// IngredientRepo records an audit entry for every successful write on the wrapped repository
+type IngredientRepo struct {
+	ingredient.Repo
+	recorder
+}
+
+func NewIngredientRepo(repo ingredient.Repo, l Log, encode Encoder) *IngredientRepo {
+	return &IngredientRepo{Repo: repo, recorder: newRecorder(l, encode)}
+}
+
+func (r *IngredientRepo) Add(ctx context.Context, i *entity.Ingredient) error {
+	if err := r.Repo.Add(ctx, i); err != nil {
+		return err
+	}
+	return r.record(ctx, event.EntityIngredient, i.ID, event.ActionCreated, nil, i)
+}
+
+func (r *IngredientRepo) Edit(ctx context.Context, i *entity.Ingredient) error {
+	before := r.find(ctx, i.ID)
+	if err := r.Repo.Edit(ctx, i); err != nil {
+		return err
+	}
+	return r.record(ctx, event.EntityIngredient, i.ID, event.ActionUpdated, before, i)
+}
+
+func (r *IngredientRepo) Delete(ctx context.Context, i *entity.Ingredient) error {
+	before := r.find(ctx, i.ID)
+	if err := r.Repo.Delete(ctx, i); err != nil {
+		return err
+	}
+	return r.record(ctx, event.EntityIngredient, i.ID, event.ActionDeleted, before, nil)
+}
+
+func (r *IngredientRepo) DetachAndDelete(ctx context.Context, i *entity.Ingredient) error {
+	before := r.find(ctx, i.ID)
+	if err := r.Repo.DetachAndDelete(ctx, i); err != nil {
+		return err
+	}
+	return r.record(ctx, event.EntityIngredient, i.ID, event.ActionDeleted, before, nil)
+}
+
+func (r *IngredientRepo) Merge(ctx context.Context, target *entity.Ingredient, duplicates []int64) error {
+	before := make([]interface{}, len(duplicates))
+	for n, id := range duplicates {
+		before[n] = r.find(ctx, id)
+	}
+	if err := r.Repo.Merge(ctx, target, duplicates); err != nil {
+		return err
+	}
+	for n, id := range duplicates {
+		if err := r.record(ctx, event.EntityIngredient, id, event.ActionDeleted, before[n], nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// find returns the stored ingredient, nil when it can't be read
+func (r *IngredientRepo) find(ctx context.Context, id int64) interface{} {
+	if found, err := r.Repo.FindByID(ctx, int(id)); err == nil {
+		return found
+	}
+	return nil
+}
+
+// CookingUnitRepo records an audit entry for every successful write on the wrapped repository
+type CookingUnitRepo struct {
+	cooking_unit.Repo
+	recorder
+}
+
+func NewCookingUnitRepo(repo cooking_unit.Repo, l Log, encode Encoder) *CookingUnitRepo {
+	return &CookingUnitRepo{Repo: repo, recorder: newRecorder(l, encode)}
+}
+
+func (r *CookingUnitRepo) Add(ctx context.Context, u *entity.CookingUnit) error {
+	if err := r.Repo.Add(ctx, u); err != nil {
+		return err
+	}
+	return r.record(ctx, event.EntityCookingUnit, u.ID, event.ActionCreated, nil, u)
+}
+
+func (r *CookingUnitRepo) Edit(ctx context.Context, u *entity.CookingUnit) error {
+	before := r.find(ctx, u.ID)
+	if err := r.Repo.Edit(ctx, u); err != nil {
+		return err
+	}
+	return r.record(ctx, event.EntityCookingUnit, u.ID, event.ActionUpdated, before, u)
+}
+
+func (r *CookingUnitRepo) Delete(ctx context.Context, u *entity.CookingUnit) error {
+	before := r.find(ctx, u.ID)
+	if err := r.Repo.Delete(ctx, u); err != nil {
+		return err
+	}
+	return r.record(ctx, event.EntityCookingUnit, u.ID, event.ActionDeleted, before, nil)
+}
+
+// find returns the stored cooking unit, nil when it can't be read
+func (r *CookingUnitRepo) find(ctx context.Context, id int64) interface{} {
+	if found, err := r.Repo.FindByID(ctx, int(id)); err == nil {
+		return found
+	}
+	return nil
+}
@@ -0,0 +1,211 @@
+package audit_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/TomeuUris/recipes-catalog/pkg/audit"
+	"github.com/TomeuUris/recipes-catalog/pkg/entity"
+	auditRepo "github.com/TomeuUris/recipes-catalog/pkg/repo/audit"
+	"github.com/TomeuUris/recipes-catalog/pkg/repo/ingredient"
+	"github.com/TomeuUris/recipes-catalog/pkg/repo/transaction"
+	"github.com/TomeuUris/recipes-catalog/pkg/sqlite"
+	"github.com/TomeuUris/recipes-catalog/pkg/sqlite/migrations"
+	gormSqlite "gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func newLog(t *testing.T) *auditRepo.RepoGorm {
+	db, err := gorm.Open(gormSqlite.Open("file::memory:"), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("failed to get database: %v", err)
+	}
+	// Every connection to :memory: opens a new database
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+	if err := auditRepo.RunMigrations(db); err != nil {
+		t.Fatalf("failed to migrate database: %v", err)
+	}
+	return auditRepo.NewGormRepo(db)
+}
+
+func requestContext(actor, requestID string) context.Context {
+	return audit.WithRequestID(audit.WithActor(context.Background(), actor), requestID)
+}
+
+func findEntries(t *testing.T, log auditRepo.Repo, f *auditRepo.FindFilter) []*entity.AuditEntry {
+	t.Helper()
+	entries, err := log.FindByFilter(context.Background(), f)
+	if err != nil {
+		t.Fatalf("failed to find entries: %v", err)
+	}
+	return entries
+}
+
+func decode(t *testing.T, data string) map[string]interface{} {
+	t.Helper()
+	if data == "" {
+		return nil
+	}
+	var decoded map[string]interface{}
+	if err := json.Unmarshal([]byte(data), &decoded); err != nil {
+		t.Fatalf("invalid JSON %q: %v", data, err)
+	}
+	return decoded
+}
+
+func TestIngredientRepo_RecordsWrites(t *testing.T) {
+	log := newLog(t)
+	repo := audit.NewIngredientRepo(ingredient.NewMemoryRepo(), log, nil)
+
+	ctx := requestContext("alice", "request-1")
+	salt := &entity.Ingredient{Name: "Salt", Type: "Spice"}
+	if err := repo.Add(ctx, salt); err != nil {
+		t.Fatalf("failed to add ingredient: %v", err)
+	}
+	ctx = requestContext("bob", "request-2")
+	salt.Name = "Sea salt"
+	if err := repo.Edit(ctx, salt); err != nil {
+		t.Fatalf("failed to edit ingredient: %v", err)
+	}
+	if err := repo.Delete(ctx, salt); err != nil {
+		t.Fatalf("failed to delete ingredient: %v", err)
+	}
+	// Failed writes are not recorded
+	if err := repo.Delete(ctx, salt); !entity.IsErrNotFound(err) {
+		t.Fatalf("expected deleting twice to fail, got %v", err)
+	}
+
+	entries := findEntries(t, log, &auditRepo.FindFilter{Entity: "ingredient", EntityID: salt.ID})
+	if len(entries) != 3 {
+		t.Fatalf("expected 3 entries, got %d", len(entries))
+	}
+	created, edited, deleted := entries[0], entries[1], entries[2]
+	if created.Action != "created" || created.Actor != "alice" || created.RequestID != "request-1" ||
+		created.Before != "" || decode(t, created.After)["Name"] != "Salt" {
+		t.Fatalf("unexpected created entry %+v", created)
+	}
+	if edited.Action != "updated" || edited.Actor != "bob" || edited.RequestID != "request-2" ||
+		decode(t, edited.Before)["Name"] != "Salt" || decode(t, edited.After)["Name"] != "Sea salt" {
+		t.Fatalf("unexpected updated entry %+v", edited)
+	}
+	if deleted.Action != "deleted" || decode(t, deleted.Before)["Name"] != "Sea salt" || deleted.After != "" {
+		t.Fatalf("unexpected deleted entry %+v", deleted)
+	}
+}
+
+func TestTxManager_RecordsInTheTransaction(t *testing.T) {
+	sqlDB, err := sqlite.Open(":memory:")
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	// Every connection to :memory: opens a new database
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+	if err := migrations.Store.Apply(context.Background(), sqlDB); err != nil {
+		t.Fatalf("failed to migrate database: %v", err)
+	}
+	log := auditRepo.NewRepo(sqlDB)
+	manager := audit.NewTxManager(transaction.NewSQLManager(sqlDB), nil)
+	ctx := requestContext("alice", "request-1")
+
+	errAbort := errors.New("abort")
+	if err := manager.Do(ctx, func(repos *transaction.Repos) error {
+		if err := repos.Ingredients.Add(ctx, &entity.Ingredient{Name: "Salt", Type: "Spice"}); err != nil {
+			return err
+		}
+		return errAbort
+	}); !errors.Is(err, errAbort) {
+		t.Fatalf("expected the error of the unit of work, got %v", err)
+	}
+	if entries := findEntries(t, log, &auditRepo.FindFilter{}); len(entries) != 0 {
+		t.Fatalf("expected no entries of a rolled back unit of work, got %d", len(entries))
+	}
+
+	if err := manager.Do(ctx, func(repos *transaction.Repos) error {
+		return repos.Ingredients.Add(ctx, &entity.Ingredient{Name: "Pepper", Type: "Spice"})
+	}); err != nil {
+		t.Fatalf("failed to run unit of work: %v", err)
+	}
+	entries := findEntries(t, log, &auditRepo.FindFilter{})
+	if len(entries) != 1 || entries[0].Actor != "alice" || decode(t, entries[0].After)["Name"] != "Pepper" {
+		t.Fatalf("expected the entry of the committed unit of work, got %+v", entries)
+	}
+
+	// A write that can't be recorded is rolled back
+	if _, err := sqlDB.Exec(`DROP TABLE audit_entries`); err != nil {
+		t.Fatalf("failed to drop the audit log: %v", err)
+	}
+	if err := manager.Do(ctx, func(repos *transaction.Repos) error {
+		return repos.Ingredients.Add(ctx, &entity.Ingredient{Name: "Cumin", Type: "Spice"})
+	}); err == nil {
+		t.Fatalf("expected the unit of work to fail without an audit log")
+	}
+	// Only the pepper is kept
+	if found, err := ingredient.NewRepo(sqlDB).FindByFilter(context.Background(), &ingredient.FindFilter{}); err != nil || len(found) != 1 {
+		t.Fatalf("expected the unrecorded ingredient to be rolled back, got %+v (%v)", found, err)
+	}
+}
+
+// stubIngredientTrash restores nothing, the ingredients it's given are
+// returned as purged
+type stubIngredientTrash struct {
+	ingredient.TrashRepo
+	purged []*entity.TrashItem
+}
+
+func (r *stubIngredientTrash) Restore(ctx context.Context, id int64) error {
+	return nil
+}
+
+func (r *stubIngredientTrash) Purge(ctx context.Context, before time.Time) ([]*entity.TrashItem, error) {
+	return r.purged, nil
+}
+
+func TestTxManager_RecordsTrash(t *testing.T) {
+	log := newLog(t)
+	ingredients := ingredient.NewMemoryRepo()
+	salt := &entity.Ingredient{Name: "Salt", Type: "Spice"}
+	if err := ingredients.Add(context.Background(), salt); err != nil {
+		t.Fatalf("failed to add ingredient: %v", err)
+	}
+	manager := audit.NewTxManager(transaction.NewPassthroughManager(transaction.Repos{
+		Ingredients:      ingredients,
+		IngredientsTrash: &stubIngredientTrash{purged: []*entity.TrashItem{{Entity: entity.TrashIngredient, ID: 7, Name: "Pepper"}}},
+		Audit:            log,
+	}), nil)
+	ctx := requestContext("alice", "request-1")
+
+	if err := manager.Do(ctx, func(repos *transaction.Repos) error {
+		if err := repos.IngredientsTrash.Restore(ctx, salt.ID); err != nil {
+			return err
+		}
+		_, err := repos.IngredientsTrash.Purge(ctx, time.Now())
+		return err
+	}); err != nil {
+		t.Fatalf("failed to run unit of work: %v", err)
+	}
+
+	entries := findEntries(t, log, &auditRepo.FindFilter{})
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries, got %+v", entries)
+	}
+	actions := map[string]*entity.AuditEntry{}
+	for _, entry := range entries {
+		actions[entry.Action] = entry
+	}
+	if restored := actions["restored"]; restored == nil || restored.EntityID != salt.ID || decode(t, restored.After)["Name"] != "Salt" {
+		t.Fatalf("expected the restored salt, got %+v", actions["restored"])
+	}
+	if purged := actions["purged"]; purged == nil || purged.EntityID != 7 || decode(t, purged.Before)["Name"] != "Pepper" || purged.After != "" {
+		t.Fatalf("expected the purged pepper, got %+v", actions["purged"])
+	}
+}
@@ -0,0 +1,39 @@
+package audit
+
+import (
+	"context"
+
+	"github.com/TomeuUris/recipes-catalog/pkg/repo/transaction"
+)
+
+// TxManager records the audit entries of the writes of a unit of work in its
+// audit repository, so they are committed or rolled back along with the
+// writes
+type TxManager struct {
+	transaction.Manager
+	encode Encoder
+}
+
+func NewTxManager(manager transaction.Manager, encode Encoder) *TxManager {
+	return &TxManager{Manager: manager, encode: encode}
+}
+
+func (m *TxManager) Do(ctx context.Context, fn func(repos *transaction.Repos) error) error {
+	return m.Manager.Do(ctx, func(repos *transaction.Repos) error {
+		audited := &transaction.Repos{
+			Ingredients:  NewIngredientRepo(repos.Ingredients, repos.Audit, m.encode),
+			Recipes:      NewRecipeRepo(repos.Recipes, repos.Audit, m.encode),
+			CookingUnits: NewCookingUnitRepo(repos.CookingUnits, repos.Audit, m.encode),
+			Outbox:       repos.Outbox,
+			Audit:        repos.Audit,
+			Tx:           repos.Tx,
+		}
+		if repos.RecipesTrash != nil {
+			audited.RecipesTrash = NewRecipeTrashRepo(repos.RecipesTrash, repos.Recipes, repos.Audit, m.encode)
+		}
+		if repos.IngredientsTrash != nil {
+			audited.IngredientsTrash = NewIngredientTrashRepo(repos.IngredientsTrash, repos.Ingredients, repos.Audit, m.encode)
+		}
+		return fn(audited)
+	})
+}
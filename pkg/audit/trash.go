@@ -0,0 +1,83 @@
+package audit
+
+import (
+	"context"
+	"time"
+
+	"github.com/TomeuUris/recipes-catalog/pkg/entity"
+	"github.com/TomeuUris/recipes-catalog/pkg/event"
+	"github.com/TomeuUris/recipes-catalog/pkg/repo/ingredient"
+	"github.com/TomeuUris/recipes-catalog/pkg/repo/recipe"
+)
+
+// RecipeTrashRepo records an audit entry for every recipe restored or purged
+// from the trash, recipes reads the restored ones
+type RecipeTrashRepo struct {
+	recipe.TrashRepo
+	recipes recipe.Repo
+	recorder
+}
+
+func NewRecipeTrashRepo(trash recipe.TrashRepo, recipes recipe.Repo, l Log, encode Encoder) *RecipeTrashRepo {
+	return &RecipeTrashRepo{TrashRepo: trash, recipes: recipes, recorder: newRecorder(l, encode)}
+}
+
+func (r *RecipeTrashRepo) Restore(ctx context.Context, id int64) error {
+	if err := r.TrashRepo.Restore(ctx, id); err != nil {
+		return err
+	}
+	var after interface{}
+	if found, err := r.recipes.FindByID(ctx, id); err == nil {
+		after = found
+	}
+	return r.record(ctx, event.EntityRecipe, id, event.ActionRestored, nil, after)
+}
+
+func (r *RecipeTrashRepo) Purge(ctx context.Context, before time.Time) ([]*entity.TrashItem, error) {
+	purged, err := r.TrashRepo.Purge(ctx, before)
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range purged {
+		if err := r.record(ctx, event.EntityRecipe, item.ID, event.ActionPurged, &entity.Recipe{ID: item.ID, Name: item.Name}, nil); err != nil {
+			return nil, err
+		}
+	}
+	return purged, nil
+}
+
+// IngredientTrashRepo records an audit entry for every ingredient restored or
+// purged from the trash, ingredients reads the restored ones
+type IngredientTrashRepo struct {
+	ingredient.TrashRepo
+	ingredients ingredient.Repo
+	recorder
+}
+
+func NewIngredientTrashRepo(trash ingredient.TrashRepo, ingredients ingredient.Repo, l Log, encode Encoder) *IngredientTrashRepo {
+	return &IngredientTrashRepo{TrashRepo: trash, ingredients: ingredients, recorder: newRecorder(l, encode)}
+}
+
+func (r *IngredientTrashRepo) Restore(ctx context.Context, id int64) error {
+	if err := r.TrashRepo.Restore(ctx, id); err != nil {
+		return err
+	}
+	var after interface{}
+	if found, err := r.ingredients.FindByID(ctx, int(id)); err == nil {
+		after = found
+	}
+	return r.record(ctx, event.EntityIngredient, id, event.ActionRestored, nil, after)
+}
+
+func (r *IngredientTrashRepo) Purge(ctx context.Context, before time.Time) ([]*entity.TrashItem, error) {
+	purged, err := r.TrashRepo.Purge(ctx, before)
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range purged {
+		if err := r.record(ctx, event.EntityIngredient, item.ID, event.ActionPurged, &entity.Ingredient{ID: item.ID, Name: item.Name}, nil); err != nil {
+			return nil, err
+		}
+	}
+	return purged, nil
+}
@@ -0,0 +1,145 @@
+// Package cache keeps the entities read from the catalog repositories in memory
+package cache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// Stats counts the lookups of a cache
+type Stats struct {
+	Hits      uint64
+	Misses    uint64
+	Evictions uint64
+	// Size is the number of cached entries, up to Capacity
+	Size     int
+	Capacity int
+}
+
+// Invalidator drops the cached entries a write changed
+type Invalidator interface {
+	Remove(id int64)
+	Purge()
+}
+
+// LRU caches up to a number of entries by ID, evicting the least recently
+// used one when full. Entries expire after the TTL, zero for never.
+type LRU struct {
+	mu       sync.Mutex
+	capacity int
+	ttl      time.Duration
+	now      func() time.Time
+	order    *list.List
+	items    map[int64]*list.Element
+	stats    Stats
+	// generation changes on every invalidation, loads started before it
+	// aren't cached
+	generation uint64
+}
+
+type lruEntry struct {
+	id        int64
+	value     interface{}
+	expiresAt time.Time
+}
+
+func NewLRU(capacity int, ttl time.Duration) *LRU {
+	return &LRU{
+		capacity: capacity,
+		ttl:      ttl,
+		now:      time.Now,
+		order:    list.New(),
+		items:    make(map[int64]*list.Element, capacity),
+	}
+}
+
+// SetClock replaces the clock the entries expire with, for tests
+func (c *LRU) SetClock(now func() time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = now
+}
+
+// Get returns the cached value of the ID, or loads and caches it. Errors
+// aren't cached.
+func (c *LRU) Get(id int64, load func() (interface{}, error)) (interface{}, error) {
+	c.mu.Lock()
+	if element, ok := c.items[id]; ok {
+		entry := element.Value.(*lruEntry)
+		if c.ttl == 0 || c.now().Before(entry.expiresAt) {
+			c.order.MoveToFront(element)
+			c.stats.Hits++
+			c.mu.Unlock()
+			return entry.value, nil
+		}
+		c.remove(element)
+	}
+	c.stats.Misses++
+	generation := c.generation
+	c.mu.Unlock()
+
+	value, err := load()
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	// A write invalidated the cache while loading, the value may be stale
+	if generation == c.generation {
+		c.add(id, value)
+	}
+	return value, nil
+}
+
+func (c *LRU) add(id int64, value interface{}) {
+	if c.capacity <= 0 {
+		return
+	}
+	entry := &lruEntry{id: id, value: value, expiresAt: c.now().Add(c.ttl)}
+	if element, ok := c.items[id]; ok {
+		element.Value = entry
+		c.order.MoveToFront(element)
+		return
+	}
+	c.items[id] = c.order.PushFront(entry)
+	if c.order.Len() > c.capacity {
+		c.remove(c.order.Back())
+		c.stats.Evictions++
+	}
+}
+
+func (c *LRU) remove(element *list.Element) {
+	c.order.Remove(element)
+	delete(c.items, element.Value.(*lruEntry).id)
+}
+
+// Remove drops the entry of the ID
+func (c *LRU) Remove(id int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.generation++
+	if element, ok := c.items[id]; ok {
+		c.remove(element)
+	}
+}
+
+// Purge drops every entry
+func (c *LRU) Purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.generation++
+	c.order.Init()
+	c.items = make(map[int64]*list.Element, c.capacity)
+}
+
+// Stats returns the counters of the cache
+func (c *LRU) Stats() Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	stats := c.stats
+	stats.Size = c.order.Len()
+	stats.Capacity = c.capacity
+	return stats
+}
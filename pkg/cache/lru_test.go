@@ -0,0 +1,127 @@
+package cache_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/TomeuUris/recipes-catalog/pkg/cache"
+)
+
+// loader counts the loads of the values, which are their IDs
+type loader struct {
+	loads int
+}
+
+func (l *loader) load(id int64) func() (interface{}, error) {
+	return func() (interface{}, error) {
+		l.loads++
+		return id, nil
+	}
+}
+
+func get(t *testing.T, c *cache.LRU, l *loader, id int64) {
+	t.Helper()
+	value, err := c.Get(id, l.load(id))
+	if err != nil {
+		t.Fatalf("failed to get %d: %v", id, err)
+	}
+	if value.(int64) != id {
+		t.Fatalf("expected %d, got %v", id, value)
+	}
+}
+
+func TestLRU_EvictsLeastRecentlyUsed(t *testing.T) {
+	c := cache.NewLRU(2, 0)
+	l := &loader{}
+
+	get(t, c, l, 1)
+	get(t, c, l, 2)
+	get(t, c, l, 1)
+	// 2 is the least recently used
+	get(t, c, l, 3)
+	get(t, c, l, 1)
+	if l.loads != 3 {
+		t.Fatalf("expected 3 loads, got %d", l.loads)
+	}
+	get(t, c, l, 2)
+	if l.loads != 4 {
+		t.Fatalf("expected 2 to be evicted, got %d loads", l.loads)
+	}
+
+	stats := c.Stats()
+	expected := cache.Stats{Hits: 2, Misses: 4, Evictions: 2, Size: 2, Capacity: 2}
+	if stats != expected {
+		t.Fatalf("expected stats %+v, got %+v", expected, stats)
+	}
+}
+
+func TestLRU_ExpiresEntries(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	c := cache.NewLRU(10, time.Minute)
+	c.SetClock(func() time.Time { return now })
+	l := &loader{}
+
+	get(t, c, l, 1)
+	now = now.Add(59 * time.Second)
+	get(t, c, l, 1)
+	if l.loads != 1 {
+		t.Fatalf("expected a hit before the TTL, got %d loads", l.loads)
+	}
+	now = now.Add(time.Second)
+	get(t, c, l, 1)
+	if l.loads != 2 {
+		t.Fatalf("expected a miss after the TTL, got %d loads", l.loads)
+	}
+}
+
+func TestLRU_Invalidation(t *testing.T) {
+	c := cache.NewLRU(10, 0)
+	l := &loader{}
+
+	get(t, c, l, 1)
+	get(t, c, l, 2)
+	c.Remove(1)
+	get(t, c, l, 1)
+	get(t, c, l, 2)
+	if l.loads != 3 {
+		t.Fatalf("expected only 1 to be reloaded, got %d loads", l.loads)
+	}
+
+	c.Purge()
+	if size := c.Stats().Size; size != 0 {
+		t.Fatalf("expected an empty cache, got %d entries", size)
+	}
+	get(t, c, l, 2)
+	if l.loads != 4 {
+		t.Fatalf("expected 2 to be reloaded, got %d loads", l.loads)
+	}
+}
+
+func TestLRU_DoesntCacheStaleLoads(t *testing.T) {
+	c := cache.NewLRU(10, 0)
+
+	// A write invalidates the entry while it is loaded
+	if _, err := c.Get(1, func() (interface{}, error) {
+		c.Remove(1)
+		return "stale", nil
+	}); err != nil {
+		t.Fatalf("failed to get: %v", err)
+	}
+	value, _ := c.Get(1, func() (interface{}, error) { return "fresh", nil })
+	if value != "fresh" {
+		t.Fatalf("expected the value loaded after the write, got %v", value)
+	}
+}
+
+func TestLRU_DoesntCacheErrors(t *testing.T) {
+	c := cache.NewLRU(10, 0)
+	errLoad := errors.New("load")
+
+	if _, err := c.Get(1, func() (interface{}, error) { return nil, errLoad }); !errors.Is(err, errLoad) {
+		t.Fatalf("expected the load error, got %v", err)
+	}
+	if size := c.Stats().Size; size != 0 {
+		t.Fatalf("expected no entries, got %d", size)
+	}
+}
@@ -0,0 +1,195 @@
+package cache
+
+import (
+	"context"
+
+	"github.com/TomeuUris/recipes-catalog/pkg/entity"
+	"github.com/TomeuUris/recipes-catalog/pkg/repo/cooking_unit"
+	"github.com/TomeuUris/recipes-catalog/pkg/repo/ingredient"
+	"github.com/TomeuUris/recipes-catalog/pkg/repo/recipe"
+)
+
+// RecipeRepo reads the recipes by ID through the cache and drops them when
+// they are written. Callers get copies, they may modify them.
+type RecipeRepo struct {
+	recipe.Repo
+	// cache is nil inside a unit of work, reads go to the wrapped repository
+	cache      *LRU
+	invalidate Invalidator
+}
+
+func NewRecipeRepo(repo recipe.Repo, cache *LRU) *RecipeRepo {
+	return &RecipeRepo{Repo: repo, cache: cache, invalidate: cache}
+}
+
+func (r *RecipeRepo) FindByID(ctx context.Context, id int64) (*entity.Recipe, error) {
+	if r.cache == nil {
+		return r.Repo.FindByID(ctx, id)
+	}
+	value, err := r.cache.Get(id, func() (interface{}, error) {
+		return r.Repo.FindByID(ctx, id)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return copyRecipe(value.(*entity.Recipe)), nil
+}
+
+// The writes drop the entry even when they fail, they may have partially
+// succeeded
+
+func (r *RecipeRepo) Add(ctx context.Context, rp *entity.Recipe) error {
+	err := r.Repo.Add(ctx, rp)
+	r.invalidate.Remove(rp.ID)
+	return err
+}
+
+func (r *RecipeRepo) Edit(ctx context.Context, rp *entity.Recipe) error {
+	err := r.Repo.Edit(ctx, rp)
+	r.invalidate.Remove(rp.ID)
+	return err
+}
+
+func (r *RecipeRepo) Delete(ctx context.Context, rp *entity.Recipe) error {
+	err := r.Repo.Delete(ctx, rp)
+	r.invalidate.Remove(rp.ID)
+	return err
+}
+
+func copyRecipe(rp *entity.Recipe) *entity.Recipe {
+	copied := *rp
+	if rp.Ingredients != nil {
+		copied.Ingredients = make([]*entity.Ingredient, len(rp.Ingredients))
+		for i, ingredient := range rp.Ingredients {
+			copied.Ingredients[i] = copyIngredient(ingredient)
+		}
+	}
+	copied.Steps = append([]string(nil), rp.Steps...)
+	return &copied
+}
+
+// IngredientRepo reads the ingredients by ID through the cache and drops them
+// when they are written, along with the recipes since they embed them
+type IngredientRepo struct {
+	ingredient.Repo
+	cache      *LRU
+	invalidate Invalidator
+	recipes    Invalidator
+}
+
+// NewIngredientRepo returns the cached repository, recipes is the cache of
+// the recipes, nil when they aren't cached
+func NewIngredientRepo(repo ingredient.Repo, cache *LRU, recipes *LRU) *IngredientRepo {
+	r := &IngredientRepo{Repo: repo, cache: cache, invalidate: cache}
+	if recipes != nil {
+		r.recipes = recipes
+	}
+	return r
+}
+
+func (r *IngredientRepo) FindByID(ctx context.Context, id int) (*entity.Ingredient, error) {
+	if r.cache == nil {
+		return r.Repo.FindByID(ctx, id)
+	}
+	value, err := r.cache.Get(int64(id), func() (interface{}, error) {
+		return r.Repo.FindByID(ctx, id)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return copyIngredient(value.(*entity.Ingredient)), nil
+}
+
+func (r *IngredientRepo) Add(ctx context.Context, i *entity.Ingredient) error {
+	err := r.Repo.Add(ctx, i)
+	r.invalidate.Remove(i.ID)
+	return err
+}
+
+func (r *IngredientRepo) Edit(ctx context.Context, i *entity.Ingredient) error {
+	err := r.Repo.Edit(ctx, i)
+	r.invalidate.Remove(i.ID)
+	r.invalidateRecipes()
+	return err
+}
+
+func (r *IngredientRepo) Delete(ctx context.Context, i *entity.Ingredient) error {
+	err := r.Repo.Delete(ctx, i)
+	// Ingredients used by recipes aren't deleted, the recipes are intact
+	r.invalidate.Remove(i.ID)
+	return err
+}
+
+func (r *IngredientRepo) DetachAndDelete(ctx context.Context, i *entity.Ingredient) error {
+	err := r.Repo.DetachAndDelete(ctx, i)
+	r.invalidate.Remove(i.ID)
+	r.invalidateRecipes()
+	return err
+}
+
+func (r *IngredientRepo) Merge(ctx context.Context, target *entity.Ingredient, duplicates []int64) error {
+	err := r.Repo.Merge(ctx, target, duplicates)
+	r.invalidate.Remove(target.ID)
+	for _, id := range duplicates {
+		r.invalidate.Remove(id)
+	}
+	r.invalidateRecipes()
+	return err
+}
+
+// invalidateRecipes drops every recipe, which recipes use the ingredient is
+// unknown
+func (r *IngredientRepo) invalidateRecipes() {
+	if r.recipes != nil {
+		r.recipes.Purge()
+	}
+}
+
+func copyIngredient(i *entity.Ingredient) *entity.Ingredient {
+	copied := *i
+	return &copied
+}
+
+// CookingUnitRepo reads the cooking units by ID through the cache and drops
+// them when they are written
+type CookingUnitRepo struct {
+	cooking_unit.Repo
+	cache      *LRU
+	invalidate Invalidator
+}
+
+func NewCookingUnitRepo(repo cooking_unit.Repo, cache *LRU) *CookingUnitRepo {
+	return &CookingUnitRepo{Repo: repo, cache: cache, invalidate: cache}
+}
+
+func (r *CookingUnitRepo) FindByID(ctx context.Context, id int) (*entity.CookingUnit, error) {
+	if r.cache == nil {
+		return r.Repo.FindByID(ctx, id)
+	}
+	value, err := r.cache.Get(int64(id), func() (interface{}, error) {
+		return r.Repo.FindByID(ctx, id)
+	})
+	if err != nil {
+		return nil, err
+	}
+	copied := *value.(*entity.CookingUnit)
+	return &copied, nil
+}
+
+func (r *CookingUnitRepo) Add(ctx context.Context, u *entity.CookingUnit) error {
+	err := r.Repo.Add(ctx, u)
+	r.invalidate.Remove(u.ID)
+	return err
+}
+
+func (r *CookingUnitRepo) Edit(ctx context.Context, u *entity.CookingUnit) error {
+	err := r.Repo.Edit(ctx, u)
+	r.invalidate.Remove(u.ID)
+	return err
+}
+
+func (r *CookingUnitRepo) Delete(ctx context.Context, u *entity.CookingUnit) error {
+	err := r.Repo.Delete(ctx, u)
+	r.invalidate.Remove(u.ID)
+	return err
+}
@@ -0,0 +1,198 @@
+package cache_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/TomeuUris/recipes-catalog/pkg/cache"
+	"github.com/TomeuUris/recipes-catalog/pkg/entity"
+	"github.com/TomeuUris/recipes-catalog/pkg/repo/ingredient"
+	"github.com/TomeuUris/recipes-catalog/pkg/repo/recipe"
+	"github.com/TomeuUris/recipes-catalog/pkg/repo/transaction"
+)
+
+var ctx = context.Background()
+
+// countingRecipeRepo counts the reads reaching the repository
+type countingRecipeRepo struct {
+	recipe.Repo
+	reads int
+}
+
+func (r *countingRecipeRepo) FindByID(ctx context.Context, id int64) (*entity.Recipe, error) {
+	r.reads++
+	return r.Repo.FindByID(ctx, id)
+}
+
+type fixture struct {
+	ingredients *ingredient.RepoMemory
+	recipes     *countingRecipeRepo
+	recipeCache *cache.LRU
+	recipe      *entity.Recipe
+}
+
+func newFixture(t *testing.T) *fixture {
+	ingredients := ingredient.NewMemoryRepo()
+	f := &fixture{
+		ingredients: ingredients,
+		recipes:     &countingRecipeRepo{Repo: recipe.NewMemoryRepo(ingredients)},
+		recipeCache: cache.NewLRU(10, 0),
+		recipe: &entity.Recipe{
+			Name:        "Salad",
+			Ingredients: []*entity.Ingredient{{Name: "Lettuce", Type: "Vegetable"}},
+			Steps:       []string{"Wash"},
+		},
+	}
+	if err := f.recipes.Add(ctx, f.recipe); err != nil {
+		t.Fatalf("failed to add recipe: %v", err)
+	}
+	return f
+}
+
+func (f *fixture) find(t *testing.T, repo recipe.Repo) *entity.Recipe {
+	t.Helper()
+	found, err := repo.FindByID(ctx, f.recipe.ID)
+	if err != nil {
+		t.Fatalf("failed to find recipe: %v", err)
+	}
+	return found
+}
+
+func TestRecipeRepo_ReadsThrough(t *testing.T) {
+	f := newFixture(t)
+	repo := cache.NewRecipeRepo(f.recipes, f.recipeCache)
+
+	found := f.find(t, repo)
+	// Callers get copies
+	found.Name = "Changed"
+	found.Ingredients[0].Name = "Changed"
+	found.Steps[0] = "Changed"
+	found = f.find(t, repo)
+	if found.Name != "Salad" || found.Ingredients[0].Name != "Lettuce" || found.Steps[0] != "Wash" {
+		t.Fatalf("expected the cached recipe to be intact, got %+v", found)
+	}
+	if f.recipes.reads != 1 {
+		t.Fatalf("expected 1 read, got %d", f.recipes.reads)
+	}
+
+	found.Name = "Green salad"
+	if err := repo.Edit(ctx, found); err != nil {
+		t.Fatalf("failed to edit recipe: %v", err)
+	}
+	if found = f.find(t, repo); found.Name != "Green salad" || f.recipes.reads != 2 {
+		t.Fatalf("expected the edited recipe to be read, got %q after %d reads", found.Name, f.recipes.reads)
+	}
+
+	if err := repo.Delete(ctx, found); err != nil {
+		t.Fatalf("failed to delete recipe: %v", err)
+	}
+	if _, err := repo.FindByID(ctx, found.ID); !entity.IsErrNotFound(err) {
+		t.Fatalf("expected the deleted recipe not to be found, got %v", err)
+	}
+}
+
+func TestIngredientRepo_InvalidatesRecipes(t *testing.T) {
+	f := newFixture(t)
+	recipes := cache.NewRecipeRepo(f.recipes, f.recipeCache)
+	ingredients := cache.NewIngredientRepo(f.ingredients, cache.NewLRU(10, 0), f.recipeCache)
+
+	lettuce, err := ingredients.FindByID(ctx, int(f.recipe.Ingredients[0].ID))
+	if err != nil {
+		t.Fatalf("failed to find ingredient: %v", err)
+	}
+	f.find(t, recipes)
+	lettuce.Name = "Romaine lettuce"
+	if err := ingredients.Edit(ctx, lettuce); err != nil {
+		t.Fatalf("failed to edit ingredient: %v", err)
+	}
+
+	if found, _ := ingredients.FindByID(ctx, int(lettuce.ID)); found.Name != "Romaine lettuce" {
+		t.Fatalf("expected the edited ingredient, got %q", found.Name)
+	}
+	if found := f.find(t, recipes); found.Ingredients[0].Name != "Romaine lettuce" {
+		t.Fatalf("expected the recipe with the edited ingredient, got %q", found.Ingredients[0].Name)
+	}
+}
+
+func TestTxManager_InvalidatesWhenDone(t *testing.T) {
+	f := newFixture(t)
+	recipes := cache.NewRecipeRepo(f.recipes, f.recipeCache)
+	manager := cache.NewTxManager(transaction.NewPassthroughManager(transaction.Repos{
+		Recipes:     f.recipes,
+		Ingredients: f.ingredients,
+	}), f.recipeCache, cache.NewLRU(10, 0), cache.NewLRU(10, 0))
+
+	f.find(t, recipes)
+	errAbort := errors.New("abort")
+	if err := manager.Do(ctx, func(repos *transaction.Repos) error {
+		edited := f.find(t, repos.Recipes)
+		edited.Name = "Green salad"
+		if err := repos.Recipes.Edit(ctx, edited); err != nil {
+			return err
+		}
+		// Reads inside the unit of work see its writes
+		if found := f.find(t, repos.Recipes); found.Name != "Green salad" {
+			t.Errorf("expected the edited recipe inside the unit of work, got %q", found.Name)
+		}
+		// Still cached until the unit of work is done
+		if found := f.find(t, recipes); found.Name != "Salad" {
+			t.Errorf("expected the cached recipe, got %q", found.Name)
+		}
+		return errAbort
+	}); !errors.Is(err, errAbort) {
+		t.Fatalf("expected the error of the unit of work, got %v", err)
+	}
+
+	// The memory repositories don't roll back, the edit is read
+	reads := f.recipes.reads
+	if found := f.find(t, recipes); found.Name != "Green salad" || f.recipes.reads != reads+1 {
+		t.Fatalf("expected the recipe to be reloaded, got %q", found.Name)
+	}
+}
+
+// stubRecipeTrash restores and purges nothing, the recipes it's given are
+// returned as purged
+type stubRecipeTrash struct {
+	recipe.TrashRepo
+	purged []*entity.TrashItem
+}
+
+func (r *stubRecipeTrash) Restore(ctx context.Context, id int64) error {
+	return nil
+}
+
+func (r *stubRecipeTrash) Purge(ctx context.Context, before time.Time) ([]*entity.TrashItem, error) {
+	return r.purged, nil
+}
+
+func TestTxManager_InvalidatesTrash(t *testing.T) {
+	f := newFixture(t)
+	recipes := cache.NewRecipeRepo(f.recipes, f.recipeCache)
+	trash := &stubRecipeTrash{purged: []*entity.TrashItem{{Entity: entity.TrashRecipe, ID: f.recipe.ID}}}
+	manager := cache.NewTxManager(transaction.NewPassthroughManager(transaction.Repos{
+		Recipes:      f.recipes,
+		Ingredients:  f.ingredients,
+		RecipesTrash: trash,
+	}), f.recipeCache, cache.NewLRU(10, 0), cache.NewLRU(10, 0))
+
+	for _, write := range []func(repos *transaction.Repos) error{
+		func(repos *transaction.Repos) error {
+			return repos.RecipesTrash.Restore(ctx, f.recipe.ID)
+		},
+		func(repos *transaction.Repos) error {
+			_, err := repos.RecipesTrash.Purge(ctx, time.Now())
+			return err
+		},
+	} {
+		f.find(t, recipes)
+		reads := f.recipes.reads
+		if err := manager.Do(ctx, write); err != nil {
+			t.Fatalf("failed to write the trash: %v", err)
+		}
+		if f.find(t, recipes); f.recipes.reads != reads+1 {
+			t.Fatalf("expected the recipe to be reloaded, got %d reads", f.recipes.reads-reads)
+		}
+	}
+}
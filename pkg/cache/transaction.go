@@ -0,0 +1,75 @@
+package cache
+
+import (
+	"context"
+
+	"github.com/TomeuUris/recipes-catalog/pkg/repo/transaction"
+)
+
+// TxManager drops the entries written by a unit of work once it is done.
+// Reads inside the unit of work skip the cache, they see its writes.
+type TxManager struct {
+	transaction.Manager
+	recipes      *LRU
+	ingredients  *LRU
+	cookingUnits *LRU
+}
+
+func NewTxManager(manager transaction.Manager, recipes, ingredients, cookingUnits *LRU) *TxManager {
+	return &TxManager{Manager: manager, recipes: recipes, ingredients: ingredients, cookingUnits: cookingUnits}
+}
+
+func (m *TxManager) Do(ctx context.Context, fn func(repos *transaction.Repos) error) error {
+	recipes := &pendingInvalidations{cache: m.recipes}
+	ingredients := &pendingInvalidations{cache: m.ingredients}
+	cookingUnits := &pendingInvalidations{cache: m.cookingUnits}
+	// Dropped after a rollback too, loads racing the commit may be stale
+	defer func() {
+		recipes.flush()
+		ingredients.flush()
+		cookingUnits.flush()
+	}()
+
+	return m.Manager.Do(ctx, func(repos *transaction.Repos) error {
+		cached := &transaction.Repos{
+			Recipes:      &RecipeRepo{Repo: repos.Recipes, invalidate: recipes},
+			Ingredients:  &IngredientRepo{Repo: repos.Ingredients, invalidate: ingredients, recipes: recipes},
+			CookingUnits: &CookingUnitRepo{Repo: repos.CookingUnits, invalidate: cookingUnits},
+			Outbox:       repos.Outbox,
+			Audit:        repos.Audit,
+			Tx:           repos.Tx,
+		}
+		if repos.RecipesTrash != nil {
+			cached.RecipesTrash = &RecipeTrashRepo{TrashRepo: repos.RecipesTrash, invalidate: recipes}
+		}
+		if repos.IngredientsTrash != nil {
+			cached.IngredientsTrash = &IngredientTrashRepo{TrashRepo: repos.IngredientsTrash, invalidate: ingredients, recipes: recipes}
+		}
+		return fn(cached)
+	})
+}
+
+// pendingInvalidations keeps the entries a unit of work wrote until it is done
+type pendingInvalidations struct {
+	cache *LRU
+	ids   []int64
+	purge bool
+}
+
+func (p *pendingInvalidations) Remove(id int64) {
+	p.ids = append(p.ids, id)
+}
+
+func (p *pendingInvalidations) Purge() {
+	p.purge = true
+}
+
+func (p *pendingInvalidations) flush() {
+	if p.purge {
+		p.cache.Purge()
+		return
+	}
+	for _, id := range p.ids {
+		p.cache.Remove(id)
+	}
+}
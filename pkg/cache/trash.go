@@ -0,0 +1,54 @@
+package cache
+
+import (
+	"context"
+	"time"
+
+	"github.com/TomeuUris/recipes-catalog/pkg/entity"
+	"github.com/TomeuUris/recipes-catalog/pkg/repo/ingredient"
+	"github.com/TomeuUris/recipes-catalog/pkg/repo/recipe"
+)
+
+// RecipeTrashRepo drops the recipes restored or purged from the trash
+type RecipeTrashRepo struct {
+	recipe.TrashRepo
+	invalidate Invalidator
+}
+
+func (r *RecipeTrashRepo) Restore(ctx context.Context, id int64) error {
+	err := r.TrashRepo.Restore(ctx, id)
+	r.invalidate.Remove(id)
+	return err
+}
+
+func (r *RecipeTrashRepo) Purge(ctx context.Context, before time.Time) ([]*entity.TrashItem, error) {
+	purged, err := r.TrashRepo.Purge(ctx, before)
+	for _, item := range purged {
+		r.invalidate.Remove(item.ID)
+	}
+	return purged, err
+}
+
+// IngredientTrashRepo drops the ingredients restored or purged from the
+// trash, along with the recipes on restore since a recipe restored before
+// the ingredient was read without it
+type IngredientTrashRepo struct {
+	ingredient.TrashRepo
+	invalidate Invalidator
+	recipes    Invalidator
+}
+
+func (r *IngredientTrashRepo) Restore(ctx context.Context, id int64) error {
+	err := r.TrashRepo.Restore(ctx, id)
+	r.invalidate.Remove(id)
+	r.recipes.Purge()
+	return err
+}
+
+func (r *IngredientTrashRepo) Purge(ctx context.Context, before time.Time) ([]*entity.TrashItem, error) {
+	purged, err := r.TrashRepo.Purge(ctx, before)
+	for _, item := range purged {
+		r.invalidate.Remove(item.ID)
+	}
+	return purged, err
+}
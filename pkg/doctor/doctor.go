@@ -0,0 +1,575 @@
+// Package doctor checks the integrity of the catalog database beyond what its
+// constraints enforce, and repairs what can be repaired without guessing.
+package doctor
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/TomeuUris/recipes-catalog/pkg/entity"
+	"github.com/TomeuUris/recipes-catalog/pkg/repo/recipe"
+	"github.com/TomeuUris/recipes-catalog/pkg/repo/schema"
+	"github.com/TomeuUris/recipes-catalog/pkg/repo/transaction"
+	"github.com/TomeuUris/recipes-catalog/pkg/sqlite"
+	"github.com/TomeuUris/recipes-catalog/pkg/sqlite/migrations"
+)
+
+// Checks run by the doctor
+const (
+	// CheckOrphanedStep finds steps of recipes that don't exist
+	CheckOrphanedStep = "orphaned_step"
+	// CheckStepOrder finds recipes whose steps have gaps or duplicates in their numbering
+	CheckStepOrder = "step_order"
+	// CheckOrphanedLink finds recipe ingredients whose recipe or ingredient doesn't exist
+	CheckOrphanedLink = "orphaned_link"
+	// CheckDeletedLink finds recipes using a soft deleted ingredient
+	CheckDeletedLink = "deleted_link"
+	// CheckRecipeWithoutSteps finds recipes with no steps
+	CheckRecipeWithoutSteps = "recipe_without_steps"
+	// CheckSchemaDrift finds differences between the database and its migrations
+	CheckSchemaDrift = "schema_drift"
+)
+
+// Schema names the tables and columns of a backend
+type Schema struct {
+	// Store has the migrations the database must match
+	Store *sqlite.MigrationStore
+
+	Recipes     string
+	Ingredients string
+	Steps       string
+	// StepRecipe and StepOrder are the columns of Steps
+	StepRecipe string
+	StepOrder  string
+	// FirstStep is the number of the first step of a recipe
+	FirstStep int
+	// Links is the table of the ingredients of the recipes
+	Links          string
+	LinkRecipe     string
+	LinkIngredient string
+	// SoftDelete is set when the tables have a deleted_at column, steps are
+	// soft deleted along with their recipe
+	SoftDelete bool
+}
+
+// GormSchema is the schema of the gorm backend
+var GormSchema = &Schema{
+	Store:          &schema.Store,
+	Recipes:        "recipes",
+	Ingredients:    "ingredients",
+	Steps:          "recipe_steps",
+	StepRecipe:     "recipe_id",
+	StepOrder:      "order",
+	FirstStep:      1,
+	Links:          "recipe_ingredients",
+	LinkRecipe:     "recipe_id",
+	LinkIngredient: "ingredient_id",
+	SoftDelete:     true,
+}
+
+// SQLSchema is the schema of the database/sql backend
+var SQLSchema = &Schema{
+	Store:          &migrations.Store,
+	Recipes:        "recipes",
+	Ingredients:    "ingredients",
+	Steps:          "recipeSteps",
+	StepRecipe:     "recipeId",
+	StepOrder:      "stepNo",
+	FirstStep:      0,
+	Links:          "recipeIngredients",
+	LinkRecipe:     "recipeId",
+	LinkIngredient: "ingredientId",
+}
+
+// Issue is a problem found in the database
+type Issue struct {
+	Check string
+	// Table and RowID locate the issue, RowID is zero when it concerns the table
+	Table   string
+	RowID   int64
+	Message string
+	// Repairable is set when Repair fixes the issue
+	Repairable bool
+	// Repaired is set once Repair fixed the issue, or would have on dry runs
+	Repaired bool
+}
+
+// Report lists the issues found, in the order of the checks
+type Report struct {
+	Issues []*Issue
+	// DryRun is set when the repairs were rolled back
+	DryRun bool
+}
+
+// Unresolved returns the issues left in the database, all of them after a dry run
+func (r *Report) Unresolved() int {
+	if r.DryRun {
+		return len(r.Issues)
+	}
+	count := 0
+	for _, issue := range r.Issues {
+		if !issue.Repaired {
+			count++
+		}
+	}
+	return count
+}
+
+// Doctor checks and repairs a database
+type Doctor struct {
+	db     *sql.DB
+	schema *Schema
+	// tx runs the repairs, nil to run them on db
+	tx transaction.Manager
+}
+
+func New(db *sql.DB, schema *Schema) *Doctor {
+	return &Doctor{db: db, schema: schema}
+}
+
+// SetTxManager runs the repairs in the units of work of the manager, on the
+// same database. The repaired recipes are saved again through its
+// repositories, so their events, audit entries and cache invalidations are
+// recorded along with the repairs.
+func (d *Doctor) SetTxManager(manager transaction.Manager) {
+	d.tx = manager
+}
+
+// errDryRun rolls back the repairs of a dry run
+var errDryRun = errors.New("dry run")
+
+// querier runs queries on the database or on a transaction
+type querier interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+// Check runs every check without changing the database
+func (d *Doctor) Check(ctx context.Context) (*Report, error) {
+	drift, err := d.checkSchema(ctx)
+	if err != nil {
+		return nil, err
+	}
+	issues, err := d.checkData(ctx, d.db)
+	if err != nil {
+		return nil, err
+	}
+	return &Report{Issues: append(drift, issues...)}, nil
+}
+
+// Repair runs every check and fixes the repairable issues in a transaction,
+// a unit of work of the manager when there is one. A dry run rolls it back,
+// reporting the issues it would have fixed as repaired. Schema drift isn't
+// repaired, the migrate command is.
+func (d *Doctor) Repair(ctx context.Context, dryRun bool) (*Report, error) {
+	// The schema is checked on its own, the database may allow a single connection
+	drift, err := d.checkSchema(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var issues []*Issue
+	err = d.do(ctx, func(q querier, recipes recipe.Repo) error {
+		if issues, err = d.checkData(ctx, q); err != nil {
+			return err
+		}
+		if err := d.repair(ctx, q, issues); err != nil {
+			return err
+		}
+		if dryRun {
+			return errDryRun
+		}
+		return d.saveRepaired(ctx, recipes, issues)
+	})
+	if err != nil && !errors.Is(err, errDryRun) {
+		return nil, err
+	}
+	return &Report{Issues: append(drift, issues...), DryRun: dryRun}, nil
+}
+
+// do runs fn in a transaction, committed when it returns nil. The recipes
+// repository is nil without a manager.
+func (d *Doctor) do(ctx context.Context, fn func(q querier, recipes recipe.Repo) error) error {
+	if d.tx != nil {
+		return d.tx.Do(ctx, func(repos *transaction.Repos) error {
+			if repos.Tx == nil {
+				return errors.New("the units of work don't run on a transaction")
+			}
+			return fn(repos.Tx, repos.Recipes)
+		})
+	}
+
+	tx, err := d.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if err := fn(tx, nil); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// saveRepaired edits the recipes whose steps or ingredients were repaired as
+// they are now, so the repositories see the change
+func (d *Doctor) saveRepaired(ctx context.Context, recipes recipe.Repo, issues []*Issue) error {
+	if recipes == nil {
+		return nil
+	}
+	saved := map[int64]bool{}
+	for _, issue := range issues {
+		switch {
+		case !issue.Repaired || saved[issue.RowID]:
+			continue
+		case issue.Check != CheckStepOrder && issue.Check != CheckOrphanedLink && issue.Check != CheckDeletedLink:
+			continue
+		}
+		saved[issue.RowID] = true
+
+		// Orphaned links may belong to a missing recipe
+		found, err := recipes.FindByID(ctx, issue.RowID)
+		if errors.Is(err, entity.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if err := recipes.Edit(ctx, found); err != nil {
+			return fmt.Errorf("failed to save repaired recipe %d: %w", issue.RowID, err)
+		}
+	}
+	return nil
+}
+
+func (d *Doctor) checkData(ctx context.Context, q querier) ([]*Issue, error) {
+	var issues []*Issue
+	for _, check := range []func(ctx context.Context, q querier) ([]*Issue, error){
+		d.checkOrphanedSteps,
+		d.checkStepOrder,
+		d.checkOrphanedLinks,
+		d.checkDeletedLinks,
+		d.checkRecipesWithoutSteps,
+	} {
+		found, err := check(ctx, q)
+		if err != nil {
+			return nil, err
+		}
+		issues = append(issues, found...)
+	}
+	return issues, nil
+}
+
+func (d *Doctor) checkOrphanedSteps(ctx context.Context, q querier) ([]*Issue, error) {
+	s := d.schema
+	rows, err := q.QueryContext(ctx, fmt.Sprintf(`SELECT id, %s FROM %s
+		WHERE NOT EXISTS (SELECT 1 FROM %s WHERE %s.id = %s.%s) ORDER BY id`,
+		quote(s.StepRecipe), quote(s.Steps), quote(s.Recipes), quote(s.Recipes), quote(s.Steps), quote(s.StepRecipe)))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var issues []*Issue
+	for rows.Next() {
+		var id, recipeID int64
+		if err := rows.Scan(&id, &recipeID); err != nil {
+			return nil, err
+		}
+		issues = append(issues, &Issue{
+			Check:      CheckOrphanedStep,
+			Table:      s.Steps,
+			RowID:      id,
+			Message:    fmt.Sprintf("step of missing recipe %d", recipeID),
+			Repairable: true,
+		})
+	}
+	return issues, rows.Err()
+}
+
+// checkStepOrder checks the steps of every recipe are numbered from the first
+// step on, soft deleted steps included since they are restored with their recipe
+func (d *Doctor) checkStepOrder(ctx context.Context, q querier) ([]*Issue, error) {
+	s := d.schema
+	rows, err := q.QueryContext(ctx, fmt.Sprintf(`SELECT %s, %s FROM %s
+		WHERE EXISTS (SELECT 1 FROM %s WHERE %s.id = %s.%s) ORDER BY %s, %s, id`,
+		quote(s.StepRecipe), quote(s.StepOrder), quote(s.Steps),
+		quote(s.Recipes), quote(s.Recipes), quote(s.Steps), quote(s.StepRecipe),
+		quote(s.StepRecipe), quote(s.StepOrder)))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var recipeIDs []int64
+	orders := map[int64][]int{}
+	for rows.Next() {
+		var recipeID int64
+		var order int
+		if err := rows.Scan(&recipeID, &order); err != nil {
+			return nil, err
+		}
+		if _, ok := orders[recipeID]; !ok {
+			recipeIDs = append(recipeIDs, recipeID)
+		}
+		orders[recipeID] = append(orders[recipeID], order)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	var issues []*Issue
+	for _, recipeID := range recipeIDs {
+		numbers := orders[recipeID]
+		if message := describeNumbering(numbers, s.FirstStep); message != "" {
+			issues = append(issues, &Issue{
+				Check:      CheckStepOrder,
+				Table:      s.Recipes,
+				RowID:      recipeID,
+				Message:    message,
+				Repairable: true,
+			})
+		}
+	}
+	return issues, nil
+}
+
+// describeNumbering returns what is wrong with the sorted step numbers, empty
+// when they go from first on without gaps
+func describeNumbering(numbers []int, first int) string {
+	var duplicates, gaps []string
+	expected := first
+	for i, number := range numbers {
+		switch {
+		case i > 0 && number == numbers[i-1]:
+			if len(duplicates) == 0 || duplicates[len(duplicates)-1] != fmt.Sprint(number) {
+				duplicates = append(duplicates, fmt.Sprint(number))
+			}
+			continue
+		case number != expected:
+			gaps = append(gaps, fmt.Sprint(expected))
+		}
+		expected = number + 1
+	}
+
+	var problems []string
+	if len(duplicates) > 0 {
+		problems = append(problems, "duplicated step "+strings.Join(duplicates, ", "))
+	}
+	if len(gaps) > 0 {
+		problems = append(problems, "missing step "+strings.Join(gaps, ", "))
+	}
+	return strings.Join(problems, "; ")
+}
+
+func (d *Doctor) checkOrphanedLinks(ctx context.Context, q querier) ([]*Issue, error) {
+	s := d.schema
+	rows, err := q.QueryContext(ctx, fmt.Sprintf(`SELECT %s, %s,
+			EXISTS (SELECT 1 FROM %s WHERE %s.id = l.%s),
+			EXISTS (SELECT 1 FROM %s WHERE %s.id = l.%s)
+		FROM %s AS l`,
+		quote(s.LinkRecipe), quote(s.LinkIngredient),
+		quote(s.Recipes), quote(s.Recipes), quote(s.LinkRecipe),
+		quote(s.Ingredients), quote(s.Ingredients), quote(s.LinkIngredient),
+		quote(s.Links)))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var issues []*Issue
+	for rows.Next() {
+		var recipeID, ingredientID int64
+		var recipeExists, ingredientExists bool
+		if err := rows.Scan(&recipeID, &ingredientID, &recipeExists, &ingredientExists); err != nil {
+			return nil, err
+		}
+		var message string
+		switch {
+		case !recipeExists && !ingredientExists:
+			message = fmt.Sprintf("missing recipe %d uses missing ingredient %d", recipeID, ingredientID)
+		case !recipeExists:
+			message = fmt.Sprintf("missing recipe %d uses ingredient %d", recipeID, ingredientID)
+		case !ingredientExists:
+			message = fmt.Sprintf("recipe %d uses missing ingredient %d", recipeID, ingredientID)
+		default:
+			continue
+		}
+		issues = append(issues, &Issue{
+			Check:      CheckOrphanedLink,
+			Table:      s.Links,
+			RowID:      recipeID,
+			Message:    message,
+			Repairable: true,
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	sortByRow(issues)
+	return issues, nil
+}
+
+// checkDeletedLinks finds the recipes using a deleted ingredient, deleting it
+// detaches it from them. Deleted recipes keep their ingredients to be restored.
+func (d *Doctor) checkDeletedLinks(ctx context.Context, q querier) ([]*Issue, error) {
+	s := d.schema
+	if !s.SoftDelete {
+		return nil, nil
+	}
+	rows, err := q.QueryContext(ctx, fmt.Sprintf(`SELECT l.%s, l.%s FROM %s AS l
+		JOIN %s AS r ON r.id = l.%s
+		JOIN %s AS i ON i.id = l.%s
+		WHERE r.deleted_at IS NULL AND i.deleted_at IS NOT NULL
+		ORDER BY l.%s, l.%s`,
+		quote(s.LinkRecipe), quote(s.LinkIngredient), quote(s.Links),
+		quote(s.Recipes), quote(s.LinkRecipe),
+		quote(s.Ingredients), quote(s.LinkIngredient),
+		quote(s.LinkRecipe), quote(s.LinkIngredient)))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var issues []*Issue
+	for rows.Next() {
+		var recipeID, ingredientID int64
+		if err := rows.Scan(&recipeID, &ingredientID); err != nil {
+			return nil, err
+		}
+		issues = append(issues, &Issue{
+			Check:      CheckDeletedLink,
+			Table:      s.Links,
+			RowID:      recipeID,
+			Message:    fmt.Sprintf("recipe %d uses deleted ingredient %d", recipeID, ingredientID),
+			Repairable: true,
+		})
+	}
+	return issues, rows.Err()
+}
+
+// checkRecipesWithoutSteps finds the recipes with no steps, they are valid but
+// likely incomplete so they are only reported
+func (d *Doctor) checkRecipesWithoutSteps(ctx context.Context, q querier) ([]*Issue, error) {
+	s := d.schema
+	live := ""
+	if s.SoftDelete {
+		live = "r.deleted_at IS NULL AND "
+	}
+	rows, err := q.QueryContext(ctx, fmt.Sprintf(`SELECT r.id, r.name FROM %s AS r
+		WHERE %sNOT EXISTS (SELECT 1 FROM %s WHERE %s.%s = r.id) ORDER BY r.id`,
+		quote(s.Recipes), live, quote(s.Steps), quote(s.Steps), quote(s.StepRecipe)))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var issues []*Issue
+	for rows.Next() {
+		var id int64
+		var name sql.NullString
+		if err := rows.Scan(&id, &name); err != nil {
+			return nil, err
+		}
+		issues = append(issues, &Issue{
+			Check:   CheckRecipeWithoutSteps,
+			Table:   s.Recipes,
+			RowID:   id,
+			Message: fmt.Sprintf("recipe %q has no steps", name.String),
+		})
+	}
+	return issues, rows.Err()
+}
+
+// repair fixes the repairable issues and marks them as repaired
+func (d *Doctor) repair(ctx context.Context, q querier, issues []*Issue) error {
+	s := d.schema
+	renumbered := map[int64]bool{}
+	for _, issue := range issues {
+		if !issue.Repairable {
+			continue
+		}
+
+		var err error
+		switch issue.Check {
+		case CheckOrphanedStep:
+			_, err = q.ExecContext(ctx, fmt.Sprintf(`DELETE FROM %s WHERE id = ?`, quote(s.Steps)), issue.RowID)
+		case CheckOrphanedLink:
+			// Every link of the recipe or to an ingredient missing
+			_, err = q.ExecContext(ctx, fmt.Sprintf(`DELETE FROM %s WHERE %s = ? AND (
+					NOT EXISTS (SELECT 1 FROM %s WHERE %s.id = %s.%s) OR
+					NOT EXISTS (SELECT 1 FROM %s WHERE %s.id = %s.%s))`,
+				quote(s.Links), quote(s.LinkRecipe),
+				quote(s.Recipes), quote(s.Recipes), quote(s.Links), quote(s.LinkRecipe),
+				quote(s.Ingredients), quote(s.Ingredients), quote(s.Links), quote(s.LinkIngredient)),
+				issue.RowID)
+		case CheckDeletedLink:
+			_, err = q.ExecContext(ctx, fmt.Sprintf(`DELETE FROM %s WHERE %s = ?
+					AND %s IN (SELECT id FROM %s WHERE deleted_at IS NOT NULL)`,
+				quote(s.Links), quote(s.LinkRecipe), quote(s.LinkIngredient), quote(s.Ingredients)),
+				issue.RowID)
+		case CheckStepOrder:
+			if !renumbered[issue.RowID] {
+				err = d.renumberSteps(ctx, q, issue.RowID)
+				renumbered[issue.RowID] = true
+			}
+		}
+		if err != nil {
+			return fmt.Errorf("failed to repair %s %s %d: %w", issue.Check, issue.Table, issue.RowID, err)
+		}
+		issue.Repaired = true
+	}
+	return nil
+}
+
+// renumberSteps numbers the steps of the recipe from the first step on, keeping
+// their order. Duplicates keep the order they were added in.
+func (d *Doctor) renumberSteps(ctx context.Context, q querier, recipeID int64) error {
+	s := d.schema
+	rows, err := q.QueryContext(ctx, fmt.Sprintf(`SELECT id FROM %s WHERE %s = ? ORDER BY %s, id`,
+		quote(s.Steps), quote(s.StepRecipe), quote(s.StepOrder)), recipeID)
+	if err != nil {
+		return err
+	}
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return err
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	// Negative numbers first so the unique index on the number never sees two steps sharing one
+	update := fmt.Sprintf(`UPDATE %s SET %s = ? WHERE id = ?`, quote(s.Steps), quote(s.StepOrder))
+	for i, id := range ids {
+		if _, err := q.ExecContext(ctx, update, -i-1, id); err != nil {
+			return err
+		}
+	}
+	for i, id := range ids {
+		if _, err := q.ExecContext(ctx, update, s.FirstStep+i, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func sortByRow(issues []*Issue) {
+	sort.SliceStable(issues, func(i, j int) bool {
+		return issues[i].RowID < issues[j].RowID
+	})
+}
+
+// quote quotes an identifier, step numbers are in a column named order
+func quote(name string) string {
+	return `"` + name + `"`
+}
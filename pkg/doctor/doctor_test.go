@@ -0,0 +1,268 @@
+package doctor_test
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"testing"
+
+	gormSqlite "gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/TomeuUris/recipes-catalog/pkg/audit"
+	"github.com/TomeuUris/recipes-catalog/pkg/doctor"
+	"github.com/TomeuUris/recipes-catalog/pkg/outbox"
+	"github.com/TomeuUris/recipes-catalog/pkg/repo/transaction"
+	"github.com/TomeuUris/recipes-catalog/pkg/sqlite"
+)
+
+var ctx = context.Background()
+
+func openDB(t *testing.T, s *doctor.Schema) *sql.DB {
+	db, err := sqlite.Open(":memory:")
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	// Every connection to :memory: opens a new database
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+	if err := s.Store.Apply(ctx, db); err != nil {
+		t.Fatalf("failed to migrate database: %v", err)
+	}
+	return db
+}
+
+func exec(t *testing.T, db *sql.DB, queries ...string) {
+	t.Helper()
+	for _, query := range queries {
+		if _, err := db.Exec(query); err != nil {
+			t.Fatalf("failed to run %q: %v", query, err)
+		}
+	}
+}
+
+// summary lists the issues as check:table:first word of the message
+func summary(report *doctor.Report) string {
+	var issues []string
+	for _, issue := range report.Issues {
+		issues = append(issues, issue.Check+":"+issue.Table+":"+strings.Fields(issue.Message)[0])
+	}
+	return strings.Join(issues, "\n")
+}
+
+func count(t *testing.T, db *sql.DB, query string) int {
+	t.Helper()
+	var n int
+	if err := db.QueryRow(query).Scan(&n); err != nil {
+		t.Fatalf("failed to run %q: %v", query, err)
+	}
+	return n
+}
+
+func TestDoctor_GormSchema(t *testing.T) {
+	db := openDB(t, doctor.GormSchema)
+	// Foreign keys would reject the orphans
+	exec(t, db,
+		`PRAGMA foreign_keys = OFF`,
+		`INSERT INTO ingredients (id, name, type) VALUES (1, 'Salt', 'Spice'), (2, 'Pepper', 'Spice')`,
+		`UPDATE ingredients SET deleted_at = CURRENT_TIMESTAMP WHERE id = 2`,
+		`INSERT INTO recipes (id, name, slug) VALUES (1, 'Soup', 'soup'), (2, 'Salad', 'salad'), (3, 'Stew', 'stew')`,
+		`UPDATE recipes SET deleted_at = CURRENT_TIMESTAMP WHERE id = 3`,
+		// Soup skips step 2, Salad has no steps, the steps of the deleted Stew are fine
+		"INSERT INTO recipe_steps (id, recipe_id, `order`, content) VALUES (1, 1, 1, 'Boil'), (2, 1, 3, 'Serve'), (3, 9, 1, 'Lost'), (4, 3, 1, 'Cook')",
+		// Soup uses the deleted pepper, the deleted Stew may, a missing recipe uses salt
+		`INSERT INTO recipe_ingredients (recipe_id, ingredient_id) VALUES (1, 1), (1, 2), (3, 2), (8, 1)`,
+	)
+	d := doctor.New(db, doctor.GormSchema)
+
+	report, err := d.Check(ctx)
+	if err != nil {
+		t.Fatalf("failed to check: %v", err)
+	}
+	expected := strings.Join([]string{
+		"orphaned_step:recipe_steps:step",
+		"step_order:recipes:missing",
+		"orphaned_link:recipe_ingredients:missing",
+		"deleted_link:recipe_ingredients:recipe",
+		"recipe_without_steps:recipes:recipe",
+	}, "\n")
+	if got := summary(report); got != expected {
+		t.Fatalf("expected issues\n%s\ngot\n%s", expected, got)
+	}
+	if report.Unresolved() != 5 {
+		t.Fatalf("expected 5 unresolved issues, got %d", report.Unresolved())
+	}
+
+	// A dry run reports the repairs without making them
+	report, err = d.Repair(ctx, true)
+	if err != nil {
+		t.Fatalf("failed to repair: %v", err)
+	}
+	repaired := 0
+	for _, issue := range report.Issues {
+		if issue.Repaired {
+			repaired++
+		}
+	}
+	if !report.DryRun || repaired != 4 || report.Unresolved() != 5 {
+		t.Fatalf("expected a dry run repairing 4 issues, got %d (%d unresolved)", repaired, report.Unresolved())
+	}
+	if n := count(t, db, `SELECT COUNT(*) FROM recipe_steps`); n != 4 {
+		t.Fatalf("expected the dry run to keep the 4 steps, got %d", n)
+	}
+
+	report, err = d.Repair(ctx, false)
+	if err != nil {
+		t.Fatalf("failed to repair: %v", err)
+	}
+	if report.DryRun || report.Unresolved() != 1 {
+		t.Fatalf("expected 1 issue left, got %d", report.Unresolved())
+	}
+	if n := count(t, db, "SELECT COUNT(*) FROM recipe_steps WHERE recipe_id = 1 AND `order` IN (1, 2)"); n != 2 {
+		t.Fatalf("expected the steps of Soup renumbered, got %d", n)
+	}
+	if n := count(t, db, `SELECT COUNT(*) FROM recipe_ingredients`); n != 2 {
+		t.Fatalf("expected the links of Soup to salt and of Stew to pepper kept, got %d", n)
+	}
+
+	report, err = d.Check(ctx)
+	if err != nil {
+		t.Fatalf("failed to check: %v", err)
+	}
+	if got := summary(report); got != "recipe_without_steps:recipes:recipe" {
+		t.Fatalf("expected only the recipe without steps left, got\n%s", got)
+	}
+}
+
+func TestDoctor_RepairsInTheUnitsOfWork(t *testing.T) {
+	db := openDB(t, doctor.GormSchema)
+	exec(t, db,
+		`PRAGMA foreign_keys = OFF`,
+		`INSERT INTO ingredients (id, name, type) VALUES (1, 'Salt', 'Spice')`,
+		`INSERT INTO recipes (id, name, slug) VALUES (1, 'Soup', 'soup')`,
+		// Soup skips step 2 and a missing recipe uses salt
+		"INSERT INTO recipe_steps (id, recipe_id, `order`, content) VALUES (1, 1, 1, 'Boil'), (2, 1, 3, 'Serve')",
+		`INSERT INTO recipe_ingredients (recipe_id, ingredient_id) VALUES (1, 1), (8, 1)`,
+	)
+	gormDB, err := gorm.Open(&gormSqlite.Dialector{DriverName: sqlite.DriverName, Conn: db}, &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to open gorm: %v", err)
+	}
+	d := doctor.New(db, doctor.GormSchema)
+	d.SetTxManager(outbox.NewTxManager(audit.NewTxManager(transaction.NewGormManager(gormDB), nil), nil))
+
+	// A dry run records nothing
+	if _, err := d.Repair(ctx, true); err != nil {
+		t.Fatalf("failed to repair: %v", err)
+	}
+	if n := count(t, db, `SELECT COUNT(*) FROM outbox_messages`); n != 0 {
+		t.Fatalf("expected no events on a dry run, got %d", n)
+	}
+
+	report, err := d.Repair(ctx, false)
+	if err != nil {
+		t.Fatalf("failed to repair: %v", err)
+	}
+	if report.Unresolved() != 0 {
+		t.Fatalf("expected every issue repaired, got %d left", report.Unresolved())
+	}
+	if n := count(t, db, "SELECT COUNT(*) FROM recipe_steps WHERE recipe_id = 1 AND `order` IN (1, 2)"); n != 2 {
+		t.Fatalf("expected the steps of Soup renumbered, got %d", n)
+	}
+	// Soup is saved once for both repairs, the missing recipe isn't
+	if n := count(t, db, `SELECT COUNT(*) FROM outbox_messages WHERE entity = 'recipe' AND entity_id = 1 AND action = 'updated'`); n != 1 {
+		t.Fatalf("expected an update event of Soup, got %d", n)
+	}
+	if n := count(t, db, `SELECT COUNT(*) FROM outbox_messages`); n != 1 {
+		t.Fatalf("expected only the event of Soup, got %d", n)
+	}
+	if n := count(t, db, `SELECT COUNT(*) FROM audit_entries WHERE entity = 'recipe' AND entity_id = 1 AND action = 'updated'`); n != 1 {
+		t.Fatalf("expected an audit entry of Soup, got %d", n)
+	}
+}
+
+func TestDoctor_SQLSchemaDuplicatedSteps(t *testing.T) {
+	db := openDB(t, doctor.SQLSchema)
+	exec(t, db,
+		`INSERT INTO recipes (id, name, slug) VALUES (1, 'Soup', 'soup')`,
+		// Unique constraints can't be dropped, the table is rebuilt without it so step 0 is given twice
+		`PRAGMA foreign_keys = OFF`,
+		`ALTER TABLE recipeSteps RENAME TO oldSteps`,
+		`CREATE TABLE recipeSteps (id INTEGER PRIMARY KEY AUTOINCREMENT, recipeId INTEGER NOT NULL, stepNo INTEGER NOT NULL, content TEXT NOT NULL)`,
+		`DROP TABLE oldSteps`,
+		`INSERT INTO recipeSteps (recipeId, stepNo, content) VALUES (1, 0, 'Boil'), (1, 0, 'Stir'), (1, 1, 'Serve')`,
+	)
+	d := doctor.New(db, doctor.SQLSchema)
+
+	report, err := d.Repair(ctx, false)
+	if err != nil {
+		t.Fatalf("failed to repair: %v", err)
+	}
+	expected := strings.Join([]string{
+		"schema_drift:recipeSteps:missing",
+		"step_order:recipes:duplicated",
+	}, "\n")
+	if got := summary(report); got != expected {
+		t.Fatalf("expected issues\n%s\ngot\n%s", expected, got)
+	}
+	if report.Issues[0].Repaired || !report.Issues[1].Repaired {
+		t.Fatalf("expected only the steps repaired, got %+v", report.Issues)
+	}
+
+	rows, err := db.Query(`SELECT content FROM recipeSteps WHERE recipeId = 1 ORDER BY stepNo`)
+	if err != nil {
+		t.Fatalf("failed to read steps: %v", err)
+	}
+	defer rows.Close()
+	var steps []string
+	for rows.Next() {
+		var step string
+		rows.Scan(&step)
+		steps = append(steps, step)
+	}
+	if strings.Join(steps, ",") != "Boil,Stir,Serve" {
+		t.Fatalf("expected the steps renumbered in order, got %v", steps)
+	}
+}
+
+func TestDoctor_SchemaDrift(t *testing.T) {
+	db := openDB(t, doctor.SQLSchema)
+	d := doctor.New(db, doctor.SQLSchema)
+
+	report, err := d.Check(ctx)
+	if err != nil {
+		t.Fatalf("failed to check: %v", err)
+	}
+	if len(report.Issues) != 0 {
+		t.Fatalf("expected a migrated database to be fine, got\n%s", summary(report))
+	}
+
+	// Tables of other repositories are ignored
+	exec(t, db,
+		`CREATE TABLE sessions (id INTEGER PRIMARY KEY)`,
+		`DROP INDEX idx_recipe_slugs_recipe_id`,
+		`ALTER TABLE recipes ADD COLUMN rating INTEGER`,
+	)
+	report, err = d.Check(ctx)
+	if err != nil {
+		t.Fatalf("failed to check: %v", err)
+	}
+	var messages []string
+	for _, issue := range report.Issues {
+		messages = append(messages, issue.Table+": "+issue.Message)
+	}
+	expected := "recipeSlugs: missing index idx_recipe_slugs_recipe_id (recipeId)\nrecipes: unexpected column rating INTEGER"
+	if got := strings.Join(messages, "\n"); got != expected {
+		t.Fatalf("expected\n%s\ngot\n%s", expected, got)
+	}
+
+	// Pending migrations are reported instead of the differences they fix
+	exec(t, db, `PRAGMA user_version = 5`)
+	report, err = d.Check(ctx)
+	if err != nil {
+		t.Fatalf("failed to check: %v", err)
+	}
+	if len(report.Issues) != 1 || !strings.Contains(report.Issues[0].Message, "pending migrations") {
+		t.Fatalf("expected the pending migrations reported, got\n%s", summary(report))
+	}
+}
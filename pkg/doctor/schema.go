@@ -0,0 +1,35 @@
+package doctor
+
+import (
+	"context"
+	"fmt"
+)
+
+// checkSchema compares the database with a new one migrated to the latest
+// version. Tables the migrations don't create are ignored.
+func (d *Doctor) checkSchema(ctx context.Context) ([]*Issue, error) {
+	status, err := d.schema.Store.Status(ctx, d.db)
+	if err != nil {
+		return nil, err
+	}
+	if err := status.Check(); err != nil {
+		return []*Issue{{Check: CheckSchemaDrift, Message: err.Error()}}, nil
+	}
+	if status.Version < status.Latest {
+		// The tables differ until the migrations are applied
+		return []*Issue{{
+			Check:   CheckSchemaDrift,
+			Message: fmt.Sprintf("version %d, %d pending migrations up to %d", status.Version, status.Latest-status.Version, status.Latest),
+		}}, nil
+	}
+
+	differences, err := d.schema.Store.Diff(ctx, d.db)
+	if err != nil {
+		return nil, err
+	}
+	var issues []*Issue
+	for _, difference := range differences {
+		issues = append(issues, &Issue{Check: CheckSchemaDrift, Table: difference.Table, Message: difference.Message})
+	}
+	return issues, nil
+}
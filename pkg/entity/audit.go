@@ -0,0 +1,20 @@
+package entity
+
+import "time"
+
+// AuditEntry records a write on a catalog entity and who made it
+type AuditEntry struct {
+	ID int64
+	// Entity is the kind of entity written, e.g. "recipe"
+	Entity   string
+	EntityID int64
+	// Action is "created", "updated", "deleted", "restored" or "purged"
+	Action    string
+	Actor     string
+	RequestID string
+	// Before and After are the JSON of the entity around the write, empty when
+	// it didn't exist
+	Before string
+	After  string
+	At     time.Time
+}
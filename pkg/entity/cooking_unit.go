@@ -1,6 +1,19 @@
 package entity
 
+import (
+	"fmt"
+	"strings"
+)
+
 type CookingUnit struct {
 	ID   int64
 	Name string
 }
+
+// Validate checks the cooking unit can be stored
+func (u *CookingUnit) Validate() error {
+	if strings.TrimSpace(u.Name) == "" {
+		return fmt.Errorf("%w: cooking unit name is required", ErrInvalidEntity)
+	}
+	return nil
+}
@@ -1,6 +1,9 @@
 package entity
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+)
 
 var ErrNotFound = errors.New("entity not found")
 
@@ -25,3 +28,38 @@ var ErrAlreadyExists = errors.New("entity already exists")
 func IsErrAlreadyExists(err error) bool {
 	return errors.Is(err, ErrAlreadyExists)
 }
+
+// AlreadyExistsError wraps ErrAlreadyExists with the ID of the entity holding
+// the name
+type AlreadyExistsError struct {
+	ID int64
+}
+
+func (e *AlreadyExistsError) Error() string {
+	return fmt.Sprintf("%s with ID %d", ErrAlreadyExists, e.ID)
+}
+
+func (e *AlreadyExistsError) Unwrap() error {
+	return ErrAlreadyExists
+}
+
+var ErrInUse = errors.New("entity in use")
+
+func IsErrInUse(err error) bool {
+	return errors.Is(err, ErrInUse)
+}
+
+// InUseError is returned when deleting an entity that recipes still reference,
+// it wraps ErrInUse and lists the blocking recipes
+type InUseError struct {
+	// Recipes only have their ID and name set
+	Recipes []*Recipe
+}
+
+func (e *InUseError) Error() string {
+	return fmt.Sprintf("%s by %d recipes", ErrInUse, len(e.Recipes))
+}
+
+func (e *InUseError) Unwrap() error {
+	return ErrInUse
+}
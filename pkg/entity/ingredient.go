@@ -1,7 +1,30 @@
 package entity
 
+import (
+	"fmt"
+	"strings"
+)
+
 type Ingredient struct {
 	ID   int64
 	Name string
 	Type string
 }
+
+// Validate checks the ingredient can be stored
+func (i *Ingredient) Validate() error {
+	if strings.TrimSpace(i.Name) == "" {
+		return fmt.Errorf("%w: ingredient name is required", ErrInvalidEntity)
+	}
+	if strings.TrimSpace(i.Type) == "" {
+		return fmt.Errorf("%w: ingredient type is required", ErrInvalidEntity)
+	}
+	return nil
+}
+
+// DuplicateGroup is a set of ingredients with similar names ordered by ID
+type DuplicateGroup struct {
+	Ingredients []*Ingredient
+	// Similarity is the lowest similarity of the pairs that grouped them
+	Similarity float64
+}
@@ -0,0 +1,24 @@
+package entity
+
+import "time"
+
+// OutboxMessage is a domain event stored in the transaction of the write that
+// caused it, until it is delivered
+type OutboxMessage struct {
+	ID int64
+	// Entity is the kind of entity written, e.g. "recipe"
+	Entity   string
+	EntityID int64
+	// Action is "created", "updated", "deleted", "restored" or "purged"
+	Action string
+	// Payload is the JSON of the entity after the write
+	Payload    string
+	OccurredAt time.Time
+	// Attempts counts the failed deliveries, the next one waits until
+	// NextAttemptAt
+	Attempts      int
+	LastError     string
+	NextAttemptAt time.Time
+	// DeliveredAt is nil until every sink received the message
+	DeliveredAt *time.Time
+}
@@ -1,11 +1,28 @@
 package entity
 
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
 type Recipe struct {
-	ID          int64
-	Name        string
+	ID   int64
+	Name string
+	// Slug identifies the recipe in links, it is set by the repositories from
+	// the name. Previous slugs keep finding the recipe after a rename.
+	Slug        string
 	Description string
 	Ingredients []*Ingredient
 	Steps       []string
+	// PrepTime and CookTime are how long preparing and cooking the recipe
+	// take, zero when unknown
+	PrepTime time.Duration
+	CookTime time.Duration
+	// Yield is what the recipe makes, such as "4 servings"
+	Yield string
+	// Calories is the energy of a serving in kcal, zero when unknown
+	Calories int
 }
 
 func NewRecipe(id int64, name string, ingredients []*Ingredient, steps []string) *Recipe {
@@ -20,8 +37,40 @@ func NewRecipe(id int64, name string, ingredients []*Ingredient, steps []string)
 func (r *Recipe) FromEntity(recipe *Recipe) error {
 	r.ID = recipe.ID
 	r.Name = recipe.Name
+	r.Slug = recipe.Slug
 	r.Description = recipe.Description
 	r.Ingredients = recipe.Ingredients
 	r.Steps = recipe.Steps
+	r.PrepTime = recipe.PrepTime
+	r.CookTime = recipe.CookTime
+	r.Yield = recipe.Yield
+	r.Calories = recipe.Calories
+	return nil
+}
+
+// SlugBase returns the slug the recipe gets, unless it is taken
+func (r *Recipe) SlugBase() string {
+	if slug := Slugify(r.Name); slug != "" {
+		return slug
+	}
+	return "recipe"
+}
+
+// Validate checks the recipe can be stored
+func (r *Recipe) Validate() error {
+	if strings.TrimSpace(r.Name) == "" {
+		return fmt.Errorf("%w: recipe name is required", ErrInvalidEntity)
+	}
+	if r.PrepTime < 0 || r.CookTime < 0 {
+		return fmt.Errorf("%w: recipe times can't be negative", ErrInvalidEntity)
+	}
+	if r.Calories < 0 {
+		return fmt.Errorf("%w: recipe calories can't be negative", ErrInvalidEntity)
+	}
+	for i, step := range r.Steps {
+		if strings.TrimSpace(step) == "" {
+			return fmt.Errorf("%w: step %d is empty", ErrInvalidEntity, i+1)
+		}
+	}
 	return nil
 }
@@ -0,0 +1,69 @@
+package entity
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// maxSlugLength keeps the slugs readable in links, suffixes are added after
+const maxSlugLength = 80
+
+// Slugify returns the name in lower case ASCII words joined by hyphens, with
+// the Spanish and Catalan letters transliterated: "Pa amb tomàquet" is
+// "pa-amb-tomaquet", "Col·liflor" is "colliflor" and "Ñoquis" is "noquis".
+// It is empty when the name has no letters nor digits.
+func Slugify(name string) string {
+	// The middle dot of the Catalan geminated l joins the word
+	name = strings.NewReplacer("l·l", "ll", "L·L", "LL", "L·l", "Ll").Replace(name)
+
+	// Letters without accents to strip
+	name = strings.NewReplacer("ß", "ss", "æ", "ae", "Æ", "ae", "œ", "oe", "Œ", "oe").Replace(name)
+
+	var slug strings.Builder
+	hyphen := false
+	for _, r := range NormalizeName(name) {
+		switch {
+		case r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)):
+			if hyphen && slug.Len() > 0 {
+				slug.WriteByte('-')
+			}
+			hyphen = false
+			slug.WriteRune(r)
+		case !unicode.IsLetter(r) && !unicode.IsDigit(r):
+			hyphen = true
+		}
+		// Letters without an ASCII transliteration are left out
+	}
+
+	result := slug.String()
+	if len(result) > maxSlugLength {
+		result = result[:maxSlugLength]
+		if cut := strings.LastIndexByte(result, '-'); cut > 0 {
+			result = result[:cut]
+		}
+	}
+	return result
+}
+
+// SlugCandidate returns the n-th slug tried for the base, starting at 1: the
+// base first, then the base with the numeric suffix -2, -3...
+func SlugCandidate(base string, n int) string {
+	if n <= 1 {
+		return base
+	}
+	return base + "-" + strconv.Itoa(n)
+}
+
+// IsSlugCandidate returns whether the slug is one of the candidates of the base
+func IsSlugCandidate(slug, base string) bool {
+	if slug == base {
+		return true
+	}
+	suffix, ok := strings.CutPrefix(slug, base+"-")
+	if !ok {
+		return false
+	}
+	n, err := strconv.Atoi(suffix)
+	return err == nil && n >= 2 && SlugCandidate(base, n) == slug
+}
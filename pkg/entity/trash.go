@@ -0,0 +1,17 @@
+package entity
+
+import "time"
+
+// Kinds of entities that can be in the trash
+const (
+	TrashRecipe     = "recipe"
+	TrashIngredient = "ingredient"
+)
+
+// TrashItem is a soft-deleted entity that can still be restored
+type TrashItem struct {
+	Entity    string
+	ID        int64
+	Name      string
+	DeletedAt time.Time
+}
@@ -0,0 +1,75 @@
+package entity
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Webhook is an integrator subscription to catalog events
+type Webhook struct {
+	ID     int64
+	URL    string
+	Secret string
+	// Events the webhook is subscribed to, e.g. "recipe.created", "ingredient.*" or "*".
+	// An empty list subscribes to every event.
+	Events []string
+	Active bool
+}
+
+// Validate checks the webhook can be stored
+func (w *Webhook) Validate() error {
+	u, err := url.Parse(w.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%w: webhook url must be an absolute http(s) URL", ErrInvalidEntity)
+	}
+	if strings.TrimSpace(w.Secret) == "" {
+		return fmt.Errorf("%w: webhook secret is required", ErrInvalidEntity)
+	}
+	for _, e := range w.Events {
+		if strings.TrimSpace(e) == "" {
+			return fmt.Errorf("%w: webhook events can not be empty", ErrInvalidEntity)
+		}
+	}
+	return nil
+}
+
+// Matches reports whether the webhook is subscribed to the event name
+func (w *Webhook) Matches(eventName string) bool {
+	if len(w.Events) == 0 {
+		return true
+	}
+	for _, e := range w.Events {
+		if e == "*" || e == eventName {
+			return true
+		}
+		if prefix, ok := strings.CutSuffix(e, ".*"); ok && strings.HasPrefix(eventName, prefix+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// Webhook delivery statuses
+const (
+	DeliveryPending   = "pending"
+	DeliverySucceeded = "succeeded"
+	DeliveryFailed    = "failed"
+)
+
+// WebhookDelivery is a single event sent (or to be sent) to a webhook
+type WebhookDelivery struct {
+	ID             int64
+	WebhookID      int64
+	EventID        int64
+	EventType      string
+	Payload        string
+	Status         string
+	Attempts       int
+	ResponseStatus int
+	LastError      string
+	NextAttemptAt  time.Time
+	DeliveredAt    *time.Time
+	CreatedAt      time.Time
+}
@@ -0,0 +1,38 @@
+package event
+
+import "time"
+
+// Kinds of entities that emit events
+const (
+	EntityRecipe      = "recipe"
+	EntityIngredient  = "ingredient"
+	EntityCookingUnit = "cooking_unit"
+)
+
+// Actions performed over an entity
+const (
+	ActionCreated = "created"
+	ActionUpdated = "updated"
+	ActionDeleted = "deleted"
+	// Restored and purged are the actions of the trash, over recipes and
+	// ingredients
+	ActionRestored = "restored"
+	ActionPurged   = "purged"
+)
+
+// Event describes a change applied to a catalog entity
+type Event struct {
+	ID       int64
+	Entity   string
+	Action   string
+	EntityID int64
+	// Payload holds a copy of the entity after the change (*entity.Recipe, *entity.Ingredient or *entity.CookingUnit),
+	// only its ID and name once purged
+	Payload    interface{}
+	OccurredAt time.Time
+}
+
+// Name returns the event name, e.g. "recipe.created"
+func (e *Event) Name() string {
+	return e.Entity + "." + e.Action
+}
@@ -0,0 +1,130 @@
+package event
+
+import (
+	"sync"
+	"time"
+)
+
+// DefaultHistorySize is the number of events kept for replay when none is given
+const DefaultHistorySize = 1024
+
+// NoReplay subscribes to new events only
+const NoReplay int64 = -1
+
+// subscriberBuffer is the number of pending events a subscriber can hold before being dropped
+const subscriberBuffer = 64
+
+// Hub fans out events to subscribers and keeps a bounded history so that
+// clients can resume from the last event they received.
+type Hub struct {
+	mu          sync.Mutex
+	lastID      int64
+	history     []*Event
+	historySize int
+	subscribers map[*Subscription]struct{}
+}
+
+// Subscription receives the events published after it was created.
+// C is closed when the subscription is cancelled or the subscriber falls too far behind.
+type Subscription struct {
+	C <-chan *Event
+	// StartID is the ID of the last event published before the subscription was created
+	StartID int64
+	c       chan *Event
+	hub     *Hub
+}
+
+func NewHub(historySize int) *Hub {
+	if historySize <= 0 {
+		historySize = DefaultHistorySize
+	}
+	return &Hub{
+		historySize: historySize,
+		subscribers: map[*Subscription]struct{}{},
+	}
+}
+
+// Publish assigns an ID to the event, stores it and delivers it to every subscriber.
+// Events with an ID, e.g. from the outbox, keep it, and are dropped when it
+// isn't after the last one so redelivered events are published once.
+func (h *Hub) Publish(e *Event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	switch {
+	case e.ID == 0:
+		h.lastID++
+		e.ID = h.lastID
+	case e.ID <= h.lastID:
+		return
+	default:
+		h.lastID = e.ID
+	}
+	if e.OccurredAt.IsZero() {
+		e.OccurredAt = time.Now()
+	}
+
+	h.history = append(h.history, e)
+	if len(h.history) > h.historySize {
+		h.history = h.history[len(h.history)-h.historySize:]
+	}
+
+	for sub := range h.subscribers {
+		select {
+		case sub.c <- e:
+		default:
+			// Slow subscriber: drop it so it reconnects and replays from its last event
+			h.remove(sub)
+		}
+	}
+}
+
+// Subscribe registers a new subscriber and returns the events published after lastID,
+// or none when lastID is NoReplay. ok is false when lastID can no longer be replayed (it fell out of the history or
+// belongs to a previous run) and the client should reload its state.
+func (h *Hub) Subscribe(lastID int64) (sub *Subscription, missed []*Event, ok bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	switch {
+	case lastID == NoReplay:
+		ok = true
+	case lastID > h.lastID:
+		ok = false
+	case len(h.history) > 0 && lastID < h.history[0].ID-1:
+		ok = false
+	default:
+		ok = true
+		for _, e := range h.history {
+			if e.ID > lastID {
+				missed = append(missed, e)
+			}
+		}
+	}
+
+	c := make(chan *Event, subscriberBuffer)
+	sub = &Subscription{C: c, StartID: h.lastID, c: c, hub: h}
+	h.subscribers[sub] = struct{}{}
+	return sub, missed, ok
+}
+
+// LastID returns the ID of the last published event
+func (h *Hub) LastID() int64 {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.lastID
+}
+
+// Cancel stops delivering events to the subscription
+func (s *Subscription) Cancel() {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+	s.hub.remove(s)
+}
+
+func (h *Hub) remove(sub *Subscription) {
+	if _, ok := h.subscribers[sub]; ok {
+		delete(h.subscribers, sub)
+		close(sub.c)
+	}
+}
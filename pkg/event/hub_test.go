@@ -0,0 +1,125 @@
+package event_test
+
+import (
+	"testing"
+
+	"github.com/TomeuUris/recipes-catalog/pkg/event"
+)
+
+func publishN(hub *event.Hub, n int) {
+	for i := 0; i < n; i++ {
+		hub.Publish(&event.Event{Entity: event.EntityRecipe, Action: event.ActionCreated, EntityID: int64(i + 1)})
+	}
+}
+
+func TestHub_SubscribeReceivesNewEvents(t *testing.T) {
+	hub := event.NewHub(10)
+
+	publishN(hub, 1)
+
+	sub, missed, ok := hub.Subscribe(event.NoReplay)
+	defer sub.Cancel()
+	if !ok || len(missed) != 0 {
+		t.Fatalf("expected a fresh subscription, got ok=%v missed=%d", ok, len(missed))
+	}
+
+	publishN(hub, 2)
+
+	for want := int64(2); want <= 3; want++ {
+		e := <-sub.C
+		if e.ID != want {
+			t.Fatalf("expected event %d, got %d", want, e.ID)
+		}
+		if e.Name() != "recipe.created" {
+			t.Fatalf("expected event name recipe.created, got %s", e.Name())
+		}
+	}
+}
+
+func TestHub_SubscribeReplaysFromLastID(t *testing.T) {
+	hub := event.NewHub(10)
+	publishN(hub, 5)
+
+	sub, missed, ok := hub.Subscribe(0)
+	sub.Cancel()
+	if !ok || len(missed) != 5 {
+		t.Fatalf("expected every event to be replayed from 0, got ok=%v missed=%d", ok, len(missed))
+	}
+
+	sub, missed, ok = hub.Subscribe(3)
+	defer sub.Cancel()
+	if !ok {
+		t.Fatalf("expected last ID 3 to be replayable")
+	}
+	if len(missed) != 2 || missed[0].ID != 4 || missed[1].ID != 5 {
+		t.Fatalf("expected events 4 and 5 to be replayed, got %v", missed)
+	}
+	if sub.StartID != 5 {
+		t.Fatalf("expected start ID 5, got %d", sub.StartID)
+	}
+}
+
+func TestHub_SubscribeRequestsResetWhenHistoryIsGone(t *testing.T) {
+	hub := event.NewHub(3)
+	publishN(hub, 10)
+
+	// Events 2..7 were dropped from the history
+	sub, missed, ok := hub.Subscribe(1)
+	sub.Cancel()
+	if ok || len(missed) != 0 {
+		t.Fatalf("expected a reset, got ok=%v missed=%d", ok, len(missed))
+	}
+
+	// The oldest kept event is 8, so resuming from 7 is still possible
+	sub, missed, ok = hub.Subscribe(7)
+	sub.Cancel()
+	if !ok || len(missed) != 3 {
+		t.Fatalf("expected 3 replayed events, got ok=%v missed=%d", ok, len(missed))
+	}
+
+	// IDs from a previous run are unknown
+	sub, _, ok = hub.Subscribe(42)
+	sub.Cancel()
+	if ok {
+		t.Fatalf("expected a reset for an unknown ID")
+	}
+}
+
+func TestHub_SlowSubscriberIsDropped(t *testing.T) {
+	hub := event.NewHub(1000)
+
+	sub, _, _ := hub.Subscribe(event.NoReplay)
+	publishN(hub, 500)
+
+	received := 0
+	for range sub.C {
+		received++
+	}
+	if received == 0 || received >= 500 {
+		t.Fatalf("expected the subscriber to be dropped after some events, got %d", received)
+	}
+}
+
+func TestHub_PublishKeepsIDsAndDropsRedeliveries(t *testing.T) {
+	hub := event.NewHub(10)
+
+	sub, _, _ := hub.Subscribe(event.NoReplay)
+	defer sub.Cancel()
+
+	for _, id := range []int64{5, 5, 3, 7} {
+		hub.Publish(&event.Event{ID: id, Entity: event.EntityRecipe, Action: event.ActionUpdated, EntityID: 1})
+	}
+	publishN(hub, 1)
+
+	for _, want := range []int64{5, 7, 8} {
+		if e := <-sub.C; e.ID != want {
+			t.Fatalf("expected event %d, got %d", want, e.ID)
+		}
+	}
+	if len(sub.C) != 0 {
+		t.Fatalf("expected redelivered events to be dropped, %d left", len(sub.C))
+	}
+	if hub.LastID() != 8 {
+		t.Fatalf("expected last ID 8, got %d", hub.LastID())
+	}
+}
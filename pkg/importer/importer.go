@@ -0,0 +1,199 @@
+// Package importer makes draft recipes from the schema.org Recipes of HTML
+// pages, uploaded or fetched from a URL, matching their ingredient lines to
+// the ingredients and cooking units of the catalog
+package importer
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"syscall"
+	"time"
+
+	"github.com/TomeuUris/recipes-catalog/pkg/entity"
+	"github.com/TomeuUris/recipes-catalog/pkg/repo/cooking_unit"
+	"github.com/TomeuUris/recipes-catalog/pkg/repo/ingredient"
+	"github.com/TomeuUris/recipes-catalog/pkg/schemaorg"
+)
+
+var (
+	// ErrInvalidURL is returned for URLs that aren't absolute http(s) ones
+	ErrInvalidURL = errors.New("invalid URL")
+	// ErrFetch is returned when the page can't be fetched
+	ErrFetch = errors.New("failed to fetch page")
+	// ErrTooLarge is returned for pages over MaxBytes
+	ErrTooLarge = errors.New("page too large")
+	// ErrNoRecipe is returned for pages without a schema.org Recipe
+	ErrNoRecipe = errors.New("no schema.org Recipe found")
+)
+
+// DefaultMaxBytes is the largest page read by default
+const DefaultMaxBytes = 5 << 20
+
+// Draft is a recipe made from a page, it isn't stored until an editor
+// reviews and creates it
+type Draft struct {
+	// Recipe has the ingredients of the matched lines
+	Recipe *entity.Recipe
+	Lines  []*Line
+	// URL is the page the recipe was fetched from, empty for uploads
+	URL string
+}
+
+// Unmatched returns the ingredient lines without an ingredient
+func (d *Draft) Unmatched() []string {
+	unmatched := []string{}
+	for _, line := range d.Lines {
+		if line.Ingredient == nil {
+			unmatched = append(unmatched, line.Text)
+		}
+	}
+	return unmatched
+}
+
+// Line is an ingredient line of the page, like "2 cups of flour"
+type Line struct {
+	Text string
+	// Quantity is 0 when the line doesn't start with one
+	Quantity float64
+	// Unit and Ingredient are nil when nothing matched
+	Unit       *entity.CookingUnit
+	Ingredient *entity.Ingredient
+}
+
+type Importer struct {
+	ingredients ingredient.Repo
+	units       cooking_unit.Repo
+	// Client fetches the pages. The default one refuses private, loopback and
+	// link-local addresses, the server mustn't fetch internal services for
+	// its users.
+	Client *http.Client
+	// MaxBytes is the largest page read, uploaded or fetched
+	MaxBytes int64
+}
+
+func New(ingredients ingredient.Repo, units cooking_unit.Repo) *Importer {
+	return &Importer{
+		ingredients: ingredients,
+		units:       units,
+		Client:      NewPublicClient(10 * time.Second),
+		MaxBytes:    DefaultMaxBytes,
+	}
+}
+
+// NewPublicClient returns a client that only connects to public addresses.
+// The address is checked once resolved, so names resolving to private ones
+// and redirects to them are refused as well.
+func NewPublicClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			ip := net.ParseIP(host)
+			if ip == nil || !isPublic(ip) {
+				return fmt.Errorf("address %s is not public", host)
+			}
+			return nil
+		},
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &http.Client{Transport: transport, Timeout: timeout}
+}
+
+func isPublic(ip net.IP) bool {
+	return !ip.IsLoopback() && !ip.IsPrivate() && !ip.IsUnspecified() && !ip.IsLinkLocalUnicast() &&
+		!ip.IsLinkLocalMulticast() && !ip.IsInterfaceLocalMulticast() && !ip.IsMulticast()
+}
+
+// ImportHTML makes a draft of the first recipe of the HTML document
+func (i *Importer) ImportHTML(ctx context.Context, r io.Reader) (*Draft, error) {
+	raw, err := io.ReadAll(io.LimitReader(r, i.MaxBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(raw)) > i.MaxBytes {
+		return nil, fmt.Errorf("%w: over %d bytes", ErrTooLarge, i.MaxBytes)
+	}
+
+	recipes, err := schemaorg.Extract(bytes.NewReader(raw))
+	if err != nil {
+		return nil, err
+	}
+	if len(recipes) == 0 {
+		return nil, ErrNoRecipe
+	}
+
+	matcher, err := i.newMatcher(ctx)
+	if err != nil {
+		return nil, err
+	}
+	draft := &Draft{Recipe: recipes[0].ToEntity(), Lines: make([]*Line, len(recipes[0].RecipeIngredient))}
+	draft.Recipe.Ingredients = []*entity.Ingredient{}
+	added := map[int64]bool{}
+	for n, text := range recipes[0].RecipeIngredient {
+		line := matcher.match(text)
+		draft.Lines[n] = line
+		if line.Ingredient != nil && !added[line.Ingredient.ID] {
+			added[line.Ingredient.ID] = true
+			draft.Recipe.Ingredients = append(draft.Recipe.Ingredients, line.Ingredient)
+		}
+	}
+	return draft, nil
+}
+
+// ImportURL fetches the page and makes a draft of its first recipe
+func (i *Importer) ImportURL(ctx context.Context, rawURL string) (*Draft, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("%w: %q, expected an http or https URL", ErrInvalidURL, rawURL)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidURL, err)
+	}
+	req.Header.Set("Accept", "text/html,application/xhtml+xml")
+	resp, err := i.Client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrFetch, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: %s responded %s", ErrFetch, u, resp.Status)
+	}
+	if mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type")); mediaType != "text/html" && mediaType != "application/xhtml+xml" {
+		return nil, fmt.Errorf("%w: %s is %q, not HTML", ErrFetch, u, mediaType)
+	}
+
+	draft, err := i.ImportHTML(ctx, resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	draft.URL = u.String()
+	return draft, nil
+}
+
+// newMatcher returns a matcher of the ingredients and cooking units stored
+func (i *Importer) newMatcher(ctx context.Context) (*matcher, error) {
+	ingredients, err := i.ingredients.FindByFilter(ctx, &ingredient.FindFilter{})
+	if err != nil {
+		return nil, err
+	}
+	units, err := i.units.FindByFilter(ctx, &cooking_unit.FindFilter{})
+	if err != nil {
+		return nil, err
+	}
+	return newMatcher(ingredients, units), nil
+}
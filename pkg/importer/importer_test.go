@@ -0,0 +1,158 @@
+package importer_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/TomeuUris/recipes-catalog/pkg/entity"
+	"github.com/TomeuUris/recipes-catalog/pkg/importer"
+	"github.com/TomeuUris/recipes-catalog/pkg/repo/cooking_unit"
+	"github.com/TomeuUris/recipes-catalog/pkg/repo/ingredient"
+)
+
+var ctx = context.Background()
+
+const page = `<html><head><script type="application/ld+json">
+{"@context": "https://schema.org", "@type": "Recipe", "name": "Tomato soup",
+ "recipeIngredient": [
+	"1 ½ cups chopped Tomatoes (ripe)",
+	"2-3 Tbsp. extra virgin olive oil",
+	"1 1/2 teaspoons salt",
+	"2 to 3 cloves of garlic",
+	"Tomato, to garnish",
+	"A pinch of saffron"
+ ],
+ "recipeInstructions": [{"@type": "HowToStep", "text": "Simmer"}, {"@type": "HowToStep", "text": "Blend"}],
+ "cookTime": "PT30M", "recipeYield": "2 bowls"}
+</script></head><body></body></html>`
+
+func newImporter(t *testing.T) *importer.Importer {
+	ingredients := ingredient.NewMemoryRepo()
+	// Ingredients of the same name only differ in type, the first is matched
+	for _, i := range []*entity.Ingredient{
+		{Name: "Tomato", Type: "Vegetable"}, {Name: "Oil", Type: "Pantry"}, {Name: "Olive oil", Type: "Pantry"},
+		{Name: "Salt", Type: "Spice"}, {Name: "Garlic", Type: "Vegetable"}, {Name: "Salt", Type: "Mineral"},
+	} {
+		if err := ingredients.Add(ctx, i); err != nil {
+			t.Fatalf("failed to add ingredient: %v", err)
+		}
+	}
+	units := cooking_unit.NewMemoryRepo()
+	for _, name := range []string{"Cup", "Tablespoon", "Teaspoons"} {
+		if err := units.Add(ctx, &entity.CookingUnit{Name: name}); err != nil {
+			t.Fatalf("failed to add cooking unit: %v", err)
+		}
+	}
+	return importer.New(ingredients, units)
+}
+
+// lines describes the lines as quantity|unit|ingredient type
+func lines(draft *importer.Draft) string {
+	var result []string
+	for _, line := range draft.Lines {
+		unit, ingredient := "-", "-"
+		if line.Unit != nil {
+			unit = line.Unit.Name
+		}
+		if line.Ingredient != nil {
+			ingredient = line.Ingredient.Name + " " + line.Ingredient.Type
+		}
+		result = append(result, fmt.Sprintf("%g|%s|%s", line.Quantity, unit, ingredient))
+	}
+	return strings.Join(result, "\n")
+}
+
+func TestImporter_ImportHTML(t *testing.T) {
+	draft, err := newImporter(t).ImportHTML(ctx, strings.NewReader(page))
+	if err != nil {
+		t.Fatalf("failed to import: %v", err)
+	}
+
+	expected := strings.Join([]string{
+		"1.5|Cup|Tomato Vegetable",
+		"2|Tablespoon|Olive oil Pantry",
+		"1.5|Teaspoons|Salt Spice",
+		"2|-|Garlic Vegetable",
+		"0|-|Tomato Vegetable",
+		"0|-|-",
+	}, "\n")
+	if got := lines(draft); got != expected {
+		t.Fatalf("expected lines\n%s\ngot\n%s", expected, got)
+	}
+	if unmatched := draft.Unmatched(); len(unmatched) != 1 || unmatched[0] != "A pinch of saffron" {
+		t.Fatalf("expected the saffron unmatched, got %q", unmatched)
+	}
+
+	var names []string
+	for _, i := range draft.Recipe.Ingredients {
+		names = append(names, i.Name)
+	}
+	if got := strings.Join(names, ","); got != "Tomato,Olive oil,Salt,Garlic" {
+		t.Fatalf("expected the matched ingredients once each, got %s", got)
+	}
+	if draft.Recipe.Name != "Tomato soup" || len(draft.Recipe.Steps) != 2 || draft.Recipe.Yield != "2 bowls" {
+		t.Fatalf("expected the recipe of the page, got %+v", draft.Recipe)
+	}
+	if err := draft.Recipe.Validate(); err != nil {
+		t.Fatalf("expected the draft to be valid: %v", err)
+	}
+}
+
+func TestImporter_ImportURL(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/soup":
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			fmt.Fprint(w, page)
+		case "/empty":
+			w.Header().Set("Content-Type", "text/html")
+			fmt.Fprint(w, "<html><body>No recipe</body></html>")
+		case "/image":
+			w.Header().Set("Content-Type", "image/png")
+		case "/large":
+			w.Header().Set("Content-Type", "text/html")
+			fmt.Fprint(w, strings.Repeat("<p>soup</p>", 1000))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	imp := newImporter(t)
+	// The default client refuses the local server
+	if _, err := imp.ImportURL(ctx, server.URL+"/soup"); !errors.Is(err, importer.ErrFetch) || !strings.Contains(err.Error(), "not public") {
+		t.Fatalf("expected the local address refused, got %v", err)
+	}
+
+	imp.Client = server.Client()
+	imp.MaxBytes = 4096
+	draft, err := imp.ImportURL(ctx, server.URL+"/soup")
+	if err != nil {
+		t.Fatalf("failed to import: %v", err)
+	}
+	if draft.URL != server.URL+"/soup" || draft.Recipe.Name != "Tomato soup" || len(draft.Lines) != 6 {
+		t.Fatalf("expected the recipe of the page, got %+v", draft)
+	}
+
+	tests := []struct {
+		url      string
+		expected error
+	}{
+		{"ftp://example.com/soup", importer.ErrInvalidURL},
+		{"/soup", importer.ErrInvalidURL},
+		{server.URL + "/missing", importer.ErrFetch},
+		{server.URL + "/image", importer.ErrFetch},
+		{server.URL + "/empty", importer.ErrNoRecipe},
+		{server.URL + "/large", importer.ErrTooLarge},
+	}
+	for _, test := range tests {
+		if _, err := imp.ImportURL(ctx, test.url); !errors.Is(err, test.expected) {
+			t.Errorf("expected %s to fail with %v, got %v", test.url, test.expected, err)
+		}
+	}
+}
@@ -0,0 +1,200 @@
+package importer
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/TomeuUris/recipes-catalog/pkg/entity"
+)
+
+// maxUnitWords is the most words a cooking unit name is matched with
+const maxUnitWords = 3
+
+// matcher matches the ingredient lines of recipes, like "1 ½ cups of
+// chopped tomatoes (ripe)", to a quantity, a cooking unit and an ingredient.
+// Words are compared in singular and unit abbreviations by the unit they
+// abbreviate, so "tbsp" matches a "Tablespoons" unit.
+type matcher struct {
+	// ingredients and units by the key of their name, the first one stored
+	// of those with the same key
+	ingredients map[string]*entity.Ingredient
+	units       map[string]*entity.CookingUnit
+	// maxIngredientWords is the most words of an ingredient name
+	maxIngredientWords int
+}
+
+func newMatcher(ingredients []*entity.Ingredient, units []*entity.CookingUnit) *matcher {
+	m := &matcher{
+		ingredients: map[string]*entity.Ingredient{},
+		units:       map[string]*entity.CookingUnit{},
+	}
+	for _, ingredient := range ingredients {
+		words := keyWords(ingredient.Name)
+		key := strings.Join(words, " ")
+		if key == "" || m.ingredients[key] != nil {
+			continue
+		}
+		m.ingredients[key] = ingredient
+		if len(words) > m.maxIngredientWords {
+			m.maxIngredientWords = len(words)
+		}
+	}
+	for _, unit := range units {
+		key := strings.Join(keyWords(unit.Name), " ")
+		if key != "" && m.units[key] == nil {
+			m.units[key] = unit
+		}
+	}
+	return m
+}
+
+// match returns the line with what it matched. The unit is looked for right
+// after the quantity, the ingredient is the longest name in the rest of the
+// line and the first one of those as long.
+func (m *matcher) match(text string) *Line {
+	line := &Line{Text: text}
+	words := keyWords(text)
+	line.Quantity, words = parseQuantity(words)
+
+	for n := min(maxUnitWords, len(words)); n > 0; n-- {
+		if unit, ok := m.units[strings.Join(words[:n], " ")]; ok {
+			line.Unit = unit
+			words = words[n:]
+			break
+		}
+	}
+
+	for n := min(m.maxIngredientWords, len(words)); n > 0 && line.Ingredient == nil; n-- {
+		for start := 0; start+n <= len(words); start++ {
+			if ingredient, ok := m.ingredients[strings.Join(words[start:start+n], " ")]; ok {
+				line.Ingredient = ingredient
+				break
+			}
+		}
+	}
+	return line
+}
+
+// fractions are the vulgar fraction characters of quantities
+var fractions = strings.NewReplacer(
+	"½", " 1/2 ", "⅓", " 1/3 ", "⅔", " 2/3 ", "¼", " 1/4 ", "¾", " 3/4 ",
+	"⅕", " 1/5 ", "⅖", " 2/5 ", "⅗", " 3/5 ", "⅘", " 4/5 ", "⅙", " 1/6 ",
+	"⅚", " 5/6 ", "⅛", " 1/8 ", "⅜", " 3/8 ", "⅝", " 5/8 ", "⅞", " 7/8 ",
+	"⁄", "/",
+)
+
+var parenthesis = regexp.MustCompile(`\([^)]*\)|\[[^\]]*\]`)
+
+// keyWords returns the words names are compared by: normalized, in singular,
+// abbreviations expanded and without what is between parenthesis
+func keyWords(text string) []string {
+	text = parenthesis.ReplaceAllString(fractions.Replace(text), " ")
+	words := strings.FieldsFunc(entity.NormalizeName(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '/' && r != '.' && r != '-' && r != '\''
+	})
+
+	result := make([]string, 0, len(words))
+	for _, word := range words {
+		if word = strings.Trim(word, ".-'"); word == "" {
+			continue
+		}
+		if unit, ok := unitAbbreviations[word]; ok {
+			word = unit
+		}
+		result = append(result, singular(word))
+	}
+	return result
+}
+
+// unitAbbreviations are the usual abbreviations of cooking units
+var unitAbbreviations = map[string]string{
+	"tbsp": "tablespoon", "tbsps": "tablespoon", "tbs": "tablespoon", "tbl": "tablespoon",
+	"tsp": "teaspoon", "tsps": "teaspoon",
+	"g": "gram", "gr": "gram", "grs": "gram", "kg": "kilogram", "kgs": "kilogram", "mg": "milligram",
+	"ml": "milliliter", "millilitre": "milliliter", "millilitres": "milliliter",
+	"cl": "centiliter", "dl": "deciliter", "l": "liter", "litre": "liter", "litres": "liter",
+	"oz": "ounce", "lb": "pound", "lbs": "pound", "pt": "pint", "qt": "quart", "gal": "gallon",
+	"c": "cup", "pkg": "package",
+}
+
+// singular returns the singular of an English word, good enough to compare
+// names written in either number
+func singular(word string) string {
+	switch {
+	case len(word) <= 3 || !strings.HasSuffix(word, "s") || strings.HasSuffix(word, "ss") || strings.HasSuffix(word, "us"):
+		return word
+	case strings.HasSuffix(word, "ies"):
+		return strings.TrimSuffix(word, "ies") + "y"
+	case strings.HasSuffix(word, "oes"), strings.HasSuffix(word, "ches"), strings.HasSuffix(word, "shes"),
+		strings.HasSuffix(word, "sses"), strings.HasSuffix(word, "xes"):
+		return strings.TrimSuffix(word, "es")
+	}
+	return strings.TrimSuffix(word, "s")
+}
+
+// parseQuantity returns the quantity the words start with and the words
+// after it. Quantities are numbers, fractions and mixed numbers, ranges like
+// "2-3" or "2 to 3" are taken by their first number.
+func parseQuantity(words []string) (float64, []string) {
+	if len(words) == 0 {
+		return 0, words
+	}
+	first, rangeEnd := words[0], ""
+	if i := strings.Index(first, "-"); i > 0 {
+		first, rangeEnd = first[:i], first[i+1:]
+	}
+	quantity, ok := parseNumber(first)
+	if !ok {
+		return 0, words
+	}
+	words = words[1:]
+
+	// Mixed numbers like "1 1/2"
+	if rangeEnd == "" && len(words) > 0 && strings.Contains(words[0], "/") && quantity == float64(int(quantity)) {
+		if fraction, ok := parseNumber(words[0]); ok {
+			quantity += fraction
+			words = words[1:]
+		}
+	}
+
+	// Ranges, the words after "-" or "to". Words joined with a hyphen that
+	// aren't a range, like "2-inch", are kept.
+	if rangeEnd != "" {
+		if _, ok := parseNumber(rangeEnd); !ok {
+			words = append([]string{rangeEnd}, words...)
+		}
+	} else if len(words) > 1 && words[0] == "to" {
+		if _, ok := parseNumber(words[1]); ok {
+			words = words[2:]
+		}
+	}
+	return quantity, words
+}
+
+// parseNumber parses integers, decimals and fractions
+func parseNumber(s string) (float64, bool) {
+	if numerator, denominator, ok := strings.Cut(s, "/"); ok {
+		n, ok := parseDecimal(numerator)
+		if !ok {
+			return 0, false
+		}
+		d, ok := parseDecimal(denominator)
+		if !ok || d == 0 {
+			return 0, false
+		}
+		return n / d, true
+	}
+	return parseDecimal(s)
+}
+
+// parseDecimal parses digits with an optional point, unlike ParseFloat it
+// doesn't take words like "inf" as numbers
+func parseDecimal(s string) (float64, bool) {
+	if s == "" || strings.IndexFunc(s, func(r rune) bool { return !unicode.IsDigit(r) && r != '.' }) >= 0 {
+		return 0, false
+	}
+	n, err := strconv.ParseFloat(s, 64)
+	return n, err == nil
+}
@@ -0,0 +1,179 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/TomeuUris/recipes-catalog/pkg/entity"
+	"github.com/TomeuUris/recipes-catalog/pkg/event"
+	repo "github.com/TomeuUris/recipes-catalog/pkg/repo/outbox"
+	"github.com/TomeuUris/recipes-catalog/pkg/webhook"
+)
+
+// Sink receives the events of the outbox. An event may be delivered more than
+// once, e.g. when another sink failed, sinks tell them apart by ID.
+type Sink interface {
+	Deliver(ctx context.Context, e *event.Event) error
+}
+
+// Dispatcher delivers the outbox messages to every sink at least once, in the
+// order they were committed. A failed message is retried with exponential
+// backoff and holds back the following ones.
+type Dispatcher struct {
+	outbox repo.Repo
+	sinks  []Sink
+
+	// PollInterval is how often the outbox is read when not notified
+	PollInterval time.Duration
+	// BatchSize is the maximum number of messages delivered per read
+	BatchSize int
+	// BaseBackoff is the wait after the first failed delivery, doubled after each one up to MaxBackoff
+	BaseBackoff time.Duration
+	MaxBackoff  time.Duration
+	// Retention is how long delivered messages are kept
+	Retention time.Duration
+
+	notified chan struct{}
+	now      func() time.Time
+}
+
+func NewDispatcher(outbox repo.Repo, sinks ...Sink) *Dispatcher {
+	return &Dispatcher{
+		outbox:       outbox,
+		sinks:        sinks,
+		PollInterval: time.Second,
+		BatchSize:    100,
+		BaseBackoff:  time.Second,
+		MaxBackoff:   5 * time.Minute,
+		Retention:    7 * 24 * time.Hour,
+		notified:     make(chan struct{}, 1),
+		now:          time.Now,
+	}
+}
+
+// Notify wakes the dispatcher up to deliver new messages without waiting for
+// the next poll
+func (d *Dispatcher) Notify() {
+	select {
+	case d.notified <- struct{}{}:
+	default:
+		// A delivery is already pending
+	}
+}
+
+// Run delivers the pending messages and removes the old delivered ones until
+// the context is done
+func (d *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.PollInterval)
+	defer ticker.Stop()
+	lastPrune := time.Time{}
+	for {
+		if err := d.DeliverPending(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("outbox: failed to deliver: %v", err)
+		}
+		if now := d.now(); now.Sub(lastPrune) >= time.Hour {
+			lastPrune = now
+			if _, err := d.outbox.DeleteDelivered(ctx, now.Add(-d.Retention)); err != nil && ctx.Err() == nil {
+				log.Printf("outbox: failed to remove delivered messages: %v", err)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-d.notified:
+		}
+	}
+}
+
+// DeliverPending delivers the pending messages in order, it stops at the first
+// one that fails or waits for a retry
+func (d *Dispatcher) DeliverPending(ctx context.Context) error {
+	for {
+		pending, err := d.outbox.FindPending(ctx, d.BatchSize)
+		if err != nil {
+			return err
+		}
+		for _, message := range pending {
+			if message.NextAttemptAt.After(d.now()) {
+				return nil
+			}
+			delivered, err := d.deliver(ctx, message)
+			if err != nil {
+				return err
+			}
+			if !delivered {
+				return nil
+			}
+		}
+		if len(pending) < d.BatchSize {
+			return nil
+		}
+	}
+}
+
+// deliver sends the message to every sink and records the outcome, it returns
+// whether every sink received it
+func (d *Dispatcher) deliver(ctx context.Context, message *entity.OutboxMessage) (bool, error) {
+	err := d.send(ctx, message)
+	if err == nil {
+		now := d.now()
+		message.DeliveredAt = &now
+		message.LastError = ""
+	} else {
+		message.Attempts++
+		message.LastError = err.Error()
+		message.NextAttemptAt = d.now().Add(webhook.Backoff(message.Attempts, d.BaseBackoff, d.MaxBackoff))
+		log.Printf("outbox: delivery of message %d failed (attempt %d): %v", message.ID, message.Attempts, err)
+	}
+
+	// The sinks may receive the message again if recording it fails
+	if editErr := d.outbox.Edit(context.WithoutCancel(ctx), message); editErr != nil {
+		return false, editErr
+	}
+	return err == nil, nil
+}
+
+func (d *Dispatcher) send(ctx context.Context, message *entity.OutboxMessage) error {
+	e, err := ToEvent(message)
+	if err != nil {
+		return err
+	}
+	for _, sink := range d.sinks {
+		if err := sink.Deliver(ctx, e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ToEvent returns the event of the message, identified by the message ID
+func ToEvent(message *entity.OutboxMessage) (*event.Event, error) {
+	var payload interface{}
+	switch message.Entity {
+	case event.EntityRecipe:
+		payload = &entity.Recipe{}
+	case event.EntityIngredient:
+		payload = &entity.Ingredient{}
+	case event.EntityCookingUnit:
+		payload = &entity.CookingUnit{}
+	default:
+		return nil, fmt.Errorf("unknown entity %q", message.Entity)
+	}
+	if err := json.Unmarshal([]byte(message.Payload), payload); err != nil {
+		return nil, fmt.Errorf("invalid payload: %w", err)
+	}
+
+	return &event.Event{
+		ID:         message.ID,
+		Entity:     message.Entity,
+		Action:     message.Action,
+		EntityID:   message.EntityID,
+		Payload:    payload,
+		OccurredAt: message.OccurredAt,
+	}, nil
+}
@@ -0,0 +1,266 @@
+package outbox_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/TomeuUris/recipes-catalog/pkg/entity"
+	"github.com/TomeuUris/recipes-catalog/pkg/event"
+	"github.com/TomeuUris/recipes-catalog/pkg/outbox"
+	"github.com/TomeuUris/recipes-catalog/pkg/repo/ingredient"
+	repo "github.com/TomeuUris/recipes-catalog/pkg/repo/outbox"
+	"github.com/TomeuUris/recipes-catalog/pkg/repo/schema"
+	"github.com/TomeuUris/recipes-catalog/pkg/repo/transaction"
+	"github.com/TomeuUris/recipes-catalog/pkg/sqlite"
+	"github.com/TomeuUris/recipes-catalog/pkg/sqlite/migrations"
+	gormsqlite "gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+var ctx = context.Background()
+
+var errAbort = errors.New("abort")
+
+func openDB(t *testing.T) *sql.DB {
+	sqlDB, err := sqlite.Open(":memory:")
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	// Every connection to :memory: opens a new database
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+
+	if err := migrations.Store.Apply(ctx, sqlDB); err != nil {
+		t.Fatalf("failed to migrate database: %v", err)
+	}
+	return sqlDB
+}
+
+func pending(t *testing.T, messages repo.Repo) []*entity.OutboxMessage {
+	t.Helper()
+	found, err := messages.FindPending(ctx, 100)
+	if err != nil {
+		t.Fatalf("failed to find pending messages: %v", err)
+	}
+	return found
+}
+
+func TestTxManager_AddsEventsInTheTransaction(t *testing.T) {
+	sqlDB := openDB(t)
+	messages := repo.NewRepo(sqlDB)
+	notified := 0
+	manager := outbox.NewTxManager(transaction.NewSQLManager(sqlDB), func() { notified++ })
+	ingredients := outbox.NewIngredientRepo(ingredient.NewRepo(sqlDB), manager)
+
+	// A rolled back write leaves no event
+	if err := manager.Do(ctx, func(repos *transaction.Repos) error {
+		if err := repos.Ingredients.Add(ctx, &entity.Ingredient{Name: "Salt", Type: "Spice"}); err != nil {
+			return err
+		}
+		return errAbort
+	}); !errors.Is(err, errAbort) {
+		t.Fatalf("expected the error of the unit of work, got %v", err)
+	}
+	if found := pending(t, messages); len(found) != 0 || notified != 0 {
+		t.Fatalf("expected no events of a rolled back unit of work, got %d (notified %d)", len(found), notified)
+	}
+
+	// Every committed write adds its event
+	pepper := &entity.Ingredient{Name: "Pepper", Type: "Spice"}
+	if err := ingredients.Add(ctx, pepper); err != nil {
+		t.Fatalf("failed to add ingredient: %v", err)
+	}
+	pepper.Name = "Black pepper"
+	if err := ingredients.Edit(ctx, pepper); err != nil {
+		t.Fatalf("failed to edit ingredient: %v", err)
+	}
+	if err := ingredients.Delete(ctx, pepper); err != nil {
+		t.Fatalf("failed to delete ingredient: %v", err)
+	}
+
+	found := pending(t, messages)
+	if len(found) != 3 || notified != 3 {
+		t.Fatalf("expected 3 events notified, got %d (notified %d)", len(found), notified)
+	}
+	for i, action := range []string{event.ActionCreated, event.ActionUpdated, event.ActionDeleted} {
+		if found[i].Entity != event.EntityIngredient || found[i].Action != action || found[i].EntityID != pepper.ID {
+			t.Fatalf("expected ingredient %d %s, got %+v", pepper.ID, action, found[i])
+		}
+	}
+	e, err := outbox.ToEvent(found[1])
+	if err != nil {
+		t.Fatalf("failed to decode the event: %v", err)
+	}
+	if payload, ok := e.Payload.(*entity.Ingredient); !ok || payload.Name != "Black pepper" || e.ID != found[1].ID {
+		t.Fatalf("expected the edited ingredient, got %+v", e)
+	}
+}
+
+func TestTxManager_FailedWriteAddsNoEvent(t *testing.T) {
+	sqlDB := openDB(t)
+	messages := repo.NewRepo(sqlDB)
+	ingredients := outbox.NewIngredientRepo(ingredient.NewRepo(sqlDB), outbox.NewTxManager(transaction.NewSQLManager(sqlDB), nil))
+
+	if err := ingredients.Edit(ctx, &entity.Ingredient{ID: 1, Name: "Salt", Type: "Spice"}); !entity.IsErrNotFound(err) {
+		t.Fatalf("expected entity.ErrNotFound, got %v", err)
+	}
+	if found := pending(t, messages); len(found) != 0 {
+		t.Fatalf("expected no events of a failed write, got %d", len(found))
+	}
+}
+
+func TestTrashRepo_AddsEvents(t *testing.T) {
+	db, err := gorm.Open(&gormsqlite.Dialector{DriverName: sqlite.DriverName, DSN: "file::memory:"}, &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("failed to get database: %v", err)
+	}
+	// Every connection to :memory: opens a new database
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+	if err := schema.Migrate(ctx, db); err != nil {
+		t.Fatalf("failed to migrate database: %v", err)
+	}
+	messages := repo.NewGormRepo(db)
+	manager := outbox.NewTxManager(transaction.NewGormManager(db), nil)
+	ingredients := ingredient.NewGormRepo(db)
+	trash := outbox.NewIngredientTrashRepo(ingredients, manager)
+
+	salt := &entity.Ingredient{Name: "Salt", Type: "Spice"}
+	if err := ingredients.Add(ctx, salt); err != nil {
+		t.Fatalf("failed to add ingredient: %v", err)
+	}
+	if err := ingredients.Delete(ctx, salt); err != nil {
+		t.Fatalf("failed to delete ingredient: %v", err)
+	}
+	if err := trash.Restore(ctx, salt.ID); err != nil {
+		t.Fatalf("failed to restore ingredient: %v", err)
+	}
+	if err := ingredients.Delete(ctx, salt); err != nil {
+		t.Fatalf("failed to delete ingredient: %v", err)
+	}
+	if _, err := trash.Purge(ctx, time.Now().Add(time.Second)); err != nil {
+		t.Fatalf("failed to purge trash: %v", err)
+	}
+
+	found := pending(t, messages)
+	if len(found) != 2 {
+		t.Fatalf("expected the restore and purge events, got %+v", found)
+	}
+	for i, action := range []string{event.ActionRestored, event.ActionPurged} {
+		e, err := outbox.ToEvent(found[i])
+		if err != nil {
+			t.Fatalf("failed to decode the event: %v", err)
+		}
+		if payload, ok := e.Payload.(*entity.Ingredient); !ok || e.Action != action || e.EntityID != salt.ID || payload.Name != "Salt" {
+			t.Fatalf("expected salt %s, got %+v", action, e)
+		}
+	}
+}
+
+// sink records the events it receives, failing while err is set
+type sink struct {
+	err    error
+	events []*event.Event
+}
+
+func (s *sink) Deliver(ctx context.Context, e *event.Event) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.events = append(s.events, e)
+	return nil
+}
+
+func addEvents(t *testing.T, messages repo.Repo, n int) {
+	t.Helper()
+	manager := outbox.NewTxManager(transaction.NewPassthroughManager(transaction.Repos{
+		Ingredients: ingredient.NewMemoryRepo(),
+		Outbox:      messages,
+	}), nil)
+	for i := 0; i < n; i++ {
+		if err := manager.Do(ctx, func(repos *transaction.Repos) error {
+			return repos.Ingredients.Add(ctx, &entity.Ingredient{Name: fmt.Sprintf("Spice %d", i), Type: "Spice"})
+		}); err != nil {
+			t.Fatalf("failed to add ingredient: %v", err)
+		}
+	}
+}
+
+func TestDispatcher_DeliversInOrderAndRetries(t *testing.T) {
+	messages := repo.NewMemoryRepo()
+	addEvents(t, messages, 2)
+	failing, working := &sink{err: errors.New("unreachable")}, &sink{}
+	dispatcher := outbox.NewDispatcher(messages, working, failing)
+	dispatcher.BaseBackoff, dispatcher.MaxBackoff = time.Hour, time.Hour
+
+	// The failed message is retried after the backoff and holds back the next
+	if err := dispatcher.DeliverPending(ctx); err != nil {
+		t.Fatalf("failed to deliver: %v", err)
+	}
+	found := pending(t, messages)
+	if len(found) != 2 || found[0].Attempts != 1 || found[0].LastError != "unreachable" || found[1].Attempts != 0 {
+		t.Fatalf("expected the first message to be retried, got %+v", found)
+	}
+	if time.Until(found[0].NextAttemptAt) < 59*time.Minute {
+		t.Fatalf("expected next attempt in an hour, got %v", found[0].NextAttemptAt)
+	}
+	failing.err = nil
+	if err := dispatcher.DeliverPending(ctx); err != nil {
+		t.Fatalf("failed to deliver: %v", err)
+	}
+	if len(failing.events) != 0 {
+		t.Fatalf("expected no delivery during the backoff, got %d", len(failing.events))
+	}
+
+	// Once due, every sink receives the messages in order, at least once
+	found[0].NextAttemptAt = time.Now()
+	if err := messages.Edit(ctx, found[0]); err != nil {
+		t.Fatalf("failed to edit message: %v", err)
+	}
+	if err := dispatcher.DeliverPending(ctx); err != nil {
+		t.Fatalf("failed to deliver: %v", err)
+	}
+	if found := pending(t, messages); len(found) != 0 {
+		t.Fatalf("expected every message delivered, got %d pending", len(found))
+	}
+	if len(failing.events) != 2 || failing.events[0].ID != 1 || failing.events[1].ID != 2 {
+		t.Fatalf("expected events 1 and 2, got %+v", failing.events)
+	}
+	if len(working.events) != 3 || working.events[0].ID != 1 {
+		t.Fatalf("expected event 1 redelivered to the working sink, got %+v", working.events)
+	}
+	if working.events[1].Name() != "ingredient.created" {
+		t.Fatalf("expected ingredient.created, got %s", working.events[1].Name())
+	}
+}
+
+func TestDispatcher_HubSinkPublishesOnce(t *testing.T) {
+	messages := repo.NewMemoryRepo()
+	addEvents(t, messages, 1)
+	hub := event.NewHub(10)
+	failing := &sink{err: errors.New("unreachable")}
+	dispatcher := outbox.NewDispatcher(messages, outbox.NewHubSink(hub), failing)
+	dispatcher.BaseBackoff = 0
+
+	if err := dispatcher.DeliverPending(ctx); err != nil {
+		t.Fatalf("failed to deliver: %v", err)
+	}
+	failing.err = nil
+	if err := dispatcher.DeliverPending(ctx); err != nil {
+		t.Fatalf("failed to deliver: %v", err)
+	}
+
+	_, missed, ok := hub.Subscribe(0)
+	if !ok || len(missed) != 1 || missed[0].ID != 1 {
+		t.Fatalf("expected event 1 published once, got %+v", missed)
+	}
+}
@@ -0,0 +1,176 @@
+// Package outbox adds a domain event to the outbox for every write on the
+// catalog repositories, in the transaction of the write, and delivers them to
+// the sinks once committed. Consumers never miss a write nor see one that was
+// rolled back.
+package outbox
+
+import (
+	"context"
+	"time"
+
+	"github.com/TomeuUris/recipes-catalog/pkg/entity"
+	"github.com/TomeuUris/recipes-catalog/pkg/repo/cooking_unit"
+	"github.com/TomeuUris/recipes-catalog/pkg/repo/ingredient"
+	"github.com/TomeuUris/recipes-catalog/pkg/repo/recipe"
+	"github.com/TomeuUris/recipes-catalog/pkg/repo/transaction"
+)
+
+// RecipeRepo runs every write on its own unit of work of the manager, which
+// adds its event to the outbox. Reads go to the wrapped repository.
+type RecipeRepo struct {
+	recipe.Repo
+	tx *TxManager
+}
+
+func NewRecipeRepo(repo recipe.Repo, tx *TxManager) *RecipeRepo {
+	return &RecipeRepo{Repo: repo, tx: tx}
+}
+
+func (r *RecipeRepo) Add(ctx context.Context, rp *entity.Recipe) error {
+	return r.tx.Do(ctx, func(repos *transaction.Repos) error {
+		return repos.Recipes.Add(ctx, rp)
+	})
+}
+
+func (r *RecipeRepo) Edit(ctx context.Context, rp *entity.Recipe) error {
+	return r.tx.Do(ctx, func(repos *transaction.Repos) error {
+		return repos.Recipes.Edit(ctx, rp)
+	})
+}
+
+func (r *RecipeRepo) Delete(ctx context.Context, rp *entity.Recipe) error {
+	return r.tx.Do(ctx, func(repos *transaction.Repos) error {
+		return repos.Recipes.Delete(ctx, rp)
+	})
+}
+
+// IngredientRepo runs every write on its own unit of work of the manager,
+// which adds its event to the outbox. Reads go to the wrapped repository.
+type IngredientRepo struct {
+	ingredient.Repo
+	tx *TxManager
+}
+
+func NewIngredientRepo(repo ingredient.Repo, tx *TxManager) *IngredientRepo {
+	return &IngredientRepo{Repo: repo, tx: tx}
+}
+
+func (r *IngredientRepo) Add(ctx context.Context, i *entity.Ingredient) error {
+	return r.tx.Do(ctx, func(repos *transaction.Repos) error {
+		return repos.Ingredients.Add(ctx, i)
+	})
+}
+
+func (r *IngredientRepo) Edit(ctx context.Context, i *entity.Ingredient) error {
+	return r.tx.Do(ctx, func(repos *transaction.Repos) error {
+		return repos.Ingredients.Edit(ctx, i)
+	})
+}
+
+func (r *IngredientRepo) Delete(ctx context.Context, i *entity.Ingredient) error {
+	return r.tx.Do(ctx, func(repos *transaction.Repos) error {
+		return repos.Ingredients.Delete(ctx, i)
+	})
+}
+
+func (r *IngredientRepo) DetachAndDelete(ctx context.Context, i *entity.Ingredient) error {
+	return r.tx.Do(ctx, func(repos *transaction.Repos) error {
+		return repos.Ingredients.DetachAndDelete(ctx, i)
+	})
+}
+
+func (r *IngredientRepo) Merge(ctx context.Context, target *entity.Ingredient, duplicates []int64) error {
+	return r.tx.Do(ctx, func(repos *transaction.Repos) error {
+		return repos.Ingredients.Merge(ctx, target, duplicates)
+	})
+}
+
+// CookingUnitRepo runs every write on its own unit of work of the manager,
+// which adds its event to the outbox. Reads go to the wrapped repository.
+type CookingUnitRepo struct {
+	cooking_unit.Repo
+	tx *TxManager
+}
+
+func NewCookingUnitRepo(repo cooking_unit.Repo, tx *TxManager) *CookingUnitRepo {
+	return &CookingUnitRepo{Repo: repo, tx: tx}
+}
+
+func (r *CookingUnitRepo) Add(ctx context.Context, u *entity.CookingUnit) error {
+	return r.tx.Do(ctx, func(repos *transaction.Repos) error {
+		return repos.CookingUnits.Add(ctx, u)
+	})
+}
+
+func (r *CookingUnitRepo) Edit(ctx context.Context, u *entity.CookingUnit) error {
+	return r.tx.Do(ctx, func(repos *transaction.Repos) error {
+		return repos.CookingUnits.Edit(ctx, u)
+	})
+}
+
+func (r *CookingUnitRepo) Delete(ctx context.Context, u *entity.CookingUnit) error {
+	return r.tx.Do(ctx, func(repos *transaction.Repos) error {
+		return repos.CookingUnits.Delete(ctx, u)
+	})
+}
+
+// RecipeTrashRepo runs every restore and purge on its own unit of work of the
+// manager, which must have a recipes trash. Reads go to the wrapped repository.
+type RecipeTrashRepo struct {
+	recipe.TrashRepo
+	tx *TxManager
+}
+
+func NewRecipeTrashRepo(repo recipe.TrashRepo, tx *TxManager) *RecipeTrashRepo {
+	return &RecipeTrashRepo{TrashRepo: repo, tx: tx}
+}
+
+func (r *RecipeTrashRepo) Restore(ctx context.Context, id int64) error {
+	return r.tx.Do(ctx, func(repos *transaction.Repos) error {
+		return repos.RecipesTrash.Restore(ctx, id)
+	})
+}
+
+func (r *RecipeTrashRepo) Purge(ctx context.Context, before time.Time) ([]*entity.TrashItem, error) {
+	var purged []*entity.TrashItem
+	err := r.tx.Do(ctx, func(repos *transaction.Repos) error {
+		var err error
+		purged, err = repos.RecipesTrash.Purge(ctx, before)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return purged, nil
+}
+
+// IngredientTrashRepo runs every restore and purge on its own unit of work of
+// the manager, which must have an ingredients trash. Reads go to the wrapped
+// repository.
+type IngredientTrashRepo struct {
+	ingredient.TrashRepo
+	tx *TxManager
+}
+
+func NewIngredientTrashRepo(repo ingredient.TrashRepo, tx *TxManager) *IngredientTrashRepo {
+	return &IngredientTrashRepo{TrashRepo: repo, tx: tx}
+}
+
+func (r *IngredientTrashRepo) Restore(ctx context.Context, id int64) error {
+	return r.tx.Do(ctx, func(repos *transaction.Repos) error {
+		return repos.IngredientsTrash.Restore(ctx, id)
+	})
+}
+
+func (r *IngredientTrashRepo) Purge(ctx context.Context, before time.Time) ([]*entity.TrashItem, error) {
+	var purged []*entity.TrashItem
+	err := r.tx.Do(ctx, func(repos *transaction.Repos) error {
+		var err error
+		purged, err = repos.IngredientsTrash.Purge(ctx, before)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return purged, nil
+}
@@ -0,0 +1,38 @@
+package outbox
+
+import (
+	"context"
+	"log"
+
+	"github.com/TomeuUris/recipes-catalog/pkg/event"
+	"github.com/TomeuUris/recipes-catalog/pkg/webhook"
+)
+
+// SinkFunc adapts a function to a Sink
+type SinkFunc func(ctx context.Context, e *event.Event) error
+
+func (f SinkFunc) Deliver(ctx context.Context, e *event.Event) error {
+	return f(ctx, e)
+}
+
+// NewLogSink logs every event
+func NewLogSink() Sink {
+	return SinkFunc(func(ctx context.Context, e *event.Event) error {
+		log.Printf("event %d: %s %d", e.ID, e.Name(), e.EntityID)
+		return nil
+	})
+}
+
+// NewHubSink publishes the events to the hub streamed on /events, which drops
+// the ones already published
+func NewHubSink(hub *event.Hub) Sink {
+	return SinkFunc(func(ctx context.Context, e *event.Event) error {
+		hub.Publish(e)
+		return nil
+	})
+}
+
+// NewWebhookSink enqueues a delivery of the events for the webhooks subscribed to them
+func NewWebhookSink(dispatcher *webhook.Dispatcher) Sink {
+	return SinkFunc(dispatcher.Enqueue)
+}
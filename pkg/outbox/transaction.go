@@ -0,0 +1,246 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/TomeuUris/recipes-catalog/pkg/entity"
+	"github.com/TomeuUris/recipes-catalog/pkg/event"
+	"github.com/TomeuUris/recipes-catalog/pkg/repo/cooking_unit"
+	"github.com/TomeuUris/recipes-catalog/pkg/repo/ingredient"
+	repo "github.com/TomeuUris/recipes-catalog/pkg/repo/outbox"
+	"github.com/TomeuUris/recipes-catalog/pkg/repo/recipe"
+	"github.com/TomeuUris/recipes-catalog/pkg/repo/transaction"
+)
+
+// TxManager adds the events of the writes of a unit of work to its outbox,
+// so they are committed or rolled back along with the writes
+type TxManager struct {
+	transaction.Manager
+	// notify is called after committing events, nil for none
+	notify func()
+}
+
+// NewTxManager returns the manager, notify is called once a unit of work with
+// events is committed, e.g. Dispatcher.Notify
+func NewTxManager(manager transaction.Manager, notify func()) *TxManager {
+	return &TxManager{Manager: manager, notify: notify}
+}
+
+func (m *TxManager) Do(ctx context.Context, fn func(repos *transaction.Repos) error) error {
+	w := &writer{}
+	if err := m.Manager.Do(ctx, func(repos *transaction.Repos) error {
+		w.outbox = repos.Outbox
+		written := &transaction.Repos{
+			Ingredients:  &ingredientWriter{Repo: repos.Ingredients, writer: w},
+			Recipes:      &recipeWriter{Repo: repos.Recipes, writer: w},
+			CookingUnits: &cookingUnitWriter{Repo: repos.CookingUnits, writer: w},
+			Outbox:       repos.Outbox,
+			Audit:        repos.Audit,
+			Tx:           repos.Tx,
+		}
+		if repos.RecipesTrash != nil {
+			written.RecipesTrash = &recipeTrashWriter{TrashRepo: repos.RecipesTrash, recipes: repos.Recipes, writer: w}
+		}
+		if repos.IngredientsTrash != nil {
+			written.IngredientsTrash = &ingredientTrashWriter{TrashRepo: repos.IngredientsTrash, ingredients: repos.Ingredients, writer: w}
+		}
+		return fn(written)
+	}); err != nil {
+		return err
+	}
+
+	if w.added && m.notify != nil {
+		m.notify()
+	}
+	return nil
+}
+
+// writer adds the events to the outbox of a unit of work. Failing to add one
+// fails the write, so it is rolled back.
+type writer struct {
+	outbox repo.Repo
+	added  bool
+}
+
+func (w *writer) add(ctx context.Context, entityName string, id int64, action string, payload interface{}) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	if err := w.outbox.Add(ctx, &entity.OutboxMessage{
+		Entity:        entityName,
+		EntityID:      id,
+		Action:        action,
+		Payload:       string(data),
+		OccurredAt:    now,
+		NextAttemptAt: now,
+	}); err != nil {
+		return err
+	}
+	w.added = true
+	return nil
+}
+
+type recipeWriter struct {
+	recipe.Repo
+	*writer
+}
+
+func (r *recipeWriter) Add(ctx context.Context, rp *entity.Recipe) error {
+	if err := r.Repo.Add(ctx, rp); err != nil {
+		return err
+	}
+	return r.add(ctx, event.EntityRecipe, rp.ID, event.ActionCreated, rp)
+}
+
+func (r *recipeWriter) Edit(ctx context.Context, rp *entity.Recipe) error {
+	if err := r.Repo.Edit(ctx, rp); err != nil {
+		return err
+	}
+	return r.add(ctx, event.EntityRecipe, rp.ID, event.ActionUpdated, rp)
+}
+
+func (r *recipeWriter) Delete(ctx context.Context, rp *entity.Recipe) error {
+	if err := r.Repo.Delete(ctx, rp); err != nil {
+		return err
+	}
+	return r.add(ctx, event.EntityRecipe, rp.ID, event.ActionDeleted, rp)
+}
+
+type ingredientWriter struct {
+	ingredient.Repo
+	*writer
+}
+
+func (r *ingredientWriter) Add(ctx context.Context, i *entity.Ingredient) error {
+	if err := r.Repo.Add(ctx, i); err != nil {
+		return err
+	}
+	return r.add(ctx, event.EntityIngredient, i.ID, event.ActionCreated, i)
+}
+
+func (r *ingredientWriter) Edit(ctx context.Context, i *entity.Ingredient) error {
+	if err := r.Repo.Edit(ctx, i); err != nil {
+		return err
+	}
+	return r.add(ctx, event.EntityIngredient, i.ID, event.ActionUpdated, i)
+}
+
+func (r *ingredientWriter) Delete(ctx context.Context, i *entity.Ingredient) error {
+	if err := r.Repo.Delete(ctx, i); err != nil {
+		return err
+	}
+	return r.add(ctx, event.EntityIngredient, i.ID, event.ActionDeleted, i)
+}
+
+func (r *ingredientWriter) DetachAndDelete(ctx context.Context, i *entity.Ingredient) error {
+	if err := r.Repo.DetachAndDelete(ctx, i); err != nil {
+		return err
+	}
+	return r.add(ctx, event.EntityIngredient, i.ID, event.ActionDeleted, i)
+}
+
+func (r *ingredientWriter) Merge(ctx context.Context, target *entity.Ingredient, duplicates []int64) error {
+	if err := r.Repo.Merge(ctx, target, duplicates); err != nil {
+		return err
+	}
+	for _, id := range duplicates {
+		if err := r.add(ctx, event.EntityIngredient, id, event.ActionDeleted, &entity.Ingredient{ID: id}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type cookingUnitWriter struct {
+	cooking_unit.Repo
+	*writer
+}
+
+func (r *cookingUnitWriter) Add(ctx context.Context, u *entity.CookingUnit) error {
+	if err := r.Repo.Add(ctx, u); err != nil {
+		return err
+	}
+	return r.add(ctx, event.EntityCookingUnit, u.ID, event.ActionCreated, u)
+}
+
+func (r *cookingUnitWriter) Edit(ctx context.Context, u *entity.CookingUnit) error {
+	if err := r.Repo.Edit(ctx, u); err != nil {
+		return err
+	}
+	return r.add(ctx, event.EntityCookingUnit, u.ID, event.ActionUpdated, u)
+}
+
+func (r *cookingUnitWriter) Delete(ctx context.Context, u *entity.CookingUnit) error {
+	if err := r.Repo.Delete(ctx, u); err != nil {
+		return err
+	}
+	return r.add(ctx, event.EntityCookingUnit, u.ID, event.ActionDeleted, u)
+}
+
+// recipeTrashWriter adds the events of the recipes restored or purged from
+// the trash, the restored ones are read from recipes
+type recipeTrashWriter struct {
+	recipe.TrashRepo
+	recipes recipe.Repo
+	*writer
+}
+
+func (r *recipeTrashWriter) Restore(ctx context.Context, id int64) error {
+	if err := r.TrashRepo.Restore(ctx, id); err != nil {
+		return err
+	}
+	restored, err := r.recipes.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	return r.add(ctx, event.EntityRecipe, id, event.ActionRestored, restored)
+}
+
+func (r *recipeTrashWriter) Purge(ctx context.Context, before time.Time) ([]*entity.TrashItem, error) {
+	purged, err := r.TrashRepo.Purge(ctx, before)
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range purged {
+		if err := r.add(ctx, event.EntityRecipe, item.ID, event.ActionPurged, &entity.Recipe{ID: item.ID, Name: item.Name}); err != nil {
+			return nil, err
+		}
+	}
+	return purged, nil
+}
+
+// ingredientTrashWriter adds the events of the ingredients restored or purged
+// from the trash, the restored ones are read from ingredients
+type ingredientTrashWriter struct {
+	ingredient.TrashRepo
+	ingredients ingredient.Repo
+	*writer
+}
+
+func (r *ingredientTrashWriter) Restore(ctx context.Context, id int64) error {
+	if err := r.TrashRepo.Restore(ctx, id); err != nil {
+		return err
+	}
+	restored, err := r.ingredients.FindByID(ctx, int(id))
+	if err != nil {
+		return err
+	}
+	return r.add(ctx, event.EntityIngredient, id, event.ActionRestored, restored)
+}
+
+func (r *ingredientTrashWriter) Purge(ctx context.Context, before time.Time) ([]*entity.TrashItem, error) {
+	purged, err := r.TrashRepo.Purge(ctx, before)
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range purged {
+		if err := r.add(ctx, event.EntityIngredient, item.ID, event.ActionPurged, &entity.Ingredient{ID: item.ID, Name: item.Name}); err != nil {
+			return nil, err
+		}
+	}
+	return purged, nil
+}
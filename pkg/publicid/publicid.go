@@ -0,0 +1,99 @@
+// Package publicid encodes the autoincrement IDs of the database as opaque
+// public IDs, so they don't reveal how many rows there are and can't be
+// enumerated
+package publicid
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"strings"
+)
+
+// ErrInvalid is returned when decoding a string that no ID encodes to
+var ErrInvalid = errors.New("invalid public ID")
+
+// alphabet is Crockford's base32 in lowercase, without letters mistaken for digits
+const alphabet = "0123456789abcdefghjkmnpqrstvwxyz"
+
+// encodedLength is the number of characters holding 64 bits
+const encodedLength = 13
+
+// rounds of the Feistel network, four make a strong pseudorandom permutation
+const rounds = 4
+
+// Codec encodes IDs with a secret key, IDs encoded with another key don't
+// decode. Public IDs start with the prefix, which tells apart the entities.
+type Codec struct {
+	prefix string
+	key    []byte
+}
+
+func NewCodec(prefix string, key []byte) *Codec {
+	return &Codec{prefix: prefix, key: append([]byte(nil), key...)}
+}
+
+// Encode returns the public ID of the ID
+func (c *Codec) Encode(id int64) string {
+	block := c.permute(uint64(id), false)
+
+	var encoded [encodedLength]byte
+	for i := encodedLength - 1; i >= 0; i-- {
+		encoded[i] = alphabet[block&31]
+		block >>= 5
+	}
+	return c.prefix + string(encoded[:])
+}
+
+// Decode returns the ID of the public ID, or ErrInvalid
+func (c *Codec) Decode(publicID string) (int64, error) {
+	encoded, ok := strings.CutPrefix(strings.ToLower(publicID), c.prefix)
+	if !ok || len(encoded) != encodedLength {
+		return 0, ErrInvalid
+	}
+
+	var block uint64
+	for i := 0; i < encodedLength; i++ {
+		digit := strings.IndexByte(alphabet, encoded[i])
+		if digit < 0 {
+			return 0, ErrInvalid
+		}
+		// The first character only holds 4 bits
+		if i == 0 && digit > 15 {
+			return 0, ErrInvalid
+		}
+		block = block<<5 | uint64(digit)
+	}
+
+	id := int64(c.permute(block, true))
+	if id <= 0 {
+		return 0, ErrInvalid
+	}
+	return id, nil
+}
+
+// permute runs the block through a Feistel network keyed with HMAC-SHA256,
+// or back when inverse is set
+func (c *Codec) permute(block uint64, inverse bool) uint64 {
+	left, right := uint32(block>>32), uint32(block)
+	for i := 0; i < rounds; i++ {
+		round := i
+		if inverse {
+			round = rounds - 1 - i
+			left, right = right^c.round(round, left), left
+		} else {
+			left, right = right, left^c.round(round, right)
+		}
+	}
+	return uint64(left)<<32 | uint64(right)
+}
+
+func (c *Codec) round(round int, half uint32) uint32 {
+	var input [5]byte
+	input[0] = byte(round)
+	binary.BigEndian.PutUint32(input[1:], half)
+	mac := hmac.New(sha256.New, c.key)
+	mac.Write(input[:])
+	return binary.BigEndian.Uint32(mac.Sum(nil))
+}
@@ -0,0 +1,57 @@
+package publicid_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/TomeuUris/recipes-catalog/pkg/publicid"
+)
+
+func TestCodec_RoundTrip(t *testing.T) {
+	codec := publicid.NewCodec("r", []byte("secret"))
+	seen := map[string]bool{}
+	for _, id := range []int64{1, 2, 3, 42, 1 << 31, 1<<63 - 1} {
+		encoded := codec.Encode(id)
+		if !strings.HasPrefix(encoded, "r") || len(encoded) != 14 {
+			t.Fatalf("expected %d to encode to r and 13 characters, got %q", id, encoded)
+		}
+		if seen[encoded] {
+			t.Fatalf("expected unique public IDs, got %q twice", encoded)
+		}
+		seen[encoded] = true
+
+		decoded, err := codec.Decode(encoded)
+		if err != nil || decoded != id {
+			t.Fatalf("expected %q to decode to %d, got %d and %v", encoded, id, decoded, err)
+		}
+		// Case is ignored, IDs may be typed
+		if decoded, err := codec.Decode(strings.ToUpper(encoded)); err != nil || decoded != id {
+			t.Fatalf("expected %q to decode to %d ignoring case, got %d and %v", encoded, id, decoded, err)
+		}
+	}
+
+	// Consecutive IDs don't look consecutive
+	if first, second := codec.Encode(1), codec.Encode(2); first[:8] == second[:8] {
+		t.Fatalf("expected unrelated public IDs, got %q and %q", first, second)
+	}
+}
+
+func TestCodec_RejectsInvalid(t *testing.T) {
+	codec := publicid.NewCodec("r", []byte("secret"))
+	other := publicid.NewCodec("r", []byte("other secret"))
+	valid := codec.Encode(42)
+
+	for _, publicID := range []string{
+		"", "42", valid[1:], "x" + valid[1:], valid + "0", valid[:len(valid)-1] + "u", "rz" + valid[2:],
+	} {
+		if _, err := codec.Decode(publicID); !errors.Is(err, publicid.ErrInvalid) {
+			t.Fatalf("expected %q to be invalid, got %v", publicID, err)
+		}
+	}
+
+	// Another key decodes to another ID, if any
+	if id, err := other.Decode(valid); err == nil && id == 42 {
+		t.Fatalf("expected another key not to decode %q", valid)
+	}
+}
@@ -0,0 +1,25 @@
+package audit
+
+import (
+	"context"
+	"time"
+
+	"github.com/TomeuUris/recipes-catalog/pkg/entity"
+)
+
+// Repo stores the audit log, entries are never edited nor deleted
+type Repo interface {
+	// FindByFilter returns the matching entries, oldest first
+	FindByFilter(ctx context.Context, f *FindFilter) ([]*entity.AuditEntry, error)
+	Add(ctx context.Context, entry *entity.AuditEntry) error
+}
+
+type FindFilter struct {
+	Entity   string `form:"entity"`
+	EntityID int64  `form:"id"`
+	Actor    string `form:"actor"`
+	// Since and Until bound the time of the entries, both included
+	Since *time.Time `form:"since" time_format:"2006-01-02T15:04:05Z07:00"`
+	Until *time.Time `form:"until" time_format:"2006-01-02T15:04:05Z07:00"`
+	Limit int        `form:"limit"`
+}
@@ -0,0 +1,52 @@
+package audit_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/TomeuUris/recipes-catalog/pkg/repo/audit"
+	"github.com/TomeuUris/recipes-catalog/pkg/repo/repotest"
+	"github.com/TomeuUris/recipes-catalog/pkg/repo/schema"
+	"github.com/TomeuUris/recipes-catalog/pkg/sqlite"
+	"github.com/TomeuUris/recipes-catalog/pkg/sqlite/migrations"
+	gormSqlite "gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestRepoGorm_Conformance(t *testing.T) {
+	repotest.TestAuditRepo(t, func(t *testing.T) audit.Repo {
+		db, err := gorm.Open(&gormSqlite.Dialector{DriverName: sqlite.DriverName, DSN: "file::memory:"}, &gorm.Config{Logger: logger.Discard})
+		if err != nil {
+			t.Fatalf("failed to connect database: %v", err)
+		}
+		sqlDB, err := db.DB()
+		if err != nil {
+			t.Fatalf("failed to get database: %v", err)
+		}
+		// Every connection to :memory: opens a new database
+		sqlDB.SetMaxOpenConns(1)
+		t.Cleanup(func() { sqlDB.Close() })
+		if err := schema.Migrate(context.Background(), db); err != nil {
+			t.Fatalf("failed to migrate database: %v", err)
+		}
+		return audit.NewGormRepo(db)
+	})
+}
+
+func TestRepoSQL_Conformance(t *testing.T) {
+	repotest.TestAuditRepo(t, func(t *testing.T) audit.Repo {
+		sqlDB, err := sqlite.Open(":memory:")
+		if err != nil {
+			t.Fatalf("failed to open database: %v", err)
+		}
+		// Every connection to :memory: opens a new database
+		sqlDB.SetMaxOpenConns(1)
+		t.Cleanup(func() { sqlDB.Close() })
+
+		if err := migrations.Store.Apply(context.Background(), sqlDB); err != nil {
+			t.Fatalf("failed to migrate database: %v", err)
+		}
+		return audit.NewRepo(sqlDB)
+	})
+}
@@ -0,0 +1,109 @@
+package audit
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/TomeuUris/recipes-catalog/pkg/entity"
+)
+
+// Database model
+type AuditEntry struct {
+	ID        uint   `gorm:"primarykey"`
+	Entity    string `gorm:"index:idx_audit_entity"`
+	EntityID  int64  `gorm:"index:idx_audit_entity"`
+	Action    string
+	Actor     string
+	RequestID string
+	Before    string
+	After     string
+	At        time.Time `gorm:"index"`
+}
+
+func (a *AuditEntry) ToEntity() *entity.AuditEntry {
+	return &entity.AuditEntry{
+		ID:        int64(a.ID),
+		Entity:    a.Entity,
+		EntityID:  a.EntityID,
+		Action:    a.Action,
+		Actor:     a.Actor,
+		RequestID: a.RequestID,
+		Before:    a.Before,
+		After:     a.After,
+		At:        a.At,
+	}
+}
+
+func (a *AuditEntry) FromEntity(entry *entity.AuditEntry) {
+	a.ID = uint(entry.ID)
+	a.Entity = entry.Entity
+	a.EntityID = entry.EntityID
+	a.Action = entry.Action
+	a.Actor = entry.Actor
+	a.RequestID = entry.RequestID
+	a.Before = entry.Before
+	a.After = entry.After
+	// SQLite compares times as text, they must share the time zone
+	a.At = entry.At.UTC()
+}
+
+// Repository implementation
+type RepoGorm struct {
+	db *gorm.DB
+}
+
+// Utility functions
+func NewGormRepo(db *gorm.DB) *RepoGorm {
+	return &RepoGorm{
+		db: db,
+	}
+}
+
+func RunMigrations(db *gorm.DB) error {
+	return db.AutoMigrate(&AuditEntry{})
+}
+
+// CRUD functions
+func (r *RepoGorm) FindByFilter(ctx context.Context, f *FindFilter) ([]*entity.AuditEntry, error) {
+	query := r.db.WithContext(ctx).Order("at, id")
+	if f.Entity != "" {
+		query = query.Where("entity = ?", f.Entity)
+	}
+	if f.EntityID != 0 {
+		query = query.Where("entity_id = ?", f.EntityID)
+	}
+	if f.Actor != "" {
+		query = query.Where("actor = ?", f.Actor)
+	}
+	if f.Since != nil {
+		query = query.Where("at >= ?", f.Since.UTC())
+	}
+	if f.Until != nil {
+		query = query.Where("at <= ?", f.Until.UTC())
+	}
+	if f.Limit > 0 {
+		query = query.Limit(f.Limit)
+	}
+
+	var entries []*AuditEntry
+	if err := query.Find(&entries).Error; err != nil {
+		return nil, err
+	}
+	result := make([]*entity.AuditEntry, len(entries))
+	for i, entry := range entries {
+		result[i] = entry.ToEntity()
+	}
+	return result, nil
+}
+
+func (r *RepoGorm) Add(ctx context.Context, entry *entity.AuditEntry) error {
+	a := &AuditEntry{}
+	a.FromEntity(entry)
+	if err := r.db.WithContext(ctx).Create(a).Error; err != nil {
+		return err
+	}
+	entry.ID = int64(a.ID)
+	return nil
+}
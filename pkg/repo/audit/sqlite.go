@@ -0,0 +1,90 @@
+package audit
+
+import (
+	"context"
+	"strings"
+
+	"github.com/TomeuUris/recipes-catalog/pkg/entity"
+	"github.com/TomeuUris/recipes-catalog/pkg/sqlite"
+)
+
+func NewRepo(db sqlite.DB) *RepoSQL {
+	return NewConnRepo(sqlite.NewConn(db))
+}
+
+// NewConnRepo returns a repository on the connection, which may run on a
+// transaction shared with other repositories
+func NewConnRepo(conn *sqlite.Conn) *RepoSQL {
+	return &RepoSQL{
+		db: conn,
+	}
+}
+
+// RepoSQL stores the entries in the audit_entries table, which keeps the
+// layout of the gorm repository
+type RepoSQL struct {
+	db *sqlite.Conn
+}
+
+func (r *RepoSQL) FindByFilter(ctx context.Context, f *FindFilter) ([]*entity.AuditEntry, error) {
+	var conditions []string
+	var args []interface{}
+	if f.Entity != "" {
+		conditions = append(conditions, "entity = ?")
+		args = append(args, f.Entity)
+	}
+	if f.EntityID != 0 {
+		conditions = append(conditions, "entity_id = ?")
+		args = append(args, f.EntityID)
+	}
+	if f.Actor != "" {
+		conditions = append(conditions, "actor = ?")
+		args = append(args, f.Actor)
+	}
+	if f.Since != nil {
+		conditions = append(conditions, "at >= ?")
+		args = append(args, f.Since.UTC())
+	}
+	if f.Until != nil {
+		conditions = append(conditions, "at <= ?")
+		args = append(args, f.Until.UTC())
+	}
+
+	query := "SELECT id, entity, entity_id, action, actor, request_id, `before`, `after`, at FROM audit_entries"
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += " ORDER BY at, id"
+	if f.Limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, f.Limit)
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []*entity.AuditEntry{}
+	for rows.Next() {
+		entry := &entity.AuditEntry{}
+		if err := rows.Scan(&entry.ID, &entry.Entity, &entry.EntityID, &entry.Action, &entry.Actor,
+			&entry.RequestID, &entry.Before, &entry.After, &entry.At); err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	return entries, rows.Err()
+}
+
+func (r *RepoSQL) Add(ctx context.Context, entry *entity.AuditEntry) error {
+	// SQLite compares times as text, they must share the time zone
+	result, err := r.db.ExecContext(ctx, "INSERT INTO audit_entries (entity, entity_id, action, actor, request_id, `before`, `after`, at) VALUES (?, ?, ?, ?, ?, ?, ?, ?)",
+		entry.Entity, entry.EntityID, entry.Action, entry.Actor, entry.RequestID, entry.Before, entry.After, entry.At.UTC())
+	if err != nil {
+		return err
+	}
+	entry.ID, err = result.LastInsertId()
+	return err
+}
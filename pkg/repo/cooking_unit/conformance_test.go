@@ -0,0 +1,22 @@
+package cooking_unit_test
+
+import (
+	"testing"
+
+	"github.com/TomeuUris/recipes-catalog/pkg/repo/cooking_unit"
+	"github.com/TomeuUris/recipes-catalog/pkg/repo/repotest"
+)
+
+func TestRepoGorm_Conformance(t *testing.T) {
+	repotest.TestCookingUnitRepo(t, func(t *testing.T) cooking_unit.Repo {
+		tx := db.Begin()
+		t.Cleanup(func() { tx.Rollback() })
+		return cooking_unit.NewGormRepo(tx)
+	})
+}
+
+func TestRepoSQL_Conformance(t *testing.T) {
+	repotest.TestCookingUnitRepo(t, func(t *testing.T) cooking_unit.Repo {
+		return newSQLRepo(t)
+	})
+}
@@ -7,12 +7,16 @@ import (
 	"gorm.io/gorm"
 
 	"github.com/TomeuUris/recipes-catalog/pkg/entity"
+	"github.com/TomeuUris/recipes-catalog/pkg/sqlite"
 )
 
 // Database model
 type CookingUnit struct {
 	ID   uint `gorm:"primaryKey"`
 	Name string
+	// NameKey is the normalized unique name. Duplicates created before it
+	// existed have none until merged.
+	NameKey *string `gorm:"uniqueIndex"`
 }
 
 func (i *CookingUnit) ToEntity() *entity.CookingUnit {
@@ -25,6 +29,8 @@ func (i *CookingUnit) ToEntity() *entity.CookingUnit {
 func (i *CookingUnit) FromEntity(unit *entity.CookingUnit) {
 	i.ID = uint(unit.ID)
 	i.Name = unit.Name
+	key := entity.NormalizeName(unit.Name)
+	i.NameKey = &key
 }
 
 // Repository implementation
@@ -40,9 +46,41 @@ func NewGormRepo(db *gorm.DB) *RepoGorm {
 }
 
 func RunMigrations(db *gorm.DB) error {
+	// The name keys must be filled before the unique index is built
+	migrator := db.Migrator()
+	if migrator.HasTable(&CookingUnit{}) && !migrator.HasColumn(&CookingUnit{}, "NameKey") {
+		if err := migrator.AddColumn(&CookingUnit{}, "NameKey"); err != nil {
+			return err
+		}
+		if err := fillNameKeys(db); err != nil {
+			return err
+		}
+	}
 	return db.AutoMigrate(&CookingUnit{})
 }
 
+// fillNameKeys sets the name key of the existing cooking units but the
+// duplicates, which would break the unique index
+func fillNameKeys(db *gorm.DB) error {
+	var units []*CookingUnit
+	if err := db.Order("id").Find(&units).Error; err != nil {
+		return err
+	}
+
+	taken := map[string]bool{}
+	for _, unit := range units {
+		key := entity.NormalizeName(unit.Name)
+		if taken[key] {
+			continue
+		}
+		taken[key] = true
+		if err := db.Model(&CookingUnit{}).Where("id = ?", unit.ID).Update("name_key", key).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // CRUD functions
 func (r *RepoGorm) FindByID(ctx context.Context, id int) (*entity.CookingUnit, error) {
 	unit := &CookingUnit{}
@@ -82,6 +120,9 @@ func (r *RepoGorm) Add(ctx context.Context, unit *entity.CookingUnit) error {
 	i.FromEntity(unit)
 	err := r.db.WithContext(ctx).Create(i).Error
 	if err != nil {
+		if sqlite.IsUniqueViolation(err) {
+			return r.alreadyExists(ctx, unit)
+		}
 		return err
 	}
 	unit.ID = int64(i.ID)
@@ -89,27 +130,42 @@ func (r *RepoGorm) Add(ctx context.Context, unit *entity.CookingUnit) error {
 }
 
 func (r *RepoGorm) Edit(ctx context.Context, unit *entity.CookingUnit) error {
-	i := &CookingUnit{}
-	i.FromEntity(unit)
-	err := r.db.WithContext(ctx).Save(i).Error
-	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return entity.ErrNotFound
+	// Save would insert a missing cooking unit
+	result := r.db.WithContext(ctx).Model(&CookingUnit{}).Where("id = ?", unit.ID).
+		Updates(map[string]interface{}{
+			"name":     unit.Name,
+			"name_key": entity.NormalizeName(unit.Name),
+		})
+	if result.Error != nil {
+		if sqlite.IsUniqueViolation(result.Error) {
+			return r.alreadyExists(ctx, unit)
 		}
-		return err
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return entity.ErrNotFound
 	}
 	return nil
 }
 
 func (r *RepoGorm) Delete(ctx context.Context, unit *entity.CookingUnit) error {
-	i := &CookingUnit{}
-	i.FromEntity(unit)
-	err := r.db.WithContext(ctx).Delete(i).Error
-	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return entity.ErrNotFound
-		}
-		return err
+	result := r.db.WithContext(ctx).Delete(&CookingUnit{}, unit.ID)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return entity.ErrNotFound
 	}
 	return nil
 }
+
+// alreadyExists returns the *entity.AlreadyExistsError of the cooking unit
+// holding the name of the given one
+func (r *RepoGorm) alreadyExists(ctx context.Context, unit *entity.CookingUnit) error {
+	existing := &CookingUnit{}
+	if err := r.db.WithContext(ctx).Where("name_key = ?", entity.NormalizeName(unit.Name)).
+		First(existing).Error; err != nil {
+		return err
+	}
+	return &entity.AlreadyExistsError{ID: int64(existing.ID)}
+}
@@ -0,0 +1,145 @@
+package cooking_unit
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/TomeuUris/recipes-catalog/pkg/entity"
+)
+
+// RepoMemory keeps the cooking units in memory, it is safe for concurrent use
+type RepoMemory struct {
+	mu     sync.RWMutex
+	lastID int64
+	units  map[int64]*entity.CookingUnit
+}
+
+func NewMemoryRepo() *RepoMemory {
+	return &RepoMemory{
+		units: map[int64]*entity.CookingUnit{},
+	}
+}
+
+func (r *RepoMemory) FindByID(ctx context.Context, id int) (*entity.CookingUnit, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	unit, ok := r.units[int64(id)]
+	if !ok {
+		return nil, entity.ErrNotFound
+	}
+	copied := *unit
+	return &copied, nil
+}
+
+func (r *RepoMemory) FindByFilter(ctx context.Context, f *FindFilter) ([]*entity.CookingUnit, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.filter(f), nil
+}
+
+func (r *RepoMemory) CountByFilter(f *FindFilter) (int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return len(r.filter(f)), nil
+}
+
+func (r *RepoMemory) Add(ctx context.Context, unit *entity.CookingUnit) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.checkUnique(unit); err != nil {
+		return err
+	}
+	r.lastID++
+	unit.ID = r.lastID
+	copied := *unit
+	r.units[unit.ID] = &copied
+	return nil
+}
+
+func (r *RepoMemory) Edit(ctx context.Context, unit *entity.CookingUnit) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.units[unit.ID]; !ok {
+		return entity.ErrNotFound
+	}
+	if err := r.checkUnique(unit); err != nil {
+		return err
+	}
+	copied := *unit
+	r.units[unit.ID] = &copied
+	return nil
+}
+
+func (r *RepoMemory) Delete(ctx context.Context, unit *entity.CookingUnit) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.units[unit.ID]; !ok {
+		return entity.ErrNotFound
+	}
+	delete(r.units, unit.ID)
+	return nil
+}
+
+// Snapshot returns every cooking unit ordered by ID
+func (r *RepoMemory) Snapshot() []*entity.CookingUnit {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.filter(nil)
+}
+
+// Restore replaces the cooking units with the ones of a snapshot
+func (r *RepoMemory) Restore(units []*entity.CookingUnit) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.units = make(map[int64]*entity.CookingUnit, len(units))
+	r.lastID = 0
+	for _, unit := range units {
+		copied := *unit
+		r.units[unit.ID] = &copied
+		if unit.ID > r.lastID {
+			r.lastID = unit.ID
+		}
+	}
+}
+
+// checkUnique returns an *entity.AlreadyExistsError when another cooking unit
+// has the same normalized name. The caller must hold the lock.
+func (r *RepoMemory) checkUnique(unit *entity.CookingUnit) error {
+	key := entity.NormalizeName(unit.Name)
+	var holder int64
+	for id, stored := range r.units {
+		if id != unit.ID && entity.NormalizeName(stored.Name) == key && (holder == 0 || id < holder) {
+			holder = id
+		}
+	}
+	if holder != 0 {
+		return &entity.AlreadyExistsError{ID: holder}
+	}
+	return nil
+}
+
+// filter returns copies of the matching cooking units ordered by ID, empty fields
+// are not filtered like in gorm. The caller must hold the lock.
+func (r *RepoMemory) filter(f *FindFilter) []*entity.CookingUnit {
+	result := []*entity.CookingUnit{}
+	for _, unit := range r.units {
+		if f != nil && f.Name != "" && unit.Name != f.Name {
+			continue
+		}
+		copied := *unit
+		result = append(result, &copied)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].ID < result[j].ID
+	})
+	return result
+}
@@ -0,0 +1,14 @@
+package cooking_unit_test
+
+import (
+	"testing"
+
+	"github.com/TomeuUris/recipes-catalog/pkg/repo/cooking_unit"
+	"github.com/TomeuUris/recipes-catalog/pkg/repo/repotest"
+)
+
+func TestRepoMemory_Conformance(t *testing.T) {
+	repotest.TestCookingUnitRepo(t, func(t *testing.T) cooking_unit.Repo {
+		return cooking_unit.NewMemoryRepo()
+	})
+}
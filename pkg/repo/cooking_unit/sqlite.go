@@ -0,0 +1,151 @@
+package cooking_unit
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+
+	"github.com/TomeuUris/recipes-catalog/pkg/entity"
+	"github.com/TomeuUris/recipes-catalog/pkg/sqlite"
+)
+
+func NewRepo(db sqlite.DB) *RepoSQL {
+	return NewConnRepo(sqlite.NewConn(db))
+}
+
+// NewConnRepo returns a repository on the connection, which may run on a
+// transaction shared with other repositories
+func NewConnRepo(conn *sqlite.Conn) *RepoSQL {
+	return &RepoSQL{
+		db: conn,
+	}
+}
+
+type RepoSQL struct {
+	db *sqlite.Conn
+}
+
+func (r *RepoSQL) FindByID(ctx context.Context, id int) (*entity.CookingUnit, error) {
+	// Get cooking unit from database
+	row := r.db.QueryRowContext(ctx, `SELECT id, name FROM cookingUnits WHERE id = ?`, id)
+
+	unit := &entity.CookingUnit{}
+	if err := row.Scan(&unit.ID, &unit.Name); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, entity.ErrNotFound
+		}
+		return nil, err
+	}
+
+	return unit, nil
+}
+
+func (r *RepoSQL) FindByFilter(ctx context.Context, f *FindFilter) ([]*entity.CookingUnit, error) {
+	where, args := filterClause(f)
+	rows, err := r.db.QueryContext(ctx, `SELECT id, name FROM cookingUnits`+where+` ORDER BY id`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	units := []*entity.CookingUnit{}
+	for rows.Next() {
+		unit := &entity.CookingUnit{}
+		if err := rows.Scan(&unit.ID, &unit.Name); err != nil {
+			return nil, err
+		}
+		units = append(units, unit)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return units, nil
+}
+
+func (r *RepoSQL) CountByFilter(f *FindFilter) (int, error) {
+	where, args := filterClause(f)
+
+	var count int
+	if err := r.db.QueryRow(`SELECT COUNT(*) FROM cookingUnits`+where, args...).Scan(&count); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func (r *RepoSQL) Add(ctx context.Context, unit *entity.CookingUnit) error {
+	result, err := r.db.ExecContext(ctx, `INSERT INTO cookingUnits (name, nameKey) VALUES (?, ?)`,
+		unit.Name, entity.NormalizeName(unit.Name))
+	if err != nil {
+		if sqlite.IsUniqueViolation(err) {
+			return r.alreadyExists(ctx, unit)
+		}
+		return err
+	}
+
+	unit.ID, err = result.LastInsertId()
+	return err
+}
+
+func (r *RepoSQL) Edit(ctx context.Context, unit *entity.CookingUnit) error {
+	result, err := r.db.ExecContext(ctx, `UPDATE cookingUnits SET name = ?, nameKey = ? WHERE id = ?`,
+		unit.Name, entity.NormalizeName(unit.Name), unit.ID)
+	if err != nil {
+		if sqlite.IsUniqueViolation(err) {
+			return r.alreadyExists(ctx, unit)
+		}
+		return err
+	}
+	return expectAffected(result)
+}
+
+func (r *RepoSQL) Delete(ctx context.Context, unit *entity.CookingUnit) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM cookingUnits WHERE id = ?`, unit.ID)
+	if err != nil {
+		return err
+	}
+	return expectAffected(result)
+}
+
+// alreadyExists returns the *entity.AlreadyExistsError of the cooking unit
+// holding the name of the given one
+func (r *RepoSQL) alreadyExists(ctx context.Context, unit *entity.CookingUnit) error {
+	var id int64
+	if err := r.db.QueryRowContext(ctx, `SELECT id FROM cookingUnits WHERE nameKey = ?`,
+		entity.NormalizeName(unit.Name)).Scan(&id); err != nil {
+		return err
+	}
+	return &entity.AlreadyExistsError{ID: id}
+}
+
+// filterClause builds the WHERE clause of the filter, empty fields are not filtered like in gorm
+func filterClause(f *FindFilter) (string, []interface{}) {
+	if f == nil {
+		return "", nil
+	}
+
+	var conditions []string
+	var args []interface{}
+	if f.Name != "" {
+		conditions = append(conditions, "name = ?")
+		args = append(args, f.Name)
+	}
+
+	if len(conditions) == 0 {
+		return "", nil
+	}
+	return " WHERE " + strings.Join(conditions, " AND "), args
+}
+
+// expectAffected returns entity.ErrNotFound when the statement didn't match any row
+func expectAffected(result sql.Result) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return entity.ErrNotFound
+	}
+	return nil
+}
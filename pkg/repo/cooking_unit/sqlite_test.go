@@ -0,0 +1,178 @@
+package cooking_unit_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/TomeuUris/recipes-catalog/pkg/entity"
+	"github.com/TomeuUris/recipes-catalog/pkg/repo/cooking_unit"
+	"github.com/TomeuUris/recipes-catalog/pkg/sqlite"
+	"github.com/TomeuUris/recipes-catalog/pkg/sqlite/migrations"
+)
+
+func newSQLRepo(t *testing.T) *cooking_unit.RepoSQL {
+	sqlDB, err := sqlite.Open(":memory:")
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	// Every connection to :memory: opens a new database
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+
+	if err := migrations.Store.Apply(context.Background(), sqlDB); err != nil {
+		t.Fatalf("failed to migrate database: %v", err)
+	}
+	return cooking_unit.NewRepo(sqlDB)
+}
+
+func TestRepoSQL_FindByFilter(t *testing.T) {
+	repo := newSQLRepo(t)
+	ctx := context.Background()
+
+	// Create sample cooking units
+	for _, unit := range []*entity.CookingUnit{
+		{Name: "gram"},
+		{Name: "litre"},
+		{Name: "cup"},
+	} {
+		if err := repo.Add(ctx, unit); err != nil {
+			t.Fatalf("failed to add cooking unit: %v", err)
+		}
+	}
+
+	// Find the cooking units
+	unitsFound, err := repo.FindByFilter(ctx, &cooking_unit.FindFilter{Name: "gram"})
+	if err != nil {
+		t.Fatalf("failed to find cooking units: %v", err)
+	}
+	if len(unitsFound) != 1 {
+		t.Fatalf("expected 1 cooking unit, got %d", len(unitsFound))
+	}
+	for _, unitFound := range unitsFound {
+		if unitFound.Name != "gram" || unitFound.ID == 0 {
+			t.Fatalf("unexpected cooking unit %+v", unitFound)
+		}
+	}
+
+	// An empty filter matches every cooking unit
+	unitsFound, err = repo.FindByFilter(ctx, &cooking_unit.FindFilter{})
+	if err != nil {
+		t.Fatalf("failed to find cooking units: %v", err)
+	}
+	if len(unitsFound) != 3 {
+		t.Fatalf("expected 3 cooking units, got %d", len(unitsFound))
+	}
+}
+
+func TestRepoSQL_CountByFilter(t *testing.T) {
+	repo := newSQLRepo(t)
+
+	// Create sample cooking units
+	for _, unit := range []*entity.CookingUnit{
+		{Name: "gram"},
+		{Name: "litre"},
+	} {
+		if err := repo.Add(context.Background(), unit); err != nil {
+			t.Fatalf("failed to add cooking unit: %v", err)
+		}
+	}
+
+	count, err := repo.CountByFilter(&cooking_unit.FindFilter{Name: "gram"})
+	if err != nil {
+		t.Fatalf("failed to count cooking units: %v", err)
+	}
+	if count != 1 {
+		t.Fatalf("expected 1 cooking unit, got %d", count)
+	}
+
+	count, err = repo.CountByFilter(&cooking_unit.FindFilter{})
+	if err != nil {
+		t.Fatalf("failed to count cooking units: %v", err)
+	}
+	if count != 2 {
+		t.Fatalf("expected 2 cooking units, got %d", count)
+	}
+}
+
+func TestRepoSQL_FindByID(t *testing.T) {
+	repo := newSQLRepo(t)
+	ctx := context.Background()
+
+	// Add the cooking unit
+	unitExample := getExampleCookingUnitEntity()
+	if err := repo.Add(ctx, unitExample); err != nil {
+		t.Fatalf("failed to add cooking unit: %v", err)
+	}
+
+	// Find the cooking unit
+	unitFound, err := repo.FindByID(ctx, int(unitExample.ID))
+	if err != nil {
+		t.Fatalf("failed to find cooking unit: %v", err)
+	}
+	if *unitFound != *unitExample {
+		t.Fatalf("expected cooking unit %+v, got %+v", unitExample, unitFound)
+	}
+
+	// Missing cooking units are not found
+	if _, err := repo.FindByID(ctx, int(unitExample.ID)+1); !entity.IsErrNotFound(err) {
+		t.Fatalf("expected not found error, got %v", err)
+	}
+}
+
+func TestRepoSQL_Edit(t *testing.T) {
+	repo := newSQLRepo(t)
+	ctx := context.Background()
+
+	// Add the cooking unit
+	unitExample := getExampleCookingUnitEntity()
+	if err := repo.Add(ctx, unitExample); err != nil {
+		t.Fatalf("failed to add cooking unit: %v", err)
+	}
+
+	// Edit the cooking unit
+	unitExample.Name = "kilogram"
+	if err := repo.Edit(ctx, unitExample); err != nil {
+		t.Fatalf("failed to edit cooking unit: %v", err)
+	}
+
+	// Check if cooking unit was edited
+	unitFound, err := repo.FindByID(ctx, int(unitExample.ID))
+	if err != nil {
+		t.Fatalf("failed to find cooking unit: %v", err)
+	}
+	if unitFound.Name != unitExample.Name {
+		t.Fatalf("expected cooking unit name to be %s, got %s", unitExample.Name, unitFound.Name)
+	}
+
+	// Missing cooking units can't be edited
+	missing := &entity.CookingUnit{ID: unitExample.ID + 1, Name: "missing"}
+	if err := repo.Edit(ctx, missing); !entity.IsErrNotFound(err) {
+		t.Fatalf("expected not found error, got %v", err)
+	}
+}
+
+func TestRepoSQL_Delete(t *testing.T) {
+	repo := newSQLRepo(t)
+	ctx := context.Background()
+
+	// Add the cooking unit
+	unitExample := getExampleCookingUnitEntity()
+	if err := repo.Add(ctx, unitExample); err != nil {
+		t.Fatalf("failed to add cooking unit: %v", err)
+	}
+
+	// Delete the cooking unit
+	if err := repo.Delete(ctx, unitExample); err != nil {
+		t.Fatalf("failed to delete cooking unit: %v", err)
+	}
+
+	// Check if cooking unit was deleted
+	if _, err := repo.FindByID(ctx, int(unitExample.ID)); !entity.IsErrNotFound(err) {
+		t.Fatalf("expected not found error, got %v", err)
+	}
+
+	// Deleting it again fails
+	if err := repo.Delete(ctx, unitExample); !entity.IsErrNotFound(err) {
+		t.Fatalf("expected not found error, got %v", err)
+	}
+}
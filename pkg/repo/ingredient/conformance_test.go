@@ -0,0 +1,22 @@
+package ingredient_test
+
+import (
+	"testing"
+
+	"github.com/TomeuUris/recipes-catalog/pkg/repo/ingredient"
+	"github.com/TomeuUris/recipes-catalog/pkg/repo/repotest"
+)
+
+func TestRepoGorm_Conformance(t *testing.T) {
+	repotest.TestIngredientRepo(t, func(t *testing.T) ingredient.Repo {
+		tx := db.Begin()
+		t.Cleanup(func() { tx.Rollback() })
+		return ingredient.NewGormRepo(tx)
+	})
+}
+
+func TestRepoSQL_Conformance(t *testing.T) {
+	repotest.TestIngredientRepo(t, func(t *testing.T) ingredient.Repo {
+		return newSQLRepo(t)
+	})
+}
@@ -0,0 +1,72 @@
+package ingredient
+
+import (
+	"sort"
+
+	"github.com/TomeuUris/recipes-catalog/pkg/entity"
+)
+
+// DefaultMinSimilarity is the name similarity from which ingredients are
+// reported as likely duplicates
+const DefaultMinSimilarity = 0.8
+
+// FindDuplicates groups the ingredients whose names are at least minSimilarity
+// similar, transitively, whatever their type. Groups are ordered by their first
+// ingredient, which is the natural merge target.
+func FindDuplicates(ingredients []*entity.Ingredient, minSimilarity float64) []*entity.DuplicateGroup {
+	sorted := append([]*entity.Ingredient(nil), ingredients...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].ID < sorted[j].ID
+	})
+
+	// Union-find of the similar pairs, rooted at the lowest index
+	parent := make([]int, len(sorted))
+	similarity := make([]float64, len(sorted))
+	for i := range parent {
+		parent[i] = i
+		similarity[i] = 1
+	}
+	var root func(i int) int
+	root = func(i int) int {
+		if parent[i] != i {
+			parent[i] = root(parent[i])
+		}
+		return parent[i]
+	}
+
+	for i := range sorted {
+		for j := i + 1; j < len(sorted); j++ {
+			pair := entity.NameSimilarity(sorted[i].Name, sorted[j].Name)
+			if pair < minSimilarity {
+				continue
+			}
+			ri, rj := root(i), root(j)
+			if ri > rj {
+				ri, rj = rj, ri
+			}
+			parent[rj] = ri
+			similarity[ri] = min(similarity[ri], similarity[rj], pair)
+		}
+	}
+
+	groups := map[int]*entity.DuplicateGroup{}
+	var all []*entity.DuplicateGroup
+	for i, ingredient := range sorted {
+		r := root(i)
+		group, ok := groups[r]
+		if !ok {
+			group = &entity.DuplicateGroup{Similarity: similarity[r]}
+			groups[r] = group
+			all = append(all, group)
+		}
+		group.Ingredients = append(group.Ingredients, ingredient)
+	}
+
+	duplicates := []*entity.DuplicateGroup{}
+	for _, group := range all {
+		if len(group.Ingredients) > 1 {
+			duplicates = append(duplicates, group)
+		}
+	}
+	return duplicates
+}
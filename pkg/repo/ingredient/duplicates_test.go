@@ -0,0 +1,42 @@
+package ingredient_test
+
+import (
+	"testing"
+
+	"github.com/TomeuUris/recipes-catalog/pkg/entity"
+	"github.com/TomeuUris/recipes-catalog/pkg/repo/ingredient"
+)
+
+func TestFindDuplicates(t *testing.T) {
+	ingredients := []*entity.Ingredient{
+		{ID: 4, Name: "Tomatoes", Type: "Vegetable"},
+		{ID: 1, Name: "Tomàquet", Type: "Vegetable"},
+		{ID: 2, Name: "Salt", Type: "Spice"},
+		{ID: 3, Name: "tomaquet", Type: "Sauce"},
+		{ID: 5, Name: "Tomaquets", Type: "Vegetable"},
+	}
+
+	groups := ingredient.FindDuplicates(ingredients, ingredient.DefaultMinSimilarity)
+	if len(groups) != 1 {
+		t.Fatalf("expected 1 group of duplicates, got %d", len(groups))
+	}
+
+	// Grouped transitively and ordered by ID, Tomatoes is too different
+	group := groups[0]
+	var ids []int64
+	for _, duplicate := range group.Ingredients {
+		ids = append(ids, duplicate.ID)
+	}
+	if len(ids) != 3 || ids[0] != 1 || ids[1] != 3 || ids[2] != 5 {
+		t.Fatalf("expected ingredients 1, 3 and 5 to be grouped, got %v", ids)
+	}
+	if group.Similarity >= 1 || group.Similarity < ingredient.DefaultMinSimilarity {
+		t.Fatalf("expected the similarity of Tomaquets and tomaquet, got %f", group.Similarity)
+	}
+
+	// Only equal names
+	groups = ingredient.FindDuplicates(ingredients, 1)
+	if len(groups) != 1 || len(groups[0].Ingredients) != 2 || groups[0].Similarity != 1 {
+		t.Fatalf("expected Tomàquet and tomaquet to be grouped, got %+v", groups)
+	}
+}
@@ -3,17 +3,23 @@ package ingredient
 import (
 	"context"
 	"errors"
+	"fmt"
+	"time"
 
 	"gorm.io/gorm"
 
 	"github.com/TomeuUris/recipes-catalog/pkg/entity"
+	"github.com/TomeuUris/recipes-catalog/pkg/sqlite"
 )
 
 // Database model
 type Ingredient struct {
 	gorm.Model
 	Name string
-	Type string
+	// NameKey is the normalized name, unique per type among the ingredients not
+	// deleted. Duplicates created before it existed have none until merged.
+	NameKey *string `gorm:"uniqueIndex:idx_ingredients_name_key,where:deleted_at IS NULL"`
+	Type    string  `gorm:"uniqueIndex:idx_ingredients_name_key,where:deleted_at IS NULL"`
 }
 
 func (i *Ingredient) ToEntity() *entity.Ingredient {
@@ -27,6 +33,8 @@ func (i *Ingredient) ToEntity() *entity.Ingredient {
 func (i *Ingredient) FromEntity(ingredient *entity.Ingredient) {
 	i.ID = uint(ingredient.ID)
 	i.Name = ingredient.Name
+	key := entity.NormalizeName(ingredient.Name)
+	i.NameKey = &key
 	i.Type = ingredient.Type
 }
 
@@ -43,9 +51,46 @@ func NewGormRepo(db *gorm.DB) *RepoGorm {
 }
 
 func RunMigrations(db *gorm.DB) error {
+	// The name keys must be filled before the unique index is built
+	migrator := db.Migrator()
+	if migrator.HasTable(&Ingredient{}) && !migrator.HasColumn(&Ingredient{}, "NameKey") {
+		if err := migrator.AddColumn(&Ingredient{}, "NameKey"); err != nil {
+			return err
+		}
+		if err := fillNameKeys(db); err != nil {
+			return err
+		}
+	}
 	return db.AutoMigrate(&Ingredient{})
 }
 
+// fillNameKeys sets the name key of the existing ingredients but the
+// duplicates, which would break the unique index
+func fillNameKeys(db *gorm.DB) error {
+	var ingredients []*Ingredient
+	if err := db.Unscoped().Order("id").Find(&ingredients).Error; err != nil {
+		return err
+	}
+
+	taken := map[[2]string]bool{}
+	for _, ingredient := range ingredients {
+		key := entity.NormalizeName(ingredient.Name)
+		// Deleted ingredients are out of the index
+		if !ingredient.DeletedAt.Valid {
+			indexed := [2]string{key, ingredient.Type}
+			if taken[indexed] {
+				continue
+			}
+			taken[indexed] = true
+		}
+		if err := db.Unscoped().Model(&Ingredient{}).Where("id = ?", ingredient.ID).
+			Update("name_key", key).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // CRUD functions
 func (r *RepoGorm) FindByID(ctx context.Context, id int) (*entity.Ingredient, error) {
 	ingredient := &Ingredient{}
@@ -85,6 +130,9 @@ func (r *RepoGorm) Add(ctx context.Context, ingredient *entity.Ingredient) error
 	i.FromEntity(ingredient)
 	err := r.db.WithContext(ctx).Create(i).Error
 	if err != nil {
+		if sqlite.IsUniqueViolation(err) {
+			return r.alreadyExists(ctx, ingredient)
+		}
 		return err
 	}
 	ingredient.ID = int64(i.ID)
@@ -92,27 +140,205 @@ func (r *RepoGorm) Add(ctx context.Context, ingredient *entity.Ingredient) error
 }
 
 func (r *RepoGorm) Edit(ctx context.Context, ingredient *entity.Ingredient) error {
-	i := &Ingredient{}
-	i.FromEntity(ingredient)
-	err := r.db.WithContext(ctx).Save(i).Error
-	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return entity.ErrNotFound
+	// Save would insert a missing ingredient
+	result := r.db.WithContext(ctx).Model(&Ingredient{}).Where("id = ?", ingredient.ID).
+		Updates(map[string]interface{}{
+			"name":     ingredient.Name,
+			"name_key": entity.NormalizeName(ingredient.Name),
+			"type":     ingredient.Type,
+		})
+	if result.Error != nil {
+		if sqlite.IsUniqueViolation(result.Error) {
+			return r.alreadyExists(ctx, ingredient)
 		}
-		return err
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return entity.ErrNotFound
 	}
 	return nil
 }
 
 func (r *RepoGorm) Delete(ctx context.Context, ingredient *entity.Ingredient) error {
-	i := &Ingredient{}
-	i.FromEntity(ingredient)
-	err := r.db.WithContext(ctx).Delete(i).Error
-	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return entity.ErrNotFound
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := deleteIngredient(tx, ingredient.ID); err != nil {
+			return err
+		}
+
+		// Rolled back when recipes still use it
+		recipes, err := usedBy(tx, ingredient.ID)
+		if err != nil {
+			return err
+		}
+		if len(recipes) > 0 {
+			return &entity.InUseError{Recipes: recipes}
+		}
+		return nil
+	})
+}
+
+func (r *RepoGorm) DetachAndDelete(ctx context.Context, ingredient *entity.Ingredient) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := deleteIngredient(tx, ingredient.ID); err != nil {
+			return err
+		}
+		if !tx.Migrator().HasTable("recipe_ingredients") {
+			return nil
+		}
+		return tx.Exec("DELETE FROM recipe_ingredients WHERE ingredient_id = ?", ingredient.ID).Error
+	})
+}
+
+func (r *RepoGorm) Merge(ctx context.Context, target *entity.Ingredient, duplicates []int64) error {
+	if err := checkMerge(target, duplicates); err != nil {
+		return err
+	}
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&Ingredient{}, target.ID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return entity.ErrNotFound
+			}
+			return err
+		}
+		for _, id := range duplicates {
+			if err := deleteIngredient(tx, id); err != nil {
+				return fmt.Errorf("ingredient %d: %w", id, err)
+			}
+		}
+		if !tx.Migrator().HasTable("recipe_ingredients") {
+			return nil
+		}
+
+		// Link the target once to the recipes using any duplicate
+		if err := tx.Exec(`INSERT INTO recipe_ingredients (recipe_id, ingredient_id)
+			SELECT DISTINCT recipe_id, ? FROM recipe_ingredients WHERE ingredient_id IN ?
+			AND recipe_id NOT IN (SELECT recipe_id FROM recipe_ingredients WHERE ingredient_id = ?)`,
+			target.ID, duplicates, target.ID).Error; err != nil {
+			return err
 		}
+		return tx.Exec("DELETE FROM recipe_ingredients WHERE ingredient_id IN ?", duplicates).Error
+	})
+}
+
+// alreadyExists returns the *entity.AlreadyExistsError of the ingredient
+// holding the name of the given one
+func (r *RepoGorm) alreadyExists(ctx context.Context, ingredient *entity.Ingredient) error {
+	existing := &Ingredient{}
+	if err := r.db.WithContext(ctx).
+		Where("name_key = ? AND type = ?", entity.NormalizeName(ingredient.Name), ingredient.Type).
+		First(existing).Error; err != nil {
 		return err
 	}
+	return &entity.AlreadyExistsError{ID: int64(existing.ID)}
+}
+
+func deleteIngredient(tx *gorm.DB, id int64) error {
+	result := tx.Delete(&Ingredient{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return entity.ErrNotFound
+	}
 	return nil
 }
+
+// usedBy returns the recipes using the ingredient, deleted ones don't block it.
+// The recipe models own the tables, they may not be migrated.
+func usedBy(tx *gorm.DB, id int64) ([]*entity.Recipe, error) {
+	if !tx.Migrator().HasTable("recipe_ingredients") {
+		return nil, nil
+	}
+
+	var rows []struct {
+		ID   int64
+		Name string
+	}
+	if err := tx.Raw(`SELECT recipes.id, recipes.name FROM recipes
+		JOIN recipe_ingredients ON recipe_ingredients.recipe_id = recipes.id
+		WHERE recipe_ingredients.ingredient_id = ? AND recipes.deleted_at IS NULL
+		ORDER BY recipes.id`, id).Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	recipes := make([]*entity.Recipe, len(rows))
+	for i, row := range rows {
+		recipes[i] = &entity.Recipe{ID: row.ID, Name: row.Name}
+	}
+	return recipes, nil
+}
+
+// Trash functions
+func (r *RepoGorm) FindDeleted(ctx context.Context) ([]*entity.TrashItem, error) {
+	var ingredients []*Ingredient
+	if err := r.db.WithContext(ctx).Unscoped().
+		Where("deleted_at IS NOT NULL").
+		Order("deleted_at DESC").
+		Find(&ingredients).Error; err != nil {
+		return nil, err
+	}
+	return toTrashItems(ingredients), nil
+}
+
+func toTrashItems(ingredients []*Ingredient) []*entity.TrashItem {
+	result := make([]*entity.TrashItem, len(ingredients))
+	for i, ingredient := range ingredients {
+		result[i] = &entity.TrashItem{
+			Entity:    entity.TrashIngredient,
+			ID:        int64(ingredient.ID),
+			Name:      ingredient.Name,
+			DeletedAt: ingredient.DeletedAt.Time,
+		}
+	}
+	return result
+}
+
+func (r *RepoGorm) Restore(ctx context.Context, id int64) error {
+	result := r.db.WithContext(ctx).Unscoped().Model(&Ingredient{}).
+		Where("id = ? AND deleted_at IS NOT NULL", id).
+		Update("deleted_at", nil)
+	if result.Error != nil {
+		if sqlite.IsUniqueViolation(result.Error) {
+			// Another ingredient took its name meanwhile
+			deleted := &Ingredient{}
+			if err := r.db.WithContext(ctx).Unscoped().First(deleted, id).Error; err != nil {
+				return err
+			}
+			return r.alreadyExists(ctx, deleted.ToEntity())
+		}
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return entity.ErrNotFound
+	}
+	return nil
+}
+
+func (r *RepoGorm) Purge(ctx context.Context, before time.Time) ([]*entity.TrashItem, error) {
+	var expired []*Ingredient
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("deleted_at < ?", before).Order("id").Find(&expired).Error; err != nil {
+			return err
+		}
+		if len(expired) == 0 {
+			return nil
+		}
+		ids := make([]uint, len(expired))
+		for i, ingredient := range expired {
+			ids[i] = ingredient.ID
+		}
+
+		// Unlink them from the recipes first, the join table belongs to the recipe models
+		if tx.Migrator().HasTable("recipe_ingredients") {
+			if err := tx.Exec("DELETE FROM recipe_ingredients WHERE ingredient_id IN (?)", ids).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Unscoped().Delete(&Ingredient{}, ids).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return toTrashItems(expired), nil
+}
@@ -2,6 +2,7 @@ package ingredient_test
 
 import (
 	"context"
+	"errors"
 	"testing"
 
 	"gorm.io/driver/sqlite"
@@ -190,7 +191,6 @@ func TestRepoGorm_Delete(t *testing.T) {
 	tx.Rollback()
 }
 
-
 func TestRepoGorm_CountByFilter(t *testing.T) {
 	tx := db.Begin()
 
@@ -229,4 +229,57 @@ func TestRepoGorm_CountByFilter(t *testing.T) {
 		t.Fatalf("expected 0 ingredient, got %d", ingredientsFound)
 	}
 	tx.Rollback()
-}
\ No newline at end of file
+}
+
+// legacyIngredient is the model before names were unique
+type legacyIngredient struct {
+	gorm.Model
+	Name string
+	Type string
+}
+
+func (legacyIngredient) TableName() string {
+	return "ingredients"
+}
+
+func TestRunMigrations_KeepsDuplicates(t *testing.T) {
+	legacyDB, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to connect database: %v", err)
+	}
+	sqlDB, err := legacyDB.DB()
+	if err != nil {
+		t.Fatalf("failed to get database: %v", err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+	defer sqlDB.Close()
+
+	if err := legacyDB.AutoMigrate(&legacyIngredient{}); err != nil {
+		t.Fatalf("failed to migrate legacy schema: %v", err)
+	}
+	for _, name := range []string{"Tomato", "tomato", "Salt"} {
+		if err := legacyDB.Create(&legacyIngredient{Name: name, Type: "type"}).Error; err != nil {
+			t.Fatalf("failed to add legacy ingredient: %v", err)
+		}
+	}
+
+	if err := ingredient.RunMigrations(legacyDB); err != nil {
+		t.Fatalf("failed to run migrations: %v", err)
+	}
+
+	repo := ingredient.NewGormRepo(legacyDB)
+	count, err := repo.CountByFilter(&ingredient.FindFilter{})
+	if err != nil {
+		t.Fatalf("failed to count ingredients: %v", err)
+	}
+	if count != 3 {
+		t.Fatalf("expected the duplicates to be kept, counted %d ingredients", count)
+	}
+
+	// The first of the duplicates holds the name
+	err = repo.Add(context.Background(), &entity.Ingredient{Name: "TOMATO", Type: "type"})
+	var exists *entity.AlreadyExistsError
+	if !errors.As(err, &exists) || exists.ID != 1 {
+		t.Fatalf("expected the name to be taken by ingredient 1, got %v", err)
+	}
+}
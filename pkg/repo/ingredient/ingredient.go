@@ -2,6 +2,7 @@ package ingredient
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/TomeuUris/recipes-catalog/pkg/entity"
 )
@@ -12,9 +13,35 @@ type Repo interface {
 	CountByFilter(f *FindFilter) (int, error)
 	Add(ctx context.Context, ingredient *entity.Ingredient) error
 	Edit(ctx context.Context, ingredient *entity.Ingredient) error
+	// Delete fails with an *entity.InUseError when recipes use the ingredient
 	Delete(ctx context.Context, ingredientingredient *entity.Ingredient) error
+	// DetachAndDelete removes the ingredient from the recipes using it and
+	// deletes it in one transaction
+	DetachAndDelete(ctx context.Context, ingredient *entity.Ingredient) error
+	// Merge makes the recipes using the duplicates use the target instead and
+	// deletes the duplicates in one transaction
+	Merge(ctx context.Context, target *entity.Ingredient, duplicates []int64) error
 }
 
 type FindFilter struct {
 	Type string `form:"type"`
 }
+
+// checkMerge fails with entity.ErrInvalidID when the duplicates are empty,
+// repeated or include the target
+func checkMerge(target *entity.Ingredient, duplicates []int64) error {
+	if len(duplicates) == 0 {
+		return fmt.Errorf("%w: no ingredients to merge", entity.ErrInvalidID)
+	}
+	seen := make(map[int64]bool, len(duplicates))
+	for _, id := range duplicates {
+		if id == target.ID {
+			return fmt.Errorf("%w: ingredient %d can't be merged into itself", entity.ErrInvalidID, id)
+		}
+		if seen[id] {
+			return fmt.Errorf("%w: ingredient %d is repeated", entity.ErrInvalidID, id)
+		}
+		seen[id] = true
+	}
+	return nil
+}
@@ -0,0 +1,237 @@
+package ingredient
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/TomeuUris/recipes-catalog/pkg/entity"
+)
+
+// Referrer finds and detaches the recipes using an ingredient. The memory
+// recipes live in their own repository, which registers itself with SetReferrer.
+type Referrer interface {
+	// FindByIngredient returns the recipes using the ingredient with their ID and name
+	FindByIngredient(ctx context.Context, id int64) ([]*entity.Recipe, error)
+	DetachIngredient(ctx context.Context, id int64) error
+	// ReplaceIngredients makes the recipes using any of the ingredients use the target once
+	ReplaceIngredients(ctx context.Context, ids []int64, target int64) error
+}
+
+// RepoMemory keeps the ingredients in memory, it is safe for concurrent use
+type RepoMemory struct {
+	mu          sync.RWMutex
+	lastID      int64
+	ingredients map[int64]*entity.Ingredient
+	referrer    Referrer
+}
+
+func NewMemoryRepo() *RepoMemory {
+	return &RepoMemory{
+		ingredients: map[int64]*entity.Ingredient{},
+	}
+}
+
+func (r *RepoMemory) FindByID(ctx context.Context, id int) (*entity.Ingredient, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	ingredient, ok := r.ingredients[int64(id)]
+	if !ok {
+		return nil, entity.ErrNotFound
+	}
+	copied := *ingredient
+	return &copied, nil
+}
+
+func (r *RepoMemory) FindByFilter(ctx context.Context, f *FindFilter) ([]*entity.Ingredient, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.filter(f), nil
+}
+
+func (r *RepoMemory) CountByFilter(f *FindFilter) (int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return len(r.filter(f)), nil
+}
+
+func (r *RepoMemory) Add(ctx context.Context, ingredient *entity.Ingredient) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.checkUnique(ingredient); err != nil {
+		return err
+	}
+	r.lastID++
+	ingredient.ID = r.lastID
+	copied := *ingredient
+	r.ingredients[ingredient.ID] = &copied
+	return nil
+}
+
+func (r *RepoMemory) Edit(ctx context.Context, ingredient *entity.Ingredient) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.ingredients[ingredient.ID]; !ok {
+		return entity.ErrNotFound
+	}
+	if err := r.checkUnique(ingredient); err != nil {
+		return err
+	}
+	copied := *ingredient
+	r.ingredients[ingredient.ID] = &copied
+	return nil
+}
+
+func (r *RepoMemory) Delete(ctx context.Context, ingredient *entity.Ingredient) error {
+	if _, err := r.FindByID(ctx, int(ingredient.ID)); err != nil {
+		return err
+	}
+
+	// The referrer reads the ingredients, it can't be called holding the lock
+	if referrer := r.getReferrer(); referrer != nil {
+		recipes, err := referrer.FindByIngredient(ctx, ingredient.ID)
+		if err != nil {
+			return err
+		}
+		if len(recipes) > 0 {
+			return &entity.InUseError{Recipes: recipes}
+		}
+	}
+	return r.remove(ingredient.ID)
+}
+
+// DetachAndDelete isn't atomic like in the databases, a recipe added meanwhile
+// could reference the deleted ingredient, which is skipped when reading it
+func (r *RepoMemory) DetachAndDelete(ctx context.Context, ingredient *entity.Ingredient) error {
+	if _, err := r.FindByID(ctx, int(ingredient.ID)); err != nil {
+		return err
+	}
+
+	if referrer := r.getReferrer(); referrer != nil {
+		if err := referrer.DetachIngredient(ctx, ingredient.ID); err != nil {
+			return err
+		}
+	}
+	return r.remove(ingredient.ID)
+}
+
+// Merge isn't atomic like in the databases, a recipe added meanwhile could
+// reference a duplicate, which is skipped when reading it
+func (r *RepoMemory) Merge(ctx context.Context, target *entity.Ingredient, duplicates []int64) error {
+	if err := checkMerge(target, duplicates); err != nil {
+		return err
+	}
+	if _, err := r.FindByID(ctx, int(target.ID)); err != nil {
+		return err
+	}
+	for _, id := range duplicates {
+		if _, err := r.FindByID(ctx, int(id)); err != nil {
+			return fmt.Errorf("ingredient %d: %w", id, err)
+		}
+	}
+
+	if referrer := r.getReferrer(); referrer != nil {
+		if err := referrer.ReplaceIngredients(ctx, duplicates, target.ID); err != nil {
+			return err
+		}
+	}
+	for _, id := range duplicates {
+		if err := r.remove(id); err != nil {
+			return fmt.Errorf("ingredient %d: %w", id, err)
+		}
+	}
+	return nil
+}
+
+// SetReferrer registers the repository of the recipes using the ingredients
+func (r *RepoMemory) SetReferrer(referrer Referrer) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.referrer = referrer
+}
+
+func (r *RepoMemory) getReferrer() Referrer {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.referrer
+}
+
+func (r *RepoMemory) remove(id int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// It may have been deleted meanwhile
+	if _, ok := r.ingredients[id]; !ok {
+		return entity.ErrNotFound
+	}
+	delete(r.ingredients, id)
+	return nil
+}
+
+// Snapshot returns every ingredient ordered by ID
+func (r *RepoMemory) Snapshot() []*entity.Ingredient {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.filter(nil)
+}
+
+// Restore replaces the ingredients with the ones of a snapshot
+func (r *RepoMemory) Restore(ingredients []*entity.Ingredient) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.ingredients = make(map[int64]*entity.Ingredient, len(ingredients))
+	r.lastID = 0
+	for _, ingredient := range ingredients {
+		copied := *ingredient
+		r.ingredients[ingredient.ID] = &copied
+		if ingredient.ID > r.lastID {
+			r.lastID = ingredient.ID
+		}
+	}
+}
+
+// checkUnique returns an *entity.AlreadyExistsError when another ingredient of
+// the same type has the same normalized name. The caller must hold the lock.
+func (r *RepoMemory) checkUnique(ingredient *entity.Ingredient) error {
+	key := entity.NormalizeName(ingredient.Name)
+	var holder int64
+	for id, stored := range r.ingredients {
+		if id == ingredient.ID || stored.Type != ingredient.Type || entity.NormalizeName(stored.Name) != key {
+			continue
+		}
+		if holder == 0 || id < holder {
+			holder = id
+		}
+	}
+	if holder != 0 {
+		return &entity.AlreadyExistsError{ID: holder}
+	}
+	return nil
+}
+
+// filter returns copies of the matching ingredients ordered by ID, empty fields
+// are not filtered like in gorm. The caller must hold the lock.
+func (r *RepoMemory) filter(f *FindFilter) []*entity.Ingredient {
+	result := []*entity.Ingredient{}
+	for _, ingredient := range r.ingredients {
+		if f != nil && f.Type != "" && ingredient.Type != f.Type {
+			continue
+		}
+		copied := *ingredient
+		result = append(result, &copied)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].ID < result[j].ID
+	})
+	return result
+}
@@ -0,0 +1,81 @@
+package ingredient_test
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/TomeuUris/recipes-catalog/pkg/entity"
+	"github.com/TomeuUris/recipes-catalog/pkg/repo/ingredient"
+	"github.com/TomeuUris/recipes-catalog/pkg/repo/repotest"
+)
+
+func TestRepoMemory_Conformance(t *testing.T) {
+	repotest.TestIngredientRepo(t, func(t *testing.T) ingredient.Repo {
+		return ingredient.NewMemoryRepo()
+	})
+}
+
+func TestRepoMemory_ConcurrentAdd(t *testing.T) {
+	repo := ingredient.NewMemoryRepo()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			// Names are unique per type
+			ingredientExample := getExampleIngredientEntity()
+			ingredientExample.Name = fmt.Sprintf("%s %d", ingredientExample.Name, i)
+			if err := repo.Add(context.Background(), ingredientExample); err != nil {
+				t.Errorf("failed to add ingredient: %v", err)
+			}
+			if _, err := repo.FindByFilter(context.Background(), &ingredient.FindFilter{}); err != nil {
+				t.Errorf("failed to find ingredients: %v", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	ingredientsFound, err := repo.FindByFilter(context.Background(), &ingredient.FindFilter{})
+	if err != nil {
+		t.Fatalf("failed to find ingredients: %v", err)
+	}
+	if len(ingredientsFound) != 50 {
+		t.Fatalf("expected 50 ingredients, got %d", len(ingredientsFound))
+	}
+	for i, ingredientFound := range ingredientsFound {
+		if ingredientFound.ID != int64(i+1) {
+			t.Fatalf("expected ingredient %d to have ID %d, got %d", i, i+1, ingredientFound.ID)
+		}
+	}
+}
+
+func TestRepoMemory_SnapshotRestore(t *testing.T) {
+	repo := ingredient.NewMemoryRepo()
+	ingredientExample := getExampleIngredientEntity()
+	if err := repo.Add(context.Background(), ingredientExample); err != nil {
+		t.Fatalf("failed to add ingredient: %v", err)
+	}
+
+	restored := ingredient.NewMemoryRepo()
+	restored.Restore(repo.Snapshot())
+
+	ingredientFound, err := restored.FindByID(context.Background(), int(ingredientExample.ID))
+	if err != nil {
+		t.Fatalf("failed to find ingredient: %v", err)
+	}
+	if *ingredientFound != *ingredientExample {
+		t.Fatalf("expected ingredient %+v, got %+v", ingredientExample, ingredientFound)
+	}
+
+	// IDs continue after the restored ones
+	other := &entity.Ingredient{Name: "Salt", Type: "Spice"}
+	if err := restored.Add(context.Background(), other); err != nil {
+		t.Fatalf("failed to add ingredient: %v", err)
+	}
+	if other.ID != ingredientExample.ID+1 {
+		t.Fatalf("expected ID %d, got %d", ingredientExample.ID+1, other.ID)
+	}
+}
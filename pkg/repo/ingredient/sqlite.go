@@ -4,71 +4,270 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
+	"strings"
 
 	"github.com/TomeuUris/recipes-catalog/pkg/entity"
+	"github.com/TomeuUris/recipes-catalog/pkg/sqlite"
 )
 
-func NewRepo(db *sql.DB) *RepoSQL {
+func NewRepo(db sqlite.DB) *RepoSQL {
+	return NewConnRepo(sqlite.NewConn(db))
+}
+
+// NewConnRepo returns a repository on the connection, which may run on a
+// transaction shared with other repositories
+func NewConnRepo(conn *sqlite.Conn) *RepoSQL {
 	return &RepoSQL{
-		db: db,
+		db: conn,
 	}
 }
 
 type RepoSQL struct {
-	db *sql.DB
+	db *sqlite.Conn
 }
 
 func (r *RepoSQL) FindByID(ctx context.Context, id int) (*entity.Ingredient, error) {
 	// Get ingredient from database
 	row := r.db.QueryRowContext(ctx, `SELECT id, name, type FROM ingredients WHERE id = ?`, id)
-	if err := row.Err(); err != nil {
+
+	ingredient := &entity.Ingredient{}
+	if err := row.Scan(&ingredient.ID, &ingredient.Name, &ingredient.Type); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, entity.ErrNotFound
 		}
 		return nil, err
 	}
 
-	ingredient := &entity.Ingredient{}
-	row.Scan(&ingredient.ID, &ingredient.Name, &ingredient.Type)
-
 	return ingredient, nil
 }
 
 func (r *RepoSQL) FindByFilter(ctx context.Context, f *FindFilter) ([]*entity.Ingredient, error) {
-	rows, err := r.db.QueryContext(ctx, `SELECT * FROM ingredients WHERE type = ?`, f.Type)
+	where, args := filterClause(f)
+	rows, err := r.db.QueryContext(ctx, `SELECT id, name, type FROM ingredients`+where+` ORDER BY id`, args...)
 	if err != nil {
 		return nil, err
 	}
+	defer rows.Close()
 
-	var ingredients []*entity.Ingredient
+	ingredients := []*entity.Ingredient{}
 	for rows.Next() {
-		var ingredient *entity.Ingredient
-		rows.Scan(ingredient)
-		ingredients = append(ingredients, &entity.Ingredient{
-			ID:   ingredient.ID,
-			Name: ingredient.Name,
-			Type: ingredient.Type,
-		})
+		ingredient := &entity.Ingredient{}
+		if err := rows.Scan(&ingredient.ID, &ingredient.Name, &ingredient.Type); err != nil {
+			return nil, err
+		}
+		ingredients = append(ingredients, ingredient)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
 	}
 
 	return ingredients, nil
 }
 
+func (r *RepoSQL) CountByFilter(f *FindFilter) (int, error) {
+	where, args := filterClause(f)
+
+	var count int
+	if err := r.db.QueryRow(`SELECT COUNT(*) FROM ingredients`+where, args...).Scan(&count); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
 func (r *RepoSQL) Add(ctx context.Context, ingredient *entity.Ingredient) error {
+	result, err := r.db.ExecContext(ctx, `INSERT INTO ingredients (name, nameKey, type) VALUES (?, ?, ?)`,
+		ingredient.Name, entity.NormalizeName(ingredient.Name), ingredient.Type)
+	if err != nil {
+		if sqlite.IsUniqueViolation(err) {
+			return r.alreadyExists(ctx, ingredient)
+		}
+		return err
+	}
+
+	ingredient.ID, err = result.LastInsertId()
+	return err
+}
+
+func (r *RepoSQL) Edit(ctx context.Context, ingredient *entity.Ingredient) error {
+	result, err := r.db.ExecContext(ctx, `UPDATE ingredients SET name = ?, nameKey = ?, type = ? WHERE id = ?`,
+		ingredient.Name, entity.NormalizeName(ingredient.Name), ingredient.Type, ingredient.ID)
+	if err != nil {
+		if sqlite.IsUniqueViolation(err) {
+			return r.alreadyExists(ctx, ingredient)
+		}
+		return err
+	}
+	return expectAffected(result)
+}
+
+func (r *RepoSQL) Delete(ctx context.Context, ingredient *entity.Ingredient) error {
 	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
 		return err
 	}
+	defer tx.Rollback()
 
-	result, err := tx.ExecContext(ctx, `INSERT INTO ingredients (name, type) VALUES (?, ?)`, ingredient.Name, ingredient.Type)
+	// Check before deleting, the foreign keys may cascade. Dangling rows left
+	// while they were off must not hide a missing ingredient.
+	var exists bool
+	if err := tx.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM ingredients WHERE id = ?)`, ingredient.ID).
+		Scan(&exists); err != nil {
+		return err
+	}
+	if !exists {
+		return entity.ErrNotFound
+	}
+	recipes, err := recipesUsing(ctx, tx, ingredient.ID)
 	if err != nil {
 		return err
 	}
+	if len(recipes) > 0 {
+		return &entity.InUseError{Recipes: recipes}
+	}
 
-	ingredient.ID, err = result.LastInsertId()
+	result, err := tx.ExecContext(ctx, `DELETE FROM ingredients WHERE id = ?`, ingredient.ID)
+	if err != nil {
+		return err
+	}
+	if err := expectAffected(result); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *RepoSQL) DetachAndDelete(ctx context.Context, ingredient *entity.Ingredient) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Explicitly, foreign keys are enabled per connection
+	if _, err := tx.ExecContext(ctx, `DELETE FROM recipeIngredients WHERE ingredientId = ?`, ingredient.ID); err != nil {
+		return err
+	}
+
+	result, err := tx.ExecContext(ctx, `DELETE FROM ingredients WHERE id = ?`, ingredient.ID)
+	if err != nil {
+		return err
+	}
+	if err := expectAffected(result); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *RepoSQL) Merge(ctx context.Context, target *entity.Ingredient, duplicates []int64) error {
+	if err := checkMerge(target, duplicates); err != nil {
+		return err
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
 		return err
 	}
+	defer tx.Rollback()
 
+	var exists bool
+	if err := tx.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM ingredients WHERE id = ?)`, target.ID).
+		Scan(&exists); err != nil {
+		return err
+	}
+	if !exists {
+		return entity.ErrNotFound
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(duplicates)), ", ")
+	args := make([]interface{}, 0, len(duplicates)+2)
+	args = append(args, target.ID)
+	for _, id := range duplicates {
+		args = append(args, id)
+	}
+	args = append(args, target.ID)
+
+	// Link the target once to the recipes using any duplicate
+	if _, err := tx.ExecContext(ctx, `INSERT INTO recipeIngredients (recipeId, ingredientId)
+		SELECT DISTINCT recipeId, ? FROM recipeIngredients WHERE ingredientId IN (`+placeholders+`)
+		AND recipeId NOT IN (SELECT recipeId FROM recipeIngredients WHERE ingredientId = ?)`, args...); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM recipeIngredients WHERE ingredientId IN (`+placeholders+`)`,
+		args[1:len(args)-1]...); err != nil {
+		return err
+	}
+
+	for _, id := range duplicates {
+		result, err := tx.ExecContext(ctx, `DELETE FROM ingredients WHERE id = ?`, id)
+		if err != nil {
+			return err
+		}
+		if err := expectAffected(result); err != nil {
+			return fmt.Errorf("ingredient %d: %w", id, err)
+		}
+	}
 	return tx.Commit()
 }
+
+// recipesUsing returns the recipes using the ingredient with their ID and name
+func recipesUsing(ctx context.Context, tx *sqlite.Tx, id int64) ([]*entity.Recipe, error) {
+	rows, err := tx.QueryContext(ctx, `SELECT DISTINCT recipes.id, recipes.name FROM recipes
+		JOIN recipeIngredients ON recipeIngredients.recipeId = recipes.id
+		WHERE recipeIngredients.ingredientId = ? ORDER BY recipes.id`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var recipes []*entity.Recipe
+	for rows.Next() {
+		recipe := &entity.Recipe{}
+		if err := rows.Scan(&recipe.ID, &recipe.Name); err != nil {
+			return nil, err
+		}
+		recipes = append(recipes, recipe)
+	}
+	return recipes, rows.Err()
+}
+
+// alreadyExists returns the *entity.AlreadyExistsError of the ingredient
+// holding the name of the given one
+func (r *RepoSQL) alreadyExists(ctx context.Context, ingredient *entity.Ingredient) error {
+	var id int64
+	if err := r.db.QueryRowContext(ctx, `SELECT id FROM ingredients WHERE nameKey = ? AND type = ?`,
+		entity.NormalizeName(ingredient.Name), ingredient.Type).Scan(&id); err != nil {
+		return err
+	}
+	return &entity.AlreadyExistsError{ID: id}
+}
+
+// filterClause builds the WHERE clause of the filter, empty fields are not filtered like in gorm
+func filterClause(f *FindFilter) (string, []interface{}) {
+	if f == nil {
+		return "", nil
+	}
+
+	var conditions []string
+	var args []interface{}
+	if f.Type != "" {
+		conditions = append(conditions, "type = ?")
+		args = append(args, f.Type)
+	}
+
+	if len(conditions) == 0 {
+		return "", nil
+	}
+	return " WHERE " + strings.Join(conditions, " AND "), args
+}
+
+// expectAffected returns entity.ErrNotFound when the statement didn't match any row
+func expectAffected(result sql.Result) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return entity.ErrNotFound
+	}
+	return nil
+}
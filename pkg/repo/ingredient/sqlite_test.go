@@ -0,0 +1,178 @@
+package ingredient_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/TomeuUris/recipes-catalog/pkg/entity"
+	"github.com/TomeuUris/recipes-catalog/pkg/repo/ingredient"
+	"github.com/TomeuUris/recipes-catalog/pkg/sqlite"
+	"github.com/TomeuUris/recipes-catalog/pkg/sqlite/migrations"
+)
+
+func newSQLRepo(t *testing.T) *ingredient.RepoSQL {
+	sqlDB, err := sqlite.Open(":memory:")
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	// Every connection to :memory: opens a new database
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+
+	if err := migrations.Store.Apply(context.Background(), sqlDB); err != nil {
+		t.Fatalf("failed to migrate database: %v", err)
+	}
+	return ingredient.NewRepo(sqlDB)
+}
+
+func TestRepoSQL_FindByFilter(t *testing.T) {
+	repo := newSQLRepo(t)
+	ctx := context.Background()
+
+	// Create sample ingredients
+	for _, i := range []*entity.Ingredient{
+		{Name: "Spaghetti", Type: "Pasta"},
+		{Name: "Macaroni", Type: "Pasta"},
+		{Name: "Salt", Type: "Spice"},
+	} {
+		if err := repo.Add(ctx, i); err != nil {
+			t.Fatalf("failed to add ingredient: %v", err)
+		}
+	}
+
+	// Find the ingredients
+	ingredientsFound, err := repo.FindByFilter(ctx, &ingredient.FindFilter{Type: "Pasta"})
+	if err != nil {
+		t.Fatalf("failed to find ingredients: %v", err)
+	}
+	if len(ingredientsFound) != 2 {
+		t.Fatalf("expected 2 ingredients, got %d", len(ingredientsFound))
+	}
+	for _, ingredientFound := range ingredientsFound {
+		if ingredientFound.Type != "Pasta" || ingredientFound.ID == 0 || ingredientFound.Name == "" {
+			t.Fatalf("unexpected ingredient %+v", ingredientFound)
+		}
+	}
+
+	// An empty filter matches every ingredient
+	ingredientsFound, err = repo.FindByFilter(ctx, &ingredient.FindFilter{})
+	if err != nil {
+		t.Fatalf("failed to find ingredients: %v", err)
+	}
+	if len(ingredientsFound) != 3 {
+		t.Fatalf("expected 3 ingredients, got %d", len(ingredientsFound))
+	}
+}
+
+func TestRepoSQL_CountByFilter(t *testing.T) {
+	repo := newSQLRepo(t)
+
+	// Create sample ingredients
+	for _, i := range []*entity.Ingredient{
+		{Name: "Spaghetti", Type: "Pasta"},
+		{Name: "Salt", Type: "Spice"},
+	} {
+		if err := repo.Add(context.Background(), i); err != nil {
+			t.Fatalf("failed to add ingredient: %v", err)
+		}
+	}
+
+	count, err := repo.CountByFilter(&ingredient.FindFilter{Type: "Pasta"})
+	if err != nil {
+		t.Fatalf("failed to count ingredients: %v", err)
+	}
+	if count != 1 {
+		t.Fatalf("expected 1 ingredient, got %d", count)
+	}
+
+	count, err = repo.CountByFilter(&ingredient.FindFilter{})
+	if err != nil {
+		t.Fatalf("failed to count ingredients: %v", err)
+	}
+	if count != 2 {
+		t.Fatalf("expected 2 ingredients, got %d", count)
+	}
+}
+
+func TestRepoSQL_FindByID(t *testing.T) {
+	repo := newSQLRepo(t)
+	ctx := context.Background()
+
+	// Add the ingredient
+	ingredientExample := getExampleIngredientEntity()
+	if err := repo.Add(ctx, ingredientExample); err != nil {
+		t.Fatalf("failed to add ingredient: %v", err)
+	}
+
+	// Find the ingredient
+	ingredientFound, err := repo.FindByID(ctx, int(ingredientExample.ID))
+	if err != nil {
+		t.Fatalf("failed to find ingredient: %v", err)
+	}
+	if *ingredientFound != *ingredientExample {
+		t.Fatalf("expected ingredient %+v, got %+v", ingredientExample, ingredientFound)
+	}
+
+	// Missing ingredients are not found
+	if _, err := repo.FindByID(ctx, int(ingredientExample.ID)+1); !entity.IsErrNotFound(err) {
+		t.Fatalf("expected not found error, got %v", err)
+	}
+}
+
+func TestRepoSQL_Edit(t *testing.T) {
+	repo := newSQLRepo(t)
+	ctx := context.Background()
+
+	// Add the ingredient
+	ingredientExample := getExampleIngredientEntity()
+	if err := repo.Add(ctx, ingredientExample); err != nil {
+		t.Fatalf("failed to add ingredient: %v", err)
+	}
+
+	// Edit the ingredient
+	ingredientExample.Name = "Spaghetti Bolognese"
+	if err := repo.Edit(ctx, ingredientExample); err != nil {
+		t.Fatalf("failed to edit ingredient: %v", err)
+	}
+
+	// Check if ingredient was edited
+	ingredientFound, err := repo.FindByID(ctx, int(ingredientExample.ID))
+	if err != nil {
+		t.Fatalf("failed to find ingredient: %v", err)
+	}
+	if ingredientFound.Name != ingredientExample.Name {
+		t.Fatalf("expected ingredient name to be %s, got %s", ingredientExample.Name, ingredientFound.Name)
+	}
+
+	// Missing ingredients can't be edited
+	missing := &entity.Ingredient{ID: ingredientExample.ID + 1, Name: "missing", Type: "type"}
+	if err := repo.Edit(ctx, missing); !entity.IsErrNotFound(err) {
+		t.Fatalf("expected not found error, got %v", err)
+	}
+}
+
+func TestRepoSQL_Delete(t *testing.T) {
+	repo := newSQLRepo(t)
+	ctx := context.Background()
+
+	// Add the ingredient
+	ingredientExample := getExampleIngredientEntity()
+	if err := repo.Add(ctx, ingredientExample); err != nil {
+		t.Fatalf("failed to add ingredient: %v", err)
+	}
+
+	// Delete the ingredient
+	if err := repo.Delete(ctx, ingredientExample); err != nil {
+		t.Fatalf("failed to delete ingredient: %v", err)
+	}
+
+	// Check if ingredient was deleted
+	if _, err := repo.FindByID(ctx, int(ingredientExample.ID)); !entity.IsErrNotFound(err) {
+		t.Fatalf("expected not found error, got %v", err)
+	}
+
+	// Deleting it again fails
+	if err := repo.Delete(ctx, ingredientExample); !entity.IsErrNotFound(err) {
+		t.Fatalf("expected not found error, got %v", err)
+	}
+}
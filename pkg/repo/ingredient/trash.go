@@ -0,0 +1,18 @@
+package ingredient
+
+import (
+	"context"
+	"time"
+
+	"github.com/TomeuUris/recipes-catalog/pkg/entity"
+)
+
+// TrashRepo gives access to deleted ingredients. It is only implemented by
+// backends that soft delete.
+type TrashRepo interface {
+	FindDeleted(ctx context.Context) ([]*entity.TrashItem, error)
+	Restore(ctx context.Context, id int64) error
+	// Purge permanently removes the ingredients deleted before the time and
+	// returns them
+	Purge(ctx context.Context, before time.Time) ([]*entity.TrashItem, error)
+}
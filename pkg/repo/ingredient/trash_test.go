@@ -0,0 +1,57 @@
+package ingredient_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/TomeuUris/recipes-catalog/pkg/entity"
+	"github.com/TomeuUris/recipes-catalog/pkg/repo/ingredient"
+)
+
+func TestRepoGorm_RestoreAndPurge(t *testing.T) {
+	tx := db.Begin()
+	defer tx.Rollback()
+	repo := ingredient.NewGormRepo(tx)
+	ctx := context.Background()
+
+	ingredientExample := getExampleIngredientEntity()
+	if err := repo.Add(ctx, ingredientExample); err != nil {
+		t.Fatalf("failed to add ingredient: %v", err)
+	}
+	if err := repo.Delete(ctx, ingredientExample); err != nil {
+		t.Fatalf("failed to delete ingredient: %v", err)
+	}
+
+	// The ingredient is in the trash
+	deleted, err := repo.FindDeleted(ctx)
+	if err != nil {
+		t.Fatalf("failed to find deleted ingredients: %v", err)
+	}
+	if len(deleted) != 1 || deleted[0].ID != ingredientExample.ID || deleted[0].Entity != entity.TrashIngredient {
+		t.Fatalf("unexpected trash %+v", deleted)
+	}
+
+	// Restore it
+	if err := repo.Restore(ctx, ingredientExample.ID); err != nil {
+		t.Fatalf("failed to restore ingredient: %v", err)
+	}
+	if _, err := repo.FindByID(ctx, int(ingredientExample.ID)); err != nil {
+		t.Fatalf("failed to find restored ingredient: %v", err)
+	}
+
+	// Delete and purge it
+	if err := repo.Delete(ctx, ingredientExample); err != nil {
+		t.Fatalf("failed to delete ingredient: %v", err)
+	}
+	purged, err := repo.Purge(ctx, time.Now().Add(time.Second))
+	if err != nil {
+		t.Fatalf("failed to purge ingredients: %v", err)
+	}
+	if len(purged) != 1 || purged[0].ID != ingredientExample.ID || purged[0].Name != ingredientExample.Name {
+		t.Fatalf("expected the ingredient to be purged, got %+v", purged)
+	}
+	if err := repo.Restore(ctx, ingredientExample.ID); !entity.IsErrNotFound(err) {
+		t.Fatalf("expected not found error, got %v", err)
+	}
+}
@@ -0,0 +1,56 @@
+package outbox_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/TomeuUris/recipes-catalog/pkg/repo/outbox"
+	"github.com/TomeuUris/recipes-catalog/pkg/repo/repotest"
+	"github.com/TomeuUris/recipes-catalog/pkg/sqlite"
+	"github.com/TomeuUris/recipes-catalog/pkg/sqlite/migrations"
+	gormSqlite "gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestRepoGorm_Conformance(t *testing.T) {
+	repotest.TestOutboxRepo(t, func(t *testing.T) outbox.Repo {
+		db, err := gorm.Open(gormSqlite.Open("file::memory:"), &gorm.Config{})
+		if err != nil {
+			t.Fatalf("failed to connect database: %v", err)
+		}
+		sqlDB, err := db.DB()
+		if err != nil {
+			t.Fatalf("failed to get database: %v", err)
+		}
+		// Every connection to :memory: opens a new database
+		sqlDB.SetMaxOpenConns(1)
+		t.Cleanup(func() { sqlDB.Close() })
+		if err := outbox.RunMigrations(db); err != nil {
+			t.Fatalf("failed to migrate database: %v", err)
+		}
+		return outbox.NewGormRepo(db)
+	})
+}
+
+func TestRepoSQL_Conformance(t *testing.T) {
+	repotest.TestOutboxRepo(t, func(t *testing.T) outbox.Repo {
+		sqlDB, err := sqlite.Open(":memory:")
+		if err != nil {
+			t.Fatalf("failed to open database: %v", err)
+		}
+		// Every connection to :memory: opens a new database
+		sqlDB.SetMaxOpenConns(1)
+		t.Cleanup(func() { sqlDB.Close() })
+
+		if err := migrations.Store.Apply(context.Background(), sqlDB); err != nil {
+			t.Fatalf("failed to migrate database: %v", err)
+		}
+		return outbox.NewRepo(sqlDB)
+	})
+}
+
+func TestRepoMemory_Conformance(t *testing.T) {
+	repotest.TestOutboxRepo(t, func(t *testing.T) outbox.Repo {
+		return outbox.NewMemoryRepo()
+	})
+}
@@ -0,0 +1,122 @@
+package outbox
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/TomeuUris/recipes-catalog/pkg/entity"
+)
+
+// Database model
+type OutboxMessage struct {
+	ID            uint `gorm:"primarykey"`
+	Entity        string
+	EntityID      int64
+	Action        string
+	Payload       string
+	OccurredAt    time.Time
+	Attempts      int
+	LastError     string
+	NextAttemptAt time.Time
+	DeliveredAt   *time.Time `gorm:"index"`
+}
+
+func (m *OutboxMessage) ToEntity() *entity.OutboxMessage {
+	return &entity.OutboxMessage{
+		ID:            int64(m.ID),
+		Entity:        m.Entity,
+		EntityID:      m.EntityID,
+		Action:        m.Action,
+		Payload:       m.Payload,
+		OccurredAt:    m.OccurredAt,
+		Attempts:      m.Attempts,
+		LastError:     m.LastError,
+		NextAttemptAt: m.NextAttemptAt,
+		DeliveredAt:   m.DeliveredAt,
+	}
+}
+
+func (m *OutboxMessage) FromEntity(message *entity.OutboxMessage) {
+	m.ID = uint(message.ID)
+	m.Entity = message.Entity
+	m.EntityID = message.EntityID
+	m.Action = message.Action
+	m.Payload = message.Payload
+	// SQLite compares times as text, they must share the time zone
+	m.OccurredAt = message.OccurredAt.UTC()
+	m.Attempts = message.Attempts
+	m.LastError = message.LastError
+	m.NextAttemptAt = message.NextAttemptAt.UTC()
+	m.DeliveredAt = nil
+	if message.DeliveredAt != nil {
+		deliveredAt := message.DeliveredAt.UTC()
+		m.DeliveredAt = &deliveredAt
+	}
+}
+
+// Repository implementation
+type RepoGorm struct {
+	db *gorm.DB
+}
+
+// Utility functions
+func NewGormRepo(db *gorm.DB) *RepoGorm {
+	return &RepoGorm{
+		db: db,
+	}
+}
+
+func RunMigrations(db *gorm.DB) error {
+	return db.AutoMigrate(&OutboxMessage{})
+}
+
+// CRUD functions
+func (r *RepoGorm) Add(ctx context.Context, message *entity.OutboxMessage) error {
+	m := &OutboxMessage{}
+	m.FromEntity(message)
+	if err := r.db.WithContext(ctx).Create(m).Error; err != nil {
+		return err
+	}
+	message.ID = int64(m.ID)
+	return nil
+}
+
+func (r *RepoGorm) FindPending(ctx context.Context, limit int) ([]*entity.OutboxMessage, error) {
+	var messages []*OutboxMessage
+	if err := r.db.WithContext(ctx).
+		Where("delivered_at IS NULL").
+		Order("id").
+		Limit(limit).
+		Find(&messages).Error; err != nil {
+		return nil, err
+	}
+
+	result := make([]*entity.OutboxMessage, len(messages))
+	for i, message := range messages {
+		result[i] = message.ToEntity()
+	}
+	return result, nil
+}
+
+func (r *RepoGorm) Edit(ctx context.Context, message *entity.OutboxMessage) error {
+	m := &OutboxMessage{}
+	m.FromEntity(message)
+	// The event itself doesn't change
+	result := r.db.WithContext(ctx).Model(m).
+		Select("Attempts", "LastError", "NextAttemptAt", "DeliveredAt").
+		Updates(m)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return entity.ErrNotFound
+	}
+	return nil
+}
+
+func (r *RepoGorm) DeleteDelivered(ctx context.Context, before time.Time) (int, error) {
+	result := r.db.WithContext(ctx).Where("delivered_at < ?", before.UTC()).Delete(&OutboxMessage{})
+	return int(result.RowsAffected), result.Error
+}
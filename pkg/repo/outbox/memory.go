@@ -0,0 +1,92 @@
+package outbox
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/TomeuUris/recipes-catalog/pkg/entity"
+)
+
+// RepoMemory keeps the outbox in memory, it is safe for concurrent use. The
+// memory backend has no transactions, messages are added after their write.
+type RepoMemory struct {
+	mu       sync.Mutex
+	lastID   int64
+	messages []*entity.OutboxMessage
+}
+
+func NewMemoryRepo() *RepoMemory {
+	return &RepoMemory{}
+}
+
+func (r *RepoMemory) Add(ctx context.Context, message *entity.OutboxMessage) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.lastID++
+	message.ID = r.lastID
+	r.messages = append(r.messages, copyMessage(message))
+	return nil
+}
+
+func (r *RepoMemory) FindPending(ctx context.Context, limit int) ([]*entity.OutboxMessage, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	result := []*entity.OutboxMessage{}
+	for _, message := range r.messages {
+		if len(result) == limit {
+			break
+		}
+		if message.DeliveredAt == nil {
+			result = append(result, copyMessage(message))
+		}
+	}
+	return result, nil
+}
+
+func (r *RepoMemory) Edit(ctx context.Context, message *entity.OutboxMessage) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, stored := range r.messages {
+		if stored.ID == message.ID {
+			stored.Attempts = message.Attempts
+			stored.LastError = message.LastError
+			stored.NextAttemptAt = message.NextAttemptAt
+			stored.DeliveredAt = copyTime(message.DeliveredAt)
+			return nil
+		}
+	}
+	return entity.ErrNotFound
+}
+
+func (r *RepoMemory) DeleteDelivered(ctx context.Context, before time.Time) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	kept := r.messages[:0]
+	for _, message := range r.messages {
+		if message.DeliveredAt == nil || !message.DeliveredAt.Before(before) {
+			kept = append(kept, message)
+		}
+	}
+	deleted := len(r.messages) - len(kept)
+	r.messages = kept
+	return deleted, nil
+}
+
+func copyMessage(message *entity.OutboxMessage) *entity.OutboxMessage {
+	copied := *message
+	copied.DeliveredAt = copyTime(message.DeliveredAt)
+	return &copied
+}
+
+func copyTime(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	copied := *t
+	return &copied
+}
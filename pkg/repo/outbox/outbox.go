@@ -0,0 +1,22 @@
+// Package outbox stores the domain events of the catalog writes until they
+// are delivered
+package outbox
+
+import (
+	"context"
+	"time"
+
+	"github.com/TomeuUris/recipes-catalog/pkg/entity"
+)
+
+// Repo stores the outbox. Messages must be added in the transaction of their
+// write, so they are only seen once it is committed.
+type Repo interface {
+	Add(ctx context.Context, message *entity.OutboxMessage) error
+	// FindPending returns the undelivered messages, oldest first
+	FindPending(ctx context.Context, limit int) ([]*entity.OutboxMessage, error)
+	// Edit records the outcome of a delivery
+	Edit(ctx context.Context, message *entity.OutboxMessage) error
+	// DeleteDelivered removes the messages delivered before the time
+	DeleteDelivered(ctx context.Context, before time.Time) (int, error)
+}
@@ -0,0 +1,93 @@
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/TomeuUris/recipes-catalog/pkg/entity"
+	"github.com/TomeuUris/recipes-catalog/pkg/sqlite"
+)
+
+func NewRepo(db sqlite.DB) *RepoSQL {
+	return NewConnRepo(sqlite.NewConn(db))
+}
+
+// NewConnRepo returns a repository on the connection, which may run on a
+// transaction shared with other repositories
+func NewConnRepo(conn *sqlite.Conn) *RepoSQL {
+	return &RepoSQL{
+		db: conn,
+	}
+}
+
+type RepoSQL struct {
+	db *sqlite.Conn
+}
+
+func (r *RepoSQL) Add(ctx context.Context, message *entity.OutboxMessage) error {
+	result, err := r.db.ExecContext(ctx, `
+		INSERT INTO outboxMessages (entity, entityId, action, payload, occurredAt, attempts, lastError, nextAttemptAt)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		message.Entity, message.EntityID, message.Action, message.Payload, message.OccurredAt.UTC(),
+		message.Attempts, message.LastError, message.NextAttemptAt.UTC())
+	if err != nil {
+		return err
+	}
+	message.ID, err = result.LastInsertId()
+	return err
+}
+
+func (r *RepoSQL) FindPending(ctx context.Context, limit int) ([]*entity.OutboxMessage, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, entity, entityId, action, payload, occurredAt, attempts, lastError, nextAttemptAt
+		FROM outboxMessages
+		WHERE deliveredAt IS NULL
+		ORDER BY id
+		LIMIT ?`, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	messages := []*entity.OutboxMessage{}
+	for rows.Next() {
+		message := &entity.OutboxMessage{}
+		if err := rows.Scan(&message.ID, &message.Entity, &message.EntityID, &message.Action, &message.Payload,
+			&message.OccurredAt, &message.Attempts, &message.LastError, &message.NextAttemptAt); err != nil {
+			return nil, err
+		}
+		messages = append(messages, message)
+	}
+	return messages, rows.Err()
+}
+
+func (r *RepoSQL) Edit(ctx context.Context, message *entity.OutboxMessage) error {
+	var deliveredAt sql.NullTime
+	if message.DeliveredAt != nil {
+		deliveredAt = sql.NullTime{Time: message.DeliveredAt.UTC(), Valid: true}
+	}
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE outboxMessages SET attempts = ?, lastError = ?, nextAttemptAt = ?, deliveredAt = ? WHERE id = ?`,
+		message.Attempts, message.LastError, message.NextAttemptAt.UTC(), deliveredAt, message.ID)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return entity.ErrNotFound
+	}
+	return nil
+}
+
+func (r *RepoSQL) DeleteDelivered(ctx context.Context, before time.Time) (int, error) {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM outboxMessages WHERE deliveredAt < ?`, before.UTC())
+	if err != nil {
+		return 0, err
+	}
+	deleted, err := result.RowsAffected()
+	return int(deleted), err
+}
@@ -0,0 +1,24 @@
+package recipe_test
+
+import (
+	"testing"
+
+	"github.com/TomeuUris/recipes-catalog/pkg/repo/ingredient"
+	"github.com/TomeuUris/recipes-catalog/pkg/repo/recipe"
+	"github.com/TomeuUris/recipes-catalog/pkg/repo/repotest"
+)
+
+func TestRepoGorm_Conformance(t *testing.T) {
+	repotest.TestRecipeRepo(t, func(t *testing.T) (recipe.Repo, ingredient.Repo) {
+		tx := db.Begin()
+		t.Cleanup(func() { tx.Rollback() })
+		return recipe.NewGormRepo(tx), ingredient.NewGormRepo(tx)
+	})
+}
+
+func TestRepoSQL_Conformance(t *testing.T) {
+	repotest.TestRecipeRepo(t, func(t *testing.T) (recipe.Repo, ingredient.Repo) {
+		repo, sqlDB := newSQLRepo(t)
+		return repo, ingredient.NewRepo(sqlDB)
+	})
+}
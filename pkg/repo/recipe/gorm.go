@@ -2,9 +2,13 @@ package recipe
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"time"
 
 	"github.com/TomeuUris/recipes-catalog/pkg/entity"
 	repo "github.com/TomeuUris/recipes-catalog/pkg/repo/ingredient"
+	"github.com/TomeuUris/recipes-catalog/pkg/sqlite"
 	"gorm.io/gorm"
 )
 
@@ -18,19 +22,30 @@ type RecipeStep struct {
 
 type Recipe struct {
 	gorm.Model
-	Name        string
+	Name string
+	// Slug is the current one, RecipeSlugs keep every slug given
+	Slug        string
 	Description string
 	Ingredients []*repo.Ingredient `gorm:"many2many:recipe_ingredients;"`
 	Steps       []*RecipeStep      `gorm:"foreignKey:RecipeID;constraint:OnDelete:CASCADE"`
+	PrepMinutes int
+	CookMinutes int
+	Yield       string
+	Calories    int
 }
 
 func (r *Recipe) ToEntity() *entity.Recipe {
 	return &entity.Recipe{
 		ID:          int64(r.ID),
 		Name:        r.Name,
+		Slug:        r.Slug,
 		Description: r.Description,
 		Ingredients: r.IngredientsToEntity(),
 		Steps:       r.StepsToEntity(),
+		PrepTime:    time.Duration(r.PrepMinutes) * time.Minute,
+		CookTime:    time.Duration(r.CookMinutes) * time.Minute,
+		Yield:       r.Yield,
+		Calories:    r.Calories,
 	}
 }
 
@@ -40,6 +55,10 @@ func (r *Recipe) FromEntity(recipe *entity.Recipe) {
 	}
 	r.Name = recipe.Name
 	r.Description = recipe.Description
+	r.PrepMinutes = int(recipe.PrepTime / time.Minute)
+	r.CookMinutes = int(recipe.CookTime / time.Minute)
+	r.Yield = recipe.Yield
+	r.Calories = recipe.Calories
 	r.IngredientsFromEntity(recipe.Ingredients)
 	r.StepsFromEntity(recipe.Steps)
 }
@@ -81,6 +100,15 @@ func (r *Recipe) StepsFromEntity(steps []string) {
 	r.Steps = result
 }
 
+// RecipeSlug is a slug given to a recipe, the current one or a previous one.
+// Slugs aren't given to another recipe until the recipe is purged.
+type RecipeSlug struct {
+	ID        uint   `gorm:"primarykey"`
+	Slug      string `gorm:"uniqueIndex"`
+	RecipeID  uint   `gorm:"index"`
+	CreatedAt time.Time
+}
+
 // Repository implementation
 type RepoGorm struct {
 	db *gorm.DB
@@ -94,7 +122,7 @@ func NewGormRepo(db *gorm.DB) *RepoGorm {
 }
 
 func RunMigrations(db *gorm.DB) error {
-	return db.AutoMigrate(&Recipe{}, &RecipeStep{})
+	return db.AutoMigrate(&Recipe{}, &RecipeStep{}, &RecipeSlug{})
 }
 
 // CRUD functions
@@ -106,12 +134,27 @@ func (r *RepoGorm) FindByID(ctx context.Context, id int64) (*entity.Recipe, erro
 		}).
 		Preload("Ingredients").
 		First(recipe, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, entity.ErrNotFound
+		}
 		return nil, err
 	}
 
 	return recipe.ToEntity(), nil
 }
 
+func (r *RepoGorm) FindBySlug(ctx context.Context, slug string) (*entity.Recipe, error) {
+	owner := &RecipeSlug{}
+	if err := r.db.WithContext(ctx).Where("slug = ?", slug).Take(owner).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, entity.ErrNotFound
+		}
+		return nil, err
+	}
+
+	return r.FindByID(ctx, int64(owner.RecipeID))
+}
+
 func (r *RepoGorm) FindByFilter(ctx context.Context, f *FindFilter) ([]*entity.Recipe, error) {
 	var recipes []*Recipe
 	if err := r.db.WithContext(ctx).
@@ -144,7 +187,15 @@ func (r *RepoGorm) CountByFilter(ctx context.Context, f *FindFilter) (int, error
 func (r *RepoGorm) Add(ctx context.Context, recipe *entity.Recipe) error {
 	rp := &Recipe{}
 	rp.FromEntity(recipe)
-	err := r.db.WithContext(ctx).Create(&rp).Error
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := createIngredients(tx, rp.Ingredients); err != nil {
+			return err
+		}
+		if err := tx.Create(&rp).Error; err != nil {
+			return err
+		}
+		return updateSlug(tx, rp, "")
+	})
 	if err != nil {
 		return err
 	}
@@ -156,55 +207,185 @@ func (r *RepoGorm) Edit(ctx context.Context, recipe *entity.Recipe) error {
 	rp := &Recipe{}
 	rp.FromEntity(recipe)
 
-	// Get actual steps
-	var steps []*RecipeStep
-	err := r.db.WithContext(ctx).Where("recipe_id = ?", rp.ID).Find(&steps).Error
-	if err != nil {
-		return err
-	}
-	//If there are less steps than before, delete the last ones (bulk delete). Also drop the ones deleted from the slice
-	if len(steps) > len(rp.Steps) {
-		err := r.db.WithContext(ctx).Delete(&RecipeStep{}, "recipe_id = ? AND `order` >= ?", rp.ID, len(rp.Steps)+1).Error
-		if err != nil {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Update the recipe fields, Save would insert a missing recipe
+		result := tx.Model(&Recipe{}).Where("id = ?", rp.ID).Updates(map[string]interface{}{
+			"name":         rp.Name,
+			"description":  rp.Description,
+			"prep_minutes": rp.PrepMinutes,
+			"cook_minutes": rp.CookMinutes,
+			"yield":        rp.Yield,
+			"calories":     rp.Calories,
+		})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return entity.ErrNotFound
+		}
+
+		// Renaming changes the slug
+		current := &Recipe{}
+		if err := tx.Select("slug").Take(current, rp.ID).Error; err != nil {
 			return err
 		}
-		steps = steps[:len(rp.Steps)]
-	}
-	// Asign id to steps that are not new
-	for i, step := range steps {
-		if i < len(steps) {
-			step.Content = rp.Steps[i].Content
+		if err := updateSlug(tx, rp, current.Slug); err != nil {
+			return err
 		}
-	}
-	// If there are more steps than before, add the new ones
-	if len(steps) < len(rp.Steps) {
-		for i := len(steps); i < len(rp.Steps); i++ {
-			steps = append(steps, &RecipeStep{
-				Content:  rp.Steps[i].Content,
-				Order:    i + 1,
-				RecipeID: rp.ID,
-			})
+
+		// Replace the steps. They are deleted permanently so their order can be reused.
+		if err := tx.Unscoped().Where("recipe_id = ?", rp.ID).Delete(&RecipeStep{}).Error; err != nil {
+			return err
+		}
+		if len(rp.Steps) > 0 {
+			if err := tx.Create(rp.Steps).Error; err != nil {
+				return err
+			}
 		}
-	}
-	rp.Steps = steps
 
-	// Save Recipe
-	err = r.db.WithContext(ctx).Save(rp).Error
+		// Replace the ingredients
+		if err := createIngredients(tx, rp.Ingredients); err != nil {
+			return err
+		}
+		return tx.Model(&Recipe{Model: gorm.Model{ID: rp.ID}}).Association("Ingredients").Replace(rp.Ingredients)
+	})
 	if err != nil {
 		return err
 	}
+
 	recipe.FromEntity(rp.ToEntity())
 	return nil
 }
 
-func (r *RepoGorm) Delete(ctx context.Context, recipe *entity.Recipe) error {
-	rp := &Recipe{}
-	rp.FromEntity(recipe)
-	// Delete steps
-	err := r.db.WithContext(ctx).Delete(&RecipeStep{}, "recipe_id = ?", rp.ID).Error
+// createIngredients creates the new ingredients of a recipe. Associations are
+// saved ignoring conflicts, which would hide duplicated names.
+func createIngredients(tx *gorm.DB, ingredients []*repo.Ingredient) error {
+	for _, ingredient := range ingredients {
+		if ingredient.ID != 0 {
+			continue
+		}
+		if err := tx.Create(ingredient).Error; err != nil {
+			if sqlite.IsUniqueViolation(err) {
+				return fmt.Errorf("ingredient %q: %w", ingredient.Name, entity.ErrAlreadyExists)
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// updateSlug gives the recipe the slug of its name, the current one is kept
+// while it matches
+func updateSlug(tx *gorm.DB, rp *Recipe, current string) error {
+	recipe := &entity.Recipe{ID: int64(rp.ID), Name: rp.Name}
+	slug, err := assignSlug(tx.Statement.Context, recipe, current, func(ctx context.Context, slug string) (int64, error) {
+		owner := &RecipeSlug{}
+		err := tx.Where("slug = ?", slug).Limit(1).Find(owner).Error
+		return int64(owner.RecipeID), err
+	})
 	if err != nil {
 		return err
 	}
-	// Delete recipe
-	return r.db.WithContext(ctx).Delete(rp).Error
+
+	rp.Slug = slug
+	if slug == current {
+		return nil
+	}
+	if err := tx.Model(&Recipe{}).Where("id = ?", rp.ID).Update("slug", slug).Error; err != nil {
+		return err
+	}
+	// The recipe may have had the slug before
+	return tx.Where(&RecipeSlug{Slug: slug, RecipeID: rp.ID}).FirstOrCreate(&RecipeSlug{}).Error
+}
+
+func (r *RepoGorm) Delete(ctx context.Context, recipe *entity.Recipe) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Delete recipe
+		result := tx.Delete(&Recipe{}, recipe.ID)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return entity.ErrNotFound
+		}
+
+		// Delete steps
+		return tx.Delete(&RecipeStep{}, "recipe_id = ?", recipe.ID).Error
+	})
+}
+
+// Trash functions
+func (r *RepoGorm) FindDeleted(ctx context.Context) ([]*entity.TrashItem, error) {
+	var recipes []*Recipe
+	if err := r.db.WithContext(ctx).Unscoped().
+		Where("deleted_at IS NOT NULL").
+		Order("deleted_at DESC").
+		Find(&recipes).Error; err != nil {
+		return nil, err
+	}
+	return toTrashItems(recipes), nil
+}
+
+func toTrashItems(recipes []*Recipe) []*entity.TrashItem {
+	result := make([]*entity.TrashItem, len(recipes))
+	for i, recipe := range recipes {
+		result[i] = &entity.TrashItem{
+			Entity:    entity.TrashRecipe,
+			ID:        int64(recipe.ID),
+			Name:      recipe.Name,
+			DeletedAt: recipe.DeletedAt.Time,
+		}
+	}
+	return result
+}
+
+func (r *RepoGorm) Restore(ctx context.Context, id int64) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Unscoped().Model(&Recipe{}).
+			Where("id = ? AND deleted_at IS NOT NULL", id).
+			Update("deleted_at", nil)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return entity.ErrNotFound
+		}
+
+		// Steps are only soft deleted together with their recipe, edits remove them permanently
+		return tx.Unscoped().Model(&RecipeStep{}).
+			Where("recipe_id = ? AND deleted_at IS NOT NULL", id).
+			Update("deleted_at", nil).Error
+	})
+}
+
+func (r *RepoGorm) Purge(ctx context.Context, before time.Time) ([]*entity.TrashItem, error) {
+	var expired []*Recipe
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("deleted_at < ?", before).Order("id").Find(&expired).Error; err != nil {
+			return err
+		}
+		if len(expired) == 0 {
+			return nil
+		}
+		ids := make([]uint, len(expired))
+		for i, recipe := range expired {
+			ids[i] = recipe.ID
+		}
+
+		if err := tx.Exec("DELETE FROM recipe_ingredients WHERE recipe_id IN (?)", ids).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Where("recipe_id IN (?)", ids).Delete(&RecipeStep{}).Error; err != nil {
+			return err
+		}
+		// Their slugs can be given to other recipes
+		if err := tx.Where("recipe_id IN (?)", ids).Delete(&RecipeSlug{}).Error; err != nil {
+			return err
+		}
+		return tx.Unscoped().Delete(&Recipe{}, ids).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return toTrashItems(expired), nil
 }
@@ -23,7 +23,7 @@ func TestMain(m *testing.M) {
 		panic("failed to connect database")
 	}
 	// Migrate the database schema
-	err = db.AutoMigrate(&recipe.Recipe{}, &ingredient.Ingredient{}, &recipe.RecipeStep{})
+	err = db.AutoMigrate(&recipe.Recipe{}, &ingredient.Ingredient{}, &recipe.RecipeStep{}, &recipe.RecipeSlug{})
 	if err != nil {
 		panic("failed to migrate database schema")
 	}
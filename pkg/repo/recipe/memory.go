@@ -0,0 +1,348 @@
+package recipe
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/TomeuUris/recipes-catalog/pkg/entity"
+	"github.com/TomeuUris/recipes-catalog/pkg/repo/ingredient"
+)
+
+// RepoMemory keeps the recipes in memory, it is safe for concurrent use.
+// Recipes only store the IDs of their ingredients, which are read from the
+// ingredients repository like gorm preloads them.
+type RepoMemory struct {
+	mu      sync.RWMutex
+	lastID  int64
+	recipes map[int64]*entity.Recipe
+	// slugs has the recipe of every slug given, current and previous
+	slugs       map[string]int64
+	ingredients ingredient.Repo
+}
+
+// NewMemoryRepo returns a repository reading the ingredients from the given one,
+// it registers itself as referrer of a memory ingredients repository
+func NewMemoryRepo(ingredients ingredient.Repo) *RepoMemory {
+	r := &RepoMemory{
+		recipes:     map[int64]*entity.Recipe{},
+		slugs:       map[string]int64{},
+		ingredients: ingredients,
+	}
+	if memory, ok := ingredients.(*ingredient.RepoMemory); ok {
+		memory.SetReferrer(r)
+	}
+	return r
+}
+
+func (r *RepoMemory) FindByID(ctx context.Context, id int64) (*entity.Recipe, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	recipe, ok := r.recipes[id]
+	if !ok {
+		return nil, entity.ErrNotFound
+	}
+	return r.load(ctx, recipe)
+}
+
+func (r *RepoMemory) FindBySlug(ctx context.Context, slug string) (*entity.Recipe, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	recipe, ok := r.recipes[r.slugs[slug]]
+	if !ok {
+		return nil, entity.ErrNotFound
+	}
+	return r.load(ctx, recipe)
+}
+
+func (r *RepoMemory) FindByFilter(ctx context.Context, f *FindFilter) ([]*entity.Recipe, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	stored := r.filter(f)
+	result := make([]*entity.Recipe, len(stored))
+	for i, recipe := range stored {
+		loaded, err := r.load(ctx, recipe)
+		if err != nil {
+			return nil, err
+		}
+		result[i] = loaded
+	}
+	return result, nil
+}
+
+func (r *RepoMemory) CountByFilter(ctx context.Context, f *FindFilter) (int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return len(r.filter(f)), nil
+}
+
+func (r *RepoMemory) Add(ctx context.Context, recipe *entity.Recipe) error {
+	stored, err := r.store(ctx, recipe)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.lastID++
+	recipe.ID = r.lastID
+	stored.ID = r.lastID
+	r.setSlug(stored, "")
+	recipe.Slug = stored.Slug
+	r.recipes[stored.ID] = stored
+	return nil
+}
+
+func (r *RepoMemory) Edit(ctx context.Context, recipe *entity.Recipe) error {
+	r.mu.RLock()
+	_, ok := r.recipes[recipe.ID]
+	r.mu.RUnlock()
+	if !ok {
+		return entity.ErrNotFound
+	}
+
+	stored, err := r.store(ctx, recipe)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// It may have been deleted meanwhile
+	current, ok := r.recipes[recipe.ID]
+	if !ok {
+		return entity.ErrNotFound
+	}
+	r.setSlug(stored, current.Slug)
+	recipe.Slug = stored.Slug
+	r.recipes[recipe.ID] = stored
+	return nil
+}
+
+func (r *RepoMemory) Delete(ctx context.Context, recipe *entity.Recipe) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.recipes[recipe.ID]; !ok {
+		return entity.ErrNotFound
+	}
+	delete(r.recipes, recipe.ID)
+	// Its slugs can be given to other recipes
+	for slug, id := range r.slugs {
+		if id == recipe.ID {
+			delete(r.slugs, slug)
+		}
+	}
+	return nil
+}
+
+// setSlug gives the recipe the slug of its name, the current one is kept
+// while it matches. The caller must hold the lock.
+func (r *RepoMemory) setSlug(recipe *entity.Recipe, current string) {
+	// The owners are in memory, looking them up doesn't fail
+	recipe.Slug, _ = assignSlug(context.Background(), recipe, current, func(ctx context.Context, slug string) (int64, error) {
+		return r.slugs[slug], nil
+	})
+	r.slugs[recipe.Slug] = recipe.ID
+}
+
+// FindByIngredient returns the recipes using the ingredient with their ID and name
+func (r *RepoMemory) FindByIngredient(ctx context.Context, id int64) ([]*entity.Recipe, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var result []*entity.Recipe
+	for _, recipe := range r.filter(nil) {
+		for _, ingredient := range recipe.Ingredients {
+			if ingredient.ID == id {
+				result = append(result, &entity.Recipe{ID: recipe.ID, Name: recipe.Name})
+				break
+			}
+		}
+	}
+	return result, nil
+}
+
+// DetachIngredient removes the ingredient from every recipe using it
+func (r *RepoMemory) DetachIngredient(ctx context.Context, id int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, recipe := range r.recipes {
+		kept := recipe.Ingredients[:0]
+		for _, ingredient := range recipe.Ingredients {
+			if ingredient.ID != id {
+				kept = append(kept, ingredient)
+			}
+		}
+		recipe.Ingredients = kept
+	}
+	return nil
+}
+
+// ReplaceIngredients makes the recipes using any of the ingredients use the target once
+func (r *RepoMemory) ReplaceIngredients(ctx context.Context, ids []int64, target int64) error {
+	replaced := make(map[int64]bool, len(ids))
+	for _, id := range ids {
+		replaced[id] = true
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, recipe := range r.recipes {
+		kept := make([]*entity.Ingredient, 0, len(recipe.Ingredients))
+		linked := false
+		for _, ingredient := range recipe.Ingredients {
+			if replaced[ingredient.ID] || ingredient.ID == target {
+				if linked {
+					continue
+				}
+				linked = true
+				ingredient = &entity.Ingredient{ID: target}
+			}
+			kept = append(kept, ingredient)
+		}
+		recipe.Ingredients = kept
+	}
+	return nil
+}
+
+// Snapshot returns every recipe ordered by ID, ingredients only have their ID set
+func (r *RepoMemory) Snapshot() []*entity.Recipe {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	stored := r.filter(nil)
+	result := make([]*entity.Recipe, len(stored))
+	for i, recipe := range stored {
+		result[i] = copyRecipe(recipe)
+	}
+	return result
+}
+
+// Slugs returns the recipe of every slug given, current and previous
+func (r *RepoMemory) Slugs() map[string]int64 {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	result := make(map[string]int64, len(r.slugs))
+	for slug, id := range r.slugs {
+		result[slug] = id
+	}
+	return result
+}
+
+// Restore replaces the recipes with the ones of a snapshot. Recipes of
+// snapshots older than slugs are given one.
+func (r *RepoMemory) Restore(recipes []*entity.Recipe) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.recipes = make(map[int64]*entity.Recipe, len(recipes))
+	r.slugs = map[string]int64{}
+	r.lastID = 0
+	for _, recipe := range recipes {
+		stored := copyRecipe(recipe)
+		for i, ingredient := range stored.Ingredients {
+			stored.Ingredients[i] = &entity.Ingredient{ID: ingredient.ID}
+		}
+		r.recipes[stored.ID] = stored
+		if stored.Slug != "" {
+			r.slugs[stored.Slug] = stored.ID
+		}
+		if stored.ID > r.lastID {
+			r.lastID = stored.ID
+		}
+	}
+	for _, recipe := range r.filter(nil) {
+		if recipe.Slug == "" {
+			r.setSlug(recipe, "")
+		}
+	}
+}
+
+// RestoreSlugs adds the previous slugs of a snapshot, the ones of missing
+// recipes or taken by another recipe are skipped. Call it after Restore.
+func (r *RepoMemory) RestoreSlugs(slugs map[string]int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for slug, id := range slugs {
+		if _, ok := r.recipes[id]; !ok {
+			continue
+		}
+		if _, taken := r.slugs[slug]; !taken {
+			r.slugs[slug] = id
+		}
+	}
+}
+
+// store returns the copy of the recipe to keep. Ingredients without ID are
+// created, like gorm does with associations.
+func (r *RepoMemory) store(ctx context.Context, recipe *entity.Recipe) (*entity.Recipe, error) {
+	stored := copyRecipe(recipe)
+	for i, ingredient := range recipe.Ingredients {
+		if ingredient.ID == 0 {
+			if err := r.ingredients.Add(ctx, ingredient); err != nil {
+				return nil, err
+			}
+		} else if _, err := r.ingredients.FindByID(ctx, int(ingredient.ID)); err != nil {
+			return nil, fmt.Errorf("ingredient %d: %w", ingredient.ID, err)
+		}
+		stored.Ingredients[i] = &entity.Ingredient{ID: ingredient.ID}
+	}
+	return stored, nil
+}
+
+// load returns a copy of the stored recipe with its ingredients, deleted
+// ingredients are skipped. The caller must hold the lock.
+func (r *RepoMemory) load(ctx context.Context, stored *entity.Recipe) (*entity.Recipe, error) {
+	recipe := copyRecipe(stored)
+	recipe.Ingredients = make([]*entity.Ingredient, 0, len(stored.Ingredients))
+	for _, ref := range stored.Ingredients {
+		ingredient, err := r.ingredients.FindByID(ctx, int(ref.ID))
+		if err != nil {
+			if entity.IsErrNotFound(err) {
+				continue
+			}
+			return nil, err
+		}
+		recipe.Ingredients = append(recipe.Ingredients, ingredient)
+	}
+	return recipe, nil
+}
+
+// filter returns the stored recipes matching the filter ordered by ID, empty
+// fields are not filtered like in gorm. The caller must hold the lock.
+func (r *RepoMemory) filter(f *FindFilter) []*entity.Recipe {
+	result := []*entity.Recipe{}
+	for _, recipe := range r.recipes {
+		if f != nil && f.Id != 0 && recipe.ID != int64(f.Id) {
+			continue
+		}
+		result = append(result, recipe)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].ID < result[j].ID
+	})
+	return result
+}
+
+func copyRecipe(recipe *entity.Recipe) *entity.Recipe {
+	copied := *recipe
+	copied.Steps = append([]string{}, recipe.Steps...)
+	copied.Ingredients = make([]*entity.Ingredient, len(recipe.Ingredients))
+	for i, ingredient := range recipe.Ingredients {
+		ingredientCopy := *ingredient
+		copied.Ingredients[i] = &ingredientCopy
+	}
+	return &copied
+}
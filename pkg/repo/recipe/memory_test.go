@@ -0,0 +1,47 @@
+package recipe_test
+
+import (
+	"testing"
+
+	"github.com/TomeuUris/recipes-catalog/pkg/repo/ingredient"
+	"github.com/TomeuUris/recipes-catalog/pkg/repo/recipe"
+	"github.com/TomeuUris/recipes-catalog/pkg/repo/repotest"
+)
+
+func TestRepoMemory_Conformance(t *testing.T) {
+	repotest.TestRecipeRepo(t, func(t *testing.T) (recipe.Repo, ingredient.Repo) {
+		ingredients := ingredient.NewMemoryRepo()
+		return recipe.NewMemoryRepo(ingredients), ingredients
+	})
+}
+
+func TestRepoMemory_SnapshotRestore(t *testing.T) {
+	ingredients := ingredient.NewMemoryRepo()
+	repo := recipe.NewMemoryRepo(ingredients)
+	recipeExample := getExampleRecipeEntity()
+	if err := repo.Add(ctx, recipeExample); err != nil {
+		t.Fatalf("failed to add recipe: %v", err)
+	}
+	previousSlug := recipeExample.Slug
+	recipeExample.Name = "Renamed"
+	if err := repo.Edit(ctx, recipeExample); err != nil {
+		t.Fatalf("failed to edit recipe: %v", err)
+	}
+
+	// Ingredients are restored separately
+	restoredIngredients := ingredient.NewMemoryRepo()
+	restoredIngredients.Restore(ingredients.Snapshot())
+	restored := recipe.NewMemoryRepo(restoredIngredients)
+	restored.Restore(repo.Snapshot())
+	restored.RestoreSlugs(repo.Slugs())
+
+	// The previous slug keeps finding it
+	recipeFound, err := restored.FindBySlug(ctx, previousSlug)
+	if err != nil {
+		t.Fatalf("failed to find recipe: %v", err)
+	}
+	if recipeFound.Name != recipeExample.Name || len(recipeFound.Steps) != 2 ||
+		len(recipeFound.Ingredients) != 1 || recipeFound.Ingredients[0].Name != "ingredient" {
+		t.Fatalf("expected recipe %+v, got %+v", recipeExample, recipeFound)
+	}
+}
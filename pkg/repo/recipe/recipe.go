@@ -8,6 +8,9 @@ import (
 
 type Repo interface {
 	FindByID(ctx context.Context, id int64) (*entity.Recipe, error)
+	// FindBySlug returns the recipe with the slug, or which had it before
+	// being renamed. Its Slug is the current one.
+	FindBySlug(ctx context.Context, slug string) (*entity.Recipe, error)
 	FindByFilter(ctx context.Context, f *FindFilter) ([]*entity.Recipe, error)
 	CountByFilter(ctx context.Context, f *FindFilter) (int, error)
 	Add(ctx context.Context, recipe *entity.Recipe) error
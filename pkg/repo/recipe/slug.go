@@ -0,0 +1,32 @@
+package recipe
+
+import (
+	"context"
+
+	"github.com/TomeuUris/recipes-catalog/pkg/entity"
+)
+
+// slugOwner returns the ID of the recipe the slug is or was given to, 0 when
+// it was never given
+type slugOwner func(ctx context.Context, slug string) (int64, error)
+
+// assignSlug returns the slug of the recipe named like it is. The current slug
+// is kept while it matches the name, otherwise the first candidate of the name
+// not given to another recipe is taken, so renaming back restores the old one.
+func assignSlug(ctx context.Context, recipe *entity.Recipe, current string, owner slugOwner) (string, error) {
+	base := recipe.SlugBase()
+	if current != "" && entity.IsSlugCandidate(current, base) {
+		return current, nil
+	}
+
+	for n := 1; ; n++ {
+		candidate := entity.SlugCandidate(base, n)
+		id, err := owner(ctx, candidate)
+		if err != nil {
+			return "", err
+		}
+		if id == 0 || id == recipe.ID {
+			return candidate, nil
+		}
+	}
+}
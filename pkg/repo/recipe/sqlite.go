@@ -4,74 +4,113 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
+	"strings"
+	"time"
 
 	"github.com/TomeuUris/recipes-catalog/pkg/entity"
+	"github.com/TomeuUris/recipes-catalog/pkg/sqlite"
 )
 
-func NewRepo(db *sql.DB) *RepoSQL {
+func NewRepo(db sqlite.DB) *RepoSQL {
+	return NewConnRepo(sqlite.NewConn(db))
+}
+
+// NewConnRepo returns a repository on the connection, which may run on a
+// transaction shared with other repositories
+func NewConnRepo(conn *sqlite.Conn) *RepoSQL {
 	return &RepoSQL{
-		db: db,
+		db: conn,
 	}
 }
 
 type RepoSQL struct {
-	db *sql.DB
+	db *sqlite.Conn
 }
 
 func (r *RepoSQL) FindByID(ctx context.Context, id int64) (*entity.Recipe, error) {
-	// Get recipe steps
-	rows, err := r.db.QueryContext(ctx, `SELECT content FROM recipeSteps WHERE recipeId = ? ORDER BY stepNo ASC`, id)
-	if err != nil {
+	// Get recipe
+	recipe := &entity.Recipe{}
+	row := r.db.QueryRowContext(ctx, `SELECT id, name, slug, description, prepMinutes, cookMinutes, yield, calories FROM recipes WHERE id = ?`, id)
+	if err := scanRecipe(row, recipe); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, entity.ErrNotFound
 		}
 		return nil, err
 	}
 
-	var steps []string
-	for i := 0; rows.Next(); i++ {
-		var temp string
-		rows.Scan(&temp)
+	if err := r.loadDetails(ctx, recipe); err != nil {
+		return nil, err
+	}
+	return recipe, nil
+}
 
-		steps = append(steps, temp)
+// scanRecipe scans the columns of the recipes table, details included, the
+// ingredients and steps are read on their own
+func scanRecipe(row interface{ Scan(...interface{}) error }, recipe *entity.Recipe) error {
+	var prepMinutes, cookMinutes int
+	if err := row.Scan(&recipe.ID, &recipe.Name, &recipe.Slug, &recipe.Description, &prepMinutes, &cookMinutes, &recipe.Yield, &recipe.Calories); err != nil {
+		return err
 	}
+	recipe.PrepTime = time.Duration(prepMinutes) * time.Minute
+	recipe.CookTime = time.Duration(cookMinutes) * time.Minute
+	return nil
+}
 
-	rows, err = r.db.QueryContext(ctx, `
-		SELECT
-			ri.ingredientId, i.name, i.type
-		FROM recipeIngredients ri
-		JOIN ingredients i ON (ri.ingredientId = i.id)
-		WHERE ri.recipeId = ?`, id)
-	if err != nil {
+// minutes returns the duration in whole minutes, the unit the times are
+// stored in
+func minutes(d time.Duration) int {
+	return int(d / time.Minute)
+}
+
+func (r *RepoSQL) FindBySlug(ctx context.Context, slug string) (*entity.Recipe, error) {
+	var id int64
+	if err := r.db.QueryRowContext(ctx, `SELECT recipeId FROM recipeSlugs WHERE slug = ?`, slug).Scan(&id); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, entity.ErrNotFound
 		}
 		return nil, err
 	}
+	return r.FindByID(ctx, id)
+}
 
-	var ingredients []*entity.Ingredient
-	for rows.Next() {
-		ingredient := &entity.Ingredient{}
-		rows.Scan(&ingredient.ID, &ingredient.Name, &ingredient.Type)
-		ingredients = append(ingredients, ingredient)
+func (r *RepoSQL) FindByFilter(ctx context.Context, f *FindFilter) ([]*entity.Recipe, error) {
+	where, args := filterClause(f)
+	rows, err := r.db.QueryContext(ctx, `SELECT id, name, slug, description, prepMinutes, cookMinutes, yield, calories FROM recipes`+where+` ORDER BY id`, args...)
+	if err != nil {
+		return nil, err
 	}
+	defer rows.Close()
 
-	// Get recipe
-	row := r.db.QueryRowContext(ctx, `SELECT id, name FROM recipes WHERE id = ?`, id)
-	if err := row.Err(); err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return nil, entity.ErrNotFound
+	recipes := []*entity.Recipe{}
+	for rows.Next() {
+		recipe := &entity.Recipe{}
+		if err := scanRecipe(rows, recipe); err != nil {
+			return nil, err
 		}
+		recipes = append(recipes, recipe)
+	}
+	if err := rows.Err(); err != nil {
 		return nil, err
 	}
+	rows.Close()
 
-	recipe := &entity.Recipe{
-		Ingredients: ingredients,
-		Steps:       steps,
+	for _, recipe := range recipes {
+		if err := r.loadDetails(ctx, recipe); err != nil {
+			return nil, err
+		}
 	}
+	return recipes, nil
+}
 
-	row.Scan(&recipe.ID, &recipe.Name)
-	return recipe, nil
+func (r *RepoSQL) CountByFilter(ctx context.Context, f *FindFilter) (int, error) {
+	where, args := filterClause(f)
+
+	var count int
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM recipes`+where, args...).Scan(&count); err != nil {
+		return 0, err
+	}
+	return count, nil
 }
 
 func (r *RepoSQL) Add(ctx context.Context, recipe *entity.Recipe) error {
@@ -79,8 +118,11 @@ func (r *RepoSQL) Add(ctx context.Context, recipe *entity.Recipe) error {
 	if err != nil {
 		return err
 	}
+	// Rolling back a committed transaction is a no-op
+	defer tx.Rollback()
 
-	result, err := tx.ExecContext(ctx, `INSERT INTO recipes (name) VALUES (?)`, recipe.Name)
+	result, err := tx.ExecContext(ctx, `INSERT INTO recipes (name, description, prepMinutes, cookMinutes, yield, calories) VALUES (?, ?, ?, ?, ?, ?)`,
+		recipe.Name, recipe.Description, minutes(recipe.PrepTime), minutes(recipe.CookTime), recipe.Yield, recipe.Calories)
 	if err != nil {
 		return err
 	}
@@ -89,16 +131,219 @@ func (r *RepoSQL) Add(ctx context.Context, recipe *entity.Recipe) error {
 	if err != nil {
 		return err
 	}
+	if err := setSlug(ctx, tx, recipe, ""); err != nil {
+		return err
+	}
+
+	if err := insertDetails(ctx, tx, recipe); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *RepoSQL) Edit(ctx context.Context, recipe *entity.Recipe) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `UPDATE recipes SET name = ?, description = ?, prepMinutes = ?, cookMinutes = ?, yield = ?, calories = ? WHERE id = ?`,
+		recipe.Name, recipe.Description, minutes(recipe.PrepTime), minutes(recipe.CookTime), recipe.Yield, recipe.Calories, recipe.ID)
+	if err != nil {
+		return err
+	}
+	if err := expectAffected(result); err != nil {
+		return err
+	}
+
+	// Renaming changes the slug
+	var current string
+	if err := tx.QueryRowContext(ctx, `SELECT slug FROM recipes WHERE id = ?`, recipe.ID).Scan(&current); err != nil {
+		return err
+	}
+	if err := setSlug(ctx, tx, recipe, current); err != nil {
+		return err
+	}
+
+	// Replace the steps and ingredients
+	if err := deleteDetails(ctx, tx, recipe.ID); err != nil {
+		return err
+	}
+	if err := insertDetails(ctx, tx, recipe); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *RepoSQL) Delete(ctx context.Context, recipe *entity.Recipe) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Foreign keys are only enforced on the connection they were enabled on,
+	// so the details are deleted explicitly instead of relying on the cascade
+	if err := deleteDetails(ctx, tx, recipe.ID); err != nil {
+		return err
+	}
+	// Its slugs can be given to other recipes
+	if _, err := tx.ExecContext(ctx, `DELETE FROM recipeSlugs WHERE recipeId = ?`, recipe.ID); err != nil {
+		return err
+	}
+
+	result, err := tx.ExecContext(ctx, `DELETE FROM recipes WHERE id = ?`, recipe.ID)
+	if err != nil {
+		return err
+	}
+	if err := expectAffected(result); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// loadDetails fills the steps and ingredients of the recipe
+func (r *RepoSQL) loadDetails(ctx context.Context, recipe *entity.Recipe) error {
+	// Get recipe steps
+	rows, err := r.db.QueryContext(ctx, `SELECT content FROM recipeSteps WHERE recipeId = ? ORDER BY stepNo ASC`, recipe.ID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	recipe.Steps = []string{}
+	for rows.Next() {
+		var step string
+		if err := rows.Scan(&step); err != nil {
+			return err
+		}
+		recipe.Steps = append(recipe.Steps, step)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	rows.Close()
+
+	// Get recipe ingredients
+	rows, err = r.db.QueryContext(ctx, `
+		SELECT
+			ri.ingredientId, i.name, i.type
+		FROM recipeIngredients ri
+		JOIN ingredients i ON (ri.ingredientId = i.id)
+		WHERE ri.recipeId = ?
+		ORDER BY ri.id`, recipe.ID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	recipe.Ingredients = []*entity.Ingredient{}
+	for rows.Next() {
+		ingredient := &entity.Ingredient{}
+		if err := rows.Scan(&ingredient.ID, &ingredient.Name, &ingredient.Type); err != nil {
+			return err
+		}
+		recipe.Ingredients = append(recipe.Ingredients, ingredient)
+	}
+	return rows.Err()
+}
 
+// insertDetails stores the steps and ingredients of the recipe. Ingredients
+// without ID are created, like gorm does with associations.
+func insertDetails(ctx context.Context, tx *sqlite.Tx, recipe *entity.Recipe) error {
 	for i, step := range recipe.Steps {
-		tx.ExecContext(ctx, `INSERT INTO recipeSteps (recipeId, stepNo, content) VALUES (?, ?, ?)`,
-			recipe.ID, i, step)
+		if _, err := tx.ExecContext(ctx, `INSERT INTO recipeSteps (recipeId, stepNo, content) VALUES (?, ?, ?)`,
+			recipe.ID, i, step); err != nil {
+			return err
+		}
 	}
 
 	for _, ingredient := range recipe.Ingredients {
-		tx.ExecContext(ctx, `INSERT INTO recipeIngredients (recipeId, ingredientId) VALUES (?, ?)`,
-			recipe.ID, ingredient.ID)
+		if ingredient.ID == 0 {
+			result, err := tx.ExecContext(ctx, `INSERT INTO ingredients (name, nameKey, type) VALUES (?, ?, ?)`,
+				ingredient.Name, entity.NormalizeName(ingredient.Name), ingredient.Type)
+			if err != nil {
+				if sqlite.IsUniqueViolation(err) {
+					return fmt.Errorf("ingredient %q: %w", ingredient.Name, entity.ErrAlreadyExists)
+				}
+				return err
+			}
+			if ingredient.ID, err = result.LastInsertId(); err != nil {
+				return err
+			}
+		}
+
+		if _, err := tx.ExecContext(ctx, `INSERT INTO recipeIngredients (recipeId, ingredientId) VALUES (?, ?)`,
+			recipe.ID, ingredient.ID); err != nil {
+			return err
+		}
 	}
+	return nil
+}
 
-	return tx.Commit()
+// setSlug gives the recipe the slug of its name, the current one is kept
+// while it matches
+func setSlug(ctx context.Context, tx *sqlite.Tx, recipe *entity.Recipe, current string) error {
+	slug, err := assignSlug(ctx, recipe, current, func(ctx context.Context, slug string) (int64, error) {
+		var id int64
+		err := tx.QueryRowContext(ctx, `SELECT recipeId FROM recipeSlugs WHERE slug = ?`, slug).Scan(&id)
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, nil
+		}
+		return id, err
+	})
+	if err != nil {
+		return err
+	}
+
+	recipe.Slug = slug
+	if slug == current {
+		return nil
+	}
+	if _, err := tx.ExecContext(ctx, `UPDATE recipes SET slug = ? WHERE id = ?`, slug, recipe.ID); err != nil {
+		return err
+	}
+	// The recipe may have had the slug before
+	_, err = tx.ExecContext(ctx, `INSERT OR IGNORE INTO recipeSlugs (slug, recipeId) VALUES (?, ?)`, slug, recipe.ID)
+	return err
+}
+
+func deleteDetails(ctx context.Context, tx *sqlite.Tx, recipeID int64) error {
+	if _, err := tx.ExecContext(ctx, `DELETE FROM recipeSteps WHERE recipeId = ?`, recipeID); err != nil {
+		return err
+	}
+	_, err := tx.ExecContext(ctx, `DELETE FROM recipeIngredients WHERE recipeId = ?`, recipeID)
+	return err
+}
+
+// filterClause builds the WHERE clause of the filter, empty fields are not filtered like in gorm
+func filterClause(f *FindFilter) (string, []interface{}) {
+	if f == nil {
+		return "", nil
+	}
+
+	var conditions []string
+	var args []interface{}
+	if f.Id != 0 {
+		conditions = append(conditions, "id = ?")
+		args = append(args, f.Id)
+	}
+
+	if len(conditions) == 0 {
+		return "", nil
+	}
+	return " WHERE " + strings.Join(conditions, " AND "), args
+}
+
+// expectAffected returns entity.ErrNotFound when the statement didn't match any row
+func expectAffected(result sql.Result) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return entity.ErrNotFound
+	}
+	return nil
 }
@@ -0,0 +1,176 @@
+package recipe_test
+
+import (
+	"database/sql"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/TomeuUris/recipes-catalog/pkg/entity"
+	"github.com/TomeuUris/recipes-catalog/pkg/repo/recipe"
+	"github.com/TomeuUris/recipes-catalog/pkg/sqlite"
+	"github.com/TomeuUris/recipes-catalog/pkg/sqlite/migrations"
+)
+
+func newSQLRepo(t *testing.T) (*recipe.RepoSQL, *sql.DB) {
+	sqlDB, err := sqlite.Open(":memory:")
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	// Every connection to :memory: opens a new database
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+
+	if err := migrations.Store.Apply(ctx, sqlDB); err != nil {
+		t.Fatalf("failed to migrate database: %v", err)
+	}
+	return recipe.NewRepo(sqlDB), sqlDB
+}
+
+func TestRepoSQL_AddAndFindByID(t *testing.T) {
+	repo, _ := newSQLRepo(t)
+
+	recipeExample := getExampleRecipeEntity()
+	if err := repo.Add(ctx, recipeExample); err != nil {
+		t.Fatalf("failed to add recipe: %v", err)
+	}
+	if recipeExample.ID == 0 || recipeExample.Ingredients[0].ID == 0 {
+		t.Fatalf("expected the recipe and its ingredient to get an ID, got %+v", recipeExample)
+	}
+
+	recipeFound, err := repo.FindByID(ctx, recipeExample.ID)
+	if err != nil {
+		t.Fatalf("failed to find recipe: %v", err)
+	}
+	if !reflect.DeepEqual(recipeFound, recipeExample) {
+		t.Fatalf("expected recipe %+v, got %+v", recipeExample, recipeFound)
+	}
+
+	// Missing recipes are not found
+	if _, err := repo.FindByID(ctx, recipeExample.ID+1); !entity.IsErrNotFound(err) {
+		t.Fatalf("expected not found error, got %v", err)
+	}
+}
+
+func TestRepoSQL_AddRollsBack(t *testing.T) {
+	repo, _ := newSQLRepo(t)
+
+	// The ingredient doesn't exist, so linking it violates the foreign key
+	recipeExample := getExampleRecipeEntity()
+	recipeExample.Ingredients[0].ID = 42
+	if err := repo.Add(ctx, recipeExample); err == nil {
+		t.Fatalf("expected error adding recipe with missing ingredient")
+	}
+
+	count, err := repo.CountByFilter(ctx, &recipe.FindFilter{})
+	if err != nil {
+		t.Fatalf("failed to count recipes: %v", err)
+	}
+	if count != 0 {
+		t.Fatalf("expected the recipe to be rolled back, got %d recipes", count)
+	}
+}
+
+func TestRepoSQL_FindByFilter(t *testing.T) {
+	repo, _ := newSQLRepo(t)
+
+	for i := 0; i < 2; i++ {
+		// Ingredient names are unique per type
+		recipeExample := getExampleRecipeEntity()
+		recipeExample.Ingredients[0].Name = fmt.Sprintf("%s %d", recipeExample.Ingredients[0].Name, i)
+		if err := repo.Add(ctx, recipeExample); err != nil {
+			t.Fatalf("failed to add recipe: %v", err)
+		}
+	}
+
+	recipes, err := repo.FindByFilter(ctx, &recipe.FindFilter{})
+	if err != nil {
+		t.Fatalf("failed to find recipes: %v", err)
+	}
+	if len(recipes) != 2 {
+		t.Fatalf("expected 2 recipes, got %d", len(recipes))
+	}
+	for _, r := range recipes {
+		if len(r.Steps) != 2 || len(r.Ingredients) != 1 {
+			t.Fatalf("expected recipe details to be loaded, got %+v", r)
+		}
+	}
+
+	recipes, err = repo.FindByFilter(ctx, &recipe.FindFilter{Id: int(recipes[1].ID)})
+	if err != nil {
+		t.Fatalf("failed to find recipes: %v", err)
+	}
+	if len(recipes) != 1 {
+		t.Fatalf("expected 1 recipe, got %d", len(recipes))
+	}
+
+	count, err := repo.CountByFilter(ctx, &recipe.FindFilter{Id: int(recipes[0].ID)})
+	if err != nil {
+		t.Fatalf("failed to count recipes: %v", err)
+	}
+	if count != 1 {
+		t.Fatalf("expected 1 recipe, got %d", count)
+	}
+}
+
+func TestRepoSQL_Edit(t *testing.T) {
+	repo, _ := newSQLRepo(t)
+
+	recipeExample := getExampleRecipeEntity()
+	if err := repo.Add(ctx, recipeExample); err != nil {
+		t.Fatalf("failed to add recipe: %v", err)
+	}
+
+	// Change every field
+	recipeExample.Name = "new name"
+	recipeExample.Description = "new description"
+	recipeExample.Steps = []string{"step3", "step1", "step2"}
+	recipeExample.Ingredients = append(recipeExample.Ingredients, &entity.Ingredient{Name: "other", Type: "type"})
+	if err := repo.Edit(ctx, recipeExample); err != nil {
+		t.Fatalf("failed to edit recipe: %v", err)
+	}
+
+	recipeFound, err := repo.FindByID(ctx, recipeExample.ID)
+	if err != nil {
+		t.Fatalf("failed to find recipe: %v", err)
+	}
+	if !reflect.DeepEqual(recipeFound, recipeExample) {
+		t.Fatalf("expected recipe %+v, got %+v", recipeExample, recipeFound)
+	}
+
+	// Missing recipes can't be edited
+	recipeExample.ID++
+	if err := repo.Edit(ctx, recipeExample); !entity.IsErrNotFound(err) {
+		t.Fatalf("expected not found error, got %v", err)
+	}
+}
+
+func TestRepoSQL_Delete(t *testing.T) {
+	repo, sqlDB := newSQLRepo(t)
+
+	recipeExample := getExampleRecipeEntity()
+	if err := repo.Add(ctx, recipeExample); err != nil {
+		t.Fatalf("failed to add recipe: %v", err)
+	}
+
+	if err := repo.Delete(ctx, recipeExample); err != nil {
+		t.Fatalf("failed to delete recipe: %v", err)
+	}
+	if _, err := repo.FindByID(ctx, recipeExample.ID); !entity.IsErrNotFound(err) {
+		t.Fatalf("expected not found error, got %v", err)
+	}
+
+	// Steps are deleted with the recipe
+	var steps int
+	if err := sqlDB.QueryRow(`SELECT COUNT(*) FROM recipeSteps`).Scan(&steps); err != nil {
+		t.Fatalf("failed to count steps: %v", err)
+	}
+	if steps != 0 {
+		t.Fatalf("expected steps to be deleted, got %d", steps)
+	}
+
+	// Deleting it again fails
+	if err := repo.Delete(ctx, recipeExample); !entity.IsErrNotFound(err) {
+		t.Fatalf("expected not found error, got %v", err)
+	}
+}
@@ -0,0 +1,19 @@
+package recipe
+
+import (
+	"context"
+	"time"
+
+	"github.com/TomeuUris/recipes-catalog/pkg/entity"
+)
+
+// TrashRepo gives access to deleted recipes. It is only implemented by
+// backends that soft delete.
+type TrashRepo interface {
+	FindDeleted(ctx context.Context) ([]*entity.TrashItem, error)
+	// Restore undeletes the recipe together with its steps
+	Restore(ctx context.Context, id int64) error
+	// Purge permanently removes the recipes deleted before the time and
+	// returns them
+	Purge(ctx context.Context, before time.Time) ([]*entity.TrashItem, error)
+}
@@ -0,0 +1,109 @@
+package recipe_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/TomeuUris/recipes-catalog/pkg/entity"
+	"github.com/TomeuUris/recipes-catalog/pkg/repo/recipe"
+)
+
+func TestRepoGorm_RestoreDeleted(t *testing.T) {
+	tx := db.Begin()
+	defer tx.Rollback()
+	repo := recipe.NewGormRepo(tx)
+
+	recipeExample := getExampleRecipeEntity()
+	if err := repo.Add(ctx, recipeExample); err != nil {
+		t.Fatalf("failed to add recipe: %v", err)
+	}
+	if err := repo.Delete(ctx, recipeExample); err != nil {
+		t.Fatalf("failed to delete recipe: %v", err)
+	}
+
+	// The recipe is in the trash
+	deleted, err := repo.FindDeleted(ctx)
+	if err != nil {
+		t.Fatalf("failed to find deleted recipes: %v", err)
+	}
+	if len(deleted) != 1 || deleted[0].ID != recipeExample.ID || deleted[0].Entity != entity.TrashRecipe ||
+		deleted[0].Name != recipeExample.Name || deleted[0].DeletedAt.IsZero() {
+		t.Fatalf("unexpected trash %+v", deleted)
+	}
+
+	// Restore it with its steps and ingredients
+	if err := repo.Restore(ctx, recipeExample.ID); err != nil {
+		t.Fatalf("failed to restore recipe: %v", err)
+	}
+	recipeFound, err := repo.FindByID(ctx, recipeExample.ID)
+	if err != nil {
+		t.Fatalf("failed to find restored recipe: %v", err)
+	}
+	if len(recipeFound.Steps) != 2 || recipeFound.Steps[0] != "step1" || len(recipeFound.Ingredients) != 1 {
+		t.Fatalf("expected steps and ingredients to be restored, got %+v", recipeFound)
+	}
+
+	// Only deleted recipes can be restored
+	if err := repo.Restore(ctx, recipeExample.ID); !entity.IsErrNotFound(err) {
+		t.Fatalf("expected not found error, got %v", err)
+	}
+	deleted, err = repo.FindDeleted(ctx)
+	if err != nil {
+		t.Fatalf("failed to find deleted recipes: %v", err)
+	}
+	if len(deleted) != 0 {
+		t.Fatalf("expected empty trash, got %+v", deleted)
+	}
+}
+
+func TestRepoGorm_Purge(t *testing.T) {
+	tx := db.Begin()
+	defer tx.Rollback()
+	repo := recipe.NewGormRepo(tx)
+
+	recipeExample := getExampleRecipeEntity()
+	if err := repo.Add(ctx, recipeExample); err != nil {
+		t.Fatalf("failed to add recipe: %v", err)
+	}
+	if err := repo.Delete(ctx, recipeExample); err != nil {
+		t.Fatalf("failed to delete recipe: %v", err)
+	}
+
+	// Recently deleted recipes are kept
+	purged, err := repo.Purge(ctx, time.Now().Add(-time.Hour))
+	if err != nil {
+		t.Fatalf("failed to purge recipes: %v", err)
+	}
+	if len(purged) != 0 {
+		t.Fatalf("expected no recipe to be purged, got %+v", purged)
+	}
+
+	purged, err = repo.Purge(ctx, time.Now().Add(time.Second))
+	if err != nil {
+		t.Fatalf("failed to purge recipes: %v", err)
+	}
+	if len(purged) != 1 || purged[0].ID != recipeExample.ID || purged[0].Name != recipeExample.Name {
+		t.Fatalf("expected the recipe to be purged, got %+v", purged)
+	}
+
+	// Purged recipes and their steps are gone
+	if err := repo.Restore(ctx, recipeExample.ID); !entity.IsErrNotFound(err) {
+		t.Fatalf("expected not found error, got %v", err)
+	}
+	var steps int64
+	if err := tx.Unscoped().Model(&recipe.RecipeStep{}).Where("recipe_id = ?", recipeExample.ID).Count(&steps).Error; err != nil {
+		t.Fatalf("failed to count steps: %v", err)
+	}
+	if steps != 0 {
+		t.Fatalf("expected steps to be purged, got %d", steps)
+	}
+
+	// Their slugs are given to new recipes
+	recreated := &entity.Recipe{Name: recipeExample.Name, Steps: []string{"step"}}
+	if err := repo.Add(ctx, recreated); err != nil {
+		t.Fatalf("failed to add recipe: %v", err)
+	}
+	if recreated.Slug != recipeExample.Slug {
+		t.Fatalf("expected the purged slug %q to be reused, got %q", recipeExample.Slug, recreated.Slug)
+	}
+}
@@ -0,0 +1,89 @@
+package repotest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/TomeuUris/recipes-catalog/pkg/entity"
+	"github.com/TomeuUris/recipes-catalog/pkg/repo/audit"
+)
+
+// TestAuditRepo runs the conformance suite against the repositories returned
+// by newRepo, which must be empty and independent of each other.
+func TestAuditRepo(t *testing.T, newRepo func(t *testing.T) audit.Repo) {
+	ctx := context.Background()
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("AddKeepsEntry", func(t *testing.T) {
+		repo := newRepo(t)
+		entry := &entity.AuditEntry{
+			Entity: "recipe", EntityID: 1, Action: "updated", Actor: "alice", RequestID: "request-1",
+			Before: `{"name":"Soup"}`, After: `{"name":"Stew"}`, At: start,
+		}
+		mustNotFail(t, repo.Add(ctx, entry), "add entry")
+		if entry.ID == 0 {
+			t.Fatalf("expected Add to assign an ID")
+		}
+
+		entries, err := repo.FindByFilter(ctx, &audit.FindFilter{})
+		mustNotFail(t, err, "find entries")
+		if len(entries) != 1 {
+			t.Fatalf("expected 1 entry, got %d", len(entries))
+		}
+		found := entries[0]
+		if found.ID != entry.ID || found.Entity != "recipe" || found.EntityID != 1 || found.Action != "updated" ||
+			found.Actor != "alice" || found.RequestID != "request-1" || found.Before != entry.Before || found.After != entry.After {
+			t.Fatalf("expected %+v, got %+v", entry, found)
+		}
+		if !found.At.Equal(start) {
+			t.Fatalf("expected the time %v, got %v", start, found.At)
+		}
+	})
+
+	t.Run("FindByFilter", func(t *testing.T) {
+		repo := newRepo(t)
+		for i, actor := range []string{"alice", "bob", "alice"} {
+			mustNotFail(t, repo.Add(ctx, &entity.AuditEntry{
+				Entity: "recipe", EntityID: int64(i + 1), Action: "created", Actor: actor,
+				// Times in another zone are compared in UTC
+				At: start.Add(time.Duration(i) * time.Hour).In(time.FixedZone("CET", 3600)),
+			}), "add entry")
+		}
+
+		since, until := start.Add(time.Hour), start.Add(2*time.Hour)
+		tests := []struct {
+			name     string
+			filter   audit.FindFilter
+			expected []int64
+		}{
+			{"all", audit.FindFilter{}, []int64{1, 2, 3}},
+			{"entity", audit.FindFilter{Entity: "recipe", EntityID: 2}, []int64{2}},
+			{"other entity", audit.FindFilter{Entity: "ingredient"}, []int64{}},
+			{"actor", audit.FindFilter{Actor: "alice"}, []int64{1, 3}},
+			{"since", audit.FindFilter{Since: &since}, []int64{2, 3}},
+			{"until", audit.FindFilter{Until: &since}, []int64{1, 2}},
+			{"time range", audit.FindFilter{Since: &since, Until: &until}, []int64{2, 3}},
+			{"limit", audit.FindFilter{Limit: 1}, []int64{1}},
+		}
+
+		for _, test := range tests {
+			t.Run(test.name, func(t *testing.T) {
+				entries, err := repo.FindByFilter(ctx, &test.filter)
+				mustNotFail(t, err, "find entries")
+				ids := make([]int64, len(entries))
+				for i, entry := range entries {
+					ids[i] = entry.EntityID
+				}
+				if len(ids) != len(test.expected) {
+					t.Fatalf("expected entities %v, got %v", test.expected, ids)
+				}
+				for i := range ids {
+					if ids[i] != test.expected[i] {
+						t.Fatalf("expected entities %v, got %v", test.expected, ids)
+					}
+				}
+			})
+		}
+	})
+}
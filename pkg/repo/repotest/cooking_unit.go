@@ -0,0 +1,135 @@
+package repotest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/TomeuUris/recipes-catalog/pkg/entity"
+	"github.com/TomeuUris/recipes-catalog/pkg/repo/cooking_unit"
+)
+
+// TestCookingUnitRepo runs the conformance suite against the repositories
+// returned by newRepo, which must be empty and independent of each other.
+func TestCookingUnitRepo(t *testing.T, newRepo func(t *testing.T) cooking_unit.Repo) {
+	ctx := context.Background()
+
+	add := func(t *testing.T, repo cooking_unit.Repo, name string) *entity.CookingUnit {
+		t.Helper()
+		unit := &entity.CookingUnit{Name: name}
+		mustNotFail(t, repo.Add(ctx, unit), "add cooking unit")
+		if unit.ID == 0 {
+			t.Fatalf("expected Add to assign an ID")
+		}
+		return unit
+	}
+
+	t.Run("FindByIDNotFound", func(t *testing.T) {
+		repo := newRepo(t)
+		_, err := repo.FindByID(ctx, 1)
+		expectNotFound(t, err, "FindByID of a missing cooking unit")
+	})
+
+	t.Run("AddAndFindByID", func(t *testing.T) {
+		repo := newRepo(t)
+		added := add(t, repo, "gram")
+
+		found, err := repo.FindByID(ctx, int(added.ID))
+		mustNotFail(t, err, "find cooking unit")
+		if *found != *added {
+			t.Fatalf("expected %+v, got %+v", added, found)
+		}
+	})
+
+	t.Run("CountMatchesFilter", func(t *testing.T) {
+		repo := newRepo(t)
+		add(t, repo, "gram")
+		add(t, repo, "litre")
+		add(t, repo, "cup")
+
+		for _, test := range []struct {
+			filter   cooking_unit.FindFilter
+			expected int
+		}{
+			{cooking_unit.FindFilter{}, 3},
+			{cooking_unit.FindFilter{Name: "gram"}, 1},
+			{cooking_unit.FindFilter{Name: "pinch"}, 0},
+		} {
+			filter := test.filter
+			found, err := repo.FindByFilter(ctx, &filter)
+			mustNotFail(t, err, "find cooking units")
+			count, err := repo.CountByFilter(&filter)
+			mustNotFail(t, err, "count cooking units")
+
+			if len(found) != test.expected || count != test.expected {
+				t.Fatalf("filter %+v: expected %d cooking units, found %d and counted %d",
+					filter, test.expected, len(found), count)
+			}
+			for _, unit := range found {
+				if filter.Name != "" && unit.Name != filter.Name {
+					t.Fatalf("filter %+v: unexpected cooking unit %+v", filter, unit)
+				}
+			}
+		}
+	})
+
+	t.Run("Edit", func(t *testing.T) {
+		repo := newRepo(t)
+		added := add(t, repo, "gram")
+
+		added.Name = "kilogram"
+		mustNotFail(t, repo.Edit(ctx, added), "edit cooking unit")
+
+		found, err := repo.FindByID(ctx, int(added.ID))
+		mustNotFail(t, err, "find cooking unit")
+		if *found != *added {
+			t.Fatalf("expected %+v, got %+v", added, found)
+		}
+	})
+
+	t.Run("EditMissing", func(t *testing.T) {
+		repo := newRepo(t)
+		missing := &entity.CookingUnit{ID: 42, Name: "gram"}
+		expectNotFound(t, repo.Edit(ctx, missing), "Edit of a missing cooking unit")
+
+		// Editing must not create it
+		_, err := repo.FindByID(ctx, int(missing.ID))
+		expectNotFound(t, err, "FindByID after editing a missing cooking unit")
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		repo := newRepo(t)
+		added := add(t, repo, "gram")
+		add(t, repo, "litre")
+
+		mustNotFail(t, repo.Delete(ctx, added), "delete cooking unit")
+		_, err := repo.FindByID(ctx, int(added.ID))
+		expectNotFound(t, err, "FindByID of a deleted cooking unit")
+		expectNotFound(t, repo.Delete(ctx, added), "Delete of a deleted cooking unit")
+
+		count, err := repo.CountByFilter(&cooking_unit.FindFilter{})
+		mustNotFail(t, err, "count cooking units")
+		if count != 1 {
+			t.Fatalf("expected 1 cooking unit left, counted %d", count)
+		}
+	})
+
+	t.Run("UniqueNames", func(t *testing.T) {
+		repo := newRepo(t)
+		gram := add(t, repo, "Gram")
+		cup := add(t, repo, "Cup")
+
+		duplicate := &entity.CookingUnit{Name: "gram"}
+		expectAlreadyExists(t, repo.Add(ctx, duplicate), gram.ID, "Add of a duplicated name")
+
+		cup.Name = "GRAM"
+		expectAlreadyExists(t, repo.Edit(ctx, cup), gram.ID, "Edit to a duplicated name")
+
+		// Renaming keeps its own name
+		gram.Name = "gram"
+		mustNotFail(t, repo.Edit(ctx, gram), "edit cooking unit case")
+
+		// Deleted names can be reused
+		mustNotFail(t, repo.Delete(ctx, gram), "delete cooking unit")
+		add(t, repo, "Gram")
+	})
+}
@@ -0,0 +1,140 @@
+package repotest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/TomeuUris/recipes-catalog/pkg/entity"
+	"github.com/TomeuUris/recipes-catalog/pkg/repo/ingredient"
+)
+
+// TestIngredientRepo runs the conformance suite against the repositories
+// returned by newRepo, which must be empty and independent of each other.
+func TestIngredientRepo(t *testing.T, newRepo func(t *testing.T) ingredient.Repo) {
+	ctx := context.Background()
+
+	add := func(t *testing.T, repo ingredient.Repo, name, typ string) *entity.Ingredient {
+		t.Helper()
+		i := &entity.Ingredient{Name: name, Type: typ}
+		mustNotFail(t, repo.Add(ctx, i), "add ingredient")
+		if i.ID == 0 {
+			t.Fatalf("expected Add to assign an ID")
+		}
+		return i
+	}
+
+	t.Run("FindByIDNotFound", func(t *testing.T) {
+		repo := newRepo(t)
+		_, err := repo.FindByID(ctx, 1)
+		expectNotFound(t, err, "FindByID of a missing ingredient")
+	})
+
+	t.Run("AddAndFindByID", func(t *testing.T) {
+		repo := newRepo(t)
+		added := add(t, repo, "Spaghetti", "Pasta")
+
+		found, err := repo.FindByID(ctx, int(added.ID))
+		mustNotFail(t, err, "find ingredient")
+		if *found != *added {
+			t.Fatalf("expected %+v, got %+v", added, found)
+		}
+	})
+
+	t.Run("CountMatchesFilter", func(t *testing.T) {
+		repo := newRepo(t)
+		add(t, repo, "Spaghetti", "Pasta")
+		add(t, repo, "Macaroni", "Pasta")
+		add(t, repo, "Salt", "Spice")
+
+		for _, test := range []struct {
+			filter   ingredient.FindFilter
+			expected int
+		}{
+			{ingredient.FindFilter{}, 3},
+			{ingredient.FindFilter{Type: "Pasta"}, 2},
+			{ingredient.FindFilter{Type: "Spice"}, 1},
+			{ingredient.FindFilter{Type: "Vegetable"}, 0},
+		} {
+			filter := test.filter
+			found, err := repo.FindByFilter(ctx, &filter)
+			mustNotFail(t, err, "find ingredients")
+			count, err := repo.CountByFilter(&filter)
+			mustNotFail(t, err, "count ingredients")
+
+			if len(found) != test.expected || count != test.expected {
+				t.Fatalf("filter %+v: expected %d ingredients, found %d and counted %d",
+					filter, test.expected, len(found), count)
+			}
+			for _, i := range found {
+				if filter.Type != "" && i.Type != filter.Type {
+					t.Fatalf("filter %+v: unexpected ingredient %+v", filter, i)
+				}
+			}
+		}
+	})
+
+	t.Run("Edit", func(t *testing.T) {
+		repo := newRepo(t)
+		added := add(t, repo, "Spaghetti", "Pasta")
+
+		added.Name = "Linguine"
+		mustNotFail(t, repo.Edit(ctx, added), "edit ingredient")
+
+		found, err := repo.FindByID(ctx, int(added.ID))
+		mustNotFail(t, err, "find ingredient")
+		if *found != *added {
+			t.Fatalf("expected %+v, got %+v", added, found)
+		}
+	})
+
+	t.Run("EditMissing", func(t *testing.T) {
+		repo := newRepo(t)
+		missing := &entity.Ingredient{ID: 42, Name: "Salt", Type: "Spice"}
+		expectNotFound(t, repo.Edit(ctx, missing), "Edit of a missing ingredient")
+
+		// Editing must not create it
+		_, err := repo.FindByID(ctx, int(missing.ID))
+		expectNotFound(t, err, "FindByID after editing a missing ingredient")
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		repo := newRepo(t)
+		added := add(t, repo, "Spaghetti", "Pasta")
+		kept := add(t, repo, "Salt", "Spice")
+
+		mustNotFail(t, repo.Delete(ctx, added), "delete ingredient")
+		_, err := repo.FindByID(ctx, int(added.ID))
+		expectNotFound(t, err, "FindByID of a deleted ingredient")
+		expectNotFound(t, repo.Delete(ctx, added), "Delete of a deleted ingredient")
+
+		count, err := repo.CountByFilter(&ingredient.FindFilter{})
+		mustNotFail(t, err, "count ingredients")
+		if count != 1 {
+			t.Fatalf("expected only %+v to be left, counted %d", kept, count)
+		}
+	})
+
+	t.Run("UniqueNamesPerType", func(t *testing.T) {
+		repo := newRepo(t)
+		tomato := add(t, repo, "Tomàquet", "Vegetable")
+		salt := add(t, repo, "Salt", "Spice")
+
+		duplicate := &entity.Ingredient{Name: "  TOMAQUET ", Type: "Vegetable"}
+		expectAlreadyExists(t, repo.Add(ctx, duplicate), tomato.ID, "Add of a duplicated name")
+
+		// Other types may use the name
+		add(t, repo, "tomaquet", "Sauce")
+
+		salt.Name = "tomàquet"
+		salt.Type = "Vegetable"
+		expectAlreadyExists(t, repo.Edit(ctx, salt), tomato.ID, "Edit to a duplicated name")
+
+		// Renaming keeps its own name
+		tomato.Name = "TOMÀQUET"
+		mustNotFail(t, repo.Edit(ctx, tomato), "edit ingredient case")
+
+		// Deleted names can be reused
+		mustNotFail(t, repo.Delete(ctx, tomato), "delete ingredient")
+		add(t, repo, "Tomaquet", "Vegetable")
+	})
+}
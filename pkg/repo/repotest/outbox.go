@@ -0,0 +1,113 @@
+package repotest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/TomeuUris/recipes-catalog/pkg/entity"
+	"github.com/TomeuUris/recipes-catalog/pkg/repo/outbox"
+)
+
+// TestOutboxRepo runs the conformance suite against the repositories returned
+// by newRepo, which must be empty and independent of each other.
+func TestOutboxRepo(t *testing.T, newRepo func(t *testing.T) outbox.Repo) {
+	ctx := context.Background()
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	add := func(t *testing.T, repo outbox.Repo, entityID int64) *entity.OutboxMessage {
+		t.Helper()
+		message := &entity.OutboxMessage{
+			Entity:        "recipe",
+			EntityID:      entityID,
+			Action:        "created",
+			Payload:       `{"id":1}`,
+			OccurredAt:    now,
+			NextAttemptAt: now,
+		}
+		mustNotFail(t, repo.Add(ctx, message), "add message")
+		if message.ID == 0 {
+			t.Fatalf("expected Add to assign an ID")
+		}
+		return message
+	}
+
+	t.Run("FindPendingInOrder", func(t *testing.T) {
+		repo := newRepo(t)
+		first := add(t, repo, 1)
+		second := add(t, repo, 2)
+		add(t, repo, 3)
+
+		pending, err := repo.FindPending(ctx, 2)
+		mustNotFail(t, err, "find pending messages")
+		if len(pending) != 2 || pending[0].ID != first.ID || pending[1].ID != second.ID {
+			t.Fatalf("expected messages %d and %d, got %+v", first.ID, second.ID, pending)
+		}
+		found := pending[0]
+		if found.Entity != "recipe" || found.EntityID != 1 || found.Action != "created" || found.Payload != `{"id":1}` {
+			t.Fatalf("expected %+v, got %+v", first, found)
+		}
+		if !found.OccurredAt.Equal(now) || !found.NextAttemptAt.Equal(now) || found.DeliveredAt != nil {
+			t.Fatalf("expected the times of %+v, got %+v", first, found)
+		}
+	})
+
+	t.Run("EditRecordsDelivery", func(t *testing.T) {
+		repo := newRepo(t)
+		failed := add(t, repo, 1)
+		delivered := add(t, repo, 2)
+
+		failed.Attempts = 1
+		failed.LastError = "unreachable"
+		failed.NextAttemptAt = now.Add(time.Minute)
+		mustNotFail(t, repo.Edit(ctx, failed), "record failed delivery")
+		deliveredAt := now.Add(time.Second)
+		delivered.DeliveredAt = &deliveredAt
+		mustNotFail(t, repo.Edit(ctx, delivered), "record delivery")
+
+		pending, err := repo.FindPending(ctx, 10)
+		mustNotFail(t, err, "find pending messages")
+		if len(pending) != 1 || pending[0].ID != failed.ID {
+			t.Fatalf("expected only message %d pending, got %+v", failed.ID, pending)
+		}
+		if pending[0].Attempts != 1 || pending[0].LastError != "unreachable" || !pending[0].NextAttemptAt.Equal(failed.NextAttemptAt) {
+			t.Fatalf("expected the failed delivery recorded, got %+v", pending[0])
+		}
+	})
+
+	t.Run("EditNotFound", func(t *testing.T) {
+		repo := newRepo(t)
+		err := repo.Edit(ctx, &entity.OutboxMessage{ID: 1, NextAttemptAt: now})
+		expectNotFound(t, err, "Edit of a missing message")
+	})
+
+	t.Run("DeleteDelivered", func(t *testing.T) {
+		repo := newRepo(t)
+		old := add(t, repo, 1)
+		recent := add(t, repo, 2)
+		add(t, repo, 3)
+		oldAt, recentAt := now.Add(-time.Hour), now
+		old.DeliveredAt = &oldAt
+		recent.DeliveredAt = &recentAt
+		mustNotFail(t, repo.Edit(ctx, old), "record delivery")
+		mustNotFail(t, repo.Edit(ctx, recent), "record delivery")
+
+		deleted, err := repo.DeleteDelivered(ctx, now.Add(-time.Minute))
+		mustNotFail(t, err, "delete delivered messages")
+		if deleted != 1 {
+			t.Fatalf("expected 1 message deleted, got %d", deleted)
+		}
+
+		// The pending message is kept
+		pending, err := repo.FindPending(ctx, 10)
+		mustNotFail(t, err, "find pending messages")
+		if len(pending) != 1 || pending[0].EntityID != 3 {
+			t.Fatalf("expected the pending message kept, got %+v", pending)
+		}
+		deleted, err = repo.DeleteDelivered(ctx, now.Add(time.Minute))
+		mustNotFail(t, err, "delete delivered messages")
+		if deleted != 1 {
+			t.Fatalf("expected the recent message deleted, got %d", deleted)
+		}
+	})
+}
@@ -0,0 +1,345 @@
+package repotest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/TomeuUris/recipes-catalog/pkg/entity"
+	"github.com/TomeuUris/recipes-catalog/pkg/repo/ingredient"
+	"github.com/TomeuUris/recipes-catalog/pkg/repo/recipe"
+)
+
+// TestRecipeRepo runs the conformance suite against the repositories returned
+// by newRepos, which must be empty and independent of each other. The
+// ingredient repository must share the storage of the recipe one, recipes
+// reference the ingredients added through it.
+func TestRecipeRepo(t *testing.T, newRepos func(t *testing.T) (recipe.Repo, ingredient.Repo)) {
+	ctx := context.Background()
+
+	addIngredient := func(t *testing.T, repo ingredient.Repo, name string) *entity.Ingredient {
+		t.Helper()
+		i := &entity.Ingredient{Name: name, Type: "type"}
+		mustNotFail(t, repo.Add(ctx, i), "add ingredient")
+		return i
+	}
+
+	add := func(t *testing.T, repo recipe.Repo, name string, ingredients []*entity.Ingredient, steps []string) *entity.Recipe {
+		t.Helper()
+		r := &entity.Recipe{Name: name, Description: name + " description", Ingredients: ingredients, Steps: steps}
+		mustNotFail(t, repo.Add(ctx, r), "add recipe")
+		if r.ID == 0 {
+			t.Fatalf("expected Add to assign an ID")
+		}
+		return r
+	}
+
+	// expectRecipe checks the stored recipe, nil and empty lists are equivalent
+	expectRecipe := func(t *testing.T, repo recipe.Repo, expected *entity.Recipe) {
+		t.Helper()
+		found, err := repo.FindByID(ctx, expected.ID)
+		mustNotFail(t, err, "find recipe")
+		expectSameRecipe(t, expected, found)
+	}
+
+	// Enough steps to catch lexicographic ordering
+	manySteps := make([]string, 12)
+	for i := range manySteps {
+		manySteps[i] = fmt.Sprintf("step %d", len(manySteps)-i)
+	}
+
+	t.Run("FindByIDNotFound", func(t *testing.T) {
+		repo, _ := newRepos(t)
+		_, err := repo.FindByID(ctx, 1)
+		expectNotFound(t, err, "FindByID of a missing recipe")
+	})
+
+	t.Run("AddAndFindByID", func(t *testing.T) {
+		repo, ingredients := newRepos(t)
+		salt := addIngredient(t, ingredients, "Salt")
+		pepper := addIngredient(t, ingredients, "Pepper")
+
+		added := add(t, repo, "Soup", []*entity.Ingredient{salt, pepper}, []string{"boil", "serve"})
+		expectRecipe(t, repo, added)
+	})
+
+	t.Run("StepOrdering", func(t *testing.T) {
+		repo, _ := newRepos(t)
+		added := add(t, repo, "Soup", nil, manySteps)
+		expectRecipe(t, repo, added)
+
+		// Reorder
+		added.Steps = []string{"c", "a", "b"}
+		mustNotFail(t, repo.Edit(ctx, added), "edit recipe")
+		expectRecipe(t, repo, added)
+
+		// Remove steps
+		added.Steps = []string{"b"}
+		mustNotFail(t, repo.Edit(ctx, added), "edit recipe")
+		expectRecipe(t, repo, added)
+
+		// Add steps back
+		added.Steps = manySteps
+		mustNotFail(t, repo.Edit(ctx, added), "edit recipe")
+		expectRecipe(t, repo, added)
+
+		// Steps are ordered when listing too
+		found, err := repo.FindByFilter(ctx, &recipe.FindFilter{})
+		mustNotFail(t, err, "find recipes")
+		if len(found) != 1 || !reflect.DeepEqual(found[0].Steps, manySteps) {
+			t.Fatalf("expected steps %v, got %+v", manySteps, found)
+		}
+	})
+
+	t.Run("CountMatchesFilter", func(t *testing.T) {
+		repo, _ := newRepos(t)
+		first := add(t, repo, "Soup", nil, []string{"boil"})
+		add(t, repo, "Salad", nil, []string{"mix"})
+
+		for _, test := range []struct {
+			filter   recipe.FindFilter
+			expected int
+		}{
+			{recipe.FindFilter{}, 2},
+			{recipe.FindFilter{Id: int(first.ID)}, 1},
+			{recipe.FindFilter{Id: 42}, 0},
+		} {
+			filter := test.filter
+			found, err := repo.FindByFilter(ctx, &filter)
+			mustNotFail(t, err, "find recipes")
+			count, err := repo.CountByFilter(ctx, &filter)
+			mustNotFail(t, err, "count recipes")
+
+			if len(found) != test.expected || count != test.expected {
+				t.Fatalf("filter %+v: expected %d recipes, found %d and counted %d",
+					filter, test.expected, len(found), count)
+			}
+			for _, r := range found {
+				if filter.Id != 0 && r.ID != int64(filter.Id) {
+					t.Fatalf("filter %+v: unexpected recipe %+v", filter, r)
+				}
+			}
+		}
+	})
+
+	t.Run("Edit", func(t *testing.T) {
+		repo, ingredients := newRepos(t)
+		salt := addIngredient(t, ingredients, "Salt")
+		pepper := addIngredient(t, ingredients, "Pepper")
+		added := add(t, repo, "Soup", []*entity.Ingredient{salt}, []string{"boil"})
+
+		added.Name = "Stew"
+		added.Description = "Slow cooked"
+		added.PrepTime, added.CookTime = 15*time.Minute, 2*time.Hour
+		added.Yield, added.Calories = "4 servings", 350
+		added.Ingredients = []*entity.Ingredient{pepper}
+		mustNotFail(t, repo.Edit(ctx, added), "edit recipe")
+		expectRecipe(t, repo, added)
+	})
+
+	t.Run("EditMissing", func(t *testing.T) {
+		repo, _ := newRepos(t)
+		missing := &entity.Recipe{ID: 42, Name: "Soup", Steps: []string{"boil"}}
+		expectNotFound(t, repo.Edit(ctx, missing), "Edit of a missing recipe")
+
+		// Editing must not create it
+		_, err := repo.FindByID(ctx, missing.ID)
+		expectNotFound(t, err, "FindByID after editing a missing recipe")
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		repo, ingredients := newRepos(t)
+		salt := addIngredient(t, ingredients, "Salt")
+		added := add(t, repo, "Soup", []*entity.Ingredient{salt}, []string{"boil"})
+		kept := add(t, repo, "Salad", []*entity.Ingredient{salt}, []string{"mix"})
+
+		mustNotFail(t, repo.Delete(ctx, added), "delete recipe")
+		_, err := repo.FindByID(ctx, added.ID)
+		expectNotFound(t, err, "FindByID of a deleted recipe")
+		expectNotFound(t, repo.Delete(ctx, added), "Delete of a deleted recipe")
+
+		// Other recipes and the ingredients are left untouched
+		expectRecipe(t, repo, kept)
+		_, err = ingredients.FindByID(ctx, int(salt.ID))
+		mustNotFail(t, err, "find ingredient of a deleted recipe")
+	})
+
+	t.Run("Slugs", func(t *testing.T) {
+		repo, _ := newRepos(t)
+		expectSlug := func(t *testing.T, slug string, expected *entity.Recipe) {
+			t.Helper()
+			found, err := repo.FindBySlug(ctx, slug)
+			mustNotFail(t, err, "find recipe by slug")
+			expectSameRecipe(t, expected, found)
+		}
+
+		first := add(t, repo, "Pa amb tomàquet", nil, []string{"rub"})
+		second := add(t, repo, "Pa amb tomaquet", nil, []string{"rub"})
+		if first.Slug != "pa-amb-tomaquet" || second.Slug != "pa-amb-tomaquet-2" {
+			t.Fatalf("expected slugs pa-amb-tomaquet and pa-amb-tomaquet-2, got %q and %q", first.Slug, second.Slug)
+		}
+		expectSlug(t, first.Slug, first)
+		expectSlug(t, second.Slug, second)
+		_, err := repo.FindBySlug(ctx, "escalivada")
+		expectNotFound(t, err, "FindBySlug of a missing slug")
+
+		// Renaming keeps the previous slug, it isn't given to another recipe
+		first.Name = "L'escalivada"
+		mustNotFail(t, repo.Edit(ctx, first), "edit recipe")
+		if first.Slug != "l-escalivada" {
+			t.Fatalf("expected slug l-escalivada after renaming, got %q", first.Slug)
+		}
+		expectSlug(t, "l-escalivada", first)
+		expectSlug(t, "pa-amb-tomaquet", first)
+		third := add(t, repo, "Pa amb tomàquet", nil, []string{"rub"})
+		if third.Slug != "pa-amb-tomaquet-3" {
+			t.Fatalf("expected slug pa-amb-tomaquet-3, got %q", third.Slug)
+		}
+
+		// Renaming back restores the slug, other edits keep it
+		first.Name = "Pa amb tomàquet"
+		mustNotFail(t, repo.Edit(ctx, first), "edit recipe")
+		if first.Slug != "pa-amb-tomaquet" {
+			t.Fatalf("expected slug pa-amb-tomaquet after renaming back, got %q", first.Slug)
+		}
+		second.Description = "With olive oil"
+		mustNotFail(t, repo.Edit(ctx, second), "edit recipe")
+		expectSlug(t, "pa-amb-tomaquet-2", second)
+
+		// Deleted recipes aren't found by any of their slugs
+		mustNotFail(t, repo.Delete(ctx, first), "delete recipe")
+		for _, slug := range []string{"pa-amb-tomaquet", "l-escalivada"} {
+			_, err := repo.FindBySlug(ctx, slug)
+			expectNotFound(t, err, "FindBySlug of a deleted recipe")
+		}
+	})
+
+	t.Run("AddDuplicatedIngredient", func(t *testing.T) {
+		repo, ingredients := newRepos(t)
+		addIngredient(t, ingredients, "Salt")
+
+		duplicate := &entity.Recipe{Name: "Soup", Steps: []string{"boil"},
+			Ingredients: []*entity.Ingredient{{Name: "salt", Type: "type"}}}
+		if err := repo.Add(ctx, duplicate); !entity.IsErrAlreadyExists(err) {
+			t.Fatalf("expected creating a duplicated ingredient to return entity.ErrAlreadyExists, got %v", err)
+		}
+	})
+
+	t.Run("DeleteIngredientInUse", func(t *testing.T) {
+		repo, ingredients := newRepos(t)
+		salt := addIngredient(t, ingredients, "Salt")
+		soup := add(t, repo, "Soup", []*entity.Ingredient{salt}, []string{"boil"})
+		salad := add(t, repo, "Salad", []*entity.Ingredient{salt}, []string{"mix"})
+
+		err := ingredients.Delete(ctx, salt)
+		var inUse *entity.InUseError
+		if !errors.As(err, &inUse) || !entity.IsErrInUse(err) {
+			t.Fatalf("expected deleting an ingredient in use to return *entity.InUseError, got %v", err)
+		}
+		if len(inUse.Recipes) != 2 ||
+			inUse.Recipes[0].ID != soup.ID || inUse.Recipes[0].Name != soup.Name ||
+			inUse.Recipes[1].ID != salad.ID || inUse.Recipes[1].Name != salad.Name {
+			t.Fatalf("expected recipes %q and %q to block the delete, got %+v", soup.Name, salad.Name, inUse.Recipes)
+		}
+
+		// Nothing changed
+		_, err = ingredients.FindByID(ctx, int(salt.ID))
+		mustNotFail(t, err, "find ingredient in use")
+		expectRecipe(t, repo, soup)
+
+		// Deleted recipes don't block it
+		mustNotFail(t, repo.Delete(ctx, soup), "delete recipe")
+		mustNotFail(t, repo.Delete(ctx, salad), "delete recipe")
+		mustNotFail(t, ingredients.Delete(ctx, salt), "delete unused ingredient")
+		expectNotFound(t, ingredients.Delete(ctx, salt), "Delete of a deleted ingredient")
+	})
+
+	t.Run("DetachAndDeleteIngredient", func(t *testing.T) {
+		repo, ingredients := newRepos(t)
+		salt := addIngredient(t, ingredients, "Salt")
+		pepper := addIngredient(t, ingredients, "Pepper")
+		soup := add(t, repo, "Soup", []*entity.Ingredient{salt, pepper}, []string{"boil"})
+
+		mustNotFail(t, ingredients.DetachAndDelete(ctx, salt), "detach and delete ingredient")
+		_, err := ingredients.FindByID(ctx, int(salt.ID))
+		expectNotFound(t, err, "FindByID of a detached ingredient")
+
+		soup.Ingredients = []*entity.Ingredient{pepper}
+		expectRecipe(t, repo, soup)
+		expectNotFound(t, ingredients.DetachAndDelete(ctx, salt), "DetachAndDelete of a deleted ingredient")
+	})
+
+	t.Run("MergeIngredients", func(t *testing.T) {
+		repo, ingredients := newRepos(t)
+		salt := addIngredient(t, ingredients, "Salt")
+		seaSalt := addIngredient(t, ingredients, "Sea salt")
+		rockSalt := addIngredient(t, ingredients, "Rock salt")
+		pepper := addIngredient(t, ingredients, "Pepper")
+		soup := add(t, repo, "Soup", []*entity.Ingredient{seaSalt, rockSalt, pepper}, []string{"boil"})
+		salad := add(t, repo, "Salad", []*entity.Ingredient{salt, seaSalt}, []string{"mix"})
+
+		err := ingredients.Merge(ctx, salt, []int64{salt.ID})
+		if !entity.IsErrInvalidID(err) {
+			t.Fatalf("expected merging an ingredient into itself to return entity.ErrInvalidID, got %v", err)
+		}
+
+		// Nothing is merged when a duplicate is missing
+		expectNotFound(t, ingredients.Merge(ctx, salt, []int64{seaSalt.ID, 42}), "Merge of a missing ingredient")
+		_, err = ingredients.FindByID(ctx, int(seaSalt.ID))
+		mustNotFail(t, err, "find ingredient of a failed merge")
+		expectRecipe(t, repo, soup)
+
+		mustNotFail(t, ingredients.Merge(ctx, salt, []int64{seaSalt.ID, rockSalt.ID}), "merge ingredients")
+		for _, merged := range []*entity.Ingredient{seaSalt, rockSalt} {
+			_, err := ingredients.FindByID(ctx, int(merged.ID))
+			expectNotFound(t, err, "FindByID of a merged ingredient")
+		}
+
+		// The recipes use the target once, in any order
+		for _, test := range []struct {
+			recipe   *entity.Recipe
+			expected []int64
+		}{
+			{soup, []int64{salt.ID, pepper.ID}},
+			{salad, []int64{salt.ID}},
+		} {
+			found, err := repo.FindByID(ctx, test.recipe.ID)
+			mustNotFail(t, err, "find recipe")
+			ids := map[int64]int{}
+			for _, ingredient := range found.Ingredients {
+				ids[ingredient.ID]++
+			}
+			if len(found.Ingredients) != len(test.expected) {
+				t.Fatalf("expected %s to use ingredients %v, got %+v", test.recipe.Name, test.expected, found.Ingredients)
+			}
+			for _, id := range test.expected {
+				if ids[id] != 1 {
+					t.Fatalf("expected %s to use ingredients %v, got %+v", test.recipe.Name, test.expected, found.Ingredients)
+				}
+			}
+		}
+	})
+}
+
+func expectSameRecipe(t *testing.T, expected, found *entity.Recipe) {
+	t.Helper()
+	if found.ID != expected.ID || found.Name != expected.Name || found.Slug != expected.Slug ||
+		found.Description != expected.Description || found.PrepTime != expected.PrepTime ||
+		found.CookTime != expected.CookTime || found.Yield != expected.Yield || found.Calories != expected.Calories {
+		t.Fatalf("expected recipe %+v, got %+v", expected, found)
+	}
+	if len(found.Steps) != len(expected.Steps) || (len(found.Steps) > 0 && !reflect.DeepEqual(found.Steps, expected.Steps)) {
+		t.Fatalf("expected steps %q, got %q", expected.Steps, found.Steps)
+	}
+	if len(found.Ingredients) != len(expected.Ingredients) {
+		t.Fatalf("expected %d ingredients, got %d", len(expected.Ingredients), len(found.Ingredients))
+	}
+	for i, ingredient := range expected.Ingredients {
+		if *found.Ingredients[i] != *ingredient {
+			t.Fatalf("expected ingredient %+v, got %+v", ingredient, found.Ingredients[i])
+		}
+	}
+}
@@ -0,0 +1,36 @@
+// Package repotest implements conformance suites every repository backend
+// (gorm, database/sql, in-memory) must pass.
+package repotest
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/TomeuUris/recipes-catalog/pkg/entity"
+)
+
+func expectNotFound(t *testing.T, err error, action string) {
+	t.Helper()
+	if !entity.IsErrNotFound(err) {
+		t.Fatalf("expected %s to return entity.ErrNotFound, got %v", action, err)
+	}
+}
+
+// expectAlreadyExists checks the error points to the entity holding the name
+func expectAlreadyExists(t *testing.T, err error, existingID int64, action string) {
+	t.Helper()
+	var exists *entity.AlreadyExistsError
+	if !errors.As(err, &exists) || !entity.IsErrAlreadyExists(err) {
+		t.Fatalf("expected %s to return *entity.AlreadyExistsError, got %v", action, err)
+	}
+	if exists.ID != existingID {
+		t.Fatalf("expected %s to point to %d, got %d", action, existingID, exists.ID)
+	}
+}
+
+func mustNotFail(t *testing.T, err error, action string) {
+	t.Helper()
+	if err != nil {
+		t.Fatalf("failed to %s: %v", action, err)
+	}
+}
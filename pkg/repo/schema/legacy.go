@@ -0,0 +1,52 @@
+package schema
+
+import (
+	"time"
+
+	"github.com/TomeuUris/recipes-catalog/pkg/repo/cooking_unit"
+	"github.com/TomeuUris/recipes-catalog/pkg/repo/ingredient"
+	"github.com/TomeuUris/recipes-catalog/pkg/repo/recipe"
+	"github.com/TomeuUris/recipes-catalog/pkg/repo/webhook"
+	"gorm.io/gorm"
+)
+
+// Recipe is the recipe model as it was when the schema was versioned. Its
+// name gives the constraints the same names as the current model.
+type Recipe struct {
+	gorm.Model
+	Name        string
+	Description string
+	Ingredients []*ingredient.Ingredient `gorm:"many2many:recipe_ingredients;"`
+	Steps       []*recipe.RecipeStep     `gorm:"foreignKey:RecipeID;constraint:OnDelete:CASCADE"`
+}
+
+// WebhookDelivery is the webhook delivery model as it was when the schema was
+// versioned, before deliveries were unique per event
+type WebhookDelivery struct {
+	gorm.Model
+	WebhookID      uint `gorm:"index"`
+	EventID        int64
+	EventType      string
+	Payload        string
+	Status         string    `gorm:"index:idx_delivery_due"`
+	NextAttemptAt  time.Time `gorm:"index:idx_delivery_due"`
+	Attempts       int
+	ResponseStatus int
+	LastError      string
+	DeliveredAt    *time.Time
+}
+
+// LegacyAutoMigrate creates the schema AutoMigrate left before it was
+// versioned, the schema of the first migration
+func LegacyAutoMigrate(db *gorm.DB) error {
+	if err := ingredient.RunMigrations(db); err != nil {
+		return err
+	}
+	if err := db.AutoMigrate(&Recipe{}, &recipe.RecipeStep{}); err != nil {
+		return err
+	}
+	if err := cooking_unit.RunMigrations(db); err != nil {
+		return err
+	}
+	return db.AutoMigrate(&webhook.Webhook{}, &WebhookDelivery{})
+}
@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS `webhook_deliveries`;
+DROP TABLE IF EXISTS `webhooks`;
+DROP TABLE IF EXISTS `cooking_units`;
+DROP TABLE IF EXISTS `recipe_steps`;
+DROP TABLE IF EXISTS `recipe_ingredients`;
+DROP TABLE IF EXISTS `ingredients`;
+DROP TABLE IF EXISTS `recipes`;
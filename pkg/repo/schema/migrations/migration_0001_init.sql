@@ -0,0 +1,82 @@
+-- Schema created by AutoMigrate before the gorm migrations were versioned
+CREATE TABLE `recipes` (
+	`id`          integer PRIMARY KEY AUTOINCREMENT,
+	`created_at`  datetime,
+	`updated_at`  datetime,
+	`deleted_at`  datetime,
+	`name`        text,
+	`description` text
+);
+CREATE INDEX `idx_recipes_deleted_at` ON `recipes`(`deleted_at`);
+
+CREATE TABLE `ingredients` (
+	`id`         integer PRIMARY KEY AUTOINCREMENT,
+	`created_at` datetime,
+	`updated_at` datetime,
+	`deleted_at` datetime,
+	`name`       text,
+	`name_key`   text,
+	`type`       text
+);
+CREATE INDEX `idx_ingredients_deleted_at` ON `ingredients`(`deleted_at`);
+CREATE UNIQUE INDEX `idx_ingredients_name_key` ON `ingredients`(`name_key`, `type`) WHERE deleted_at IS NULL;
+
+CREATE TABLE `recipe_ingredients` (
+	`recipe_id`     integer,
+	`ingredient_id` integer,
+	PRIMARY KEY (`recipe_id`, `ingredient_id`),
+	CONSTRAINT `fk_recipe_ingredients_ingredient` FOREIGN KEY (`ingredient_id`) REFERENCES `ingredients`(`id`),
+	CONSTRAINT `fk_recipe_ingredients_recipe` FOREIGN KEY (`recipe_id`) REFERENCES `recipes`(`id`)
+);
+
+CREATE TABLE `recipe_steps` (
+	`id`         integer PRIMARY KEY AUTOINCREMENT,
+	`created_at` datetime,
+	`updated_at` datetime,
+	`deleted_at` datetime,
+	`content`    text,
+	`order`      integer,
+	`recipe_id`  integer,
+	CONSTRAINT `fk_recipes_steps` FOREIGN KEY (`recipe_id`) REFERENCES `recipes`(`id`) ON DELETE CASCADE
+);
+CREATE UNIQUE INDEX `idx_recipe_order` ON `recipe_steps`(`order`, `recipe_id`);
+CREATE INDEX `idx_recipe_steps_deleted_at` ON `recipe_steps`(`deleted_at`);
+
+CREATE TABLE `cooking_units` (
+	`id`       integer PRIMARY KEY AUTOINCREMENT,
+	`name`     text,
+	`name_key` text
+);
+CREATE UNIQUE INDEX `idx_cooking_units_name_key` ON `cooking_units`(`name_key`);
+
+CREATE TABLE `webhooks` (
+	`id`         integer PRIMARY KEY AUTOINCREMENT,
+	`created_at` datetime,
+	`updated_at` datetime,
+	`deleted_at` datetime,
+	`url`        text,
+	`secret`     text,
+	`events`     text,
+	`active`     numeric
+);
+CREATE INDEX `idx_webhooks_deleted_at` ON `webhooks`(`deleted_at`);
+
+CREATE TABLE `webhook_deliveries` (
+	`id`              integer PRIMARY KEY AUTOINCREMENT,
+	`created_at`      datetime,
+	`updated_at`      datetime,
+	`deleted_at`      datetime,
+	`webhook_id`      integer,
+	`event_id`        integer,
+	`event_type`      text,
+	`payload`         text,
+	`status`          text,
+	`next_attempt_at` datetime,
+	`attempts`        integer,
+	`response_status` integer,
+	`last_error`      text,
+	`delivered_at`    datetime
+);
+CREATE INDEX `idx_webhook_deliveries_webhook_id` ON `webhook_deliveries`(`webhook_id`);
+CREATE INDEX `idx_webhook_deliveries_deleted_at` ON `webhook_deliveries`(`deleted_at`);
+CREATE INDEX `idx_delivery_due` ON `webhook_deliveries`(`status`, `next_attempt_at`);
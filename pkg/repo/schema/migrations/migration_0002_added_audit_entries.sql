@@ -0,0 +1,13 @@
+CREATE TABLE `audit_entries` (
+	`id`         integer PRIMARY KEY AUTOINCREMENT,
+	`entity`     text,
+	`entity_id`  integer,
+	`action`     text,
+	`actor`      text,
+	`request_id` text,
+	`before`     text,
+	`after`      text,
+	`at`         datetime
+);
+CREATE INDEX `idx_audit_entries_at` ON `audit_entries`(`at`);
+CREATE INDEX `idx_audit_entity` ON `audit_entries`(`entity`, `entity_id`);
@@ -0,0 +1,2 @@
+DROP TABLE `recipe_slugs`;
+ALTER TABLE `recipes` DROP COLUMN `slug`;
@@ -0,0 +1,26 @@
+-- Recipes are found by slug. Every slug given to a recipe is kept, so links to
+-- its previous names keep finding it.
+ALTER TABLE `recipes` ADD COLUMN `slug` text;
+
+CREATE TABLE `recipe_slugs` (
+	`id`         integer PRIMARY KEY AUTOINCREMENT,
+	`slug`       text,
+	`recipe_id`  integer,
+	`created_at` datetime
+);
+CREATE UNIQUE INDEX `idx_recipe_slugs_slug` ON `recipe_slugs`(`slug`);
+CREATE INDEX `idx_recipe_slugs_recipe_id` ON `recipe_slugs`(`recipe_id`);
+
+-- The first recipe of a name gets its slug, the others are told apart by ID.
+-- Slugify never returns two hyphens in a row, they are the last resort.
+UPDATE `recipes` SET `slug` = COALESCE(NULLIF(slugify(`name`), ''), 'recipe')
+WHERE `id` IN (SELECT MIN(`id`) FROM `recipes` GROUP BY COALESCE(NULLIF(slugify(`name`), ''), 'recipe'));
+UPDATE `recipes` SET `slug` = COALESCE(NULLIF(slugify(`name`), ''), 'recipe') || '-' || `id`
+WHERE `slug` IS NULL AND NOT EXISTS (
+	SELECT 1 FROM `recipes` AS `taken`
+	WHERE `taken`.`slug` = COALESCE(NULLIF(slugify(`recipes`.`name`), ''), 'recipe') || '-' || `recipes`.`id`
+);
+UPDATE `recipes` SET `slug` = COALESCE(NULLIF(slugify(`name`), ''), 'recipe') || '--' || `id` WHERE `slug` IS NULL;
+
+INSERT INTO `recipe_slugs` (`slug`, `recipe_id`, `created_at`)
+SELECT `slug`, `id`, CURRENT_TIMESTAMP FROM `recipes`;
@@ -0,0 +1,15 @@
+-- Domain events are added in the transaction of their write and delivered
+-- from here
+CREATE TABLE `outbox_messages` (
+	`id`              integer PRIMARY KEY AUTOINCREMENT,
+	`entity`          text,
+	`entity_id`       integer,
+	`action`          text,
+	`payload`         text,
+	`occurred_at`     datetime,
+	`attempts`        integer,
+	`last_error`      text,
+	`next_attempt_at` datetime,
+	`delivered_at`    datetime
+);
+CREATE INDEX `idx_outbox_messages_delivered_at` ON `outbox_messages`(`delivered_at`);
@@ -0,0 +1,4 @@
+ALTER TABLE `recipes` DROP COLUMN `calories`;
+ALTER TABLE `recipes` DROP COLUMN `yield`;
+ALTER TABLE `recipes` DROP COLUMN `cook_minutes`;
+ALTER TABLE `recipes` DROP COLUMN `prep_minutes`;
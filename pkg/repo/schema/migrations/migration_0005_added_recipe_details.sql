@@ -0,0 +1,5 @@
+-- Times, yield and nutrition published in the schema.org markup of recipes
+ALTER TABLE `recipes` ADD COLUMN `prep_minutes` integer;
+ALTER TABLE `recipes` ADD COLUMN `cook_minutes` integer;
+ALTER TABLE `recipes` ADD COLUMN `yield` text;
+ALTER TABLE `recipes` ADD COLUMN `calories` integer;
@@ -0,0 +1,7 @@
+-- Deliveries are unique per webhook and event, so an event the outbox
+-- dispatches again is delivered once. The duplicates delivered before are
+-- dropped, keeping the first delivery.
+DELETE FROM `webhook_deliveries` WHERE `id` NOT IN (
+	SELECT MIN(`id`) FROM `webhook_deliveries` GROUP BY `webhook_id`, `event_id`
+);
+CREATE UNIQUE INDEX `idx_delivery_event` ON `webhook_deliveries`(`webhook_id`, `event_id`);
@@ -0,0 +1,91 @@
+// Package schema versions the database of the gorm backend with the embedded
+// SQL migrations, the gorm models must match the schema they leave
+package schema
+
+import (
+	"context"
+	"embed"
+	"fmt"
+
+	"github.com/TomeuUris/recipes-catalog/pkg/repo/ingredient"
+	"github.com/TomeuUris/recipes-catalog/pkg/sqlite"
+	"gorm.io/gorm"
+)
+
+//go:embed migrations/*.sql
+var MigrationsFS embed.FS
+
+// legacyVersion is the migration matching the schema AutoMigrate created
+const legacyVersion = 1
+
+var Store sqlite.MigrationStore
+
+func init() {
+	if err := Store.Load(MigrationsFS); err != nil {
+		panic(err)
+	}
+}
+
+// Migrate adopts a database created by AutoMigrate and applies the pending
+// migrations. It fails without changes when the database is ahead of the
+// migrations or an applied one was edited, and after applying them when its
+// tables differ from the ones the migrations create.
+func Migrate(ctx context.Context, db *gorm.DB) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	if err := Adopt(ctx, db); err != nil {
+		return fmt.Errorf("failed to adopt AutoMigrate schema: %w", err)
+	}
+	if err := Store.Apply(ctx, sqlDB); err != nil {
+		return err
+	}
+	return Store.Verify(ctx, sqlDB)
+}
+
+// Check returns an error when the database schema is ahead of or diverges from
+// the migrations: an applied migration was edited or, once every migration is
+// applied, the tables in sqlite_master differ from the ones they create.
+// Pending migrations are fine.
+func Check(ctx context.Context, db *gorm.DB) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	return Store.Verify(ctx, sqlDB)
+}
+
+// IsLegacy returns whether the database was created by AutoMigrate, which
+// left it unversioned
+func IsLegacy(ctx context.Context, db *gorm.DB) (bool, error) {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return false, err
+	}
+	status, err := Store.Status(ctx, sqlDB)
+	if err != nil {
+		return false, err
+	}
+	return status.Version == 0 && db.WithContext(ctx).Migrator().HasTable(&ingredient.Ingredient{}), nil
+}
+
+// Adopt brings a database created by AutoMigrate to the schema of the first
+// migration, running AutoMigrate a last time, and marks it as migrated
+func Adopt(ctx context.Context, db *gorm.DB) error {
+	legacy, err := IsLegacy(ctx, db)
+	if err != nil || !legacy {
+		return err
+	}
+
+	db = db.WithContext(ctx)
+	if err := LegacyAutoMigrate(db); err != nil {
+		return err
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	return Store.Baseline(ctx, sqlDB, legacyVersion)
+}
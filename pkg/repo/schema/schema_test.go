@@ -0,0 +1,202 @@
+package schema_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/TomeuUris/recipes-catalog/pkg/entity"
+	"github.com/TomeuUris/recipes-catalog/pkg/repo/audit"
+	"github.com/TomeuUris/recipes-catalog/pkg/repo/cooking_unit"
+	"github.com/TomeuUris/recipes-catalog/pkg/repo/ingredient"
+	"github.com/TomeuUris/recipes-catalog/pkg/repo/outbox"
+	"github.com/TomeuUris/recipes-catalog/pkg/repo/recipe"
+	"github.com/TomeuUris/recipes-catalog/pkg/repo/schema"
+	"github.com/TomeuUris/recipes-catalog/pkg/repo/webhook"
+	sqlitedb "github.com/TomeuUris/recipes-catalog/pkg/sqlite"
+	"github.com/TomeuUris/recipes-catalog/pkg/sqlite/migrations"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+var ctx = context.Background()
+
+func openDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(&sqlite.Dialector{DriverName: sqlitedb.DriverName, DSN: "file::memory:"}, &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("failed to get database: %v", err)
+	}
+	// Every connection to :memory: opens a new database
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+	return db
+}
+
+// schemaOf returns the SQL of the tables and indexes by name
+func schemaOf(t *testing.T, db *gorm.DB) map[string]string {
+	t.Helper()
+	var rows []struct{ Name, Sql string }
+	if err := db.Raw(`SELECT name, sql FROM sqlite_master WHERE sql IS NOT NULL AND name NOT LIKE 'sqlite_%'`).
+		Scan(&rows).Error; err != nil {
+		t.Fatalf("failed to read schema: %v", err)
+	}
+	result := make(map[string]string, len(rows))
+	for _, row := range rows {
+		result[row.Name] = row.Sql
+	}
+	return result
+}
+
+// legacyMigrations created the schema before it was versioned
+var legacyMigrations = []func(*gorm.DB) error{schema.LegacyAutoMigrate}
+
+// modelMigrations create the schema of every gorm model
+var modelMigrations = []func(*gorm.DB) error{
+	ingredient.RunMigrations, recipe.RunMigrations, cooking_unit.RunMigrations, webhook.RunMigrations,
+	audit.RunMigrations, outbox.RunMigrations,
+}
+
+func autoMigrate(t *testing.T, db *gorm.DB, migrations []func(*gorm.DB) error) {
+	t.Helper()
+	for _, migrate := range migrations {
+		if err := migrate(db); err != nil {
+			t.Fatalf("failed to auto migrate: %v", err)
+		}
+	}
+}
+
+func TestMigrate_MatchesModels(t *testing.T) {
+	db := openDB(t)
+	if err := schema.Migrate(ctx, db); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+	migrated := schemaOf(t, db)
+
+	// AutoMigrate changes nothing when the models match the migrations
+	autoMigrate(t, db, modelMigrations)
+	if models := schemaOf(t, db); !reflect.DeepEqual(migrated, models) {
+		t.Fatalf("gorm models don't match the migrations, add a migration\nmigrations: %v\nmodels:     %v", migrated, models)
+	}
+}
+
+func TestMigrate_AdoptsAutoMigrate(t *testing.T) {
+	db := openDB(t)
+	autoMigrate(t, db, legacyMigrations)
+	repo := ingredient.NewGormRepo(db)
+	if err := repo.Add(ctx, &entity.Ingredient{Name: "Tomato", Type: "Vegetable"}); err != nil {
+		t.Fatalf("failed to add ingredient: %v", err)
+	}
+
+	if err := schema.Migrate(ctx, db); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+	sqlDB, _ := db.DB()
+	status, err := schema.Store.Status(ctx, sqlDB)
+	if err != nil {
+		t.Fatalf("failed to get status: %v", err)
+	}
+	if status.Version != status.Latest {
+		t.Fatalf("expected version %d, got %d", status.Latest, status.Version)
+	}
+	if _, err := repo.FindByID(ctx, 1); err != nil {
+		t.Fatalf("expected the ingredients to be kept, got %v", err)
+	}
+
+	// Adopting twice does nothing
+	if err := schema.Migrate(ctx, db); err != nil {
+		t.Fatalf("failed to migrate again: %v", err)
+	}
+}
+
+func TestMigrate_FailsOnNewerSchema(t *testing.T) {
+	db := openDB(t)
+	if err := schema.Migrate(ctx, db); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+	if err := db.Exec(`PRAGMA user_version = 99`).Error; err != nil {
+		t.Fatalf("failed to set version: %v", err)
+	}
+
+	if err := schema.Check(ctx, db); !errors.Is(err, sqlitedb.ErrSchemaAhead) {
+		t.Fatalf("expected ErrSchemaAhead, got %v", err)
+	}
+	if err := schema.Migrate(ctx, db); !errors.Is(err, sqlitedb.ErrSchemaAhead) {
+		t.Fatalf("expected migrating to fail with ErrSchemaAhead, got %v", err)
+	}
+}
+
+func TestMigrate_FailsOnDivergentSchema(t *testing.T) {
+	db := openDB(t)
+	sqlDB, _ := db.DB()
+	// Migrated by the sql backend instead
+	if err := migrations.Store.Migrate(ctx, sqlDB, 1); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+
+	if err := schema.Check(ctx, db); !errors.Is(err, sqlitedb.ErrMigrationEdited) {
+		t.Fatalf("expected ErrMigrationEdited, got %v", err)
+	}
+	if err := schema.Migrate(ctx, db); !errors.Is(err, sqlitedb.ErrMigrationEdited) {
+		t.Fatalf("expected migrating to fail with ErrMigrationEdited, got %v", err)
+	}
+}
+
+func TestMigrate_BackfillsRecipeSlugs(t *testing.T) {
+	db := openDB(t)
+	autoMigrate(t, db, legacyMigrations)
+	legacy := []*schema.Recipe{{Name: "Pa amb tomàquet"}, {Name: "Pa amb tomaquet"}, {Name: "Pa amb tomàquet 2"}, {Name: "¡!"}}
+	if err := db.Create(legacy).Error; err != nil {
+		t.Fatalf("failed to add recipes: %v", err)
+	}
+
+	if err := schema.Migrate(ctx, db); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+
+	// Repeated names are told apart by ID, unless the name took the slug
+	repo := recipe.NewGormRepo(db)
+	for slug, id := range map[string]uint{
+		"pa-amb-tomaquet":    legacy[0].ID,
+		"pa-amb-tomaquet--2": legacy[1].ID,
+		"pa-amb-tomaquet-2":  legacy[2].ID,
+		"recipe":             legacy[3].ID,
+	} {
+		found, err := repo.FindBySlug(ctx, slug)
+		if err != nil {
+			t.Fatalf("failed to find recipe by slug %q: %v", slug, err)
+		}
+		if found.ID != int64(id) || found.Slug != slug {
+			t.Fatalf("expected slug %q to find recipe %d, got %d with slug %q", slug, id, found.ID, found.Slug)
+		}
+	}
+}
+
+func TestCheck_FailsOnChangedTables(t *testing.T) {
+	db := openDB(t)
+	if err := schema.Migrate(ctx, db); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+	if err := schema.Check(ctx, db); err != nil {
+		t.Fatalf("expected the migrated database to pass, got %v", err)
+	}
+
+	// Changed by hand, the checksums are intact
+	if err := db.Exec(`DROP INDEX idx_recipe_slugs_recipe_id`).Error; err != nil {
+		t.Fatalf("failed to drop index: %v", err)
+	}
+	err := schema.Check(ctx, db)
+	if !errors.Is(err, sqlitedb.ErrSchemaDrift) || !strings.Contains(err.Error(), "recipe_slugs: missing index idx_recipe_slugs_recipe_id") {
+		t.Fatalf("expected ErrSchemaDrift for the dropped index, got %v", err)
+	}
+	if err := schema.Migrate(ctx, db); !errors.Is(err, sqlitedb.ErrSchemaDrift) {
+		t.Fatalf("expected migrating to fail with ErrSchemaDrift, got %v", err)
+	}
+}
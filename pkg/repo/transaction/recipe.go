@@ -0,0 +1,44 @@
+package transaction
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/TomeuUris/recipes-catalog/pkg/entity"
+	"github.com/TomeuUris/recipes-catalog/pkg/repo/ingredient"
+)
+
+// AddRecipe adds the recipe and its new ingredients in one unit of work
+func AddRecipe(ctx context.Context, m Manager, recipe *entity.Recipe) error {
+	return m.Do(ctx, func(repos *Repos) error {
+		if err := addNewIngredients(ctx, repos.Ingredients, recipe); err != nil {
+			return err
+		}
+		return repos.Recipes.Add(ctx, recipe)
+	})
+}
+
+// EditRecipe updates the recipe and adds its new ingredients in one unit of
+// work
+func EditRecipe(ctx context.Context, m Manager, recipe *entity.Recipe) error {
+	return m.Do(ctx, func(repos *Repos) error {
+		if err := addNewIngredients(ctx, repos.Ingredients, recipe); err != nil {
+			return err
+		}
+		return repos.Recipes.Edit(ctx, recipe)
+	})
+}
+
+// addNewIngredients creates the ingredients of the recipe without ID through
+// the ingredients repository, instead of letting the recipes one insert them
+func addNewIngredients(ctx context.Context, ingredients ingredient.Repo, recipe *entity.Recipe) error {
+	for _, i := range recipe.Ingredients {
+		if i.ID != 0 {
+			continue
+		}
+		if err := ingredients.Add(ctx, i); err != nil {
+			return fmt.Errorf("ingredient %q: %w", i.Name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+// Package transaction runs writes on several repositories atomically
+package transaction
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/TomeuUris/recipes-catalog/pkg/repo/audit"
+	"github.com/TomeuUris/recipes-catalog/pkg/repo/cooking_unit"
+	"github.com/TomeuUris/recipes-catalog/pkg/repo/ingredient"
+	"github.com/TomeuUris/recipes-catalog/pkg/repo/outbox"
+	"github.com/TomeuUris/recipes-catalog/pkg/repo/recipe"
+	"github.com/TomeuUris/recipes-catalog/pkg/sqlite"
+	"gorm.io/gorm"
+)
+
+// Repos are the repositories of a unit of work
+type Repos struct {
+	Ingredients  ingredient.Repo
+	Recipes      recipe.Repo
+	CookingUnits cooking_unit.Repo
+	// RecipesTrash and IngredientsTrash are nil for backends that don't soft
+	// delete
+	RecipesTrash     recipe.TrashRepo
+	IngredientsTrash ingredient.TrashRepo
+	// Outbox receives the events of the writes, in the same transaction
+	Outbox outbox.Repo
+	// Audit receives the audit entries of the writes, in the same transaction
+	Audit audit.Repo
+	// Tx runs the queries the repositories don't cover on the transaction,
+	// nil for backends without transactions
+	Tx Querier
+}
+
+// Querier runs queries on the transaction of a unit of work
+type Querier interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+// Manager runs units of work
+type Manager interface {
+	// Do runs fn with repositories bound to a transaction, which is committed
+	// when fn returns nil and rolled back otherwise
+	Do(ctx context.Context, fn func(repos *Repos) error) error
+}
+
+// GormManager runs the units of work in gorm transactions
+type GormManager struct {
+	db *gorm.DB
+}
+
+func NewGormManager(db *gorm.DB) *GormManager {
+	return &GormManager{db: db}
+}
+
+func (m *GormManager) Do(ctx context.Context, fn func(repos *Repos) error) error {
+	return m.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		ingredients, recipes := ingredient.NewGormRepo(tx), recipe.NewGormRepo(tx)
+		return fn(&Repos{
+			Ingredients:      ingredients,
+			Recipes:          recipes,
+			CookingUnits:     cooking_unit.NewGormRepo(tx),
+			RecipesTrash:     recipes,
+			IngredientsTrash: ingredients,
+			Outbox:           outbox.NewGormRepo(tx),
+			Audit:            audit.NewGormRepo(tx),
+			Tx:               tx.Statement.ConnPool,
+		})
+	})
+}
+
+// SQLManager runs the units of work in database/sql transactions, the
+// transactions the repositories begin are savepoints of it
+type SQLManager struct {
+	conn *sqlite.Conn
+}
+
+func NewSQLManager(db sqlite.DB) *SQLManager {
+	return &SQLManager{conn: sqlite.NewConn(db)}
+}
+
+func (m *SQLManager) Do(ctx context.Context, fn func(repos *Repos) error) error {
+	tx, err := m.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	// Rolling back a committed transaction is a no-op
+	defer tx.Rollback()
+
+	if err := fn(&Repos{
+		Ingredients:  ingredient.NewConnRepo(tx.Conn),
+		Recipes:      recipe.NewConnRepo(tx.Conn),
+		CookingUnits: cooking_unit.NewConnRepo(tx.Conn),
+		Outbox:       outbox.NewConnRepo(tx.Conn),
+		Audit:        audit.NewConnRepo(tx.Conn),
+		Tx:           tx.Conn,
+	}); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// PassthroughManager runs the units of work on the repositories themselves,
+// for backends without transactions like memory. The writes done before a
+// failure are kept.
+type PassthroughManager struct {
+	repos Repos
+}
+
+func NewPassthroughManager(repos Repos) *PassthroughManager {
+	return &PassthroughManager{repos: repos}
+}
+
+func (m *PassthroughManager) Do(ctx context.Context, fn func(repos *Repos) error) error {
+	repos := m.repos
+	return fn(&repos)
+}
@@ -0,0 +1,156 @@
+package transaction_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/TomeuUris/recipes-catalog/pkg/entity"
+	"github.com/TomeuUris/recipes-catalog/pkg/repo/ingredient"
+	"github.com/TomeuUris/recipes-catalog/pkg/repo/recipe"
+	"github.com/TomeuUris/recipes-catalog/pkg/repo/schema"
+	"github.com/TomeuUris/recipes-catalog/pkg/repo/transaction"
+	"github.com/TomeuUris/recipes-catalog/pkg/sqlite"
+	"github.com/TomeuUris/recipes-catalog/pkg/sqlite/migrations"
+	gormsqlite "gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+var ctx = context.Background()
+
+var errAbort = errors.New("abort")
+
+// backend returns a manager and the repositories outside its transactions
+type backend func(t *testing.T) (transaction.Manager, *transaction.Repos)
+
+func newGorm(t *testing.T) (transaction.Manager, *transaction.Repos) {
+	db, err := gorm.Open(&gormsqlite.Dialector{DriverName: sqlite.DriverName, DSN: "file::memory:"}, &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("failed to get database: %v", err)
+	}
+	// Every connection to :memory: opens a new database
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+	if err := schema.Migrate(ctx, db); err != nil {
+		t.Fatalf("failed to migrate database: %v", err)
+	}
+	return transaction.NewGormManager(db), &transaction.Repos{
+		Ingredients:      ingredient.NewGormRepo(db),
+		Recipes:          recipe.NewGormRepo(db),
+		RecipesTrash:     recipe.NewGormRepo(db),
+		IngredientsTrash: ingredient.NewGormRepo(db),
+	}
+}
+
+func newSQL(t *testing.T) (transaction.Manager, *transaction.Repos) {
+	sqlDB, err := sqlite.Open(":memory:")
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	// Every connection to :memory: opens a new database
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+	if err := migrations.Store.Apply(ctx, sqlDB); err != nil {
+		t.Fatalf("failed to migrate database: %v", err)
+	}
+	return transaction.NewSQLManager(sqlDB), &transaction.Repos{
+		Ingredients: ingredient.NewRepo(sqlDB),
+		Recipes:     recipe.NewRepo(sqlDB),
+	}
+}
+
+func forEachBackend(t *testing.T, test func(t *testing.T, manager transaction.Manager, repos *transaction.Repos)) {
+	for name, open := range map[string]backend{"gorm": newGorm, "sql": newSQL} {
+		t.Run(name, func(t *testing.T) {
+			manager, repos := open(t)
+			test(t, manager, repos)
+		})
+	}
+}
+
+// addRecipe adds a recipe with a new ingredient
+func addRecipe(repos *transaction.Repos) (*entity.Recipe, error) {
+	tomato := &entity.Ingredient{Name: "Tomato", Type: "Vegetable"}
+	if err := repos.Ingredients.Add(ctx, tomato); err != nil {
+		return nil, err
+	}
+	salad := &entity.Recipe{Name: "Salad", Steps: []string{"Cut"}, Ingredients: []*entity.Ingredient{tomato}}
+	return salad, repos.Recipes.Add(ctx, salad)
+}
+
+func expectCounts(t *testing.T, repos *transaction.Repos, ingredients, recipes int) {
+	t.Helper()
+	foundIngredients, err := repos.Ingredients.FindByFilter(ctx, &ingredient.FindFilter{})
+	if err != nil {
+		t.Fatalf("failed to find ingredients: %v", err)
+	}
+	foundRecipes, err := repos.Recipes.FindByFilter(ctx, &recipe.FindFilter{})
+	if err != nil {
+		t.Fatalf("failed to find recipes: %v", err)
+	}
+	if len(foundIngredients) != ingredients || len(foundRecipes) != recipes {
+		t.Fatalf("expected %d ingredients and %d recipes, got %d and %d",
+			ingredients, recipes, len(foundIngredients), len(foundRecipes))
+	}
+}
+
+func TestManager_Commits(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, manager transaction.Manager, repos *transaction.Repos) {
+		var added *entity.Recipe
+		if err := manager.Do(ctx, func(tx *transaction.Repos) (err error) {
+			added, err = addRecipe(tx)
+			return err
+		}); err != nil {
+			t.Fatalf("failed to run unit of work: %v", err)
+		}
+
+		expectCounts(t, repos, 1, 1)
+		found, err := repos.Recipes.FindByID(ctx, added.ID)
+		if err != nil || len(found.Ingredients) != 1 {
+			t.Fatalf("expected the recipe with its ingredient, got %+v, %v", found, err)
+		}
+	})
+}
+
+func TestManager_RollsBack(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, manager transaction.Manager, repos *transaction.Repos) {
+		err := manager.Do(ctx, func(tx *transaction.Repos) error {
+			if _, err := addRecipe(tx); err != nil {
+				return err
+			}
+			return errAbort
+		})
+		if !errors.Is(err, errAbort) {
+			t.Fatalf("expected the error of the unit of work, got %v", err)
+		}
+
+		expectCounts(t, repos, 0, 0)
+	})
+}
+
+func TestManager_NestsRepositoryTransactions(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, manager transaction.Manager, repos *transaction.Repos) {
+		err := manager.Do(ctx, func(tx *transaction.Repos) error {
+			added, err := addRecipe(tx)
+			if err != nil {
+				return err
+			}
+			// The repository rolls back its own transaction only
+			err = tx.Ingredients.Delete(ctx, added.Ingredients[0])
+			if !entity.IsErrInUse(err) {
+				t.Fatalf("expected the ingredient to be in use, got %v", err)
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("failed to run unit of work: %v", err)
+		}
+
+		expectCounts(t, repos, 1, 1)
+	})
+}
@@ -0,0 +1,34 @@
+package transaction
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/TomeuUris/recipes-catalog/pkg/entity"
+)
+
+// ErrNoTrash is returned when purging the trash of a backend that doesn't
+// soft delete
+var ErrNoTrash = errors.New("the backend has no trash")
+
+// PurgeTrash permanently removes the recipes and ingredients deleted before
+// the time in one unit of work, so both are purged or neither is
+func PurgeTrash(ctx context.Context, m Manager, before time.Time) (recipes, ingredients []*entity.TrashItem, err error) {
+	err = m.Do(ctx, func(repos *Repos) error {
+		if repos.RecipesTrash == nil || repos.IngredientsTrash == nil {
+			return ErrNoTrash
+		}
+		// Recipes first, so their links to purged ingredients are gone too
+		var err error
+		if recipes, err = repos.RecipesTrash.Purge(ctx, before); err != nil {
+			return err
+		}
+		ingredients, err = repos.IngredientsTrash.Purge(ctx, before)
+		return err
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return recipes, ingredients, nil
+}
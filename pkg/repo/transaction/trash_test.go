@@ -0,0 +1,60 @@
+package transaction_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/TomeuUris/recipes-catalog/pkg/entity"
+	"github.com/TomeuUris/recipes-catalog/pkg/repo/ingredient"
+	"github.com/TomeuUris/recipes-catalog/pkg/repo/transaction"
+)
+
+// failingPurge fails to purge the ingredients trash
+type failingPurge struct {
+	ingredient.TrashRepo
+}
+
+func (failingPurge) Purge(ctx context.Context, before time.Time) ([]*entity.TrashItem, error) {
+	return nil, errAbort
+}
+
+// failingPurgeManager runs the units of work with the failing ingredients trash
+type failingPurgeManager struct {
+	transaction.Manager
+}
+
+func (m failingPurgeManager) Do(ctx context.Context, fn func(repos *transaction.Repos) error) error {
+	return m.Manager.Do(ctx, func(repos *transaction.Repos) error {
+		failing := *repos
+		failing.IngredientsTrash = failingPurge{repos.IngredientsTrash}
+		return fn(&failing)
+	})
+}
+
+func TestPurgeTrash_RollsBackTheRecipes(t *testing.T) {
+	manager, repos := newGorm(t)
+	added, err := addRecipe(repos)
+	if err != nil {
+		t.Fatalf("failed to add recipe: %v", err)
+	}
+	if err := repos.Recipes.Delete(ctx, added); err != nil {
+		t.Fatalf("failed to delete recipe: %v", err)
+	}
+	before := time.Now().Add(time.Hour)
+
+	_, _, err = transaction.PurgeTrash(ctx, failingPurgeManager{manager}, before)
+	if !errors.Is(err, errAbort) {
+		t.Fatalf("expected the error of the ingredients purge, got %v", err)
+	}
+	deleted, err := repos.RecipesTrash.FindDeleted(ctx)
+	if err != nil || len(deleted) != 1 {
+		t.Fatalf("expected the recipe still in the trash, got %+v, %v", deleted, err)
+	}
+
+	recipes, _, err := transaction.PurgeTrash(ctx, manager, before)
+	if err != nil || len(recipes) != 1 {
+		t.Fatalf("expected the recipe to be purged, got %+v, %v", recipes, err)
+	}
+}
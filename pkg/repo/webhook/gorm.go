@@ -0,0 +1,261 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/TomeuUris/recipes-catalog/pkg/entity"
+	"github.com/TomeuUris/recipes-catalog/pkg/sqlite"
+)
+
+// Database model
+type Webhook struct {
+	gorm.Model
+	URL    string
+	Secret string
+	Events string
+	Active bool
+}
+
+func (w *Webhook) ToEntity() *entity.Webhook {
+	var events []string
+	if w.Events != "" {
+		events = strings.Split(w.Events, ",")
+	}
+	return &entity.Webhook{
+		ID:     int64(w.ID),
+		URL:    w.URL,
+		Secret: w.Secret,
+		Events: events,
+		Active: w.Active,
+	}
+}
+
+func (w *Webhook) FromEntity(webhook *entity.Webhook) {
+	w.ID = uint(webhook.ID)
+	w.URL = webhook.URL
+	w.Secret = webhook.Secret
+	w.Events = strings.Join(webhook.Events, ",")
+	w.Active = webhook.Active
+}
+
+type WebhookDelivery struct {
+	gorm.Model
+	WebhookID      uint  `gorm:"index;uniqueIndex:idx_delivery_event"`
+	EventID        int64 `gorm:"uniqueIndex:idx_delivery_event"`
+	EventType      string
+	Payload        string
+	Status         string    `gorm:"index:idx_delivery_due"`
+	NextAttemptAt  time.Time `gorm:"index:idx_delivery_due"`
+	Attempts       int
+	ResponseStatus int
+	LastError      string
+	DeliveredAt    *time.Time
+}
+
+func (d *WebhookDelivery) ToEntity() *entity.WebhookDelivery {
+	return &entity.WebhookDelivery{
+		ID:             int64(d.ID),
+		WebhookID:      int64(d.WebhookID),
+		EventID:        d.EventID,
+		EventType:      d.EventType,
+		Payload:        d.Payload,
+		Status:         d.Status,
+		Attempts:       d.Attempts,
+		ResponseStatus: d.ResponseStatus,
+		LastError:      d.LastError,
+		NextAttemptAt:  d.NextAttemptAt,
+		DeliveredAt:    d.DeliveredAt,
+		CreatedAt:      d.CreatedAt,
+	}
+}
+
+func (d *WebhookDelivery) FromEntity(delivery *entity.WebhookDelivery) {
+	d.ID = uint(delivery.ID)
+	d.WebhookID = uint(delivery.WebhookID)
+	d.EventID = delivery.EventID
+	d.EventType = delivery.EventType
+	d.Payload = delivery.Payload
+	d.Status = delivery.Status
+	d.Attempts = delivery.Attempts
+	d.ResponseStatus = delivery.ResponseStatus
+	d.LastError = delivery.LastError
+	d.NextAttemptAt = delivery.NextAttemptAt
+	d.DeliveredAt = delivery.DeliveredAt
+	d.CreatedAt = delivery.CreatedAt
+}
+
+// Repository implementation
+type RepoGorm struct {
+	db *gorm.DB
+}
+
+type DeliveryRepoGorm struct {
+	db *gorm.DB
+}
+
+// Utility functions
+func NewGormRepo(db *gorm.DB) *RepoGorm {
+	return &RepoGorm{
+		db: db,
+	}
+}
+
+func NewGormDeliveryRepo(db *gorm.DB) *DeliveryRepoGorm {
+	return &DeliveryRepoGorm{
+		db: db,
+	}
+}
+
+func RunMigrations(db *gorm.DB) error {
+	return db.AutoMigrate(&Webhook{}, &WebhookDelivery{})
+}
+
+// CRUD functions
+func (r *RepoGorm) FindByID(ctx context.Context, id int64) (*entity.Webhook, error) {
+	webhook := &Webhook{}
+	if err := r.db.WithContext(ctx).First(webhook, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, entity.ErrNotFound
+		}
+		return nil, err
+	}
+
+	return webhook.ToEntity(), nil
+}
+
+func (r *RepoGorm) FindByFilter(ctx context.Context, f *FindFilter) ([]*entity.Webhook, error) {
+	query := r.db.WithContext(ctx)
+	if f.Active != nil {
+		query = query.Where("active = ?", *f.Active)
+	}
+
+	var webhooks []*Webhook
+	if err := query.Find(&webhooks).Error; err != nil {
+		return nil, err
+	}
+	result := make([]*entity.Webhook, len(webhooks))
+	for i, webhook := range webhooks {
+		result[i] = webhook.ToEntity()
+	}
+
+	return result, nil
+}
+
+func (r *RepoGorm) Add(ctx context.Context, webhook *entity.Webhook) error {
+	w := &Webhook{}
+	w.FromEntity(webhook)
+	if err := r.db.WithContext(ctx).Create(w).Error; err != nil {
+		return err
+	}
+	webhook.ID = int64(w.ID)
+	return nil
+}
+
+func (r *RepoGorm) Edit(ctx context.Context, webhook *entity.Webhook) error {
+	w := &Webhook{}
+	w.FromEntity(webhook)
+	result := r.db.WithContext(ctx).Model(w).Select("URL", "Secret", "Events", "Active").Updates(w)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return entity.ErrNotFound
+	}
+	return nil
+}
+
+func (r *RepoGorm) Delete(ctx context.Context, webhook *entity.Webhook) error {
+	result := r.db.WithContext(ctx).Delete(&Webhook{}, webhook.ID)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return entity.ErrNotFound
+	}
+	return nil
+}
+
+func (r *DeliveryRepoGorm) FindByID(ctx context.Context, id int64) (*entity.WebhookDelivery, error) {
+	delivery := &WebhookDelivery{}
+	if err := r.db.WithContext(ctx).First(delivery, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, entity.ErrNotFound
+		}
+		return nil, err
+	}
+
+	return delivery.ToEntity(), nil
+}
+
+func (r *DeliveryRepoGorm) FindByFilter(ctx context.Context, f *DeliveryFilter) ([]*entity.WebhookDelivery, error) {
+	query := r.db.WithContext(ctx).Order("id DESC")
+	if f.WebhookID != 0 {
+		query = query.Where("webhook_id = ?", f.WebhookID)
+	}
+	if f.Status != "" {
+		query = query.Where("status = ?", f.Status)
+	}
+	if f.Limit > 0 {
+		query = query.Limit(f.Limit)
+	}
+
+	var deliveries []*WebhookDelivery
+	if err := query.Find(&deliveries).Error; err != nil {
+		return nil, err
+	}
+	return deliveriesToEntity(deliveries), nil
+}
+
+func (r *DeliveryRepoGorm) FindDue(ctx context.Context, at time.Time, limit int) ([]*entity.WebhookDelivery, error) {
+	var deliveries []*WebhookDelivery
+	if err := r.db.WithContext(ctx).
+		Where("status = ? AND next_attempt_at <= ?", entity.DeliveryPending, at).
+		Order("next_attempt_at ASC").
+		Limit(limit).
+		Find(&deliveries).Error; err != nil {
+		return nil, err
+	}
+	return deliveriesToEntity(deliveries), nil
+}
+
+func (r *DeliveryRepoGorm) Add(ctx context.Context, delivery *entity.WebhookDelivery) error {
+	d := &WebhookDelivery{}
+	d.FromEntity(delivery)
+	if err := r.db.WithContext(ctx).Create(d).Error; err != nil {
+		if sqlite.IsUniqueViolation(err) {
+			return entity.ErrAlreadyExists
+		}
+		return err
+	}
+	delivery.ID = int64(d.ID)
+	delivery.CreatedAt = d.CreatedAt
+	return nil
+}
+
+func (r *DeliveryRepoGorm) Edit(ctx context.Context, delivery *entity.WebhookDelivery) error {
+	d := &WebhookDelivery{}
+	d.FromEntity(delivery)
+	result := r.db.WithContext(ctx).Model(d).
+		Select("Status", "Attempts", "ResponseStatus", "LastError", "NextAttemptAt", "DeliveredAt").
+		Updates(d)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return entity.ErrNotFound
+	}
+	return nil
+}
+
+func deliveriesToEntity(deliveries []*WebhookDelivery) []*entity.WebhookDelivery {
+	result := make([]*entity.WebhookDelivery, len(deliveries))
+	for i, delivery := range deliveries {
+		result[i] = delivery.ToEntity()
+	}
+	return result
+}
@@ -0,0 +1,36 @@
+package webhook
+
+import (
+	"context"
+	"time"
+
+	"github.com/TomeuUris/recipes-catalog/pkg/entity"
+)
+
+type Repo interface {
+	FindByID(ctx context.Context, id int64) (*entity.Webhook, error)
+	FindByFilter(ctx context.Context, f *FindFilter) ([]*entity.Webhook, error)
+	Add(ctx context.Context, webhook *entity.Webhook) error
+	Edit(ctx context.Context, webhook *entity.Webhook) error
+	Delete(ctx context.Context, webhook *entity.Webhook) error
+}
+
+type FindFilter struct {
+	Active *bool `form:"active"`
+}
+
+type DeliveryRepo interface {
+	FindByID(ctx context.Context, id int64) (*entity.WebhookDelivery, error)
+	FindByFilter(ctx context.Context, f *DeliveryFilter) ([]*entity.WebhookDelivery, error)
+	// FindDue returns the pending deliveries whose next attempt is due at the given time, oldest first
+	FindDue(ctx context.Context, at time.Time, limit int) ([]*entity.WebhookDelivery, error)
+	// Add returns entity.ErrAlreadyExists when the webhook has a delivery of the event
+	Add(ctx context.Context, delivery *entity.WebhookDelivery) error
+	Edit(ctx context.Context, delivery *entity.WebhookDelivery) error
+}
+
+type DeliveryFilter struct {
+	WebhookID int64  `form:"-"`
+	Status    string `form:"status"`
+	Limit     int    `form:"limit"`
+}
@@ -0,0 +1,369 @@
+package schemaorg
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	stdhtml "html"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/TomeuUris/recipes-catalog/pkg/entity"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// Extract returns the schema.org Recipes of an HTML document, those in its
+// JSON-LD scripts first and then those in its microdata. Pages write the
+// vocabulary loosely, values given as a single item or a list, numbers or
+// text and instructions as text, steps or sections are all read.
+func Extract(r io.Reader) ([]*Recipe, error) {
+	doc, err := html.Parse(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse HTML: %w", err)
+	}
+
+	var recipes []*Recipe
+	walk(doc, func(n *html.Node) bool {
+		if n.DataAtom == atom.Script && strings.EqualFold(strings.TrimSpace(attr(n, "type")), MIMEType) {
+			recipes = append(recipes, fromJSONLD(textContent(n))...)
+			return false
+		}
+		return true
+	})
+	walk(doc, func(n *html.Node) bool {
+		if _, ok := attrOK(n, "itemscope"); ok && isRecipe(strings.Fields(attr(n, "itemtype"))) {
+			recipes = append(recipes, fromItem(microdataItem(n)))
+			return false
+		}
+		return true
+	})
+	return recipes, nil
+}
+
+// fromJSONLD returns the recipes anywhere in a JSON-LD document, scripts that
+// aren't valid JSON are skipped like search engines do
+func fromJSONLD(raw string) []*Recipe {
+	var document interface{}
+	if err := json.Unmarshal([]byte(raw), &document); err != nil {
+		return nil
+	}
+
+	var recipes []*Recipe
+	var find func(value interface{})
+	find = func(value interface{}) {
+		switch value := value.(type) {
+		case []interface{}:
+			for _, item := range value {
+				find(item)
+			}
+		case map[string]interface{}:
+			if isRecipe(texts(value["@type"])) {
+				recipes = append(recipes, fromItem(value))
+				return
+			}
+			for _, item := range value {
+				find(item)
+			}
+		}
+	}
+	find(document)
+	return recipes
+}
+
+// fromItem reads a recipe from the properties of a JSON-LD object or a
+// microdata item, both decoded to the values of encoding/json
+func fromItem(item map[string]interface{}) *Recipe {
+	recipe := &Recipe{
+		Context:            Context,
+		Type:               "Recipe",
+		Name:               text(item["name"]),
+		Description:        text(item["description"]),
+		RecipeIngredient:   texts(item["recipeIngredient"]),
+		RecipeInstructions: instructions(item["recipeInstructions"]),
+		PrepTime:           text(item["prepTime"]),
+		CookTime:           text(item["cookTime"]),
+		TotalTime:          text(item["totalTime"]),
+		RecipeYield:        yield(item["recipeYield"]),
+	}
+	// ingredients is the property recipeIngredient superseded
+	if len(recipe.RecipeIngredient) == 0 {
+		recipe.RecipeIngredient = texts(item["ingredients"])
+	}
+	if nutrition, ok := first(item["nutrition"]).(map[string]interface{}); ok {
+		if calories := text(nutrition["calories"]); calories != "" {
+			recipe.Nutrition = &NutritionInformation{Type: "NutritionInformation", Calories: calories}
+		}
+	}
+	return recipe
+}
+
+// instructions returns the steps of the instructions, given as a text of one
+// step per line, a list of texts or HowToSteps or HowToSections of them
+func instructions(value interface{}) []*HowToStep {
+	var steps []*HowToStep
+	var add func(value interface{})
+	add = func(value interface{}) {
+		switch value := value.(type) {
+		case []interface{}:
+			for _, item := range value {
+				add(item)
+			}
+		case map[string]interface{}:
+			if elements, ok := value["itemListElement"]; ok {
+				add(elements)
+				return
+			}
+			step := text(value["text"])
+			if step == "" {
+				step = text(value["name"])
+			}
+			add(step)
+		default:
+			for _, line := range strings.Split(text(value), "\n") {
+				if line = strings.TrimSpace(line); line != "" {
+					steps = append(steps, &HowToStep{Type: "HowToStep", Position: len(steps) + 1, Text: line})
+				}
+			}
+		}
+	}
+	add(value)
+	return steps
+}
+
+// yield returns the yield with its unit, pages often give the number of
+// servings alone along with the text
+func yield(value interface{}) string {
+	values := texts(value)
+	for _, v := range values {
+		if _, err := strconv.ParseFloat(v, 64); err != nil {
+			return v
+		}
+	}
+	if len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+func isRecipe(types []string) bool {
+	for _, t := range types {
+		if t == "Recipe" || strings.HasSuffix(t, "schema.org/Recipe") {
+			return true
+		}
+	}
+	return false
+}
+
+// first returns the value, or the first item of a list
+func first(value interface{}) interface{} {
+	if list, ok := value.([]interface{}); ok {
+		if len(list) == 0 {
+			return nil
+		}
+		return list[0]
+	}
+	return value
+}
+
+// text returns the value as text without the HTML entities some pages escape
+// their JSON-LD with
+func text(value interface{}) string {
+	switch value := first(value).(type) {
+	case string:
+		return strings.TrimSpace(stdhtml.UnescapeString(value))
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	case map[string]interface{}:
+		// Items like a Text or a QuantitativeValue
+		if v, ok := value["@value"]; ok {
+			return text(v)
+		}
+		return text(value["value"])
+	}
+	return ""
+}
+
+// texts returns the value, a single item or a list, as a list of texts
+func texts(value interface{}) []string {
+	list, ok := value.([]interface{})
+	if !ok {
+		list = []interface{}{value}
+	}
+	result := []string{}
+	for _, item := range list {
+		if t := text(item); t != "" {
+			result = append(result, t)
+		}
+	}
+	return result
+}
+
+// microdataItem returns the properties of an item as a JSON-LD object, every
+// property as a list of values and nested items as objects
+func microdataItem(n *html.Node) map[string]interface{} {
+	item := map[string]interface{}{"@type": attr(n, "itemtype")}
+	var collect func(n *html.Node)
+	collect = func(n *html.Node) {
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			if child.Type != html.ElementNode {
+				continue
+			}
+			names := strings.Fields(attr(child, "itemprop"))
+			_, scoped := attrOK(child, "itemscope")
+			if len(names) > 0 {
+				var value interface{}
+				if scoped {
+					value = microdataItem(child)
+				} else {
+					value = microdataValue(child)
+				}
+				for _, name := range names {
+					values, _ := item[name].([]interface{})
+					item[name] = append(values, value)
+				}
+			}
+			// Properties of nested items belong to them
+			if !scoped {
+				collect(child)
+			}
+		}
+	}
+	collect(n)
+	return item
+}
+
+// microdataValue returns the value of a property element, read from the
+// attribute HTML gives it by element
+func microdataValue(n *html.Node) string {
+	switch n.DataAtom {
+	case atom.Meta:
+		return attr(n, "content")
+	case atom.A, atom.Link, atom.Area:
+		return attr(n, "href")
+	case atom.Img, atom.Audio, atom.Video, atom.Source, atom.Iframe, atom.Embed:
+		return attr(n, "src")
+	case atom.Data, atom.Meter:
+		return attr(n, "value")
+	case atom.Time:
+		if datetime, ok := attrOK(n, "datetime"); ok {
+			return datetime
+		}
+	}
+	// Pages show a text and give the value in content, like durations
+	if content, ok := attrOK(n, "content"); ok {
+		return content
+	}
+	return textContent(n)
+}
+
+// textContent returns the text of the node, with the lines of block elements
+// and line breaks kept
+func textContent(n *html.Node) string {
+	var b strings.Builder
+	walk(n, func(n *html.Node) bool {
+		switch {
+		case n.Type == html.TextNode:
+			b.WriteString(n.Data)
+		case n.DataAtom == atom.Br || n.DataAtom == atom.Li || n.DataAtom == atom.P:
+			b.WriteString("\n")
+		}
+		return true
+	})
+
+	lines := strings.Split(b.String(), "\n")
+	for i, line := range lines {
+		lines[i] = strings.Join(strings.Fields(line), " ")
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+// walk visits the node and its descendants in document order, fn returns
+// false to skip the descendants of a node
+func walk(n *html.Node, fn func(n *html.Node) bool) {
+	if !fn(n) {
+		return
+	}
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		walk(child, fn)
+	}
+}
+
+func attr(n *html.Node, key string) string {
+	value, _ := attrOK(n, key)
+	return value
+}
+
+func attrOK(n *html.Node, key string) (string, bool) {
+	for _, a := range n.Attr {
+		if a.Namespace == "" && a.Key == key {
+			return a.Val, true
+		}
+	}
+	return "", false
+}
+
+var isoDuration = regexp.MustCompile(`^P(?:(\d+)D)?(?:T(?:(\d+)H)?(?:(\d+)M)?(?:(\d+(?:\.\d+)?)S)?)?$`)
+
+// ErrInvalidDuration is returned for durations other than the ISO 8601 ones
+// of days, hours, minutes and seconds recipes use
+var ErrInvalidDuration = errors.New("invalid ISO 8601 duration")
+
+// ParseDuration parses an ISO 8601 duration like PT1H30M
+func ParseDuration(s string) (time.Duration, error) {
+	s = strings.ToUpper(strings.TrimSpace(s))
+	match := isoDuration.FindStringSubmatch(s)
+	// Every part is optional, but one must be given
+	if match == nil || s == "P" || strings.HasSuffix(s, "T") {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidDuration, s)
+	}
+
+	var d time.Duration
+	for i, unit := range []time.Duration{24 * time.Hour, time.Hour, time.Minute, time.Second} {
+		if match[i+1] == "" {
+			continue
+		}
+		value, err := strconv.ParseFloat(match[i+1], 64)
+		if err != nil {
+			return 0, fmt.Errorf("%w: %q", ErrInvalidDuration, s)
+		}
+		d += time.Duration(value * float64(unit))
+	}
+	return d, nil
+}
+
+var leadingNumber = regexp.MustCompile(`^\d+(?:[.,]\d+)?`)
+
+// ToEntity returns the recipe without ingredients, the lines of
+// RecipeIngredient are matched to ingredients by the importer. Times that
+// aren't valid durations are left unknown, like calories that aren't a
+// number of kcal.
+func (r *Recipe) ToEntity() *entity.Recipe {
+	recipe := &entity.Recipe{
+		Name:        r.Name,
+		Description: r.Description,
+		Steps:       make([]string, 0, len(r.RecipeInstructions)),
+		Yield:       r.RecipeYield,
+	}
+	for _, step := range r.RecipeInstructions {
+		recipe.Steps = append(recipe.Steps, step.Text)
+	}
+	recipe.PrepTime, _ = ParseDuration(r.PrepTime)
+	recipe.CookTime, _ = ParseDuration(r.CookTime)
+	// The total time is kept as the cooking time when it is the only one
+	if recipe.PrepTime == 0 && recipe.CookTime == 0 {
+		recipe.CookTime, _ = ParseDuration(r.TotalTime)
+	}
+
+	if r.Nutrition != nil {
+		calories := strings.ToLower(r.Nutrition.Calories)
+		if number := leadingNumber.FindString(calories); number != "" && !strings.Contains(calories, "kj") {
+			value, _ := strconv.ParseFloat(strings.Replace(number, ",", ".", 1), 64)
+			recipe.Calories = int(value + 0.5)
+		}
+	}
+	return recipe
+}
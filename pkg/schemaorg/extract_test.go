@@ -0,0 +1,136 @@
+package schemaorg_test
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/TomeuUris/recipes-catalog/pkg/schemaorg"
+)
+
+func TestParseDuration(t *testing.T) {
+	tests := []struct {
+		duration string
+		expected time.Duration
+	}{
+		{"PT45M", 45 * time.Minute},
+		{"PT1H30M", 90 * time.Minute},
+		{"pt2h", 2 * time.Hour},
+		{"P1DT2H", 26 * time.Hour},
+		{"PT90S", 90 * time.Second},
+		{"PT0H20M", 20 * time.Minute},
+	}
+	for _, test := range tests {
+		got, err := schemaorg.ParseDuration(test.duration)
+		if err != nil || got != test.expected {
+			t.Errorf("expected %q parsed as %v, got %v (%v)", test.duration, test.expected, got, err)
+		}
+	}
+
+	for _, invalid := range []string{"", "P", "PT", "45 minutes", "P1Y", "PT1M30"} {
+		if _, err := schemaorg.ParseDuration(invalid); !errors.Is(err, schemaorg.ErrInvalidDuration) {
+			t.Errorf("expected %q to be invalid, got %v", invalid, err)
+		}
+	}
+}
+
+// summary encodes the recipes without their context and types
+func summary(t *testing.T, recipes []*schemaorg.Recipe) string {
+	t.Helper()
+	var lines []string
+	for _, recipe := range recipes {
+		var steps []string
+		for _, step := range recipe.RecipeInstructions {
+			steps = append(steps, step.Text)
+		}
+		raw, err := json.Marshal(map[string]interface{}{
+			"name": recipe.Name, "description": recipe.Description, "ingredients": recipe.RecipeIngredient, "steps": steps,
+			"prep": recipe.PrepTime, "cook": recipe.CookTime, "total": recipe.TotalTime,
+			"yield": recipe.RecipeYield, "nutrition": recipe.Nutrition,
+		})
+		if err != nil {
+			t.Fatalf("failed to encode: %v", err)
+		}
+		lines = append(lines, string(raw))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func TestExtract_JSONLD(t *testing.T) {
+	page := `<html><head>
+<script type="application/ld+json">{"@context": "https://schema.org", "@type": "WebSite", "name": "Cooking"}</script>
+<script type="application/ld+json">not JSON</script>
+<script type="application/ld+json">
+{"@context": "https://schema.org", "@graph": [
+	{"@type": "BreadcrumbList"},
+	{"@type": ["Recipe", "NewsArticle"], "name": "Mac &amp; cheese", "description": " Creamy ",
+	 "recipeIngredient": ["200 g macaroni", "1 cup cheese"],
+	 "recipeInstructions": [
+		{"@type": "HowToSection", "name": "Pasta", "itemListElement": [{"@type": "HowToStep", "text": "Boil"}]},
+		{"@type": "HowToStep", "name": "Mix it"},
+		"Bake"
+	 ],
+	 "prepTime": "PT10M", "totalTime": "PT40M", "recipeYield": ["4", "4 servings"],
+	 "nutrition": {"@type": "NutritionInformation", "calories": "320 kcal"}}
+]}
+</script>
+<script type="application/ld+json">[{"@type": "Recipe", "name": "Toast", "recipeInstructions": "Slice\n\nToast", "recipeYield": 2}]</script>
+</head><body></body></html>`
+
+	recipes, err := schemaorg.Extract(strings.NewReader(page))
+	if err != nil {
+		t.Fatalf("failed to extract: %v", err)
+	}
+	expected := strings.Join([]string{
+		`{"cook":"","description":"Creamy","ingredients":["200 g macaroni","1 cup cheese"],"name":"Mac \u0026 cheese",` +
+			`"nutrition":{"@type":"NutritionInformation","calories":"320 kcal"},"prep":"PT10M","steps":["Boil","Mix it","Bake"],"total":"PT40M","yield":"4 servings"}`,
+		`{"cook":"","description":"","ingredients":[],"name":"Toast","nutrition":null,"prep":"","steps":["Slice","Toast"],"total":"","yield":"2"}`,
+	}, "\n")
+	if got := summary(t, recipes); got != expected {
+		t.Fatalf("expected\n%s\ngot\n%s", expected, got)
+	}
+
+	// The total time isn't taken as the cooking time when the preparation is known
+	recipe := recipes[0].ToEntity()
+	if recipe.PrepTime != 10*time.Minute || recipe.CookTime != 0 || recipe.Calories != 320 || recipe.Yield != "4 servings" {
+		t.Fatalf("expected the times, calories and yield of the recipe, got %+v", recipe)
+	}
+	recipe = recipes[1].ToEntity()
+	if recipe.CookTime != 0 || len(recipe.Steps) != 2 || recipe.Calories != 0 {
+		t.Fatalf("expected a recipe without times nor calories, got %+v", recipe)
+	}
+}
+
+func TestExtract_Microdata(t *testing.T) {
+	page := `<html><body>
+<div itemscope itemtype="http://schema.org/Recipe">
+	<h1 itemprop="name">Lemonade</h1>
+	<meta itemprop="cookTime" content="PT5M">
+	<span itemprop="recipeYield">1 jug</span>
+	<ul>
+		<li itemprop="recipeIngredient">3 lemons</li>
+		<li itemprop="recipeIngredient">1 l  water</li>
+	</ul>
+	<div itemprop="nutrition" itemscope itemtype="http://schema.org/NutritionInformation">
+		<span itemprop="calories">90 calories</span>
+		<span itemprop="name">Not the recipe name</span>
+	</div>
+	<ol itemprop="recipeInstructions">
+		<li>Squeeze the lemons</li>
+		<li>Add water</li>
+	</ol>
+</div>
+</body></html>`
+
+	recipes, err := schemaorg.Extract(strings.NewReader(page))
+	if err != nil {
+		t.Fatalf("failed to extract: %v", err)
+	}
+	expected := `{"cook":"PT5M","description":"","ingredients":["3 lemons","1 l water"],"name":"Lemonade",` +
+		`"nutrition":{"@type":"NutritionInformation","calories":"90 calories"},"prep":"","steps":["Squeeze the lemons","Add water"],"total":"","yield":"1 jug"}`
+	if got := summary(t, recipes); got != expected {
+		t.Fatalf("expected\n%s\ngot\n%s", expected, got)
+	}
+}
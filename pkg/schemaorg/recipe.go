@@ -0,0 +1,111 @@
+// Package schemaorg converts recipes to the schema.org Recipe vocabulary, the
+// JSON-LD search engines read to show recipes as rich results
+package schemaorg
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/TomeuUris/recipes-catalog/pkg/entity"
+)
+
+const (
+	// Context is the @context of the documents
+	Context = "https://schema.org"
+	// MIMEType is the content type of JSON-LD documents
+	MIMEType = "application/ld+json"
+)
+
+// Recipe is a schema.org Recipe. Times are ISO 8601 durations, the fields
+// the catalog doesn't know are omitted.
+type Recipe struct {
+	// Context is omitted for the recipes of a Graph
+	Context            string                `json:"@context,omitempty"`
+	Type               string                `json:"@type"`
+	Name               string                `json:"name"`
+	Description        string                `json:"description,omitempty"`
+	RecipeIngredient   []string              `json:"recipeIngredient"`
+	RecipeInstructions []*HowToStep          `json:"recipeInstructions"`
+	PrepTime           string                `json:"prepTime,omitempty"`
+	CookTime           string                `json:"cookTime,omitempty"`
+	TotalTime          string                `json:"totalTime,omitempty"`
+	RecipeYield        string                `json:"recipeYield,omitempty"`
+	Nutrition          *NutritionInformation `json:"nutrition,omitempty"`
+}
+
+// HowToStep is a step of the instructions of a recipe
+type HowToStep struct {
+	Type     string `json:"@type"`
+	Position int    `json:"position"`
+	Text     string `json:"text"`
+}
+
+// NutritionInformation is the nutrition of a serving
+type NutritionInformation struct {
+	Type     string `json:"@type"`
+	Calories string `json:"calories"`
+}
+
+// Graph holds the recipes of an export in a single document
+type Graph struct {
+	Context string    `json:"@context"`
+	Graph   []*Recipe `json:"@graph"`
+}
+
+// FromEntity sets the recipe from the entity, with the @context of a
+// standalone document
+func (r *Recipe) FromEntity(recipe *entity.Recipe) {
+	r.Context = Context
+	r.Type = "Recipe"
+	r.Name = recipe.Name
+	r.Description = recipe.Description
+
+	r.RecipeIngredient = make([]string, len(recipe.Ingredients))
+	for i, ingredient := range recipe.Ingredients {
+		r.RecipeIngredient[i] = ingredient.Name
+	}
+	r.RecipeInstructions = make([]*HowToStep, len(recipe.Steps))
+	for i, step := range recipe.Steps {
+		r.RecipeInstructions[i] = &HowToStep{Type: "HowToStep", Position: i + 1, Text: step}
+	}
+
+	r.PrepTime = FormatDuration(recipe.PrepTime)
+	r.CookTime = FormatDuration(recipe.CookTime)
+	r.TotalTime = FormatDuration(recipe.PrepTime + recipe.CookTime)
+	r.RecipeYield = recipe.Yield
+	r.Nutrition = nil
+	if recipe.Calories > 0 {
+		r.Nutrition = &NutritionInformation{Type: "NutritionInformation", Calories: fmt.Sprintf("%d calories", recipe.Calories)}
+	}
+}
+
+// NewGraph returns the document of the recipes
+func NewGraph(recipes []*entity.Recipe) *Graph {
+	graph := &Graph{Context: Context, Graph: make([]*Recipe, len(recipes))}
+	for i, recipe := range recipes {
+		graph.Graph[i] = &Recipe{}
+		graph.Graph[i].FromEntity(recipe)
+		graph.Graph[i].Context = ""
+	}
+	return graph
+}
+
+// FormatDuration returns the duration as an ISO 8601 duration of hours and
+// minutes, like PT1H30M, or an empty string when it is zero
+func FormatDuration(d time.Duration) string {
+	minutes := int(d / time.Minute)
+	if minutes <= 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString("PT")
+	if hours := minutes / 60; hours > 0 {
+		fmt.Fprintf(&b, "%dH", hours)
+	}
+	if minutes%60 > 0 {
+		fmt.Fprintf(&b, "%dM", minutes%60)
+	}
+	return b.String()
+}
@@ -0,0 +1,69 @@
+package schemaorg_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/TomeuUris/recipes-catalog/pkg/entity"
+	"github.com/TomeuUris/recipes-catalog/pkg/schemaorg"
+)
+
+func TestFormatDuration(t *testing.T) {
+	tests := []struct {
+		duration time.Duration
+		expected string
+	}{
+		{0, ""},
+		{30 * time.Second, ""},
+		{45 * time.Minute, "PT45M"},
+		{2 * time.Hour, "PT2H"},
+		{90 * time.Minute, "PT1H30M"},
+		{26 * time.Hour, "PT26H"},
+	}
+	for _, test := range tests {
+		if got := schemaorg.FormatDuration(test.duration); got != test.expected {
+			t.Errorf("expected %v formatted as %q, got %q", test.duration, test.expected, got)
+		}
+	}
+}
+
+func TestRecipe_FromEntity(t *testing.T) {
+	recipe := &entity.Recipe{
+		ID:          1,
+		Name:        "Soup",
+		Description: "Warm",
+		Ingredients: []*entity.Ingredient{{ID: 1, Name: "Salt"}, {ID: 2, Name: "Water"}},
+		Steps:       []string{"Boil", "Serve"},
+		PrepTime:    10 * time.Minute,
+		CookTime:    time.Hour,
+		Yield:       "4 servings",
+		Calories:    120,
+	}
+	document := &schemaorg.Recipe{}
+	document.FromEntity(recipe)
+
+	raw, err := json.Marshal(document)
+	if err != nil {
+		t.Fatalf("failed to encode: %v", err)
+	}
+	expected := `{"@context":"https://schema.org","@type":"Recipe","name":"Soup","description":"Warm",` +
+		`"recipeIngredient":["Salt","Water"],"recipeInstructions":[` +
+		`{"@type":"HowToStep","position":1,"text":"Boil"},{"@type":"HowToStep","position":2,"text":"Serve"}],` +
+		`"prepTime":"PT10M","cookTime":"PT1H","totalTime":"PT1H10M","recipeYield":"4 servings",` +
+		`"nutrition":{"@type":"NutritionInformation","calories":"120 calories"}}`
+	if string(raw) != expected {
+		t.Fatalf("expected\n%s\ngot\n%s", expected, raw)
+	}
+
+	// Unknown details are omitted, the graph holds the context
+	graph := schemaorg.NewGraph([]*entity.Recipe{{Name: "Salad"}})
+	raw, err = json.Marshal(graph)
+	if err != nil {
+		t.Fatalf("failed to encode: %v", err)
+	}
+	expected = `{"@context":"https://schema.org","@graph":[{"@type":"Recipe","name":"Salad","recipeIngredient":[],"recipeInstructions":[]}]}`
+	if string(raw) != expected {
+		t.Fatalf("expected\n%s\ngot\n%s", expected, raw)
+	}
+}
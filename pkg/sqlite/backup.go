@@ -0,0 +1,356 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+// backupTimeFormat stamps the backup files, it sorts like the times
+const backupTimeFormat = "20060102T150405.000Z"
+
+// ErrInvalidBackup is returned when a backup fails the checks before restoring it
+var ErrInvalidBackup = errors.New("invalid backup")
+
+// ErrDatabaseInUse is returned when restoring a database another connection
+// has open
+var ErrDatabaseInUse = errors.New("database in use")
+
+// Backup is a backup file of a database
+type Backup struct {
+	Name      string
+	Path      string
+	Size      int64
+	CreatedAt time.Time
+}
+
+// Retention decides which backups are removed after taking one, the newest is
+// always kept
+type Retention struct {
+	// Keep is the number of backups kept, zero for no limit
+	Keep int
+	// MaxAge removes the backups taken longer ago, zero for no limit
+	MaxAge time.Duration
+}
+
+// Backups takes online backups of a database into a directory, named after
+// the database file and the time they were taken
+type Backups struct {
+	Retention
+	db     *sql.DB
+	dir    string
+	prefix string
+	// mu serializes the backups and their rotation
+	mu sync.Mutex
+}
+
+// NewBackups returns the backups of the database at path, kept in dir
+func NewBackups(db *sql.DB, path, dir string, retention Retention) *Backups {
+	prefix := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	return &Backups{Retention: retention, db: db, dir: dir, prefix: prefix}
+}
+
+// Create takes a consistent backup while the database is in use, with the
+// SQLite backup API, and removes the backups out of the retention policy
+func (b *Backups) Create(ctx context.Context) (*Backup, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if err := os.MkdirAll(b.dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	// Truncated to the precision of the file name, like the listed backups
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	path := filepath.Join(b.dir, b.prefix+"-"+now.Format(backupTimeFormat)+".sqlite")
+	// The backup is written to a temporary file so a failure leaves no
+	// partial backup behind
+	tmp := path + ".tmp"
+	if err := backupTo(ctx, b.db, tmp); err != nil {
+		os.Remove(tmp)
+		return nil, fmt.Errorf("failed to back up database: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return nil, err
+	}
+
+	if _, err := b.rotate(now); err != nil {
+		return nil, fmt.Errorf("failed to rotate backups: %w", err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	return &Backup{Name: filepath.Base(path), Path: path, Size: info.Size(), CreatedAt: now}, nil
+}
+
+// List returns the backups, newest first
+func (b *Backups) List() ([]*Backup, error) {
+	entries, err := os.ReadDir(b.dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return []*Backup{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	backups := []*Backup{}
+	for _, entry := range entries {
+		stamp, ok := strings.CutPrefix(entry.Name(), b.prefix+"-")
+		if !ok || entry.IsDir() {
+			continue
+		}
+		stamp, ok = strings.CutSuffix(stamp, ".sqlite")
+		if !ok {
+			continue
+		}
+		createdAt, err := time.Parse(backupTimeFormat, stamp)
+		if err != nil {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return nil, err
+		}
+		backups = append(backups, &Backup{
+			Name:      entry.Name(),
+			Path:      filepath.Join(b.dir, entry.Name()),
+			Size:      info.Size(),
+			CreatedAt: createdAt,
+		})
+	}
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[i].CreatedAt.After(backups[j].CreatedAt)
+	})
+	return backups, nil
+}
+
+// Rotate removes the backups out of the retention policy at the given time
+// and returns them
+func (b *Backups) Rotate(now time.Time) ([]*Backup, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.rotate(now)
+}
+
+func (b *Backups) rotate(now time.Time) ([]*Backup, error) {
+	backups, err := b.List()
+	if err != nil {
+		return nil, err
+	}
+
+	removed := []*Backup{}
+	for i, backup := range backups {
+		tooMany := b.Keep > 0 && i >= b.Keep
+		tooOld := b.MaxAge > 0 && now.Sub(backup.CreatedAt) > b.MaxAge
+		if i == 0 || (!tooMany && !tooOld) {
+			continue
+		}
+		if err := os.Remove(backup.Path); err != nil {
+			return removed, err
+		}
+		removed = append(removed, backup)
+	}
+	return removed, nil
+}
+
+// backupTo copies the database to the file in one step, which in WAL mode
+// doesn't block the writers
+func backupTo(ctx context.Context, db *sql.DB, path string) error {
+	dest, err := sql.Open(DriverName, path)
+	if err != nil {
+		return err
+	}
+	defer dest.Close()
+
+	destConn, err := dest.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer destConn.Close()
+	srcConn, err := db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer srcConn.Close()
+
+	return destConn.Raw(func(destDriverConn interface{}) error {
+		return srcConn.Raw(func(srcDriverConn interface{}) error {
+			destSQLite, ok := destDriverConn.(*sqlite3.SQLiteConn)
+			if !ok {
+				return fmt.Errorf("unexpected connection %T", destDriverConn)
+			}
+			srcSQLite, ok := srcDriverConn.(*sqlite3.SQLiteConn)
+			if !ok {
+				return fmt.Errorf("unexpected connection %T", srcDriverConn)
+			}
+
+			backup, err := destSQLite.Backup("main", srcSQLite, "main")
+			if err != nil {
+				return err
+			}
+			if _, err := backup.Step(-1); err != nil {
+				backup.Close()
+				return err
+			}
+			return backup.Finish()
+		})
+	})
+}
+
+// VerifyBackup checks the backup is an intact database migrated by the store,
+// not ahead of it nor with edited migrations
+func VerifyBackup(ctx context.Context, path string, store *MigrationStore) (*Status, error) {
+	if _, err := os.Stat(path); err != nil {
+		return nil, err
+	}
+	db, err := sql.Open(DriverName, "file:"+path+"?mode=ro")
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	var integrity string
+	if err := db.QueryRowContext(ctx, `PRAGMA integrity_check`).Scan(&integrity); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidBackup, err)
+	}
+	if integrity != "ok" {
+		return nil, fmt.Errorf("%w: integrity check failed: %s", ErrInvalidBackup, integrity)
+	}
+
+	status, err := store.Status(ctx, db)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidBackup, err)
+	}
+	if status.Version == 0 {
+		return nil, fmt.Errorf("%w: database is not migrated", ErrInvalidBackup)
+	}
+	if err := status.Check(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidBackup, err)
+	}
+	return status, nil
+}
+
+// Restored is the outcome of a restore
+type Restored struct {
+	// Status is the migration status of the restored database
+	Status *Status
+	// Replaced is where the replaced database was moved, empty when there
+	// was no database
+	Replaced string
+}
+
+// Restore replaces the database at path with the verified backup. The server
+// must be stopped, a database still open by another connection is rejected
+// with ErrDatabaseInUse. The replaced database is kept next to it with the
+// .before-restore suffix and the time of the restore, so restoring twice
+// keeps both.
+func Restore(ctx context.Context, backupPath, path string, store *MigrationStore) (*Restored, error) {
+	status, err := VerifyBackup(ctx, backupPath, store)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkNotInUse(ctx, path); err != nil {
+		return nil, err
+	}
+
+	// Copy the backup next to the database so the swap is a rename
+	restoring := path + ".restoring"
+	if err := copyFile(backupPath, restoring); err != nil {
+		os.Remove(restoring)
+		return nil, err
+	}
+
+	// The WAL of the database holds its latest writes, it is kept along. A
+	// failed rename moves back the files already moved.
+	replaced := path + ".before-restore-" + time.Now().UTC().Format(backupTimeFormat)
+	var moved []string
+	rollback := func() {
+		for i := len(moved) - 1; i >= 0; i-- {
+			os.Rename(replaced+moved[i], path+moved[i])
+		}
+		os.Remove(restoring)
+	}
+	for _, suffix := range []string{"", "-wal", "-shm"} {
+		err := os.Rename(path+suffix, replaced+suffix)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			rollback()
+			return nil, err
+		}
+		moved = append(moved, suffix)
+	}
+	if err := os.Rename(restoring, path); err != nil {
+		rollback()
+		return nil, err
+	}
+
+	restored := &Restored{Status: status}
+	if len(moved) > 0 && moved[0] == "" {
+		restored.Replaced = replaced
+	}
+	return restored, nil
+}
+
+// checkNotInUse takes an exclusive lock on the database, which fails while
+// another connection in WAL mode, like the server's, has it open
+func checkNotInUse(ctx context.Context, path string) error {
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	db, err := sql.Open(DriverName, "file:"+path+"?mode=rw&_locking_mode=EXCLUSIVE&_busy_timeout=0")
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	conn, err := db.Conn(ctx)
+	if err == nil {
+		defer conn.Close()
+		if _, err = conn.ExecContext(ctx, `BEGIN EXCLUSIVE`); err == nil {
+			_, err = conn.ExecContext(ctx, `ROLLBACK`)
+		}
+	}
+	var sqliteErr sqlite3.Error
+	if errors.As(err, &sqliteErr) && (sqliteErr.Code == sqlite3.ErrBusy || sqliteErr.Code == sqlite3.ErrLocked) {
+		return fmt.Errorf("%w: stop the server before restoring", ErrDatabaseInUse)
+	}
+	// A database that fails to open for other reasons is replaced all the
+	// same, it may be the reason to restore
+	return nil
+}
+
+func copyFile(src, dest string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.Create(dest)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Sync(); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,287 @@
+package sqlite_test
+
+import (
+	"database/sql"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/TomeuUris/recipes-catalog/pkg/sqlite"
+)
+
+func openFileDB(t *testing.T, path string) *sql.DB {
+	t.Helper()
+	db, err := sqlite.Open(path)
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func countFruits(t *testing.T, db *sql.DB) int {
+	t.Helper()
+	var count int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM fruits`).Scan(&count); err != nil {
+		t.Fatalf("failed to count fruits: %v", err)
+	}
+	return count
+}
+
+func TestBackups_CreateWhileWriting(t *testing.T) {
+	store := loadStore(t, testMigrations())
+	dir := t.TempDir()
+	path := filepath.Join(dir, "catalog.sqlite")
+	db := openFileDB(t, path)
+	if err := store.Apply(ctx, db); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+
+	// Keep writing while the backup is taken
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			if _, err := db.Exec(`INSERT INTO fruits (name) VALUES ('apple')`); err != nil {
+				t.Errorf("failed to write: %v", err)
+				return
+			}
+		}
+	}()
+
+	backups := sqlite.NewBackups(db, path, filepath.Join(dir, "backups"), sqlite.Retention{})
+	backup, err := backups.Create(ctx)
+	close(stop)
+	wg.Wait()
+	if err != nil {
+		t.Fatalf("failed to create backup: %v", err)
+	}
+	if backup.Name != filepath.Base(backup.Path) || backup.Size == 0 {
+		t.Fatalf("unexpected backup %+v", backup)
+	}
+
+	status, err := sqlite.VerifyBackup(ctx, backup.Path, store)
+	if err != nil {
+		t.Fatalf("expected a valid backup, got %v", err)
+	}
+	if status.Version != 3 {
+		t.Fatalf("expected the backup at version 3, got %d", status.Version)
+	}
+	copied := openFileDB(t, backup.Path)
+	if count := countFruits(t, copied); count > countFruits(t, db) {
+		t.Fatalf("expected at most the fruits of the database, got %d", count)
+	}
+
+	listed, err := backups.List()
+	if err != nil {
+		t.Fatalf("failed to list backups: %v", err)
+	}
+	if len(listed) != 1 || listed[0].Name != backup.Name {
+		t.Fatalf("expected the backup to be listed, got %+v", listed)
+	}
+}
+
+func TestBackups_Rotate(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+	ages := []time.Duration{0, time.Hour, 2 * time.Hour, 48 * time.Hour}
+	for _, age := range ages {
+		name := "catalog-" + now.Add(-age).Format("20060102T150405.000Z") + ".sqlite"
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0o644); err != nil {
+			t.Fatalf("failed to write backup: %v", err)
+		}
+	}
+	// Other files are left alone
+	for _, name := range []string{"catalog.sqlite", "other-20240310T120000.000Z.sqlite", "catalog-latest.sqlite"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0o644); err != nil {
+			t.Fatalf("failed to write file: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name      string
+		retention sqlite.Retention
+		at        time.Time
+		kept      int
+	}{
+		{"no limit", sqlite.Retention{}, now, 4},
+		{"max age", sqlite.Retention{MaxAge: 24 * time.Hour}, now, 3},
+		{"keep", sqlite.Retention{Keep: 2}, now, 2},
+		{"newest is kept", sqlite.Retention{MaxAge: time.Minute}, now.Add(time.Hour), 1},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			backups := sqlite.NewBackups(nil, "catalog.sqlite", dir, test.retention)
+			if _, err := backups.Rotate(test.at); err != nil {
+				t.Fatalf("failed to rotate: %v", err)
+			}
+			listed, err := backups.List()
+			if err != nil {
+				t.Fatalf("failed to list backups: %v", err)
+			}
+			if len(listed) != test.kept {
+				t.Fatalf("expected %d backups, got %d", test.kept, len(listed))
+			}
+			if !listed[0].CreatedAt.Equal(now) {
+				t.Fatalf("expected the newest backup first, got %v", listed[0].CreatedAt)
+			}
+		})
+	}
+
+	if entries, _ := os.ReadDir(dir); len(entries) != 4 {
+		t.Fatalf("expected the other files to be kept, got %d files", len(entries))
+	}
+}
+
+func TestRestore(t *testing.T) {
+	store := loadStore(t, testMigrations())
+	dir := t.TempDir()
+
+	// A backup with one fruit
+	backupPath := filepath.Join(dir, "backup.sqlite")
+	backupDB := openFileDB(t, backupPath)
+	if err := store.Apply(ctx, backupDB); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+	if _, err := backupDB.Exec(`INSERT INTO fruits (name) VALUES ('apple')`); err != nil {
+		t.Fatalf("failed to write: %v", err)
+	}
+	backupDB.Close()
+
+	path := filepath.Join(dir, "catalog.sqlite")
+	if err := os.WriteFile(path, []byte("current"), 0o644); err != nil {
+		t.Fatalf("failed to write database: %v", err)
+	}
+	restored, err := sqlite.Restore(ctx, backupPath, path, store)
+	if err != nil {
+		t.Fatalf("failed to restore: %v", err)
+	}
+	if restored.Status.Version != 3 {
+		t.Fatalf("expected version 3, got %d", restored.Status.Version)
+	}
+	if count := countFruits(t, openFileDB(t, path)); count != 1 {
+		t.Fatalf("expected the restored fruit, got %d", count)
+	}
+	if data, err := os.ReadFile(restored.Replaced); err != nil || string(data) != "current" {
+		t.Fatalf("expected the replaced database to be kept, got %q, %v", data, err)
+	}
+}
+
+func TestRestore_KeepsEveryReplacedDatabase(t *testing.T) {
+	store := loadStore(t, testMigrations())
+	dir := t.TempDir()
+
+	backupPath := filepath.Join(dir, "backup.sqlite")
+	backupDB := openFileDB(t, backupPath)
+	if err := store.Apply(ctx, backupDB); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+	backupDB.Close()
+
+	path := filepath.Join(dir, "catalog.sqlite")
+	if err := os.WriteFile(path, []byte("current"), 0o644); err != nil {
+		t.Fatalf("failed to write database: %v", err)
+	}
+	first, err := sqlite.Restore(ctx, backupPath, path, store)
+	if err != nil {
+		t.Fatalf("failed to restore: %v", err)
+	}
+	// The backup file names are stamped to the millisecond
+	time.Sleep(2 * time.Millisecond)
+	second, err := sqlite.Restore(ctx, backupPath, path, store)
+	if err != nil {
+		t.Fatalf("failed to restore again: %v", err)
+	}
+	if first.Replaced == second.Replaced {
+		t.Fatalf("expected the second restore to keep the first replaced database, both are %s", first.Replaced)
+	}
+	if data, err := os.ReadFile(first.Replaced); err != nil || string(data) != "current" {
+		t.Fatalf("expected the first replaced database to be kept, got %q, %v", data, err)
+	}
+	if _, err := os.Stat(second.Replaced); err != nil {
+		t.Fatalf("expected the second replaced database to be kept: %v", err)
+	}
+}
+
+func TestRestore_RejectsDatabaseInUse(t *testing.T) {
+	store := loadStore(t, testMigrations())
+	dir := t.TempDir()
+
+	backupPath := filepath.Join(dir, "backup.sqlite")
+	backupDB := openFileDB(t, backupPath)
+	if err := store.Apply(ctx, backupDB); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+	backupDB.Close()
+
+	// The server keeps the database open
+	path := filepath.Join(dir, "catalog.sqlite")
+	db := openFileDB(t, path)
+	if err := store.Apply(ctx, db); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+	if _, err := db.Exec(`INSERT INTO fruits (name) VALUES ('apple')`); err != nil {
+		t.Fatalf("failed to write: %v", err)
+	}
+
+	if _, err := sqlite.Restore(ctx, backupPath, path, store); !errors.Is(err, sqlite.ErrDatabaseInUse) {
+		t.Fatalf("expected the database in use, got %v", err)
+	}
+	if count := countFruits(t, db); count != 1 {
+		t.Fatalf("expected the database to be kept, got %d fruits", count)
+	}
+
+	db.Close()
+	if _, err := sqlite.Restore(ctx, backupPath, path, store); err != nil {
+		t.Fatalf("failed to restore once closed: %v", err)
+	}
+}
+
+func TestRestore_RejectsInvalidBackups(t *testing.T) {
+	store := loadStore(t, testMigrations())
+	dir := t.TempDir()
+
+	corrupt := filepath.Join(dir, "corrupt.sqlite")
+	if err := os.WriteFile(corrupt, []byte("not a database"), 0o644); err != nil {
+		t.Fatalf("failed to write backup: %v", err)
+	}
+
+	empty := filepath.Join(dir, "empty.sqlite")
+	openFileDB(t, empty).Exec(`CREATE TABLE fruits (name TEXT)`)
+
+	ahead := filepath.Join(dir, "ahead.sqlite")
+	aheadDB := openFileDB(t, ahead)
+	if err := store.Apply(ctx, aheadDB); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+	aheadDB.Exec(`PRAGMA user_version = 4`)
+
+	path := filepath.Join(dir, "catalog.sqlite")
+	if err := os.WriteFile(path, []byte("current"), 0o644); err != nil {
+		t.Fatalf("failed to write database: %v", err)
+	}
+	for _, backup := range []string{corrupt, empty, ahead} {
+		t.Run(filepath.Base(backup), func(t *testing.T) {
+			if _, err := sqlite.Restore(ctx, backup, path, store); !errors.Is(err, sqlite.ErrInvalidBackup) {
+				t.Fatalf("expected an invalid backup, got %v", err)
+			}
+			if data, err := os.ReadFile(path); err != nil || string(data) != "current" {
+				t.Fatalf("expected the database to be kept, got %q, %v", data, err)
+			}
+		})
+	}
+
+	if _, err := sqlite.Restore(ctx, filepath.Join(dir, "missing.sqlite"), path, store); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("expected a missing backup, got %v", err)
+	}
+}
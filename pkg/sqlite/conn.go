@@ -0,0 +1,110 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// Conn runs queries on a database or on one of its transactions. Repositories
+// built on a transaction join it: the transactions they begin are savepoints,
+// like gorm nests them.
+type Conn struct {
+	db DB
+	tx *sql.Tx
+	// savepoints counts the savepoints of the transaction to name them
+	savepoints *int
+}
+
+// NewConn returns a connection running every query on the database
+func NewConn(db DB) *Conn {
+	return &Conn{db: db}
+}
+
+// InTx returns whether the connection runs on a transaction
+func (c *Conn) InTx() bool {
+	return c.tx != nil
+}
+
+func (c *Conn) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	if c.tx != nil {
+		return c.tx.ExecContext(ctx, query, args...)
+	}
+	return c.db.ExecContext(ctx, query, args...)
+}
+
+func (c *Conn) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	if c.tx != nil {
+		return c.tx.QueryContext(ctx, query, args...)
+	}
+	return c.db.QueryContext(ctx, query, args...)
+}
+
+func (c *Conn) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	if c.tx != nil {
+		return c.tx.QueryRowContext(ctx, query, args...)
+	}
+	return c.db.QueryRowContext(ctx, query, args...)
+}
+
+func (c *Conn) QueryRow(query string, args ...interface{}) *sql.Row {
+	return c.QueryRowContext(context.Background(), query, args...)
+}
+
+// BeginTx begins a transaction or, on a transaction, a savepoint of it. The
+// options only apply to transactions.
+func (c *Conn) BeginTx(ctx context.Context, opts *sql.TxOptions) (*Tx, error) {
+	if c.tx == nil {
+		tx, err := c.db.BeginTx(ctx, opts)
+		if err != nil {
+			return nil, err
+		}
+		return &Tx{Conn: &Conn{db: c.db, tx: tx, savepoints: new(int)}}, nil
+	}
+
+	*c.savepoints++
+	savepoint := fmt.Sprintf("sp%d", *c.savepoints)
+	if _, err := c.tx.ExecContext(ctx, `SAVEPOINT `+savepoint); err != nil {
+		return nil, err
+	}
+	return &Tx{Conn: c, savepoint: savepoint, ctx: ctx}, nil
+}
+
+// Tx is a transaction or a savepoint, its connection runs the queries on it
+type Tx struct {
+	*Conn
+	// savepoint is empty for transactions
+	savepoint string
+	ctx       context.Context
+	done      bool
+}
+
+// Commit commits the transaction or releases the savepoint
+func (t *Tx) Commit() error {
+	if t.done {
+		return sql.ErrTxDone
+	}
+	t.done = true
+	if t.savepoint == "" {
+		return t.tx.Commit()
+	}
+	_, err := t.tx.ExecContext(t.ctx, `RELEASE `+t.savepoint)
+	return err
+}
+
+// Rollback rolls back the transaction or to the savepoint, like sql.Tx it is
+// a no-op once committed
+func (t *Tx) Rollback() error {
+	if t.done {
+		return sql.ErrTxDone
+	}
+	t.done = true
+	if t.savepoint == "" {
+		return t.tx.Rollback()
+	}
+	if _, err := t.tx.ExecContext(t.ctx, `ROLLBACK TO `+t.savepoint); err != nil {
+		return err
+	}
+	_, err := t.tx.ExecContext(t.ctx, `RELEASE `+t.savepoint)
+	return err
+}
@@ -2,97 +2,407 @@ package sqlite
 
 import (
 	"context"
+	"crypto/sha256"
 	"database/sql"
 	"embed"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"io/fs"
 	"log"
 	"path"
 	"regexp"
 	"sort"
 	"strconv"
+	"time"
 )
 
 //go:embed migrations/*.sql
 var MigrationsFS embed.FS
 
+var (
+	// ErrSchemaAhead is returned when the database was migrated by a newer binary
+	ErrSchemaAhead = errors.New("database schema is ahead of the migrations")
+	// ErrMigrationEdited is returned when an applied migration changed since
+	ErrMigrationEdited = errors.New("applied migration was edited")
+)
+
+// historyTable records the applied migrations with their checksums, the
+// version of the database is still PRAGMA user_version
+const historyTable = "schema_migrations"
+
+// migrationFilename matches migration_0001_name.sql and its paired
+// migration_0001_name.down.sql
+var migrationFilename = regexp.MustCompile(`^migration_(\d+)_(\w+?)(\.down)?\.sql$`)
+
 type Migration struct {
 	Version int
-	Query   string
+	Name    string
+	// Query migrates up to the version, Down back to the previous one
+	Query string
+	Down  string
+}
+
+// Checksum identifies the up query, it must not change once applied
+func (m *Migration) Checksum() string {
+	sum := sha256.Sum256([]byte(m.Query))
+	return hex.EncodeToString(sum[:])
+}
+
+// Step is a migration run in one direction
+type Step struct {
+	Migration
+	Up bool
+}
+
+// SQL returns the query run by the step
+func (s *Step) SQL() string {
+	if s.Up {
+		return s.Query
+	}
+	return s.Down
+}
+
+// MigrationStatus describes a migration in a database
+type MigrationStatus struct {
+	Migration
+	Applied bool
+	// AppliedAt is zero when unknown, the migration was applied before the
+	// history was kept
+	AppliedAt time.Time
+	// Edited is set when the migration changed after being applied
+	Edited bool
+}
+
+// Status describes the migrations of a database
+type Status struct {
+	Version    int
+	Latest     int
+	Migrations []MigrationStatus
+}
+
+// Check returns an error when the database is ahead of the migrations or an
+// applied migration was edited
+func (s *Status) Check() error {
+	if s.Version > s.Latest {
+		return fmt.Errorf("%w: version %d, latest migration %d", ErrSchemaAhead, s.Version, s.Latest)
+	}
+	for _, migration := range s.Migrations {
+		if migration.Edited {
+			return fmt.Errorf("%w: %d %s", ErrMigrationEdited, migration.Version, migration.Name)
+		}
+	}
+	return nil
 }
 
 type MigrationStore struct {
 	migrations []Migration
 }
 
-func (s *MigrationStore) Load(fs embed.FS) error {
+// Load reads the migrations of the migrations directory. Every version from 1
+// must have its up and down files.
+func (s *MigrationStore) Load(fsys fs.FS) error {
 	migrationsDir := "migrations"
-	entries, err := fs.ReadDir(migrationsDir)
+	entries, err := fs.ReadDir(fsys, migrationsDir)
 	if err != nil {
 		return err
 	}
 
+	byVersion := map[int]*Migration{}
 	for _, entry := range entries {
 		if entry.IsDir() {
 			continue
 		}
 
-		version := versionFromFilename(entry.Name())
-		query, err := fs.ReadFile(path.Join(migrationsDir, entry.Name()))
+		version, name, down, err := parseFilename(entry.Name())
+		if err != nil {
+			return err
+		}
+		query, err := fs.ReadFile(fsys, path.Join(migrationsDir, entry.Name()))
 		if err != nil {
 			return err
 		}
 
-		s.migrations = append(s.migrations, Migration{
-			Version: version,
-			Query:   string(query),
-		})
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: name}
+			byVersion[version] = migration
+		} else if migration.Name != name {
+			return fmt.Errorf("migration %d is named both %s and %s", version, migration.Name, name)
+		}
+		if down {
+			migration.Down = string(query)
+		} else {
+			migration.Query = string(query)
+		}
 	}
 
-	sort.Slice(s.migrations, func(i, j int) bool {
-		return s.migrations[i].Version < s.migrations[j].Version
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
 	})
 
+	for i, migration := range migrations {
+		if migration.Version != i+1 {
+			return fmt.Errorf("missing migration %d", i+1)
+		}
+		if migration.Query == "" {
+			return fmt.Errorf("migration %d %s has no up file", migration.Version, migration.Name)
+		}
+		if migration.Down == "" {
+			return fmt.Errorf("migration %d %s has no down file", migration.Version, migration.Name)
+		}
+	}
+
+	s.migrations = migrations
 	return nil
 }
 
+// Latest returns the version of the last migration
+func (s *MigrationStore) Latest() int {
+	return len(s.migrations)
+}
+
+// Apply migrates the database up to the latest version
 func (s *MigrationStore) Apply(ctx context.Context, db *sql.DB) error {
-	var version int
-	if err := db.QueryRow(`PRAGMA user_version`).Scan(&version); err != nil {
-		return fmt.Errorf("failed to get user_version: %w", err)
-	}
+	return s.Migrate(ctx, db, s.Latest())
+}
 
-	log.Printf("Migration counter: %d/%d", version, len(s.migrations))
+// Status returns the version of the database and its applied migrations, it
+// doesn't write to the database
+func (s *MigrationStore) Status(ctx context.Context, db *sql.DB) (*Status, error) {
+	version, err := userVersion(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	history, err := readHistory(ctx, db)
+	if err != nil {
+		return nil, err
+	}
 
-	for _, migration := range s.migrations {
-		if migration.Version <= version {
-			continue
+	status := &Status{Version: version, Latest: s.Latest(), Migrations: make([]MigrationStatus, len(s.migrations))}
+	for i, migration := range s.migrations {
+		migrationStatus := MigrationStatus{Migration: migration, Applied: migration.Version <= version}
+		if record, ok := history[migration.Version]; ok && migrationStatus.Applied {
+			migrationStatus.AppliedAt = record.appliedAt
+			migrationStatus.Edited = record.checksum != migration.Checksum()
 		}
+		status.Migrations[i] = migrationStatus
+	}
+	return status, nil
+}
 
-		tx, err := db.BeginTx(ctx, nil)
-		if err != nil {
-			return fmt.Errorf("failed to create migration transaction: %w", err)
-		}
+// Plan returns the steps migrating the database to the target version
+func (s *MigrationStore) Plan(ctx context.Context, db *sql.DB, target int) ([]Step, error) {
+	if target < 0 || target > s.Latest() {
+		return nil, fmt.Errorf("invalid target version %d, expected 0 to %d", target, s.Latest())
+	}
+
+	status, err := s.Status(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	if err := status.Check(); err != nil {
+		return nil, err
+	}
+
+	var steps []Step
+	for version := status.Version + 1; version <= target; version++ {
+		steps = append(steps, Step{Migration: s.migrations[version-1], Up: true})
+	}
+	for version := status.Version; version > target; version-- {
+		steps = append(steps, Step{Migration: s.migrations[version-1], Up: false})
+	}
+	return steps, nil
+}
+
+// Migrate moves the database up or down to the target version, running every
+// migration in its own transaction
+func (s *MigrationStore) Migrate(ctx context.Context, db *sql.DB, target int) error {
+	steps, err := s.Plan(ctx, db, target)
+	if err != nil {
+		return err
+	}
+	if err := s.recordHistory(ctx, db); err != nil {
+		return fmt.Errorf("failed to record migration history: %w", err)
+	}
+
+	version, err := userVersion(ctx, db)
+	if err != nil {
+		return err
+	}
+	log.Printf("Migration counter: %d/%d", version, s.Latest())
 
-		if _, err = tx.Exec(migration.Query); err != nil {
-			return fmt.Errorf("failed to run migration: %w", err)
+	for _, step := range steps {
+		if err := runStep(ctx, db, step); err != nil {
+			direction := "down"
+			if step.Up {
+				direction = "up"
+			}
+			return fmt.Errorf("failed to run migration %d %s %s: %w", step.Version, step.Name, direction, err)
 		}
 
-		if _, err = tx.Exec(fmt.Sprintf(`PRAGMA user_version=%d`, migration.Version)); err != nil {
-			return fmt.Errorf("failed to update version: %w", err)
+		version = step.Version
+		if !step.Up {
+			version--
 		}
+		log.Printf("Migration counter: %d/%d", version, s.Latest())
+	}
+
+	return nil
+}
+
+// Baseline marks an unversioned database as migrated up to the version without
+// running the migrations, for databases whose schema was created otherwise
+func (s *MigrationStore) Baseline(ctx context.Context, db *sql.DB, version int) error {
+	if version < 1 || version > s.Latest() {
+		return fmt.Errorf("invalid baseline version %d, expected 1 to %d", version, s.Latest())
+	}
+	current, err := userVersion(ctx, db)
+	if err != nil {
+		return err
+	}
+	if current != 0 {
+		return fmt.Errorf("database is already at version %d", current)
+	}
 
-		if err = tx.Commit(); err != nil {
-			return fmt.Errorf("failed to commit migration: %w", err)
+	if _, err := db.ExecContext(ctx, fmt.Sprintf(`PRAGMA user_version=%d`, version)); err != nil {
+		return fmt.Errorf("failed to update version: %w", err)
+	}
+	if err := s.recordHistory(ctx, db); err != nil {
+		return fmt.Errorf("failed to record migration history: %w", err)
+	}
+	log.Printf("Migration counter: %d/%d (baseline)", version, s.Latest())
+	return nil
+}
+
+// recordHistory creates the history table, the migrations applied before it
+// existed are trusted and recorded with their current checksum
+func (s *MigrationStore) recordHistory(ctx context.Context, db *sql.DB) error {
+	version, err := userVersion(ctx, db)
+	if err != nil {
+		return err
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS `+historyTable+` (
+		version    INTEGER PRIMARY KEY,
+		name       TEXT NOT NULL,
+		checksum   TEXT NOT NULL,
+		applied_at TIMESTAMP
+	)`); err != nil {
+		return err
+	}
+	for _, migration := range s.migrations {
+		if migration.Version > version {
+			break
+		}
+		if _, err := tx.ExecContext(ctx, `INSERT OR IGNORE INTO `+historyTable+` (version, name, checksum) VALUES (?, ?, ?)`,
+			migration.Version, migration.Name, migration.Checksum()); err != nil {
+			return err
 		}
+	}
+	return tx.Commit()
+}
+
+// runStep runs the step and updates the version in one transaction, which is
+// rolled back on failure
+func runStep(ctx context.Context, db *sql.DB, step Step) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create migration transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, step.SQL()); err != nil {
+		return err
+	}
 
-		log.Printf("Migration counter: %d/%d", migration.Version, len(s.migrations))
+	version := step.Version
+	if step.Up {
+		_, err = tx.ExecContext(ctx, `INSERT OR REPLACE INTO `+historyTable+` (version, name, checksum, applied_at) VALUES (?, ?, ?, ?)`,
+			step.Version, step.Name, step.Checksum(), time.Now().UTC())
+	} else {
+		version--
+		_, err = tx.ExecContext(ctx, `DELETE FROM `+historyTable+` WHERE version = ?`, step.Version)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update history: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf(`PRAGMA user_version=%d`, version)); err != nil {
+		return fmt.Errorf("failed to update version: %w", err)
 	}
 
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit migration: %w", err)
+	}
 	return nil
 }
 
-func versionFromFilename(filename string) int {
-	versionStr := regexp.MustCompile(`migration_(\d+)_.*\.sql`).FindStringSubmatch(filename)
-	version, _ := strconv.ParseInt(versionStr[1], 10, 32)
-	return int(version)
+type historyRecord struct {
+	checksum  string
+	appliedAt time.Time
+}
+
+// readHistory returns the recorded migrations by version, none when the table
+// doesn't exist yet
+func readHistory(ctx context.Context, db *sql.DB) (map[int]historyRecord, error) {
+	var exists bool
+	if err := db.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM sqlite_master WHERE type = 'table' AND name = ?)`,
+		historyTable).Scan(&exists); err != nil {
+		return nil, err
+	}
+	history := map[int]historyRecord{}
+	if !exists {
+		return history, nil
+	}
+
+	rows, err := db.QueryContext(ctx, `SELECT version, checksum, applied_at FROM `+historyTable)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var version int
+		var record historyRecord
+		var appliedAt sql.NullTime
+		if err := rows.Scan(&version, &record.checksum, &appliedAt); err != nil {
+			return nil, err
+		}
+		record.appliedAt = appliedAt.Time
+		history[version] = record
+	}
+	return history, rows.Err()
+}
+
+func userVersion(ctx context.Context, db *sql.DB) (int, error) {
+	var version int
+	if err := db.QueryRowContext(ctx, `PRAGMA user_version`).Scan(&version); err != nil {
+		return 0, fmt.Errorf("failed to get user_version: %w", err)
+	}
+	return version, nil
+}
+
+// parseFilename returns the version and name of a migration file, and whether
+// it is a down migration
+func parseFilename(filename string) (version int, name string, down bool, err error) {
+	match := migrationFilename.FindStringSubmatch(filename)
+	if match == nil {
+		return 0, "", false, fmt.Errorf("invalid migration filename %q, expected migration_0001_name.sql or migration_0001_name.down.sql", filename)
+	}
+	version, err = strconv.Atoi(match[1])
+	if err != nil || version == 0 {
+		return 0, "", false, fmt.Errorf("invalid version in migration filename %q", filename)
+	}
+	return version, match[2], match[3] != "", nil
 }
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS recipeIngredients;
+DROP TABLE IF EXISTS recipeSteps;
+DROP TABLE IF EXISTS recipes;
@@ -0,0 +1 @@
+ALTER TABLE recipes ADD COLUMN description TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,4 @@
+CREATE TABLE IF NOT EXISTS cookingUnits (
+	id   INTEGER PRIMARY KEY AUTOINCREMENT,
+	name TEXT NOT NULL
+);
@@ -0,0 +1,5 @@
+DROP INDEX idx_cooking_units_name_key;
+ALTER TABLE cookingUnits DROP COLUMN nameKey;
+
+DROP INDEX idx_ingredients_name_key;
+ALTER TABLE ingredients DROP COLUMN nameKey;
@@ -0,0 +1,11 @@
+-- Names are unique ignoring case and accents, ingredients per type. Duplicates
+-- created before keep a NULL key, which doesn't collide, until they are merged.
+ALTER TABLE ingredients ADD COLUMN nameKey TEXT;
+UPDATE ingredients SET nameKey = normalize_name(name)
+WHERE id IN (SELECT MIN(id) FROM ingredients GROUP BY normalize_name(name), type);
+CREATE UNIQUE INDEX idx_ingredients_name_key ON ingredients(nameKey, type);
+
+ALTER TABLE cookingUnits ADD COLUMN nameKey TEXT;
+UPDATE cookingUnits SET nameKey = normalize_name(name)
+WHERE id IN (SELECT MIN(id) FROM cookingUnits GROUP BY normalize_name(name));
+CREATE UNIQUE INDEX idx_cooking_units_name_key ON cookingUnits(nameKey);
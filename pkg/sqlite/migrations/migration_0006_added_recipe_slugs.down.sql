@@ -0,0 +1,2 @@
+DROP TABLE recipeSlugs;
+ALTER TABLE recipes DROP COLUMN slug;
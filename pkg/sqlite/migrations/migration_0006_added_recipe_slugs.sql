@@ -0,0 +1,25 @@
+-- Recipes are found by slug. Every slug given to a recipe is kept, so links to
+-- its previous names keep finding it.
+ALTER TABLE recipes ADD COLUMN slug TEXT;
+
+CREATE TABLE IF NOT EXISTS recipeSlugs (
+	id       INTEGER PRIMARY KEY AUTOINCREMENT,
+	slug     TEXT NOT NULL UNIQUE,
+	recipeId INTEGER NOT NULL,
+
+	FOREIGN KEY(recipeId) REFERENCES recipes(id) ON DELETE CASCADE
+);
+CREATE INDEX idx_recipe_slugs_recipe_id ON recipeSlugs(recipeId);
+
+-- The first recipe of a name gets its slug, the others are told apart by ID.
+-- Slugify never returns two hyphens in a row, they are the last resort.
+UPDATE recipes SET slug = COALESCE(NULLIF(slugify(name), ''), 'recipe')
+WHERE id IN (SELECT MIN(id) FROM recipes GROUP BY COALESCE(NULLIF(slugify(name), ''), 'recipe'));
+UPDATE recipes SET slug = COALESCE(NULLIF(slugify(name), ''), 'recipe') || '-' || id
+WHERE slug IS NULL AND NOT EXISTS (
+	SELECT 1 FROM recipes AS taken
+	WHERE taken.slug = COALESCE(NULLIF(slugify(recipes.name), ''), 'recipe') || '-' || recipes.id
+);
+UPDATE recipes SET slug = COALESCE(NULLIF(slugify(name), ''), 'recipe') || '--' || id WHERE slug IS NULL;
+
+INSERT INTO recipeSlugs (slug, recipeId) SELECT slug, id FROM recipes;
@@ -0,0 +1,15 @@
+-- Domain events are added in the transaction of their write and delivered
+-- from here
+CREATE TABLE IF NOT EXISTS outboxMessages (
+	id            INTEGER PRIMARY KEY AUTOINCREMENT,
+	entity        TEXT NOT NULL,
+	entityId      INTEGER NOT NULL,
+	action        TEXT NOT NULL,
+	payload       TEXT NOT NULL,
+	occurredAt    DATETIME NOT NULL,
+	attempts      INTEGER NOT NULL DEFAULT 0,
+	lastError     TEXT NOT NULL DEFAULT '',
+	nextAttemptAt DATETIME NOT NULL,
+	deliveredAt   DATETIME
+);
+CREATE INDEX idx_outbox_messages_delivered_at ON outboxMessages(deliveredAt);
@@ -0,0 +1,4 @@
+ALTER TABLE recipes DROP COLUMN calories;
+ALTER TABLE recipes DROP COLUMN yield;
+ALTER TABLE recipes DROP COLUMN cookMinutes;
+ALTER TABLE recipes DROP COLUMN prepMinutes;
@@ -0,0 +1,5 @@
+-- Times, yield and nutrition published in the schema.org markup of recipes
+ALTER TABLE recipes ADD COLUMN prepMinutes INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE recipes ADD COLUMN cookMinutes INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE recipes ADD COLUMN yield TEXT NOT NULL DEFAULT '';
+ALTER TABLE recipes ADD COLUMN calories INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,3 @@
+DROP TABLE audit_entries;
+DROP TABLE webhook_deliveries;
+DROP TABLE webhooks;
@@ -0,0 +1,47 @@
+-- Webhooks and the audit log, with the table layout of their gorm
+-- repositories. Databases where AutoMigrate created them keep them as they are.
+CREATE TABLE IF NOT EXISTS `webhooks` (
+	`id`         integer PRIMARY KEY AUTOINCREMENT,
+	`created_at` datetime,
+	`updated_at` datetime,
+	`deleted_at` datetime,
+	`url`        text,
+	`secret`     text,
+	`events`     text,
+	`active`     numeric
+);
+CREATE INDEX IF NOT EXISTS `idx_webhooks_deleted_at` ON `webhooks`(`deleted_at`);
+
+CREATE TABLE IF NOT EXISTS `webhook_deliveries` (
+	`id`              integer PRIMARY KEY AUTOINCREMENT,
+	`created_at`      datetime,
+	`updated_at`      datetime,
+	`deleted_at`      datetime,
+	`webhook_id`      integer,
+	`event_id`        integer,
+	`event_type`      text,
+	`payload`         text,
+	`status`          text,
+	`next_attempt_at` datetime,
+	`attempts`        integer,
+	`response_status` integer,
+	`last_error`      text,
+	`delivered_at`    datetime
+);
+CREATE INDEX IF NOT EXISTS `idx_webhook_deliveries_webhook_id` ON `webhook_deliveries`(`webhook_id`);
+CREATE INDEX IF NOT EXISTS `idx_webhook_deliveries_deleted_at` ON `webhook_deliveries`(`deleted_at`);
+CREATE INDEX IF NOT EXISTS `idx_delivery_due` ON `webhook_deliveries`(`status`, `next_attempt_at`);
+
+CREATE TABLE IF NOT EXISTS `audit_entries` (
+	`id`         integer PRIMARY KEY AUTOINCREMENT,
+	`entity`     text,
+	`entity_id`  integer,
+	`action`     text,
+	`actor`      text,
+	`request_id` text,
+	`before`     text,
+	`after`      text,
+	`at`         datetime
+);
+CREATE INDEX IF NOT EXISTS `idx_audit_entries_at` ON `audit_entries`(`at`);
+CREATE INDEX IF NOT EXISTS `idx_audit_entity` ON `audit_entries`(`entity`, `entity_id`);
@@ -0,0 +1 @@
+DROP INDEX `idx_delivery_event`;
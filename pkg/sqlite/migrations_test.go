@@ -0,0 +1,246 @@
+package sqlite_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"testing/fstest"
+
+	"github.com/TomeuUris/recipes-catalog/pkg/sqlite"
+)
+
+var ctx = context.Background()
+
+func openDB(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := sqlite.Open(":memory:")
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func loadStore(t *testing.T, files fstest.MapFS) *sqlite.MigrationStore {
+	t.Helper()
+	var store sqlite.MigrationStore
+	if err := store.Load(files); err != nil {
+		t.Fatalf("failed to load migrations: %v", err)
+	}
+	return &store
+}
+
+func file(query string) *fstest.MapFile {
+	return &fstest.MapFile{Data: []byte(query)}
+}
+
+func testMigrations() fstest.MapFS {
+	return fstest.MapFS{
+		"migrations/migration_0001_fruits.sql":            file(`CREATE TABLE fruits (name TEXT);`),
+		"migrations/migration_0001_fruits.down.sql":       file(`DROP TABLE fruits;`),
+		"migrations/migration_0002_vegetables.sql":        file(`CREATE TABLE vegetables (name TEXT);`),
+		"migrations/migration_0002_vegetables.down.sql":   file(`DROP TABLE vegetables;`),
+		"migrations/migration_0003_fruit_colour.sql":      file(`ALTER TABLE fruits ADD COLUMN colour TEXT;`),
+		"migrations/migration_0003_fruit_colour.down.sql": file(`ALTER TABLE fruits DROP COLUMN colour;`),
+	}
+}
+
+func expectVersion(t *testing.T, store *sqlite.MigrationStore, db *sql.DB, expected int) *sqlite.Status {
+	t.Helper()
+	status, err := store.Status(ctx, db)
+	if err != nil {
+		t.Fatalf("failed to get status: %v", err)
+	}
+	if status.Version != expected {
+		t.Fatalf("expected version %d, got %d", expected, status.Version)
+	}
+	for _, migration := range status.Migrations {
+		if migration.Applied != (migration.Version <= expected) {
+			t.Fatalf("expected migration %d to be applied only up to %d, got %+v", migration.Version, expected, migration)
+		}
+	}
+	return status
+}
+
+func hasTable(t *testing.T, db *sql.DB, table string) bool {
+	t.Helper()
+	var exists bool
+	if err := db.QueryRow(`SELECT EXISTS (SELECT 1 FROM sqlite_master WHERE type = 'table' AND name = ?)`, table).
+		Scan(&exists); err != nil {
+		t.Fatalf("failed to check table: %v", err)
+	}
+	return exists
+}
+
+func TestMigrationStore_UpAndDown(t *testing.T) {
+	store := loadStore(t, testMigrations())
+	db := openDB(t)
+
+	if err := store.Apply(ctx, db); err != nil {
+		t.Fatalf("failed to apply migrations: %v", err)
+	}
+	status := expectVersion(t, store, db, 3)
+	if status.Migrations[2].AppliedAt.IsZero() {
+		t.Fatalf("expected the time migrations are applied to be recorded")
+	}
+
+	if err := store.Migrate(ctx, db, 1); err != nil {
+		t.Fatalf("failed to migrate down: %v", err)
+	}
+	expectVersion(t, store, db, 1)
+	if hasTable(t, db, "vegetables") {
+		t.Fatalf("expected migration 2 to be rolled back")
+	}
+
+	if err := store.Migrate(ctx, db, 0); err != nil {
+		t.Fatalf("failed to migrate down: %v", err)
+	}
+	expectVersion(t, store, db, 0)
+
+	if err := store.Migrate(ctx, db, 2); err != nil {
+		t.Fatalf("failed to migrate up: %v", err)
+	}
+	expectVersion(t, store, db, 2)
+	if !hasTable(t, db, "vegetables") {
+		t.Fatalf("expected migration 2 to be applied")
+	}
+
+	if err := store.Migrate(ctx, db, 4); err == nil {
+		t.Fatalf("expected migrating to a missing version to fail")
+	}
+}
+
+func TestMigrationStore_Plan(t *testing.T) {
+	store := loadStore(t, testMigrations())
+	db := openDB(t)
+
+	if err := store.Migrate(ctx, db, 2); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+
+	steps, err := store.Plan(ctx, db, 0)
+	if err != nil {
+		t.Fatalf("failed to plan: %v", err)
+	}
+	if len(steps) != 2 || steps[0].Version != 2 || steps[0].Up || steps[1].Version != 1 || steps[1].SQL() != `DROP TABLE fruits;` {
+		t.Fatalf("expected to roll back 2 and then 1, got %+v", steps)
+	}
+
+	// Planning doesn't migrate
+	expectVersion(t, store, db, 2)
+}
+
+func TestMigrationStore_RollsBackFailures(t *testing.T) {
+	files := testMigrations()
+	files["migrations/migration_0003_fruit_colour.sql"] = file(`CREATE TABLE colours (name TEXT); INVALID SQL;`)
+	store := loadStore(t, files)
+	db := openDB(t)
+
+	if err := store.Apply(ctx, db); err == nil {
+		t.Fatalf("expected a broken migration to fail")
+	}
+	expectVersion(t, store, db, 2)
+	if hasTable(t, db, "colours") {
+		t.Fatalf("expected the broken migration to be rolled back")
+	}
+}
+
+func TestMigrationStore_DetectsEditedMigrations(t *testing.T) {
+	db := openDB(t)
+	if err := loadStore(t, testMigrations()).Migrate(ctx, db, 2); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+
+	files := testMigrations()
+	files["migrations/migration_0002_vegetables.sql"] = file(`CREATE TABLE vegetables (name TEXT, colour TEXT);`)
+	edited := loadStore(t, files)
+
+	status, err := edited.Status(ctx, db)
+	if err != nil {
+		t.Fatalf("failed to get status: %v", err)
+	}
+	if status.Migrations[0].Edited || !status.Migrations[1].Edited || status.Migrations[2].Edited {
+		t.Fatalf("expected only migration 2 to be edited, got %+v", status.Migrations)
+	}
+	if err := edited.Apply(ctx, db); !errors.Is(err, sqlite.ErrMigrationEdited) {
+		t.Fatalf("expected migrating with an edited migration to return ErrMigrationEdited, got %v", err)
+	}
+}
+
+func TestMigrationStore_DetectsNewerSchema(t *testing.T) {
+	db := openDB(t)
+	if err := loadStore(t, testMigrations()).Apply(ctx, db); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+
+	files := testMigrations()
+	delete(files, "migrations/migration_0003_fruit_colour.sql")
+	delete(files, "migrations/migration_0003_fruit_colour.down.sql")
+	older := loadStore(t, files)
+
+	if err := older.Apply(ctx, db); !errors.Is(err, sqlite.ErrSchemaAhead) {
+		t.Fatalf("expected migrating a newer schema to return ErrSchemaAhead, got %v", err)
+	}
+}
+
+func TestMigrationStore_TrustsUntrackedMigrations(t *testing.T) {
+	db := openDB(t)
+	// Migrated before the history was kept
+	if _, err := db.Exec(`CREATE TABLE fruits (name TEXT); PRAGMA user_version = 1;`); err != nil {
+		t.Fatalf("failed to set up database: %v", err)
+	}
+
+	store := loadStore(t, testMigrations())
+	if err := store.Apply(ctx, db); err != nil {
+		t.Fatalf("failed to apply migrations: %v", err)
+	}
+	status := expectVersion(t, store, db, 3)
+	if !status.Migrations[0].AppliedAt.IsZero() {
+		t.Fatalf("expected the time of untracked migrations to be unknown")
+	}
+}
+
+func TestMigrationStore_LoadErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		files fstest.MapFS
+	}{
+		{"malformed filename", fstest.MapFS{"migrations/0001_fruits.sql": file(`SELECT 1;`)}},
+		{"missing down file", fstest.MapFS{"migrations/migration_0001_fruits.sql": file(`SELECT 1;`)}},
+		{"missing up file", fstest.MapFS{"migrations/migration_0001_fruits.down.sql": file(`SELECT 1;`)}},
+		{"missing version", fstest.MapFS{
+			"migrations/migration_0002_fruits.sql":      file(`SELECT 1;`),
+			"migrations/migration_0002_fruits.down.sql": file(`SELECT 1;`),
+		}},
+		{"mismatched names", fstest.MapFS{
+			"migrations/migration_0001_fruits.sql":          file(`SELECT 1;`),
+			"migrations/migration_0001_vegetables.down.sql": file(`SELECT 1;`),
+		}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var store sqlite.MigrationStore
+			if err := store.Load(test.files); err == nil {
+				t.Fatalf("expected loading to fail")
+			}
+		})
+	}
+}
+
+func TestMigrationsFS_RoundTrip(t *testing.T) {
+	var store sqlite.MigrationStore
+	if err := store.Load(sqlite.MigrationsFS); err != nil {
+		t.Fatalf("failed to load migrations: %v", err)
+	}
+	db := openDB(t)
+
+	for _, target := range []int{store.Latest(), 0, store.Latest()} {
+		if err := store.Migrate(ctx, db, target); err != nil {
+			t.Fatalf("failed to migrate to %d: %v", target, err)
+		}
+		expectVersion(t, &store, db, target)
+	}
+}
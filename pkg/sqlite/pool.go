@@ -0,0 +1,323 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+// DB runs the queries of the repositories, a *sql.DB or a *Pool
+type DB interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+	BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error)
+}
+
+// ErrAcquireTimeout is returned when no connection of a pool frees up in
+// time, e.g. a write outside of a transaction waiting for the writer the
+// transaction holds
+var ErrAcquireTimeout = errors.New("timed out waiting for a database connection")
+
+// PoolOptions configures the connections of a pool
+type PoolOptions struct {
+	// Readers is the number of read-only connections, 4 by default
+	Readers int
+	// AcquireTimeout is how long a query waits for a free connection before
+	// failing with ErrAcquireTimeout, 5s by default
+	AcquireTimeout time.Duration
+	// BusyTimeout is how long a connection waits for a lock held by another
+	// process before failing with SQLITE_BUSY, 5s by default
+	BusyTimeout time.Duration
+	// ForeignKeys enforces the foreign keys
+	ForeignKeys bool
+	// Retries is the number of times a statement or transaction failing with
+	// SQLITE_BUSY is retried, 5 by default
+	Retries int
+	// BaseBackoff is the wait before the first retry, doubled after each one up to MaxBackoff
+	BaseBackoff time.Duration
+	MaxBackoff  time.Duration
+}
+
+func (o *PoolOptions) setDefaults() {
+	if o.Readers <= 0 {
+		o.Readers = 4
+	}
+	if o.AcquireTimeout <= 0 {
+		o.AcquireTimeout = 5 * time.Second
+	}
+	if o.BusyTimeout <= 0 {
+		o.BusyTimeout = 5 * time.Second
+	}
+	if o.Retries <= 0 {
+		o.Retries = 5
+	}
+	if o.BaseBackoff <= 0 {
+		o.BaseBackoff = 10 * time.Millisecond
+	}
+	if o.MaxBackoff <= 0 {
+		o.MaxBackoff = time.Second
+	}
+}
+
+// Pool sends the writes of a database to a single connection, so concurrent
+// writers queue for it instead of failing with "database is locked", and the
+// reads to a pool of read-only connections, which WAL lets run alongside the
+// writer. Transactions take the write lock when they begin, the lock other
+// processes hold is waited for up to the busy timeout and then retried with
+// backoff.
+//
+// It implements the connection pool of gorm, statements other than SELECT
+// and transactions go to the writer. Waiting for a connection is bounded by
+// the acquire timeout, a write outside of the transaction holding the writer
+// fails instead of waiting for it forever.
+type Pool struct {
+	writer  *sql.DB
+	reader  *sql.DB
+	options PoolOptions
+}
+
+// OpenPool opens the database file in WAL mode with a writer and a pool of
+// readers, in-memory databases can't be shared by them
+func OpenPool(path string, options PoolOptions) (*Pool, error) {
+	options.setDefaults()
+	params := url.Values{}
+	params.Set("_journal_mode", "WAL")
+	params.Set("_busy_timeout", fmt.Sprint(options.BusyTimeout.Milliseconds()))
+	if options.ForeignKeys {
+		params.Set("_foreign_keys", "1")
+	}
+
+	// The writer is opened first so the WAL the readers need exists
+	writerParams := cloneValues(params)
+	writerParams.Set("_txlock", "immediate")
+	writer, err := sql.Open(DriverName, "file:"+path+"?"+writerParams.Encode())
+	if err != nil {
+		return nil, err
+	}
+	// Writers wait for the connection in the queue of database/sql
+	writer.SetMaxOpenConns(1)
+	if err := writer.Ping(); err != nil {
+		writer.Close()
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+
+	readerParams := cloneValues(params)
+	readerParams.Set("mode", "ro")
+	readerParams.Set("_query_only", "1")
+	reader, err := sql.Open(DriverName, "file:"+path+"?"+readerParams.Encode())
+	if err != nil {
+		writer.Close()
+		return nil, err
+	}
+	reader.SetMaxOpenConns(options.Readers)
+	reader.SetMaxIdleConns(options.Readers)
+
+	return &Pool{writer: writer, reader: reader, options: options}, nil
+}
+
+func cloneValues(values url.Values) url.Values {
+	clone := url.Values{}
+	for key, value := range values {
+		clone[key] = append([]string(nil), value...)
+	}
+	return clone
+}
+
+// Writer returns the single connection the writes go through
+func (p *Pool) Writer() *sql.DB {
+	return p.writer
+}
+
+// Reader returns the read-only connections
+func (p *Pool) Reader() *sql.DB {
+	return p.reader
+}
+
+// GetDBConn returns the writer, for gorm's DB
+func (p *Pool) GetDBConn() (*sql.DB, error) {
+	return p.writer, nil
+}
+
+func (p *Pool) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	var result sql.Result
+	err := p.retry(ctx, func() error {
+		conn, err := p.acquire(ctx, p.writer)
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+		result, err = conn.ExecContext(ctx, query, args...)
+		return err
+	})
+	return result, err
+}
+
+func (p *Pool) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	db := p.route(query)
+	var rows *sql.Rows
+	err := p.retry(ctx, func() error {
+		acquireCtx := p.acquireContext(ctx)
+		defer acquireCtx.disarm()
+		var err error
+		rows, err = db.QueryContext(acquireCtx, query, args...)
+		return err
+	})
+	return rows, err
+}
+
+// QueryRowContext isn't retried, its error is only known once scanned
+func (p *Pool) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	acquireCtx := p.acquireContext(ctx)
+	defer acquireCtx.disarm()
+	return p.route(query).QueryRowContext(acquireCtx, query, args...)
+}
+
+// PrepareContext prepares on the database, the statements wait for a
+// connection without the acquire timeout
+func (p *Pool) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	return p.route(query).PrepareContext(ctx, query)
+}
+
+// BeginTx begins a transaction on the writer, or on a reader when read-only.
+// Beginning takes the write lock, so the transaction won't fail with
+// SQLITE_BUSY once it begun.
+func (p *Pool) BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error) {
+	db := p.writer
+	if opts != nil && opts.ReadOnly {
+		db = p.reader
+	}
+	var tx *sql.Tx
+	err := p.retry(ctx, func() error {
+		acquireCtx := p.acquireContext(ctx)
+		defer acquireCtx.disarm()
+		var err error
+		tx, err = db.BeginTx(acquireCtx, opts)
+		return err
+	})
+	return tx, err
+}
+
+// acquire waits for a connection of the database up to the acquire timeout
+func (p *Pool) acquire(ctx context.Context, db *sql.DB) (*sql.Conn, error) {
+	acquireCtx, cancel := context.WithTimeout(ctx, p.options.AcquireTimeout)
+	defer cancel()
+	conn, err := db.Conn(acquireCtx)
+	if errors.Is(err, context.DeadlineExceeded) && ctx.Err() == nil {
+		return nil, fmt.Errorf("%w after %s", ErrAcquireTimeout, p.options.AcquireTimeout)
+	}
+	return conn, err
+}
+
+// acquireContext returns a context bounding the wait for a connection by the
+// acquire timeout. The rows and transactions database/sql opens with it keep
+// it, and return the connection when they are closed, so it is disarmed once
+// they are opened: from then on it is only done with the parent.
+func (p *Pool) acquireContext(parent context.Context) *acquireContext {
+	ctx := &acquireContext{Context: parent, done: make(chan struct{}), armed: true}
+	context.AfterFunc(parent, func() { ctx.cancel(nil) })
+	ctx.timer = time.AfterFunc(p.options.AcquireTimeout, func() {
+		ctx.cancel(fmt.Errorf("%w after %s", ErrAcquireTimeout, p.options.AcquireTimeout))
+	})
+	return ctx
+}
+
+type acquireContext struct {
+	context.Context
+	timer *time.Timer
+
+	mu    sync.Mutex
+	done  chan struct{}
+	armed bool
+	err   error
+}
+
+// cancel closes the context with the error, nil for the error of the parent.
+// The timeout no longer does once disarmed.
+func (c *acquireContext) cancel(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err != nil || (err != nil && !c.armed) {
+		return
+	}
+	if err == nil {
+		err = c.Context.Err()
+	}
+	c.err = err
+	close(c.done)
+}
+
+// disarm stops the acquire timeout, the context is done with its parent
+func (c *acquireContext) disarm() {
+	c.timer.Stop()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.armed = false
+}
+
+func (c *acquireContext) Done() <-chan struct{} {
+	return c.done
+}
+
+func (c *acquireContext) Err() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.err
+}
+
+// Close closes the readers and the writer
+func (p *Pool) Close() error {
+	return errors.Join(p.reader.Close(), p.writer.Close())
+}
+
+// route returns the readers for the queries that only read
+func (p *Pool) route(query string) *sql.DB {
+	if isRead(query) {
+		return p.reader
+	}
+	return p.writer
+}
+
+// isRead reports whether the query is a SELECT, statements like INSERT
+// RETURNING query rows too
+func isRead(query string) bool {
+	query = strings.TrimLeft(query, " \t\r\n(")
+	return len(query) >= len("SELECT") && strings.EqualFold(query[:len("SELECT")], "SELECT")
+}
+
+// retry runs fn until it doesn't fail with SQLITE_BUSY, up to the retries,
+// waiting with exponential backoff and jitter between attempts
+func (p *Pool) retry(ctx context.Context, fn func() error) error {
+	backoff := p.options.BaseBackoff
+	for attempt := 0; ; attempt++ {
+		err := fn()
+		if err == nil || !IsBusy(err) || attempt == p.options.Retries {
+			return err
+		}
+
+		wait := backoff/2 + time.Duration(rand.Int63n(int64(backoff/2)+1))
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(wait):
+		}
+		if backoff *= 2; backoff > p.options.MaxBackoff {
+			backoff = p.options.MaxBackoff
+		}
+	}
+}
+
+// IsBusy reports whether the error comes from a lock held by another
+// connection, "database is locked"
+func IsBusy(err error) bool {
+	var sqliteErr sqlite3.Error
+	return errors.As(err, &sqliteErr) && (sqliteErr.Code == sqlite3.ErrBusy || sqliteErr.Code == sqlite3.ErrLocked)
+}
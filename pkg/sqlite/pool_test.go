@@ -0,0 +1,245 @@
+package sqlite_test
+
+import (
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/TomeuUris/recipes-catalog/pkg/sqlite"
+)
+
+func openPool(t testing.TB, path string, options sqlite.PoolOptions) *sqlite.Pool {
+	t.Helper()
+	pool, err := sqlite.OpenPool(path, options)
+	if err != nil {
+		t.Fatalf("failed to open pool: %v", err)
+	}
+	t.Cleanup(func() { pool.Close() })
+	if _, err := pool.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS counters (id INTEGER PRIMARY KEY, value INTEGER NOT NULL)`); err != nil {
+		t.Fatalf("failed to create table: %v", err)
+	}
+	if _, err := pool.ExecContext(ctx, `INSERT OR IGNORE INTO counters (id, value) VALUES (1, 0)`); err != nil {
+		t.Fatalf("failed to insert counter: %v", err)
+	}
+	return pool
+}
+
+// increment reads the counter and writes it back incremented, the pattern of
+// an edit that fails with SQLITE_BUSY when transactions only lock on write
+func increment(db sqlite.DB) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var value int
+	if err := tx.QueryRowContext(ctx, `SELECT value FROM counters WHERE id = 1`).Scan(&value); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `UPDATE counters SET value = ? WHERE id = 1`, value+1); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func counter(t testing.TB, db sqlite.DB) int {
+	t.Helper()
+	var value int
+	if err := db.QueryRowContext(ctx, `SELECT value FROM counters WHERE id = 1`).Scan(&value); err != nil {
+		t.Fatalf("failed to read counter: %v", err)
+	}
+	return value
+}
+
+func TestPool_RoutesReads(t *testing.T) {
+	pool := openPool(t, filepath.Join(t.TempDir(), "catalog.sqlite"), sqlite.PoolOptions{})
+
+	if _, err := pool.Reader().Exec(`UPDATE counters SET value = 1`); err == nil {
+		t.Fatalf("expected the readers to be read-only")
+	}
+
+	// Statements returning rows that write go to the writer
+	var id int64
+	if err := pool.QueryRowContext(ctx, `INSERT INTO counters (value) VALUES (7) RETURNING id`).Scan(&id); err != nil {
+		t.Fatalf("failed to insert counter: %v", err)
+	}
+	rows, err := pool.QueryContext(ctx, `
+		SELECT value FROM counters WHERE id = ?`, id)
+	if err != nil {
+		t.Fatalf("failed to read counter: %v", err)
+	}
+	defer rows.Close()
+	if !rows.Next() {
+		t.Fatalf("expected the readers to see the committed counter")
+	}
+}
+
+func TestPool_ParallelWritersDontFail(t *testing.T) {
+	pool := openPool(t, filepath.Join(t.TempDir(), "catalog.sqlite"), sqlite.PoolOptions{})
+
+	const writers, increments = 16, 25
+	var wg sync.WaitGroup
+	errs := make(chan error, writers*increments)
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < increments; j++ {
+				if err := increment(pool); err != nil {
+					errs <- err
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Fatalf("failed to write: %v", err)
+	}
+	if value := counter(t, pool); value != writers*increments {
+		t.Fatalf("expected %d increments, got %d", writers*increments, value)
+	}
+}
+
+func TestPool_WriteOutsideTheTransactionTimesOut(t *testing.T) {
+	pool := openPool(t, filepath.Join(t.TempDir(), "catalog.sqlite"), sqlite.PoolOptions{AcquireTimeout: 50 * time.Millisecond})
+
+	tx, err := pool.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatalf("failed to begin: %v", err)
+	}
+	defer tx.Rollback()
+
+	// The transaction holds the only writer connection
+	start := time.Now()
+	if _, err := pool.ExecContext(ctx, `UPDATE counters SET value = 1`); !errors.Is(err, sqlite.ErrAcquireTimeout) {
+		t.Fatalf("expected the write to time out, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("expected the write to fail after the acquire timeout, took %s", elapsed)
+	}
+	var id int64
+	if err := pool.QueryRowContext(ctx, `INSERT INTO counters (value) VALUES (7) RETURNING id`).Scan(&id); !errors.Is(err, sqlite.ErrAcquireTimeout) {
+		t.Fatalf("expected the insert to time out, got %v", err)
+	}
+
+	// Once it is done the writes go through
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("failed to commit: %v", err)
+	}
+	if _, err := pool.ExecContext(ctx, `UPDATE counters SET value = 1`); err != nil {
+		t.Fatalf("failed to write after the transaction: %v", err)
+	}
+	if value := counter(t, pool); value != 1 {
+		t.Fatalf("expected the counter written, got %d", value)
+	}
+}
+
+func TestPool_ReleasesTheReaderWhenTheRowsClose(t *testing.T) {
+	pool := openPool(t, filepath.Join(t.TempDir(), "catalog.sqlite"), sqlite.PoolOptions{Readers: 1, AcquireTimeout: 50 * time.Millisecond})
+
+	rows, err := pool.QueryContext(ctx, `SELECT value FROM counters`)
+	if err != nil {
+		t.Fatalf("failed to read counters: %v", err)
+	}
+	defer rows.Close()
+	// The rows hold the only reader, past the acquire timeout
+	if _, err := pool.QueryContext(ctx, `SELECT value FROM counters`); !errors.Is(err, sqlite.ErrAcquireTimeout) {
+		t.Fatalf("expected the read to time out, got %v", err)
+	}
+	if !rows.Next() {
+		t.Fatalf("expected the rows to outlive the acquire timeout, got %v", rows.Err())
+	}
+
+	if err := rows.Close(); err != nil {
+		t.Fatalf("failed to close rows: %v", err)
+	}
+	if value := counter(t, pool); value != 0 {
+		t.Fatalf("expected the counter read, got %d", value)
+	}
+}
+
+func TestPool_RetriesLocksOfOtherProcesses(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "catalog.sqlite")
+	pool := openPool(t, path, sqlite.PoolOptions{BusyTimeout: 10 * time.Millisecond, Retries: 8})
+
+	// Another process holds the write lock for a while
+	other := openFileDB(t, path)
+	conn, err := other.Conn(ctx)
+	if err != nil {
+		t.Fatalf("failed to get connection: %v", err)
+	}
+	defer conn.Close()
+	if _, err := conn.ExecContext(ctx, `BEGIN IMMEDIATE`); err != nil {
+		t.Fatalf("failed to lock: %v", err)
+	}
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		conn.ExecContext(ctx, `COMMIT`)
+	}()
+
+	if err := increment(pool); err != nil {
+		t.Fatalf("expected the write to wait for the lock, got %v", err)
+	}
+
+	// Without retries left the busy error is returned
+	impatient := openPool(t, path, sqlite.PoolOptions{BusyTimeout: time.Millisecond, Retries: 1, BaseBackoff: time.Millisecond})
+	if _, err := conn.ExecContext(ctx, `BEGIN IMMEDIATE`); err != nil {
+		t.Fatalf("failed to lock: %v", err)
+	}
+	defer conn.ExecContext(ctx, `ROLLBACK`)
+	if err := increment(impatient); !sqlite.IsBusy(err) {
+		t.Fatalf("expected a busy error, got %v", err)
+	}
+}
+
+// BenchmarkParallelWriters compares edits from parallel writers sharing a
+// pool of connections, which fail with "database is locked" when two read
+// before writing, with edits queued for the single writer of a Pool
+func BenchmarkParallelWriters(b *testing.B) {
+	for _, bench := range []struct {
+		name string
+		open func(b *testing.B, path string) sqlite.DB
+	}{
+		{"SharedPool", func(b *testing.B, path string) sqlite.DB {
+			db, err := sql.Open(sqlite.DriverName, "file:"+path+"?_journal_mode=WAL&_busy_timeout=5000")
+			if err != nil {
+				b.Fatalf("failed to open database: %v", err)
+			}
+			b.Cleanup(func() { db.Close() })
+			openPool(b, path, sqlite.PoolOptions{}).Close()
+			return db
+		}},
+		{"SingleWriter", func(b *testing.B, path string) sqlite.DB {
+			return openPool(b, path, sqlite.PoolOptions{})
+		}},
+	} {
+		b.Run(bench.name, func(b *testing.B) {
+			db := bench.open(b, filepath.Join(b.TempDir(), "catalog.sqlite"))
+			var failures atomic.Int64
+
+			b.SetParallelism(4)
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					if err := increment(db); err != nil {
+						failures.Add(1)
+					}
+				}
+			})
+			b.StopTimer()
+
+			b.ReportMetric(float64(failures.Load())/float64(b.N), "failures/op")
+			b.ReportMetric(float64(int64(b.N)-failures.Load())/b.Elapsed().Seconds(), "writes/s")
+			if written := counter(b, db); written+int(failures.Load()) != b.N {
+				b.Fatalf("expected %d writes, %d written and %d failed", b.N, written, failures.Load())
+			}
+		})
+	}
+}
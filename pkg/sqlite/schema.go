@@ -0,0 +1,233 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ErrSchemaDrift is returned when the tables of a migrated database differ
+// from the ones the migrations create
+var ErrSchemaDrift = errors.New("database schema differs from the migrations")
+
+// SchemaDifference describes how a table differs from the migrations
+type SchemaDifference struct {
+	Table   string
+	Message string
+}
+
+// Diff compares the tables of a database migrated to the latest version with
+// the ones of a new database migrated by the store. Tables the migrations
+// don't create, like the ones of other repositories, are ignored.
+func (s *MigrationStore) Diff(ctx context.Context, db *sql.DB) ([]SchemaDifference, error) {
+	expected, err := Open(":memory:")
+	if err != nil {
+		return nil, err
+	}
+	defer expected.Close()
+	// Every connection to :memory: opens a new database
+	expected.SetMaxOpenConns(1)
+	if err := s.Apply(ctx, expected); err != nil {
+		return nil, err
+	}
+
+	want, err := readTables(ctx, expected)
+	if err != nil {
+		return nil, err
+	}
+	got, err := readTables(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(want))
+	for name := range want {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var differences []SchemaDifference
+	for _, name := range names {
+		for _, message := range diffTable(want[name], got[name]) {
+			differences = append(differences, SchemaDifference{Table: name, Message: message})
+		}
+	}
+	return differences, nil
+}
+
+// Verify returns an error when the database is ahead of the migrations, an
+// applied migration was edited or, once every migration is applied, its
+// tables differ from the ones the migrations create. Pending migrations are
+// fine.
+func (s *MigrationStore) Verify(ctx context.Context, db *sql.DB) error {
+	status, err := s.Status(ctx, db)
+	if err != nil {
+		return err
+	}
+	if err := status.Check(); err != nil {
+		return err
+	}
+	if status.Version < status.Latest {
+		// The tables differ until the migrations are applied
+		return nil
+	}
+
+	differences, err := s.Diff(ctx, db)
+	if err != nil {
+		return err
+	}
+	if len(differences) == 0 {
+		return nil
+	}
+	messages := make([]string, len(differences))
+	for i, difference := range differences {
+		messages[i] = difference.Table + ": " + difference.Message
+	}
+	return fmt.Errorf("%w: %s", ErrSchemaDrift, strings.Join(messages, "; "))
+}
+
+// table describes the columns and indexes of a table by name
+type table struct {
+	columns map[string]string
+	indexes map[string]string
+}
+
+// readTables describes the tables of the database, but the internal ones of
+// SQLite
+func readTables(ctx context.Context, db *sql.DB) (map[string]*table, error) {
+	names, err := queryStrings(ctx, db, `SELECT name FROM sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite_%'`)
+	if err != nil {
+		return nil, err
+	}
+
+	tables := map[string]*table{}
+	for _, name := range names {
+		t := &table{columns: map[string]string{}, indexes: map[string]string{}}
+		rows, err := db.QueryContext(ctx, `SELECT name, type, "notnull", dflt_value, pk FROM pragma_table_info(?)`, name)
+		if err != nil {
+			return nil, err
+		}
+		for rows.Next() {
+			var column, columnType string
+			var notNull, pk int
+			var defaultValue sql.NullString
+			if err := rows.Scan(&column, &columnType, &notNull, &defaultValue, &pk); err != nil {
+				rows.Close()
+				return nil, err
+			}
+			t.columns[column] = describeColumn(columnType, notNull != 0, defaultValue, pk != 0)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+
+		rows, err = db.QueryContext(ctx, `SELECT l.name, l."unique", l.partial, group_concat(i.name, ', ')
+			FROM pragma_index_list(?) AS l, pragma_index_info(l.name) AS i
+			GROUP BY l.name ORDER BY l.name`, name)
+		if err != nil {
+			return nil, err
+		}
+		for rows.Next() {
+			var index, columns string
+			var unique, partial int
+			if err := rows.Scan(&index, &unique, &partial, &columns); err != nil {
+				rows.Close()
+				return nil, err
+			}
+			t.indexes[index] = describeIndex(columns, unique != 0, partial != 0)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+		tables[name] = t
+	}
+	return tables, nil
+}
+
+func describeColumn(columnType string, notNull bool, defaultValue sql.NullString, pk bool) string {
+	description := strings.ToUpper(columnType)
+	if pk {
+		description += " PRIMARY KEY"
+	}
+	if notNull {
+		description += " NOT NULL"
+	}
+	if defaultValue.Valid {
+		description += " DEFAULT " + defaultValue.String
+	}
+	return strings.TrimSpace(description)
+}
+
+func describeIndex(columns string, unique, partial bool) string {
+	description := "(" + columns + ")"
+	if unique {
+		description = "UNIQUE " + description
+	}
+	if partial {
+		description += " WHERE ..."
+	}
+	return description
+}
+
+// diffTable describes how the table differs from the expected one
+func diffTable(want, got *table) []string {
+	if got == nil {
+		return []string{"missing table"}
+	}
+
+	var messages []string
+	messages = append(messages, diffMap("column", want.columns, got.columns)...)
+	messages = append(messages, diffMap("index", want.indexes, got.indexes)...)
+	return messages
+}
+
+func diffMap(kind string, want, got map[string]string) []string {
+	var messages []string
+	for _, name := range sortedKeys(want) {
+		description, ok := got[name]
+		switch {
+		case !ok:
+			messages = append(messages, fmt.Sprintf("missing %s %s %s", kind, name, want[name]))
+		case description != want[name]:
+			messages = append(messages, fmt.Sprintf("%s %s is %s, expected %s", kind, name, description, want[name]))
+		}
+	}
+	for _, name := range sortedKeys(got) {
+		if _, ok := want[name]; !ok {
+			messages = append(messages, fmt.Sprintf("unexpected %s %s %s", kind, name, got[name]))
+		}
+	}
+	return messages
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func queryStrings(ctx context.Context, db *sql.DB, query string, args ...interface{}) ([]string, error) {
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var values []string
+	for rows.Next() {
+		var value string
+		if err := rows.Scan(&value); err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	return values, rows.Err()
+}
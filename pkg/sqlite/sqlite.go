@@ -2,13 +2,38 @@ package sqlite
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
 
-	_ "github.com/mattn/go-sqlite3"
+	"github.com/mattn/go-sqlite3"
+
+	"github.com/TomeuUris/recipes-catalog/pkg/entity"
 )
 
+// DriverName is the sqlite3 driver registering the functions migrations use:
+// normalize_name(name) returns entity.NormalizeName(name) and slugify(name)
+// returns entity.Slugify(name)
+const DriverName = "sqlite3_catalog"
+
+func init() {
+	sql.Register(DriverName, &sqlite3.SQLiteDriver{
+		ConnectHook: func(conn *sqlite3.SQLiteConn) error {
+			if err := conn.RegisterFunc("normalize_name", entity.NormalizeName, true); err != nil {
+				return err
+			}
+			return conn.RegisterFunc("slugify", entity.Slugify, true)
+		},
+	})
+}
+
+// IsUniqueViolation reports whether the error comes from a UNIQUE constraint
+func IsUniqueViolation(err error) bool {
+	var sqliteErr sqlite3.Error
+	return errors.As(err, &sqliteErr) && sqliteErr.ExtendedCode == sqlite3.ErrConstraintUnique
+}
+
 func Open(path string) (*sql.DB, error) {
-	db, err := sql.Open("sqlite3", path)
+	db, err := sql.Open(DriverName, path)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,202 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/TomeuUris/recipes-catalog/pkg/entity"
+	"github.com/TomeuUris/recipes-catalog/pkg/event"
+	repo "github.com/TomeuUris/recipes-catalog/pkg/repo/webhook"
+)
+
+// Encoder serializes an event into the body sent to webhooks
+type Encoder func(e *event.Event) ([]byte, error)
+
+// Dispatcher turns catalog events into webhook deliveries and sends them,
+// retrying failed attempts with exponential backoff.
+type Dispatcher struct {
+	hooks      repo.Repo
+	deliveries repo.DeliveryRepo
+	encode     Encoder
+	client     *http.Client
+
+	// MaxAttempts is the number of attempts before a delivery is marked as failed
+	MaxAttempts int
+	// BaseBackoff is the wait after the first failed attempt, doubled after each one up to MaxBackoff
+	BaseBackoff time.Duration
+	MaxBackoff  time.Duration
+	// PollInterval is how often due deliveries are looked up
+	PollInterval time.Duration
+	// BatchSize is the maximum number of deliveries attempted per poll
+	BatchSize int
+
+	now func() time.Time
+}
+
+func NewDispatcher(hooks repo.Repo, deliveries repo.DeliveryRepo, encode Encoder) *Dispatcher {
+	return &Dispatcher{
+		hooks:        hooks,
+		deliveries:   deliveries,
+		encode:       encode,
+		client:       &http.Client{Timeout: 10 * time.Second},
+		MaxAttempts:  8,
+		BaseBackoff:  10 * time.Second,
+		MaxBackoff:   time.Hour,
+		PollInterval: time.Second,
+		BatchSize:    50,
+		now:          time.Now,
+	}
+}
+
+// Run sends due deliveries until the context is done, the events are enqueued
+// by the outbox
+func (d *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.PollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := d.DeliverDue(ctx); err != nil {
+				log.Printf("webhook: failed to deliver: %v", err)
+			}
+		}
+	}
+}
+
+// Enqueue creates a pending delivery of the event for every active webhook
+// subscribed to it. An event enqueued again, like a message the outbox
+// dispatches twice, keeps its deliveries.
+func (d *Dispatcher) Enqueue(ctx context.Context, e *event.Event) error {
+	active := true
+	hooks, err := d.hooks.FindByFilter(ctx, &repo.FindFilter{Active: &active})
+	if err != nil {
+		return err
+	}
+
+	var body []byte
+	for _, hook := range hooks {
+		if !hook.Matches(e.Name()) {
+			continue
+		}
+		if body == nil {
+			if body, err = d.encode(e); err != nil {
+				return err
+			}
+		}
+
+		delivery := &entity.WebhookDelivery{
+			WebhookID:     hook.ID,
+			EventID:       e.ID,
+			EventType:     e.Name(),
+			Payload:       string(body),
+			Status:        entity.DeliveryPending,
+			NextAttemptAt: d.now(),
+		}
+		if err := d.deliveries.Add(ctx, delivery); err != nil && !errors.Is(err, entity.ErrAlreadyExists) {
+			return err
+		}
+	}
+	return nil
+}
+
+// DeliverDue attempts every pending delivery whose next attempt is due
+func (d *Dispatcher) DeliverDue(ctx context.Context) error {
+	due, err := d.deliveries.FindDue(ctx, d.now(), d.BatchSize)
+	if err != nil {
+		return err
+	}
+
+	for _, delivery := range due {
+		if err := d.Attempt(ctx, delivery); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Attempt sends the delivery once and records the outcome, scheduling a retry on failure
+func (d *Dispatcher) Attempt(ctx context.Context, delivery *entity.WebhookDelivery) error {
+	hook, err := d.hooks.FindByID(ctx, delivery.WebhookID)
+	if err != nil {
+		if !entity.IsErrNotFound(err) {
+			return err
+		}
+		// The webhook was removed, there is nobody to deliver to
+		delivery.Status = entity.DeliveryFailed
+		delivery.LastError = "webhook not found"
+		return d.deliveries.Edit(ctx, delivery)
+	}
+
+	delivery.Attempts++
+	delivery.ResponseStatus, err = d.send(ctx, hook, delivery)
+	if err == nil {
+		now := d.now()
+		delivery.Status = entity.DeliverySucceeded
+		delivery.LastError = ""
+		delivery.DeliveredAt = &now
+	} else {
+		delivery.LastError = err.Error()
+		if delivery.Attempts >= d.MaxAttempts {
+			delivery.Status = entity.DeliveryFailed
+		} else {
+			delivery.NextAttemptAt = d.now().Add(Backoff(delivery.Attempts, d.BaseBackoff, d.MaxBackoff))
+		}
+		log.Printf("webhook: delivery %d to %s failed (attempt %d/%d): %v",
+			delivery.ID, hook.URL, delivery.Attempts, d.MaxAttempts, err)
+	}
+
+	return d.deliveries.Edit(ctx, delivery)
+}
+
+// send posts the signed payload and returns the response status code
+func (d *Dispatcher) send(ctx context.Context, hook *entity.Webhook, delivery *entity.WebhookDelivery) (int, error) {
+	body := []byte(delivery.Payload)
+	timestamp := d.now().Unix()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "recipes-catalog-webhooks/1")
+	req.Header.Set(HeaderEvent, delivery.EventType)
+	req.Header.Set(HeaderDelivery, strconv.FormatInt(delivery.ID, 10))
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderSignature, Sign(hook.Secret, timestamp, body))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return resp.StatusCode, nil
+}
+
+// Backoff returns the wait before the attempt following the given number of failed attempts
+func Backoff(attempts int, base, max time.Duration) time.Duration {
+	wait := base
+	for i := 1; i < attempts; i++ {
+		wait *= 2
+		if wait >= max {
+			return max
+		}
+	}
+	if wait > max {
+		return max
+	}
+	return wait
+}
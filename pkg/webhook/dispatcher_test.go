@@ -0,0 +1,227 @@
+package webhook_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/TomeuUris/recipes-catalog/pkg/entity"
+	"github.com/TomeuUris/recipes-catalog/pkg/event"
+	repo "github.com/TomeuUris/recipes-catalog/pkg/repo/webhook"
+	"github.com/TomeuUris/recipes-catalog/pkg/webhook"
+)
+
+var ctx = context.Background()
+
+func encode(e *event.Event) ([]byte, error) {
+	return json.Marshal(map[string]interface{}{"id": e.ID, "type": e.Name()})
+}
+
+func setupDispatcher(t *testing.T) (*webhook.Dispatcher, repo.Repo, repo.DeliveryRepo) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to connect database: %v", err)
+	}
+	sqlDB, _ := db.DB()
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+	if err := repo.RunMigrations(db); err != nil {
+		t.Fatalf("failed to migrate database schema: %v", err)
+	}
+
+	hooks := repo.NewGormRepo(db)
+	deliveries := repo.NewGormDeliveryRepo(db)
+	return webhook.NewDispatcher(hooks, deliveries, encode), hooks, deliveries
+}
+
+type receiver struct {
+	mu       sync.Mutex
+	statuses []int
+	requests []*http.Request
+	bodies   [][]byte
+}
+
+func (rc *receiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	body, _ := io.ReadAll(r.Body)
+	rc.requests = append(rc.requests, r)
+	rc.bodies = append(rc.bodies, body)
+	status := http.StatusOK
+	if len(rc.statuses) > 0 {
+		status, rc.statuses = rc.statuses[0], rc.statuses[1:]
+	}
+	w.WriteHeader(status)
+}
+
+func TestDispatcher_EnqueueOnlyMatchingWebhooks(t *testing.T) {
+	dispatcher, hooks, deliveries := setupDispatcher(t)
+
+	for _, hook := range []*entity.Webhook{
+		{URL: "http://example.com/all", Secret: "s", Active: true},
+		{URL: "http://example.com/recipes", Secret: "s", Events: []string{"recipe.*"}, Active: true},
+		{URL: "http://example.com/units", Secret: "s", Events: []string{"cooking_unit.deleted"}, Active: true},
+		{URL: "http://example.com/inactive", Secret: "s", Active: false},
+	} {
+		if err := hooks.Add(ctx, hook); err != nil {
+			t.Fatalf("failed to add webhook: %v", err)
+		}
+	}
+
+	err := dispatcher.Enqueue(ctx, &event.Event{ID: 1, Entity: event.EntityRecipe, Action: event.ActionCreated})
+	if err != nil {
+		t.Fatalf("failed to enqueue event: %v", err)
+	}
+
+	due, err := deliveries.FindDue(ctx, time.Now(), 10)
+	if err != nil {
+		t.Fatalf("failed to find due deliveries: %v", err)
+	}
+	if len(due) != 2 {
+		t.Fatalf("expected 2 deliveries, got %d", len(due))
+	}
+}
+
+func TestDispatcher_EnqueueTwiceDeliversOnce(t *testing.T) {
+	dispatcher, hooks, deliveries := setupDispatcher(t)
+
+	rc := &receiver{}
+	server := httptest.NewServer(rc)
+	defer server.Close()
+
+	hook := &entity.Webhook{URL: server.URL, Secret: "s", Active: true}
+	if err := hooks.Add(ctx, hook); err != nil {
+		t.Fatalf("failed to add webhook: %v", err)
+	}
+
+	// The outbox dispatches the message again after the delivery
+	e := &event.Event{ID: 3, Entity: event.EntityRecipe, Action: event.ActionCreated}
+	for i := 0; i < 2; i++ {
+		if err := dispatcher.Enqueue(ctx, e); err != nil {
+			t.Fatalf("failed to enqueue event: %v", err)
+		}
+		if err := dispatcher.DeliverDue(ctx); err != nil {
+			t.Fatalf("failed to deliver: %v", err)
+		}
+	}
+
+	history, err := deliveries.FindByFilter(ctx, &repo.DeliveryFilter{WebhookID: hook.ID})
+	if err != nil || len(history) != 1 {
+		t.Fatalf("expected 1 delivery, got %d (%v)", len(history), err)
+	}
+	if len(rc.requests) != 1 {
+		t.Fatalf("expected the event sent once, got %d requests", len(rc.requests))
+	}
+}
+
+func TestDispatcher_RetriesAndSigns(t *testing.T) {
+	dispatcher, hooks, deliveries := setupDispatcher(t)
+	dispatcher.BaseBackoff = time.Hour
+
+	rc := &receiver{statuses: []int{http.StatusInternalServerError}}
+	server := httptest.NewServer(rc)
+	defer server.Close()
+
+	hook := &entity.Webhook{URL: server.URL, Secret: "top-secret", Active: true}
+	if err := hooks.Add(ctx, hook); err != nil {
+		t.Fatalf("failed to add webhook: %v", err)
+	}
+	if err := dispatcher.Enqueue(ctx, &event.Event{ID: 7, Entity: event.EntityIngredient, Action: event.ActionUpdated}); err != nil {
+		t.Fatalf("failed to enqueue event: %v", err)
+	}
+
+	// The first attempt fails and is scheduled after the backoff
+	if err := dispatcher.DeliverDue(ctx); err != nil {
+		t.Fatalf("failed to deliver: %v", err)
+	}
+	history, err := deliveries.FindByFilter(ctx, &repo.DeliveryFilter{WebhookID: hook.ID})
+	if err != nil || len(history) != 1 {
+		t.Fatalf("expected 1 delivery, got %d (%v)", len(history), err)
+	}
+	delivery := history[0]
+	if delivery.Status != entity.DeliveryPending || delivery.Attempts != 1 || delivery.ResponseStatus != 500 {
+		t.Fatalf("unexpected delivery after failure: %+v", delivery)
+	}
+	if time.Until(delivery.NextAttemptAt) < 59*time.Minute {
+		t.Fatalf("expected next attempt in an hour, got %v", delivery.NextAttemptAt)
+	}
+	due, _ := deliveries.FindDue(ctx, time.Now(), 10)
+	if len(due) != 0 {
+		t.Fatalf("expected no due deliveries during the backoff, got %d", len(due))
+	}
+
+	// The retry succeeds
+	if err := dispatcher.Attempt(ctx, delivery); err != nil {
+		t.Fatalf("failed to attempt delivery: %v", err)
+	}
+	delivery, _ = deliveries.FindByID(ctx, delivery.ID)
+	if delivery.Status != entity.DeliverySucceeded || delivery.Attempts != 2 || delivery.DeliveredAt == nil {
+		t.Fatalf("unexpected delivery after success: %+v", delivery)
+	}
+
+	// Check the request is signed
+	req, body := rc.requests[1], rc.bodies[1]
+	if req.Header.Get(webhook.HeaderEvent) != "ingredient.updated" {
+		t.Fatalf("unexpected event header %q", req.Header.Get(webhook.HeaderEvent))
+	}
+	timestamp, _ := strconv.ParseInt(req.Header.Get(webhook.HeaderTimestamp), 10, 64)
+	if !webhook.Verify("top-secret", timestamp, body, req.Header.Get(webhook.HeaderSignature)) {
+		t.Fatalf("invalid signature %q", req.Header.Get(webhook.HeaderSignature))
+	}
+}
+
+func TestDispatcher_GivesUpAfterMaxAttempts(t *testing.T) {
+	dispatcher, hooks, deliveries := setupDispatcher(t)
+	dispatcher.MaxAttempts = 2
+	dispatcher.BaseBackoff = 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	hook := &entity.Webhook{URL: server.URL, Secret: "s", Active: true}
+	if err := hooks.Add(ctx, hook); err != nil {
+		t.Fatalf("failed to add webhook: %v", err)
+	}
+	if err := dispatcher.Enqueue(ctx, &event.Event{ID: 1, Entity: event.EntityRecipe, Action: event.ActionDeleted}); err != nil {
+		t.Fatalf("failed to enqueue event: %v", err)
+	}
+
+	for i := 0; i < 3; i++ {
+		if err := dispatcher.DeliverDue(ctx); err != nil {
+			t.Fatalf("failed to deliver: %v", err)
+		}
+	}
+
+	history, _ := deliveries.FindByFilter(ctx, &repo.DeliveryFilter{WebhookID: hook.ID})
+	if len(history) != 1 || history[0].Status != entity.DeliveryFailed || history[0].Attempts != 2 {
+		t.Fatalf("expected a failed delivery after 2 attempts, got %+v", history[0])
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{1, 10 * time.Second},
+		{2, 20 * time.Second},
+		{3, 40 * time.Second},
+		{10, time.Minute},
+	}
+	for _, tt := range tests {
+		if got := webhook.Backoff(tt.attempts, 10*time.Second, time.Minute); got != tt.want {
+			t.Errorf("Backoff(%d) = %v, want %v", tt.attempts, got, tt.want)
+		}
+	}
+}